@@ -145,3 +145,45 @@ Generate watch namespace argument
 {{- "" }}
 {{- end }}
 {{- end }}
+
+{{/*
+Agent selector labels
+*/}}
+{{- define "kubecrsh.agentSelectorLabels" -}}
+app.kubernetes.io/name: {{ include "kubecrsh.name" . }}-agent
+app.kubernetes.io/instance: {{ .Release.Name }}
+{{- end }}
+
+{{/*
+Agent labels
+*/}}
+{{- define "kubecrsh.agentLabels" -}}
+helm.sh/chart: {{ include "kubecrsh.chart" . }}
+{{ include "kubecrsh.agentSelectorLabels" . }}
+app.kubernetes.io/component: agent
+{{- if .Chart.AppVersion }}
+app.kubernetes.io/version: {{ .Chart.AppVersion | quote }}
+{{- end }}
+app.kubernetes.io/managed-by: {{ .Release.Service }}
+{{- end }}
+
+{{/*
+Generate agent args
+*/}}
+{{- define "kubecrsh.agentArgs" -}}
+- agent
+- --config=/config/config.yaml
+- --http-addr={{ .Values.agent.httpAddr }}
+- --log-root={{ .Values.agent.logRoot }}
+{{- if .Values.agent.kmsg.enabled }}
+- --kmsg-source={{ .Values.agent.kmsg.source }}
+{{- end }}
+{{- if .Values.agent.forwardUrl }}
+- --forward-url={{ .Values.agent.forwardUrl }}
+{{- else }}
+- --forward-url=http://{{ include "kubecrsh.fullname" . }}.{{ include "kubecrsh.namespace" . }}.svc:{{ .Values.service.port }}
+{{- end }}
+{{- range .Values.agent.extraArgs }}
+- {{ . | quote }}
+{{- end }}
+{{- end }}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/config"
+	"github.com/kadirbelkuyu/kubecrsh/internal/daemon"
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	"github.com/kadirbelkuyu/kubecrsh/internal/kernel"
+	"github.com/kadirbelkuyu/kubecrsh/internal/redaction"
+	"github.com/kadirbelkuyu/kubecrsh/internal/reporter"
+	"github.com/kadirbelkuyu/kubecrsh/pkg/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Run kubecrsh as a node-local agent",
+	Long: `Run kubecrsh on a single node (typically as a DaemonSet).
+Watches only pods scheduled on this node, reads container logs directly
+from the kubelet log directory (including rotated .gz files) and forwards
+reports to the central kubecrsh daemon. With --kmsg-source the agent also
+parses kernel OOM-killer records and attaches them to matching reports.`,
+	RunE: runAgent,
+}
+
+var (
+	agentNodeName     string
+	agentLogRoot      string
+	agentForwardURL   string
+	agentForwardToken string
+	agentHTTPAddr     string
+	agentKmsgSource   string
+)
+
+func init() {
+	agentCmd.Flags().StringVar(&agentNodeName, "node-name", "", "name of the node this agent runs on (default: $NODE_NAME)")
+	agentCmd.Flags().StringVar(&agentLogRoot, "log-root", "", "kubelet pod log directory (default /var/log/pods)")
+	agentCmd.Flags().StringVar(&agentForwardURL, "forward-url", "", "URL of the central kubecrsh daemon to forward reports to")
+	agentCmd.Flags().StringVar(&agentForwardToken, "forward-token", "", "bearer token for the central kubecrsh daemon")
+	agentCmd.Flags().StringVar(&agentKmsgSource, "kmsg-source", "", "kernel log to parse for OOM-killer records (e.g. /dev/kmsg)")
+	agentCmd.Flags().StringVar(&agentHTTPAddr, "http-addr", ":8081", "HTTP server address for metrics and health")
+
+	rootCmd.AddCommand(agentCmd)
+}
+
+func runAgent(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if kubeconfig != "" {
+		cfg.Kubeconfig = kubeconfig
+	}
+	if k8sContext != "" {
+		cfg.Context = k8sContext
+	}
+	if agentNodeName != "" {
+		cfg.Agent.NodeName = agentNodeName
+	}
+	if cfg.Agent.NodeName == "" {
+		cfg.Agent.NodeName = os.Getenv("NODE_NAME")
+	}
+	if agentLogRoot != "" {
+		cfg.Agent.LogRoot = agentLogRoot
+	}
+	if agentForwardURL != "" {
+		cfg.Agent.ForwardURL = agentForwardURL
+	}
+	if agentForwardToken != "" {
+		cfg.Agent.ForwardToken = agentForwardToken
+	}
+
+	if agentKmsgSource != "" {
+		cfg.Agent.KmsgSource = agentKmsgSource
+	}
+
+	if cfg.Agent.NodeName == "" {
+		return fmt.Errorf("node name is required (set --node-name or NODE_NAME)")
+	}
+
+	client, err := kubernetes.NewClient(kubernetes.ClientConfig{
+		Kubeconfig: cfg.Kubeconfig,
+		Context:    cfg.Context,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	var storage reporter.Storage
+	if cfg.Agent.ForwardURL != "" {
+		storage, err = reporter.NewRemoteStore(reporter.RemoteConfig{
+			URL:   cfg.Agent.ForwardURL,
+			Token: cfg.Agent.ForwardToken,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create remote store: %w", err)
+		}
+	} else {
+		keys, err := loadKeyring(cfg)
+		if err != nil {
+			return err
+		}
+		storage, err = newReportStore(cfg, keys)
+		if err != nil {
+			return fmt.Errorf("failed to create report store: %w", err)
+		}
+	}
+
+	redactor, err := redaction.New(cfg.Reports.Redaction)
+	if err != nil {
+		return fmt.Errorf("failed to init redaction: %w", err)
+	}
+
+	var redactorCfg interface {
+		Apply(report *domain.ForensicReport)
+	}
+	if redactor != nil {
+		redactorCfg = redactor
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var oomMatcher interface {
+		Match(crash domain.PodCrash) []domain.OOMKill
+	}
+	if cfg.Agent.KmsgSource != "" {
+		tracker := kernel.NewOOMTracker()
+		reader := kernel.NewReader(cfg.Agent.KmsgSource)
+		go func() {
+			if err := reader.Run(ctx, tracker.Add); err != nil {
+				fmt.Printf("Kernel log reader stopped: %v\n", err)
+			}
+		}()
+		oomMatcher = tracker
+	}
+
+	logBuffer, err := newLogBuffer(client, cfg.Watch.LogBuffer)
+	if err != nil {
+		return err
+	}
+
+	retention, err := retentionPolicy(cfg.Reports)
+	if err != nil {
+		return err
+	}
+
+	srv := daemon.New(client, daemon.Config{
+		Namespace:   cfg.Namespace,
+		Reasons:     cfg.Watch.Reasons,
+		NodeName:    cfg.Agent.NodeName,
+		NodeLogRoot: cfg.Agent.LogRoot,
+		HTTPAddr:    agentHTTPAddr,
+		Storage:     storage,
+		Retention:   retention,
+		LogBuffer:   logBuffer,
+		OOMMatcher:  oomMatcher,
+		Diagnoser:   newDiagnoser(cfg.Diagnosis),
+		Classifier:  newClassifier(cfg.Severity, client),
+		Redactor:    redactorCfg,
+	})
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		fmt.Println("\nShutting down...")
+		cancel()
+	}()
+
+	fmt.Printf("Starting kubecrsh agent on %s\n", agentHTTPAddr)
+	fmt.Printf("Node: %s, log root: %s\n", cfg.Agent.NodeName, cfg.Agent.LogRoot)
+	if cfg.Agent.KmsgSource != "" {
+		fmt.Printf("Kernel OOM records: %s\n", cfg.Agent.KmsgSource)
+	}
+	if cfg.Agent.ForwardURL != "" {
+		fmt.Printf("Forwarding reports to: %s\n", cfg.Agent.ForwardURL)
+	}
+
+	if err := srv.Start(ctx); err != nil {
+		return fmt.Errorf("agent error: %w", err)
+	}
+
+	return nil
+}
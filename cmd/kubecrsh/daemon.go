@@ -5,16 +5,24 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 
+	"github.com/kadirbelkuyu/kubecrsh/internal/collector"
 	"github.com/kadirbelkuyu/kubecrsh/internal/config"
 	"github.com/kadirbelkuyu/kubecrsh/internal/daemon"
+	"github.com/kadirbelkuyu/kubecrsh/internal/diagnosis"
 	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
 	"github.com/kadirbelkuyu/kubecrsh/internal/notifier"
 	"github.com/kadirbelkuyu/kubecrsh/internal/redaction"
 	"github.com/kadirbelkuyu/kubecrsh/internal/reporter"
+	"github.com/kadirbelkuyu/kubecrsh/internal/severity"
 	"github.com/kadirbelkuyu/kubecrsh/pkg/kubernetes"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	k8s "k8s.io/client-go/kubernetes"
 )
 
 var daemonCmd = &cobra.Command{
@@ -66,32 +74,83 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create kubernetes client: %w", err)
 	}
 
-	store, err := reporter.NewStore(cfg.Reports.Path, reporter.WithCompression(cfg.Reports.Compression))
+	keys, err := loadKeyring(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to create report store: %w", err)
+		return err
+	}
+	if keys != nil {
+		fmt.Printf("Report encryption enabled (key %s)\n", keys.ActiveKeyID())
 	}
 
-	var storage reporter.Storage = store
+	storage, err := newReportStore(cfg, keys)
+	if err != nil {
+		return fmt.Errorf("failed to create report store: %w", err)
+	}
+	backends := []reporter.Backend{{Name: reportBackendName(cfg.Reports), Store: storage, Required: true}}
 
 	if cfg.Elasticsearch.Enabled {
-		esCfg := reporter.ElasticConfig{
-			Addresses: cfg.Elasticsearch.Addresses,
-			Username:  cfg.Elasticsearch.Username,
-			Password:  cfg.Elasticsearch.Password,
-			CloudID:   cfg.Elasticsearch.CloudID,
-			APIKey:    cfg.Elasticsearch.APIKey,
-			Index:     cfg.Elasticsearch.Index,
+		esCfg := elasticConfig(cfg.Elasticsearch)
+
+		if bulk := cfg.Elasticsearch.Bulk; bulk.Enabled {
+			metrics := reporter.NewElasticBulkMetrics()
+			prometheus.MustRegister(metrics.Indexed, metrics.Retried, metrics.Dropped)
+
+			esCfg.Bulk = reporter.ElasticBulkConfig{
+				Enabled:       true,
+				Actions:       bulk.Actions,
+				FlushInterval: bulk.FlushInterval,
+				MaxBuffered:   bulk.MaxBuffered,
+				SpoolDir:      bulk.SpoolDir,
+				SpoolKeys:     keys,
+				MaxRetries:    bulk.MaxRetries,
+				Metrics:       metrics,
+			}
 		}
 
 		esStore, err := reporter.NewElasticStore(esCfg)
 		if err != nil {
 			return fmt.Errorf("failed to create elasticsearch store: %w", err)
 		}
+		defer func() {
+			if err := esStore.Close(); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+		}()
 
-		storage = reporter.NewMultiStore(store, esStore)
+		backends = append(backends, reporter.Backend{Name: "elasticsearch", Store: esStore, Required: cfg.Elasticsearch.Required})
 		fmt.Printf("Elasticsearch storage enabled: %v\n", cfg.Elasticsearch.Addresses)
 	}
 
+	if cfg.Reports.CRD.Enabled && backends[0].Name != "crd" {
+		crdStore, err := newCRDStore(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create crash report resource store: %w", err)
+		}
+		backends = append(backends, reporter.Backend{Name: "crd", Store: crdStore})
+		fmt.Println("CrashReport resources enabled")
+	}
+
+	if len(backends) > 1 {
+		multi, err := newMultiStore(backends, cfg.Reports, keys)
+		if err != nil {
+			return fmt.Errorf("failed to create report stores: %w", err)
+		}
+		defer multi.Close()
+		storage = multi
+	}
+
+	ledger, err := openLedger(cfg.Reports.Path, cfg.Reports.Ledger)
+	if err != nil {
+		return err
+	}
+	if ledger != nil {
+		status := ledger.Status()
+		fmt.Printf("Report ledger enabled: %d entries\n", status.Entries)
+		for _, p := range status.Problems {
+			fmt.Printf("Warning: report ledger: %s\n", p)
+		}
+	}
+
 	var notifiers []notifier.Notifier
 
 	if slackWebhook != "" {
@@ -122,16 +181,36 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 		redactorCfg = redactor
 	}
 
+	logBuffer, err := newLogBuffer(client, cfg.Watch.LogBuffer)
+	if err != nil {
+		return err
+	}
+
+	retention, err := retentionPolicy(cfg.Reports)
+	if err != nil {
+		return err
+	}
+
+	if cfg.API.IngestEnabled && strings.TrimSpace(cfg.API.Token) == "" {
+		return fmt.Errorf("api.ingest_enabled requires api.token")
+	}
+
 	daemonCfg := daemon.Config{
 		Namespace:         cfg.Namespace,
 		Reasons:           cfg.Watch.Reasons,
 		HTTPAddr:          httpAddr,
 		Notifiers:         notifiers,
 		Storage:           storage,
+		Ledger:            ledger,
 		APIReportsEnabled: cfg.API.ReportsEnabled,
+		APIIngestEnabled:  cfg.API.IngestEnabled,
+		APITriageEnabled:  cfg.API.TriageEnabled,
 		APIToken:          cfg.API.Token,
 		APIAllowFull:      cfg.API.AllowFull,
-		ReportRetention:   cfg.Reports.Retention,
+		Retention:         retention,
+		LogBuffer:         logBuffer,
+		Diagnoser:         newDiagnoser(cfg.Diagnosis),
+		Classifier:        newClassifier(cfg.Severity, client),
 		Redactor:          redactorCfg,
 	}
 
@@ -158,3 +237,167 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func newLogBuffer(client k8s.Interface, cfg config.LogBufferConfig) (*collector.LogBuffer, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	buffer, err := collector.NewLogBuffer(client, collector.LogBufferConfig{
+		Annotation:    cfg.Annotation,
+		Selector:      cfg.Selector,
+		MaxLines:      cfg.MaxLines,
+		MaxBytes:      cfg.MaxBytes,
+		MaxContainers: cfg.MaxContainers,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to init log buffer: %w", err)
+	}
+
+	fmt.Printf("Pre-crash log buffer enabled (annotation %s=true", cfg.Annotation)
+	if cfg.Selector != "" {
+		fmt.Printf(", selector %s", cfg.Selector)
+	}
+	fmt.Println(")")
+
+	return buffer, nil
+}
+
+func elasticConfig(cfg config.ElasticsearchConfig) reporter.ElasticConfig {
+	return reporter.ElasticConfig{
+		Addresses:      cfg.Addresses,
+		Username:       cfg.Username,
+		Password:       cfg.Password,
+		CloudID:        cfg.CloudID,
+		APIKey:         cfg.APIKey,
+		Index:          cfg.Index,
+		IndexPeriod:    cfg.IndexPeriod,
+		ILMPolicy:      cfg.ILMPolicy,
+		ILMDeleteAfter: cfg.ILMDeleteAfter,
+	}
+}
+
+func newMultiStore(backends []reporter.Backend, cfg config.ReportsConfig, keys *reporter.Keyring) (*reporter.MultiStore, error) {
+	metrics := reporter.NewMultiStoreMetrics()
+	prometheus.MustRegister(metrics.Up, metrics.WriteFailures, metrics.OutboxPending, metrics.OutboxLag, metrics.Replayed, metrics.Reconciled)
+
+	opts := []reporter.MultiOption{
+		reporter.WithMultiStoreMetrics(metrics),
+		reporter.WithReconcile(cfg.Reconcile.Interval, cfg.Reconcile.Window),
+		reporter.WithTombstones(filepath.Join(cfg.Path, reporter.TombstonesFileName)),
+	}
+	if cfg.Outbox.Enabled {
+		dir := cfg.Outbox.Dir
+		if dir == "" {
+			dir = filepath.Join(cfg.Path, "outbox")
+		}
+		opts = append(opts,
+			reporter.WithOutbox(dir),
+			reporter.WithOutboxEncryption(keys),
+			reporter.WithOutboxRetry(cfg.Outbox.RetryInterval, cfg.Outbox.MaxBackoff),
+		)
+	}
+
+	return reporter.NewMultiStore(backends, opts...)
+}
+
+// hasSecondaryStores reports whether the daemon writes reports to other
+// backends next to the report store.
+func hasSecondaryStores(cfg *config.Config) bool {
+	return cfg.Elasticsearch.Enabled || (cfg.Reports.CRD.Enabled && reportBackendName(cfg.Reports) != "crd")
+}
+
+// reportBackendName names the configured report store among the backends of
+// a MultiStore.
+func reportBackendName(cfg config.ReportsConfig) string {
+	if backend := strings.ToLower(strings.TrimSpace(cfg.Backend)); backend != "" {
+		return backend
+	}
+	return "file"
+}
+
+func newReportStore(root *config.Config, keys *reporter.Keyring) (reporter.Storage, error) {
+	cfg := root.Reports
+	switch backend := strings.ToLower(strings.TrimSpace(cfg.Backend)); backend {
+	case "", "file":
+		return reporter.NewStore(cfg.Path, reporter.WithCompression(cfg.Compression), reporter.WithEncryption(keys))
+	case "sqlite":
+		path := cfg.SQLitePath
+		if path == "" {
+			dir := cfg.Path
+			if dir == "" {
+				dir = "reports"
+			}
+			path = filepath.Join(dir, "reports.db")
+		}
+		store, err := reporter.NewSQLiteStore(path, reporter.WithImportDir(cfg.Path, reporter.WithImportKeys(keys)))
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "s3":
+		store, err := reporter.NewS3Store(reporter.S3Config{
+			Endpoint:             cfg.S3.Endpoint,
+			Region:               cfg.S3.Region,
+			Bucket:               cfg.S3.Bucket,
+			Prefix:               cfg.S3.Prefix,
+			AccessKeyID:          cfg.S3.AccessKeyID,
+			SecretAccessKey:      cfg.S3.SecretAccessKey,
+			SessionToken:         cfg.S3.SessionToken,
+			ServerSideEncryption: cfg.S3.ServerSideEncryption,
+			KMSKeyID:             cfg.S3.KMSKeyID,
+			PartSize:             cfg.S3.PartSize,
+			Timeout:              cfg.S3.Timeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "crd":
+		return newCRDStore(root)
+	default:
+		return nil, fmt.Errorf("unknown reports backend %q", backend)
+	}
+}
+
+func newCRDStore(cfg *config.Config) (*reporter.CRDStore, error) {
+	clientCfg := kubernetes.ClientConfig{Kubeconfig: cfg.Kubeconfig, Context: cfg.Context}
+	if kubeconfig != "" {
+		clientCfg.Kubeconfig = kubeconfig
+	}
+	if k8sContext != "" {
+		clientCfg.Context = k8sContext
+	}
+	client, err := kubernetes.NewDynamicClient(clientCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	return reporter.NewCRDStore(client, reporter.CRDConfig{
+		Namespace:       cfg.Namespace,
+		MaxBodyBytes:    cfg.Reports.CRD.MaxBodyBytes,
+		OwnerReferences: cfg.Reports.CRD.OwnerReferences,
+	})
+}
+
+func newClassifier(cfg config.SeverityConfig, client k8s.Interface) interface {
+	Classify(ctx context.Context, report *domain.ForensicReport, pod *corev1.Pod) domain.Severity
+} {
+	if !cfg.Enabled {
+		return nil
+	}
+	return severity.New(
+		severity.WithClient(client),
+		severity.WithCriticalityLabel(cfg.CriticalityLabel),
+		severity.WithFrequencyWindow(cfg.FrequencyWindow),
+	)
+}
+
+func newDiagnoser(cfg config.DiagnosisConfig) interface {
+	Diagnose(report *domain.ForensicReport, pod *corev1.Pod) []domain.Finding
+} {
+	if !cfg.Enabled {
+		return nil
+	}
+	return diagnosis.New(diagnosis.WithDisabledRules(cfg.DisabledRules...))
+}
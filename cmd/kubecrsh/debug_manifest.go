@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/config"
+	"github.com/kadirbelkuyu/kubecrsh/internal/debugpod"
+	"github.com/kadirbelkuyu/kubecrsh/pkg/kubernetes"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var debugManifestCmd = &cobra.Command{
+	Use:   "debug-manifest <report-id>",
+	Short: "Generate a sleeping copy of a crashed pod for debugging",
+	Long: `Generate a Pod manifest from the crashed pod's spec with the crashed
+container's command replaced by sleep, probes removed, images pinned to the
+digest that was running, env and volumes kept and labels stripped so the copy
+does not join Services. Pipe the output to kubectl apply -f -.
+
+By default the live pod is read from the cluster. With --server the manifest
+is requested from a kubecrsh daemon, which can also use the last pod object it
+observed when the pod no longer exists.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDebugManifest,
+}
+
+var (
+	debugManifestServer string
+	debugManifestToken  string
+	debugManifestOutput string
+)
+
+func init() {
+	debugManifestCmd.Flags().StringVar(&debugManifestServer, "server", "", "URL of a kubecrsh daemon to request the manifest from")
+	debugManifestCmd.Flags().StringVar(&debugManifestToken, "token", "", "bearer token for the kubecrsh daemon API")
+	debugManifestCmd.Flags().StringVarP(&debugManifestOutput, "output", "o", "", "write the manifest to a file instead of stdout")
+
+	rootCmd.AddCommand(debugManifestCmd)
+}
+
+func runDebugManifest(cmd *cobra.Command, args []string) error {
+	id := args[0]
+
+	var manifest []byte
+	var err error
+	if debugManifestServer != "" {
+		manifest, err = fetchDebugManifest(debugManifestServer, debugManifestToken, id)
+	} else {
+		manifest, err = buildDebugManifest(id)
+	}
+	if err != nil {
+		return err
+	}
+
+	if debugManifestOutput != "" {
+		if err := os.WriteFile(debugManifestOutput, manifest, 0o600); err != nil {
+			return fmt.Errorf("failed to write manifest: %w", err)
+		}
+		return nil
+	}
+
+	_, err = os.Stdout.Write(manifest)
+	return err
+}
+
+func buildDebugManifest(id string) ([]byte, error) {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if kubeconfig != "" {
+		cfg.Kubeconfig = kubeconfig
+	}
+	if k8sContext != "" {
+		cfg.Context = k8sContext
+	}
+
+	keys, err := loadKeyring(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := newReportStore(cfg, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report store: %w", err)
+	}
+
+	report, err := store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kubernetes.NewClient(kubernetes.ClientConfig{
+		Kubeconfig: cfg.Kubeconfig,
+		Context:    cfg.Context,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pod, err := client.CoreV1().Pods(report.Crash.Namespace).Get(ctx, report.Crash.PodName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod %s/%s (use --server to build from the daemon's last observed pod): %w",
+			report.Crash.Namespace, report.Crash.PodName, err)
+	}
+
+	return debugpod.YAML(debugpod.Build(pod, report))
+}
+
+func fetchDebugManifest(server, token, id string) ([]byte, error) {
+	endpoint := strings.TrimRight(server, "/") + "/reports/" + url.PathEscape(id) + "/debug-manifest"
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request debug manifest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read debug manifest: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(string(body))
+		if msg == "" {
+			msg = http.StatusText(resp.StatusCode)
+		}
+		return nil, fmt.Errorf("daemon returned status %d: %s", resp.StatusCode, msg)
+	}
+
+	return body, nil
+}
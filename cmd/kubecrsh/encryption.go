@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/config"
+	"github.com/kadirbelkuyu/kubecrsh/internal/reporter"
+	"github.com/kadirbelkuyu/kubecrsh/pkg/kubernetes"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var reencryptCmd = &cobra.Command{
+	Use:   "reencrypt",
+	Short: "Encrypt stored reports with the active encryption key",
+	Long: `Encrypt every report of the file store that is still in plaintext and
+seal reports encrypted with an older key again, so that they all use the key
+set by reports.encryption.key_id.
+
+To rotate keys, add the new key next to the old one, point key_id at it,
+restart the daemon and run reencrypt. The old key can be removed once
+reencrypt reports no failures.`,
+	RunE: runReencrypt,
+}
+
+func init() {
+	rootCmd.AddCommand(reencryptCmd)
+}
+
+func runReencrypt(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if backend := reportBackendName(cfg.Reports); backend != "file" {
+		return fmt.Errorf("reencrypt only supports the file backend, not %s", backend)
+	}
+
+	keys, err := loadKeyring(cfg)
+	if err != nil {
+		return err
+	}
+	if keys == nil {
+		return fmt.Errorf("no encryption key configured (set reports.encryption.key_file or reports.encryption.secret)")
+	}
+
+	store, err := reporter.NewStore(cfg.Reports.Path, reporter.WithCompression(cfg.Reports.Compression), reporter.WithEncryption(keys))
+	if err != nil {
+		return fmt.Errorf("failed to create report store: %w", err)
+	}
+
+	res, err := store.Reencrypt()
+	fmt.Printf("Encrypted %d, resealed %d, unchanged %d, failed %d reports (key %s)\n",
+		res.Encrypted, res.Resealed, res.Unchanged, res.Failed, keys.ActiveKeyID())
+	return err
+}
+
+// loadKeyring loads the report encryption keys, or returns nil when none
+// are configured. When encryption is required, a missing key is an error so
+// that reports are never written in plaintext by mistake.
+func loadKeyring(cfg *config.Config) (*reporter.Keyring, error) {
+	enc := cfg.Reports.Encryption
+
+	var keys *reporter.Keyring
+	var err error
+	switch {
+	case enc.KeyFile != "":
+		keys, err = reporter.LoadKeyring(enc.KeyFile, enc.KeyID)
+	case enc.Secret != "":
+		keys, err = loadSecretKeyring(cfg, enc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load report encryption keys: %w", err)
+	}
+
+	if keys == nil {
+		if enc.Required {
+			return nil, fmt.Errorf("report encryption is required but no key is configured (set reports.encryption.key_file or reports.encryption.secret)")
+		}
+		return nil, nil
+	}
+
+	if backend := reportBackendName(cfg.Reports); backend != "file" {
+		if enc.Required {
+			return nil, fmt.Errorf("report encryption is required but the %s backend does not support it", backend)
+		}
+		fmt.Printf("Warning: report encryption only applies to the file backend, %s reports are not encrypted\n", backend)
+	}
+
+	for _, name := range plaintextSecondaries(cfg) {
+		if enc.Required {
+			return nil, fmt.Errorf("report encryption is required but reports are also written unencrypted to %s", name)
+		}
+		fmt.Printf("Warning: reports written to %s are not encrypted\n", name)
+	}
+
+	return keys, nil
+}
+
+// plaintextSecondaries names the stores that receive full reports next to
+// the primary backend without kubecrsh encrypting them.
+func plaintextSecondaries(cfg *config.Config) []string {
+	var names []string
+	if cfg.Elasticsearch.Enabled {
+		names = append(names, "elasticsearch")
+	}
+	if cfg.Reports.CRD.Enabled && reportBackendName(cfg.Reports) != "crd" && cfg.Reports.CRD.MaxBodyBytes > 0 {
+		names = append(names, "CrashReport resources")
+	}
+	return names
+}
+
+func loadSecretKeyring(cfg *config.Config, enc config.EncryptionConfig) (*reporter.Keyring, error) {
+	ns, name, ok := strings.Cut(enc.Secret, "/")
+	if !ok || ns == "" || name == "" {
+		return nil, fmt.Errorf("invalid secret %q: want <namespace>/<name>", enc.Secret)
+	}
+
+	clientCfg := kubernetes.ClientConfig{Kubeconfig: cfg.Kubeconfig, Context: cfg.Context}
+	if kubeconfig != "" {
+		clientCfg.Kubeconfig = kubeconfig
+	}
+	if k8sContext != "" {
+		clientCfg.Context = k8sContext
+	}
+	client, err := kubernetes.NewClient(clientCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	secret, err := client.CoreV1().Secrets(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %s: %w", enc.Secret, err)
+	}
+
+	keys := make(map[string][]byte, len(secret.Data))
+	for id, data := range secret.Data {
+		key, err := reporter.ParseEncryptionKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s key %s: %w", enc.Secret, id, err)
+		}
+		keys[id] = key
+	}
+	return reporter.NewKeyring(keys, enc.KeyID)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/config"
+	"github.com/kadirbelkuyu/kubecrsh/internal/export"
+	"github.com/kadirbelkuyu/kubecrsh/internal/redaction"
+	"github.com/kadirbelkuyu/kubecrsh/pkg/kubernetes"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <report-id>",
+	Short: "Export a report as Markdown, HTML or a tar.gz bundle",
+	Long: `Render a report for sharing outside the cluster.
+
+  markdown  a Markdown document for post-mortems and tickets
+  html      a single self-contained HTML page
+  bundle    a tar.gz with report.json, logs/*.log, events.json, the pod spec
+            as pod.yaml and manifest.json with the SHA-256 of every file
+
+The file is written to the current directory under a name derived from the
+report unless --output is given; use --output - for stdout. Bundles include
+pod.yaml when the pod can still be read from the cluster, or when exporting
+through a daemon with --server that saw the pod.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExport,
+}
+
+var (
+	exportFormat string
+	exportOutput string
+	exportServer string
+	exportToken  string
+)
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", export.FormatMarkdown, "export format: markdown, html or bundle")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "file to write, - for stdout (default: derived from the report)")
+	exportCmd.Flags().StringVar(&exportServer, "server", "", "URL of a kubecrsh daemon to export the report from")
+	exportCmd.Flags().StringVar(&exportToken, "token", "", "bearer token for the kubecrsh daemon API")
+
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	id := args[0]
+
+	format, err := export.ParseFormat(exportFormat)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	var name string
+	if exportServer != "" {
+		data, name, err = fetchExport(exportServer, exportToken, id, format)
+	} else {
+		data, name, err = buildExport(id, format)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch exportOutput {
+	case "-":
+		_, err = os.Stdout.Write(data)
+		return err
+	case "":
+		exportOutput = name
+	}
+
+	if err := os.WriteFile(exportOutput, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported report %s to %s\n", id, exportOutput)
+	return nil
+}
+
+func buildExport(id, format string) ([]byte, string, error) {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if kubeconfig != "" {
+		cfg.Kubeconfig = kubeconfig
+	}
+	if k8sContext != "" {
+		cfg.Context = k8sContext
+	}
+
+	keys, err := loadKeyring(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+
+	store, err := newReportStore(cfg, keys)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create report store: %w", err)
+	}
+
+	report, err := store.Load(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var opts []export.Option
+	if format == export.FormatBundle {
+		redactor, err := redaction.New(cfg.Reports.Redaction)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to init redaction: %w", err)
+		}
+		if redactor != nil {
+			opts = append(opts, export.WithRedactor(redactor))
+		}
+
+		client, err := kubernetes.NewClient(kubernetes.ClientConfig{
+			Kubeconfig: cfg.Kubeconfig,
+			Context:    cfg.Context,
+		})
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			pod, getErr := client.CoreV1().Pods(report.Crash.Namespace).Get(ctx, report.Crash.PodName, metav1.GetOptions{})
+			cancel()
+			err = getErr
+			if err == nil {
+				opts = append(opts, export.WithPod(pod))
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: bundle will not include pod.yaml: %v\n", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := export.Write(&buf, report, format, opts...); err != nil {
+		return nil, "", fmt.Errorf("failed to export report: %w", err)
+	}
+	return buf.Bytes(), export.FileName(report, format), nil
+}
+
+func fetchExport(server, token, id, format string) ([]byte, string, error) {
+	endpoint := strings.TrimRight(server, "/") + "/reports/" + url.PathEscape(id) + "/export?format=" + url.QueryEscape(format)
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to request export: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read export: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(string(body))
+		if msg == "" {
+			msg = http.StatusText(resp.StatusCode)
+		}
+		return nil, "", fmt.Errorf("daemon returned status %d: %s", resp.StatusCode, msg)
+	}
+
+	name := filepath.Base("kubecrsh-" + id + export.Extension(format))
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		name = filepath.Base(params["filename"])
+	}
+	return body, name, nil
+}
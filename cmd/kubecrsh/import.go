@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/config"
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	"github.com/kadirbelkuyu/kubecrsh/internal/reporter"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <path>...",
+	Short: "Import reports from bundles, report files or a reports directory",
+	Long: `Load reports collected elsewhere into the configured report store. Each
+path is a bundle written by kubecrsh export -f bundle, a report file (.json
+or .json.gz, encrypted with the configured keys or not) or a directory of
+them, such as the reports directory of another file store.
+
+Reports are migrated to the current schema and validated; bundles are
+checked against the hashes in their manifest. Report IDs are kept, unless
+the store already holds a different report with the same ID: the report is
+then stored under a new ID, with the original in origin.originalID.
+Reports already imported are skipped, so the command can be rerun.
+
+With --cluster, reports are tagged with the cluster they came from in
+origin.cluster. Reports that already name a source cluster keep it.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runImport,
+}
+
+var (
+	importCluster string
+	importOutput  string
+)
+
+func init() {
+	importCmd.Flags().StringVar(&importCluster, "cluster", "", "name of the cluster the reports were collected in")
+	importCmd.Flags().StringVarP(&importOutput, "output", "o", "", "output format: json")
+
+	rootCmd.AddCommand(importCmd)
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	if importOutput != "" && importOutput != "json" {
+		return fmt.Errorf("unsupported output format %q", importOutput)
+	}
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if kubeconfig != "" {
+		cfg.Kubeconfig = kubeconfig
+	}
+	if k8sContext != "" {
+		cfg.Context = k8sContext
+	}
+
+	keys, err := loadKeyring(cfg)
+	if err != nil {
+		return err
+	}
+	store, err := newReportStore(cfg, keys)
+	if err != nil {
+		return fmt.Errorf("failed to create report store: %w", err)
+	}
+
+	opts := []reporter.ImportOption{
+		reporter.WithSourceCluster(importCluster),
+		reporter.WithImportKeys(keys),
+	}
+	ledger, err := openLedger(cfg.Reports.Path, cfg.Reports.Ledger)
+	if err != nil {
+		return err
+	}
+	if ledger != nil {
+		opts = append(opts, reporter.WithImportHook(func(report *domain.ForensicReport) error {
+			_, err := ledger.Record(report)
+			return err
+		}))
+	}
+
+	res, err := reporter.Import(store, args, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to import reports: %w", err)
+	}
+
+	if importOutput == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(res); err != nil {
+			return err
+		}
+	} else {
+		for _, item := range res.Reports {
+			switch item.Action {
+			case reporter.ImportFailed:
+				fmt.Printf("%s  failed: %s\n", item.Source, item.Error)
+			case reporter.ImportRemapped:
+				fmt.Printf("%s  remapped %s to %s\n", item.Source, item.OriginalID, item.ID)
+			default:
+				fmt.Printf("%s  %s %s\n", item.Source, item.Action, item.ID)
+			}
+		}
+		fmt.Printf("Imported %d, remapped %d, skipped %d, failed %d reports\n",
+			res.Imported, res.Remapped, res.Skipped, res.Failed)
+	}
+
+	if res.Failed > 0 {
+		return fmt.Errorf("failed to import %d of %d reports", res.Failed, len(res.Reports))
+	}
+	return nil
+}
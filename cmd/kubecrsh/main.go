@@ -10,7 +10,6 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/kadirbelkuyu/kubecrsh/internal/config"
 	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
-	"github.com/kadirbelkuyu/kubecrsh/internal/reporter"
 	"github.com/kadirbelkuyu/kubecrsh/internal/tui"
 	"github.com/kadirbelkuyu/kubecrsh/internal/watcher"
 	"github.com/kadirbelkuyu/kubecrsh/pkg/kubernetes"
@@ -89,7 +88,12 @@ func runWatch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create kubernetes client: %w", err)
 	}
 
-	store, err := reporter.NewStore(cfg.Reports.Path)
+	keys, err := loadKeyring(cfg)
+	if err != nil {
+		return err
+	}
+
+	store, err := newReportStore(cfg, keys)
 	if err != nil {
 		return fmt.Errorf("failed to create report store: %w", err)
 	}
@@ -157,7 +161,12 @@ func runList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create kubernetes client: %w", err)
 	}
 
-	store, err := reporter.NewStore(cfg.Reports.Path)
+	keys, err := loadKeyring(cfg)
+	if err != nil {
+		return err
+	}
+
+	store, err := newReportStore(cfg, keys)
 	if err != nil {
 		return fmt.Errorf("failed to create report store: %w", err)
 	}
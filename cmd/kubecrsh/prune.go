@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/config"
+	"github.com/kadirbelkuyu/kubecrsh/internal/reporter"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Apply the report retention policy now",
+	Long: `Delete reports older than reports.retention and apply the quotas of
+reports.retention_policy: the newest max_per_workload reports of each
+workload and max_per_namespace of each namespace are kept, the oldest are
+deleted once the rest add up to max_bytes, and reports older than
+strip_logs_after keep their summary but lose their logs.
+
+With --dry-run nothing is changed and the reports that would be deleted or
+stripped are listed. The daemon applies the same policy every hour.`,
+	RunE: runPrune,
+}
+
+var (
+	pruneDryRun bool
+	pruneOutput string
+)
+
+func init() {
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "only show what would be deleted or stripped")
+	pruneCmd.Flags().StringVarP(&pruneOutput, "output", "o", "", "output format: json")
+
+	rootCmd.AddCommand(pruneCmd)
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	if pruneOutput != "" && pruneOutput != "json" {
+		return fmt.Errorf("unsupported output format %q", pruneOutput)
+	}
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	policy, err := retentionPolicy(cfg.Reports)
+	if err != nil {
+		return err
+	}
+	policy.DryRun = policy.DryRun || pruneDryRun
+	if policy.IsZero() {
+		return fmt.Errorf("no retention configured (set reports.retention or reports.retention_policy)")
+	}
+
+	keys, err := loadKeyring(cfg)
+	if err != nil {
+		return err
+	}
+	store, err := newReportStore(cfg, keys)
+	if err != nil {
+		return fmt.Errorf("failed to create report store: %w", err)
+	}
+
+	res, err := reporter.ApplyRetention(store, policy)
+	if err != nil {
+		return fmt.Errorf("failed to prune reports: %w", err)
+	}
+
+	// The daemon would copy reports deleted here back from the backends
+	// that only prune by age.
+	if !policy.DryRun && res.Deleted > 0 && hasSecondaryStores(cfg) {
+		if err := reporter.RecordTombstones(filepath.Join(cfg.Reports.Path, reporter.TombstonesFileName), res.Actions); err != nil {
+			return fmt.Errorf("failed to record pruned reports: %w", err)
+		}
+	}
+
+	if !policy.DryRun && res.Stripped > 0 {
+		if err := recordStripped(store, cfg.Reports, res.Actions); err != nil {
+			return err
+		}
+	}
+
+	if pruneOutput == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+
+	verb := map[string]string{reporter.RetentionDelete: "deleted", reporter.RetentionStrip: "stripped"}
+	if policy.DryRun {
+		verb = map[string]string{reporter.RetentionDelete: "would delete", reporter.RetentionStrip: "would strip"}
+	}
+	for _, a := range res.Actions {
+		fmt.Printf("%s  %s/%s  %s (%s)\n", a.ID, a.Namespace, a.Workload, verb[a.Action], a.Reason)
+	}
+	fmt.Printf("Deleted %d, stripped %d, kept %d, failed %d reports; %d bytes reclaimed\n",
+		res.Deleted, res.Stripped, res.Kept, res.Failed, res.BytesReclaimed)
+	if policy.DryRun {
+		fmt.Println("Dry run: nothing was changed")
+	}
+	return nil
+}
+
+// recordStripped records reports whose logs were stripped in the ledger
+// again as superseded, so that verify does not flag them as modified.
+func recordStripped(store reporter.Storage, cfg config.ReportsConfig, actions []reporter.PruneAction) error {
+	ledger, err := openLedger(cfg.Path, cfg.Ledger)
+	if err != nil || ledger == nil {
+		return err
+	}
+	for _, a := range actions {
+		if a.Action != reporter.RetentionStrip {
+			continue
+		}
+		report, err := store.Load(a.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load report %s: %w", a.ID, err)
+		}
+		if _, err := ledger.Supersede(report, reporter.LedgerReasonStripped); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retentionPolicy builds the retention policy from reports.retention and
+// reports.retention_policy.
+func retentionPolicy(cfg config.ReportsConfig) (reporter.RetentionPolicy, error) {
+	rp := cfg.RetentionPolicy
+	policy := reporter.RetentionPolicy{
+		MaxAge:          cfg.Retention,
+		MaxPerNamespace: rp.MaxPerNamespace,
+		MaxPerWorkload:  rp.MaxPerWorkload,
+		StripLogsAfter:  rp.StripLogsAfter,
+		DryRun:          rp.DryRun,
+	}
+	if rp.MaxBytes != "" {
+		q, err := resource.ParseQuantity(rp.MaxBytes)
+		if err != nil {
+			return policy, fmt.Errorf("invalid reports.retention_policy.max_bytes %q: %w", rp.MaxBytes, err)
+		}
+		policy.MaxBytes = q.Value()
+	}
+	if policy.MaxBytes < 0 || policy.MaxPerNamespace < 0 || policy.MaxPerWorkload < 0 || policy.StripLogsAfter < 0 {
+		return policy, fmt.Errorf("reports.retention_policy values must not be negative")
+	}
+	return policy, nil
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/config"
+	"github.com/kadirbelkuyu/kubecrsh/internal/reporter"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <text>",
+	Short: "Search the logs, events and warnings of stored reports",
+	Long: `Search the logs, previous logs, last words, event messages and warnings
+of stored reports. Words and "quoted phrases" must all occur; OR accepts
+either of two terms, a leading - or NOT excludes one, a trailing * matches a
+prefix and logs:, previous_log:, last_words:, event_messages: or warnings:
+limits a term to one field.
+
+By default the configured report store is searched, together with
+Elasticsearch when it is enabled. With --server the search runs on a
+kubecrsh daemon through its /reports/search endpoint.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+var (
+	searchNamespace string
+	searchSince     string
+	searchLimit     int
+	searchServer    string
+	searchToken     string
+	searchOutput    string
+)
+
+func init() {
+	searchCmd.Flags().StringVarP(&searchNamespace, "namespace", "n", "", "only search reports from this namespace")
+	searchCmd.Flags().StringVar(&searchSince, "since", "", "only search reports collected after this duration ago or RFC3339 time")
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 20, "maximum number of reports to show")
+	searchCmd.Flags().StringVar(&searchServer, "server", "", "URL of a kubecrsh daemon to search")
+	searchCmd.Flags().StringVar(&searchToken, "token", "", "bearer token for the kubecrsh daemon API")
+	searchCmd.Flags().StringVarP(&searchOutput, "output", "o", "", "output format: json")
+
+	rootCmd.AddCommand(searchCmd)
+}
+
+// searchResultItem is the shape of a /reports/search item, which is also
+// what -o json prints.
+type searchResultItem struct {
+	ID          string    `json:"id"`
+	Namespace   string    `json:"namespace"`
+	PodName     string    `json:"podName"`
+	Container   string    `json:"container"`
+	Reason      string    `json:"reason"`
+	ExitCode    int32     `json:"exitCode"`
+	CollectedAt time.Time `json:"collectedAt"`
+	Highlights  []string  `json:"highlights,omitempty"`
+}
+
+type searchResponse struct {
+	Items      []searchResultItem `json:"items"`
+	Total      int                `json:"total"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	text := strings.Join(args, " ")
+	if searchOutput != "" && searchOutput != "json" {
+		return fmt.Errorf("unsupported output format %q", searchOutput)
+	}
+	if searchLimit <= 0 || searchLimit > reporter.MaxQueryLimit {
+		return fmt.Errorf("limit must be between 1 and %d", reporter.MaxQueryLimit)
+	}
+
+	var resp searchResponse
+	var err error
+	if searchServer != "" {
+		resp, err = fetchSearch(searchServer, searchToken, text)
+	} else {
+		resp, err = searchLocal(text)
+	}
+	if err != nil {
+		return err
+	}
+
+	if searchOutput == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(resp)
+	}
+
+	for _, item := range resp.Items {
+		fmt.Printf("%s  %s/%s  %s (exit %d)  %s\n",
+			item.CollectedAt.Local().Format("2006-01-02 15:04:05"),
+			item.Namespace, item.PodName, item.Reason, item.ExitCode, item.ID)
+		for _, line := range item.Highlights {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+	fmt.Printf("%d of %d matching reports\n", len(resp.Items), resp.Total)
+	return nil
+}
+
+func searchLocal(text string) (searchResponse, error) {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return searchResponse{}, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	keys, err := loadKeyring(cfg)
+	if err != nil {
+		return searchResponse{}, err
+	}
+
+	store, err := newReportStore(cfg, keys)
+	if err != nil {
+		return searchResponse{}, fmt.Errorf("failed to create report store: %w", err)
+	}
+	if cfg.Elasticsearch.Enabled {
+		esStore, err := reporter.NewElasticStore(elasticConfig(cfg.Elasticsearch))
+		if err != nil {
+			return searchResponse{}, fmt.Errorf("failed to connect to elasticsearch: %w", err)
+		}
+		store, err = reporter.NewMultiStore([]reporter.Backend{
+			{Name: reportBackendName(cfg.Reports), Store: store, Required: true},
+			{Name: "elasticsearch", Store: esStore},
+		})
+		if err != nil {
+			return searchResponse{}, err
+		}
+	}
+
+	q := reporter.SearchQuery{
+		Query: reporter.Query{Namespace: searchNamespace, Limit: searchLimit},
+		Text:  text,
+	}
+	if searchSince != "" {
+		if q.Since, err = parseSince(searchSince); err != nil {
+			return searchResponse{}, err
+		}
+	}
+
+	res, err := reporter.SearchReports(store, q)
+	if err != nil {
+		return searchResponse{}, err
+	}
+
+	resp := searchResponse{Items: make([]searchResultItem, 0, len(res.Hits)), Total: res.Total, NextCursor: res.NextCursor}
+	for _, hit := range res.Hits {
+		r := hit.Report
+		resp.Items = append(resp.Items, searchResultItem{
+			ID:          r.ID,
+			Namespace:   r.Crash.Namespace,
+			PodName:     r.Crash.PodName,
+			Container:   r.Crash.ContainerName,
+			Reason:      r.Crash.Reason,
+			ExitCode:    r.Crash.ExitCode,
+			CollectedAt: r.CollectedAt,
+			Highlights:  hit.Highlights,
+		})
+	}
+	return resp, nil
+}
+
+func parseSince(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q: want a duration or RFC3339 time", raw)
+	}
+	return time.Now().Add(-d), nil
+}
+
+func fetchSearch(server, token, text string) (searchResponse, error) {
+	params := url.Values{}
+	params.Set("q", text)
+	params.Set("limit", strconv.Itoa(searchLimit))
+	if searchNamespace != "" {
+		params.Set("namespace", searchNamespace)
+	}
+	if searchSince != "" {
+		params.Set("since", searchSince)
+	}
+	endpoint := strings.TrimRight(server, "/") + "/reports/search?" + params.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return searchResponse{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return searchResponse{}, fmt.Errorf("failed to search reports: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return searchResponse{}, fmt.Errorf("failed to read search results: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(string(body))
+		if msg == "" {
+			msg = http.StatusText(resp.StatusCode)
+		}
+		return searchResponse{}, fmt.Errorf("daemon returned status %d: %s", resp.StatusCode, msg)
+	}
+
+	var out searchResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return searchResponse{}, fmt.Errorf("failed to decode search results: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/config"
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	"github.com/kadirbelkuyu/kubecrsh/internal/reporter"
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [report-id...]",
+	Short: "Check stored reports against the report ledger",
+	Long: `Check that the report ledger is intact, that every entry links to the one
+before it and carries a valid signature, and that stored reports still have
+the digest recorded when they were saved. Without report IDs every stored
+report is checked.
+
+Reports saved before the ledger was enabled show as unrecorded. The command
+fails when the ledger is damaged or a report was modified.`,
+	RunE: runVerify,
+}
+
+var (
+	verifyLedgerPath string
+	verifyPublicKeys []string
+	verifyOutput     string
+)
+
+func init() {
+	verifyCmd.Flags().StringVar(&verifyLedgerPath, "ledger", "", "ledger file (default from reports.ledger)")
+	verifyCmd.Flags().StringSliceVar(&verifyPublicKeys, "public-key", nil, "ed25519 public key file to verify signatures with (repeatable)")
+	verifyCmd.Flags().StringVarP(&verifyOutput, "output", "o", "", "output format: json")
+
+	rootCmd.AddCommand(verifyCmd)
+}
+
+type verifyResult struct {
+	Ledger  reporter.LedgerStatus      `json:"ledger"`
+	Reports []reporter.ReportIntegrity `json:"reports"`
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	if verifyOutput != "" && verifyOutput != "json" {
+		return fmt.Errorf("unsupported output format %q", verifyOutput)
+	}
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ledgerCfg := cfg.Reports.Ledger
+	if verifyLedgerPath != "" {
+		ledgerCfg.Enabled = true
+		ledgerCfg.Path = verifyLedgerPath
+	}
+	ledgerCfg.PublicKeys = append(ledgerCfg.PublicKeys, verifyPublicKeys...)
+	if !ledgerCfg.Enabled {
+		return fmt.Errorf("the report ledger is not enabled (set reports.ledger.enabled or --ledger)")
+	}
+	if _, err := os.Stat(ledgerPath(cfg.Reports.Path, ledgerCfg)); err != nil {
+		return fmt.Errorf("failed to read ledger: %w", err)
+	}
+
+	ledger, err := openLedger(cfg.Reports.Path, ledgerCfg)
+	if err != nil {
+		return err
+	}
+
+	keys, err := loadKeyring(cfg)
+	if err != nil {
+		return err
+	}
+	store, err := newReportStore(cfg, keys)
+	if err != nil {
+		return fmt.Errorf("failed to create report store: %w", err)
+	}
+
+	var reports []*domain.ForensicReport
+	if len(args) == 0 {
+		if reports, err = store.List(); err != nil {
+			return fmt.Errorf("failed to list reports: %w", err)
+		}
+	}
+	for _, id := range args {
+		report, err := store.Load(id)
+		if err != nil {
+			return fmt.Errorf("failed to load report %s: %w", id, err)
+		}
+		reports = append(reports, report)
+	}
+
+	res := verifyResult{Ledger: ledger.Status(), Reports: make([]reporter.ReportIntegrity, 0, len(reports))}
+	failed := !res.Ledger.Intact()
+	for _, report := range reports {
+		integrity, err := ledger.VerifyReport(report)
+		if err != nil {
+			return err
+		}
+		if integrity.Status == reporter.IntegrityModified {
+			failed = true
+		}
+		res.Reports = append(res.Reports, integrity)
+	}
+
+	if verifyOutput == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(res); err != nil {
+			return err
+		}
+	} else {
+		printVerifyResult(res)
+	}
+
+	if failed {
+		return fmt.Errorf("verification failed")
+	}
+	return nil
+}
+
+func printVerifyResult(res verifyResult) {
+	state := "intact"
+	if !res.Ledger.Intact() {
+		state = "DAMAGED"
+	}
+	fmt.Printf("Ledger: %d entries, %d signed (%d with unknown keys), %s\n",
+		res.Ledger.Entries, res.Ledger.Signed, res.Ledger.Unverified, state)
+	if res.Ledger.Head != "" {
+		fmt.Printf("  head %s\n", res.Ledger.Head)
+	}
+	for _, p := range res.Ledger.Problems {
+		fmt.Printf("  %s\n", p)
+	}
+
+	counts := make(map[string]int)
+	for _, r := range res.Reports {
+		counts[r.Status]++
+		switch r.Status {
+		case reporter.IntegrityModified:
+			fmt.Printf("%s  MODIFIED: recorded %s, now %s\n", r.ID, r.RecordedDigest, r.Digest)
+		case reporter.IntegrityUnrecorded:
+			fmt.Printf("%s  unrecorded\n", r.ID)
+		case reporter.IntegrityIntact:
+			if r.Reason != "" {
+				fmt.Printf("%s  superseded (%s)\n", r.ID, r.Reason)
+			}
+		}
+	}
+	fmt.Printf("Reports: %d intact, %d modified, %d unrecorded\n",
+		counts[reporter.IntegrityIntact], counts[reporter.IntegrityModified], counts[reporter.IntegrityUnrecorded])
+}
+
+// openLedger opens the report ledger, or returns nil when it is disabled.
+func openLedger(reportsPath string, cfg config.LedgerConfig) (*reporter.Ledger, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	var opts []reporter.LedgerOption
+	if cfg.SigningKey != "" {
+		data, err := os.ReadFile(cfg.SigningKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ledger signing key: %w", err)
+		}
+		key, err := reporter.ParseSigningKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ledger signing key: %w", err)
+		}
+		opts = append(opts, reporter.WithSigningKey(key))
+	}
+	for _, file := range cfg.PublicKeys {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ledger public key: %w", err)
+		}
+		key, err := reporter.ParseVerifyKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ledger public key %s: %w", file, err)
+		}
+		opts = append(opts, reporter.WithVerifyKeys(key))
+	}
+
+	ledger, err := reporter.OpenLedger(ledgerPath(reportsPath, cfg), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open report ledger: %w", err)
+	}
+	return ledger, nil
+}
+
+func ledgerPath(reportsPath string, cfg config.LedgerConfig) string {
+	if cfg.Path != "" {
+		return cfg.Path
+	}
+	if reportsPath == "" {
+		reportsPath = "reports"
+	}
+	return filepath.Join(reportsPath, reporter.LedgerFileName)
+}
@@ -9,30 +9,45 @@ import (
 )
 
 type Collector struct {
-	logCollector   *LogCollector
-	eventCollector *EventCollector
-	envCollector   *EnvCollector
+	logCollector     *LogCollector
+	nodeLogCollector *NodeLogCollector
+	eventCollector   *EventCollector
+	envCollector     *EnvCollector
 }
 
-func New(client kubernetes.Interface) *Collector {
-	return &Collector{
+type Option func(*Collector)
+
+func WithNodeLogs(root string) Option {
+	return func(c *Collector) {
+		c.nodeLogCollector = NewNodeLogCollector(root, 1000)
+	}
+}
+
+func New(client kubernetes.Interface, opts ...Option) *Collector {
+	c := &Collector{
 		logCollector:   NewLogCollector(client, 1000),
 		eventCollector: NewEventCollector(client),
 		envCollector:   NewEnvCollector(client),
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
 func (c *Collector) CollectForensics(ctx context.Context, crash domain.PodCrash) (*domain.ForensicReport, error) {
 	report := domain.NewForensicReport(crash)
 
-	logs, err := c.logCollector.GetLogs(ctx, crash.Namespace, crash.PodName, crash.ContainerName)
+	logs, err := c.getLogs(ctx, crash, false)
 	if err == nil {
 		report.SetLogs(logs)
 	} else {
 		report.AddWarning(fmt.Sprintf("logs: %v", err))
 	}
 
-	previousLogs, err := c.logCollector.GetPreviousLogs(ctx, crash.Namespace, crash.PodName, crash.ContainerName)
+	previousLogs, err := c.getLogs(ctx, crash, true)
 	if err == nil {
 		report.SetPreviousLogs(previousLogs)
 	} else {
@@ -57,5 +72,31 @@ func (c *Collector) CollectForensics(ctx context.Context, crash domain.PodCrash)
 		report.AddWarning(fmt.Sprintf("env: %v", err))
 	}
 
+	report.UpdateFingerprint()
+
 	return report, nil
 }
+
+func (c *Collector) getLogs(ctx context.Context, crash domain.PodCrash, previous bool) ([]string, error) {
+	if c.nodeLogCollector == nil {
+		return c.logCollector.getLogs(ctx, crash.Namespace, crash.PodName, crash.ContainerName, previous)
+	}
+
+	var nodeLogs []string
+	var nodeErr error
+	if previous {
+		nodeLogs, nodeErr = c.nodeLogCollector.GetPreviousLogs(crash)
+	} else {
+		nodeLogs, nodeErr = c.nodeLogCollector.GetLogs(crash)
+	}
+	if nodeErr == nil {
+		return nodeLogs, nil
+	}
+
+	logs, err := c.logCollector.getLogs(ctx, crash.Namespace, crash.PodName, crash.ContainerName, previous)
+	if err != nil {
+		return nil, fmt.Errorf("node: %v; api: %w", nodeErr, err)
+	}
+
+	return logs, nil
+}
@@ -0,0 +1,351 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	DefaultLogBufferAnnotation = "kubecrsh.io/log-buffer"
+
+	defaultLogBufferLines      = 5000
+	defaultLogBufferBytes      = 1 << 20
+	defaultLogBufferContainers = 200
+	logBufferRetention         = 10 * time.Minute
+	logBufferReconnectDelay    = time.Second
+)
+
+type LogBufferConfig struct {
+	Annotation    string
+	Selector      string
+	MaxLines      int
+	MaxBytes      int
+	MaxContainers int
+}
+
+type LogBuffer struct {
+	client        kubernetes.Interface
+	annotation    string
+	selector      labels.Selector
+	maxLines      int
+	maxBytes      int
+	maxContainers int
+	ctx           context.Context
+	streams       map[string]*followedLogs
+	mu            sync.Mutex
+}
+
+type followedLogs struct {
+	namespace    string
+	podName      string
+	container    string
+	containerID  string
+	containerKey string
+	startedAt    time.Time
+	endedAt      time.Time
+	ring         *logRing
+	done         chan struct{}
+}
+
+func NewLogBuffer(client kubernetes.Interface, cfg LogBufferConfig) (*LogBuffer, error) {
+	selector := labels.Nothing()
+	if strings.TrimSpace(cfg.Selector) != "" {
+		parsed, err := labels.Parse(cfg.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log buffer selector: %w", err)
+		}
+		selector = parsed
+	}
+
+	if cfg.Annotation == "" {
+		cfg.Annotation = DefaultLogBufferAnnotation
+	}
+	if cfg.MaxLines <= 0 {
+		cfg.MaxLines = defaultLogBufferLines
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = defaultLogBufferBytes
+	}
+	if cfg.MaxContainers <= 0 {
+		cfg.MaxContainers = defaultLogBufferContainers
+	}
+
+	return &LogBuffer{
+		client:        client,
+		annotation:    cfg.Annotation,
+		selector:      selector,
+		maxLines:      cfg.MaxLines,
+		maxBytes:      cfg.MaxBytes,
+		maxContainers: cfg.MaxContainers,
+		streams:       make(map[string]*followedLogs),
+	}, nil
+}
+
+func (b *LogBuffer) Start(ctx context.Context) {
+	b.mu.Lock()
+	b.ctx = ctx
+	b.mu.Unlock()
+
+	go b.cleanupLoop(ctx)
+}
+
+func (b *LogBuffer) Observe(pod *corev1.Pod) {
+	if !b.selected(pod) {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.ctx == nil {
+		return
+	}
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Running == nil || cs.ContainerID == "" {
+			continue
+		}
+
+		id := containerIDFromStatus(cs.ContainerID)
+		containerKey := pod.Namespace + "/" + pod.Name + "/" + cs.Name
+		key := containerKey + "/" + id
+		if _, exists := b.streams[key]; exists {
+			continue
+		}
+		if b.activeStreams() >= b.maxContainers {
+			return
+		}
+
+		f := &followedLogs{
+			namespace:    pod.Namespace,
+			podName:      pod.Name,
+			container:    cs.Name,
+			containerID:  id,
+			containerKey: containerKey,
+			startedAt:    cs.State.Running.StartedAt.Time,
+			ring:         newLogRing(b.maxLines, b.maxBytes),
+			done:         make(chan struct{}),
+		}
+		b.streams[key] = f
+
+		go b.follow(b.ctx, key, f)
+	}
+}
+
+func (b *LogBuffer) LastWords(crash domain.PodCrash, wait time.Duration) []string {
+	key := crash.ContainerKey()
+
+	b.mu.Lock()
+	var found *followedLogs
+	for _, f := range b.streams {
+		if f.containerKey != key {
+			continue
+		}
+		if crash.ContainerID != "" && f.containerID != crash.ContainerID {
+			continue
+		}
+		if found == nil || f.startedAt.After(found.startedAt) {
+			found = f
+		}
+	}
+	b.mu.Unlock()
+
+	if found == nil {
+		return nil
+	}
+
+	select {
+	case <-found.done:
+	case <-time.After(wait):
+	}
+
+	return found.ring.Lines()
+}
+
+func (b *LogBuffer) selected(pod *corev1.Pod) bool {
+	if strings.EqualFold(pod.Annotations[b.annotation], "true") {
+		return true
+	}
+	return b.selector.Matches(labels.Set(pod.Labels))
+}
+
+func (b *LogBuffer) activeStreams() int {
+	count := 0
+	for _, f := range b.streams {
+		if f.endedAt.IsZero() {
+			count++
+		}
+	}
+	return count
+}
+
+func (b *LogBuffer) follow(ctx context.Context, key string, f *followedLogs) {
+	var since *metav1.Time
+	received := false
+
+	for {
+		n, err := b.stream(ctx, f, since)
+		if n > 0 {
+			received = true
+		}
+		if ctx.Err() != nil || (err != nil && !received) {
+			break
+		}
+		if !b.stillRunning(ctx, f) {
+			break
+		}
+
+		since = &metav1.Time{Time: time.Now()}
+		select {
+		case <-ctx.Done():
+		case <-time.After(logBufferReconnectDelay):
+		}
+	}
+
+	b.mu.Lock()
+	if received {
+		f.endedAt = time.Now()
+	} else {
+		delete(b.streams, key)
+	}
+	b.mu.Unlock()
+
+	close(f.done)
+}
+
+func (b *LogBuffer) stream(ctx context.Context, f *followedLogs, since *metav1.Time) (int, error) {
+	opts := &corev1.PodLogOptions{
+		Container:  f.container,
+		Follow:     true,
+		Timestamps: true,
+		SinceTime:  since,
+	}
+
+	stream, err := b.client.CoreV1().Pods(f.namespace).GetLogs(f.podName, opts).Stream(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to follow logs: %w", err)
+	}
+	defer stream.Close()
+
+	reader := bufio.NewReader(stream)
+	count := 0
+	for {
+		line, err := reader.ReadString('\n')
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			f.ring.Add(line)
+			count++
+		}
+		if err != nil {
+			if err == io.EOF {
+				return count, nil
+			}
+			return count, err
+		}
+	}
+}
+
+func (b *LogBuffer) stillRunning(ctx context.Context, f *followedLogs) bool {
+	pod, err := b.client.CoreV1().Pods(f.namespace).Get(ctx, f.podName, metav1.GetOptions{})
+	if err != nil {
+		return false
+	}
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name == f.container {
+			return cs.State.Running != nil && containerIDFromStatus(cs.ContainerID) == f.containerID
+		}
+	}
+	return false
+}
+
+func (b *LogBuffer) cleanupLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.cleanup(logBufferRetention)
+		}
+	}
+}
+
+func (b *LogBuffer) cleanup(retention time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for key, f := range b.streams {
+		if !f.endedAt.IsZero() && now.Sub(f.endedAt) > retention {
+			delete(b.streams, key)
+		}
+	}
+}
+
+func containerIDFromStatus(raw string) string {
+	if i := strings.Index(raw, "://"); i >= 0 {
+		return raw[i+3:]
+	}
+	return raw
+}
+
+type logRing struct {
+	maxLines int
+	maxBytes int
+	lines    []string
+	head     int
+	bytes    int
+	mu       sync.Mutex
+}
+
+func newLogRing(maxLines, maxBytes int) *logRing {
+	return &logRing{
+		maxLines: maxLines,
+		maxBytes: maxBytes,
+	}
+}
+
+func (r *logRing) Add(line string) {
+	if len(line) > r.maxBytes {
+		line = line[:r.maxBytes]
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lines = append(r.lines, line)
+	r.bytes += len(line)
+
+	for len(r.lines)-r.head > r.maxLines || r.bytes > r.maxBytes {
+		r.bytes -= len(r.lines[r.head])
+		r.lines[r.head] = ""
+		r.head++
+	}
+
+	if r.head > 0 && r.head >= len(r.lines)/2 {
+		r.lines = append(r.lines[:0], r.lines[r.head:]...)
+		r.head = 0
+	}
+}
+
+func (r *logRing) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]string, len(r.lines)-r.head)
+	copy(out, r.lines[r.head:])
+	return out
+}
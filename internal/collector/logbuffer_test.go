@@ -0,0 +1,119 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func bufferedPod(annotations, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "api",
+			Namespace:   "default",
+			Annotations: annotations,
+			Labels:      labels,
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:        "main",
+					ContainerID: "containerd://abc123",
+					State: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{StartedAt: metav1.Now()},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestLogBuffer_CapturesLastWords(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	buffer, err := NewLogBuffer(client, LogBufferConfig{})
+	if err != nil {
+		t.Fatalf("NewLogBuffer() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	buffer.Start(ctx)
+
+	buffer.Observe(bufferedPod(map[string]string{DefaultLogBufferAnnotation: "true"}, nil))
+
+	lines := buffer.LastWords(domain.PodCrash{
+		Namespace:     "default",
+		PodName:       "api",
+		ContainerName: "main",
+		ContainerID:   "abc123",
+	}, 2*time.Second)
+
+	if len(lines) != 1 || lines[0] != "fake logs" {
+		t.Errorf("LastWords() = %v, want [fake logs]", lines)
+	}
+
+	if lines := buffer.LastWords(domain.PodCrash{
+		Namespace:     "default",
+		PodName:       "api",
+		ContainerName: "main",
+		ContainerID:   "other",
+	}, 0); lines != nil {
+		t.Errorf("LastWords() for another container instance = %v, want nil", lines)
+	}
+}
+
+func TestLogBuffer_IgnoresUnselectedPods(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	buffer, err := NewLogBuffer(client, LogBufferConfig{Selector: "app=api"})
+	if err != nil {
+		t.Fatalf("NewLogBuffer() error = %v", err)
+	}
+	buffer.Start(context.Background())
+
+	buffer.Observe(bufferedPod(nil, map[string]string{"app": "worker"}))
+	if len(buffer.streams) != 0 {
+		t.Errorf("expected no followed containers, got %d", len(buffer.streams))
+	}
+
+	if !buffer.selected(bufferedPod(nil, map[string]string{"app": "api"})) {
+		t.Error("pod matching selector should be selected")
+	}
+}
+
+func TestNewLogBuffer_InvalidSelector(t *testing.T) {
+	if _, err := NewLogBuffer(fake.NewSimpleClientset(), LogBufferConfig{Selector: "app in ("}); err == nil {
+		t.Error("expected error for invalid selector")
+	}
+}
+
+func TestLogRing_Caps(t *testing.T) {
+	ring := newLogRing(3, 1<<20)
+	for i := 0; i < 10; i++ {
+		ring.Add(strings.Repeat("x", i+1))
+	}
+	lines := ring.Lines()
+	if len(lines) != 3 || lines[0] != strings.Repeat("x", 8) {
+		t.Errorf("line cap: got %v", lines)
+	}
+
+	ring = newLogRing(100, 10)
+	ring.Add("aaaa")
+	ring.Add("bbbb")
+	ring.Add("cccc")
+	lines = ring.Lines()
+	if len(lines) != 2 || lines[0] != "bbbb" {
+		t.Errorf("byte cap: got %v", lines)
+	}
+
+	ring.Add(strings.Repeat("z", 50))
+	lines = ring.Lines()
+	if len(lines) != 1 || len(lines[0]) != 10 {
+		t.Errorf("oversized line: got %v", lines)
+	}
+}
@@ -0,0 +1,230 @@
+package collector
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+const DefaultNodeLogRoot = "/var/log/pods"
+
+type NodeLogCollector struct {
+	root      string
+	tailLines int
+}
+
+func NewNodeLogCollector(root string, tailLines int) *NodeLogCollector {
+	if root == "" {
+		root = DefaultNodeLogRoot
+	}
+	if tailLines <= 0 {
+		tailLines = 1000
+	}
+	return &NodeLogCollector{
+		root:      root,
+		tailLines: tailLines,
+	}
+}
+
+func (c *NodeLogCollector) GetLogs(crash domain.PodCrash) ([]string, error) {
+	return c.readAttempt(crash, crash.RestartCount)
+}
+
+func (c *NodeLogCollector) GetPreviousLogs(crash domain.PodCrash) ([]string, error) {
+	if crash.RestartCount <= 0 {
+		return nil, fmt.Errorf("no previous container attempt")
+	}
+	return c.readAttempt(crash, crash.RestartCount-1)
+}
+
+func (c *NodeLogCollector) readAttempt(crash domain.PodCrash, attempt int32) ([]string, error) {
+	podDir, err := c.findPodDir(crash.Namespace, crash.PodName, crash.PodUID)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := attemptFiles(filepath.Join(podDir, crash.ContainerName), attempt)
+	if err != nil {
+		return nil, err
+	}
+
+	tail := newLineTail(c.tailLines)
+	for _, file := range files {
+		if err := readCRILogFile(file, tail); err != nil {
+			return nil, err
+		}
+	}
+
+	return tail.Lines(), nil
+}
+
+func (c *NodeLogCollector) findPodDir(namespace, podName, podUID string) (string, error) {
+	if podUID != "" {
+		dir := filepath.Join(c.root, fmt.Sprintf("%s_%s_%s", namespace, podName, podUID))
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+
+	matches, err := filepath.Glob(filepath.Join(c.root, fmt.Sprintf("%s_%s_*", namespace, podName)))
+	if err != nil {
+		return "", fmt.Errorf("failed to search pod log directory: %w", err)
+	}
+
+	var newest string
+	var newestMod int64
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		if newest == "" || info.ModTime().UnixNano() > newestMod {
+			newest = m
+			newestMod = info.ModTime().UnixNano()
+		}
+	}
+
+	if newest == "" {
+		return "", fmt.Errorf("pod log directory not found for %s/%s", namespace, podName)
+	}
+
+	return newest, nil
+}
+
+func attemptFiles(containerDir string, attempt int32) ([]string, error) {
+	base := strconv.Itoa(int(attempt)) + ".log"
+
+	rotated, err := filepath.Glob(filepath.Join(containerDir, base+".*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search rotated logs: %w", err)
+	}
+	sort.Strings(rotated)
+
+	files := make([]string, 0, len(rotated)+1)
+	for _, f := range rotated {
+		if strings.HasSuffix(f, ".tmp") {
+			continue
+		}
+		files = append(files, f)
+	}
+
+	current := filepath.Join(containerDir, base)
+	if _, err := os.Stat(current); err == nil {
+		files = append(files, current)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no log files for attempt %d in %s", attempt, containerDir)
+	}
+
+	return files, nil
+}
+
+func readCRILogFile(path string, tail *lineTail) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("failed to open gzip log file: %w", err)
+		}
+		defer gr.Close()
+		r = gr
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var partial strings.Builder
+	var partialTS string
+	for scanner.Scan() {
+		ts, tag, content, ok := parseCRILine(scanner.Text())
+		if !ok {
+			continue
+		}
+
+		if tag == "P" {
+			if partial.Len() == 0 {
+				partialTS = ts
+			}
+			partial.WriteString(content)
+			continue
+		}
+
+		if partial.Len() > 0 {
+			partial.WriteString(content)
+			tail.Add(partialTS + " " + partial.String())
+			partial.Reset()
+			continue
+		}
+
+		tail.Add(ts + " " + content)
+	}
+
+	if partial.Len() > 0 {
+		tail.Add(partialTS + " " + partial.String())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read log file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func parseCRILine(line string) (ts, tag, content string, ok bool) {
+	parts := strings.SplitN(line, " ", 4)
+	if len(parts) < 3 {
+		return "", "", "", false
+	}
+
+	ts = parts[0]
+	tag = parts[2]
+	if tag != "F" && tag != "P" {
+		tag = "F"
+	}
+	if len(parts) == 4 {
+		content = parts[3]
+	}
+
+	return ts, tag, content, true
+}
+
+type lineTail struct {
+	max   int
+	lines []string
+}
+
+func newLineTail(max int) *lineTail {
+	return &lineTail{max: max}
+}
+
+func (t *lineTail) Add(line string) {
+	t.lines = append(t.lines, line)
+	if len(t.lines) >= 2*t.max {
+		t.lines = append(t.lines[:0], t.lines[len(t.lines)-t.max:]...)
+	}
+}
+
+func (t *lineTail) Lines() []string {
+	if len(t.lines) == 0 {
+		return []string{}
+	}
+	if len(t.lines) > t.max {
+		return append([]string(nil), t.lines[len(t.lines)-t.max:]...)
+	}
+	return t.lines
+}
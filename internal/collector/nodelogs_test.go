@@ -0,0 +1,174 @@
+package collector
+
+import (
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func writeFixture(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func writeGzipFixture(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	if _, err := gw.Write([]byte(content)); err != nil {
+		t.Fatalf("gzip Write() error = %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("gzip Close() error = %v", err)
+	}
+}
+
+func nodeLogFixture(t *testing.T) string {
+	root := t.TempDir()
+	containerDir := filepath.Join(root, "payments_api-7d9f_uid-123", "app")
+
+	writeGzipFixture(t, filepath.Join(containerDir, "2.log.20240101-100000.gz"),
+		"2024-01-01T10:00:00.000000001Z stdout F starting\n"+
+			"2024-01-01T10:00:01.000000001Z stdout F connecting to redis\n")
+	writeFixture(t, filepath.Join(containerDir, "2.log.20240101-110000"),
+		"2024-01-01T11:00:00.000000001Z stderr P connection \n"+
+			"2024-01-01T11:00:00.000000002Z stderr F refused\n")
+	writeFixture(t, filepath.Join(containerDir, "2.log"),
+		"2024-01-01T12:00:00.000000001Z stderr F panic: boom\n"+
+			"garbage\n")
+	writeFixture(t, filepath.Join(containerDir, "3.log"),
+		"2024-01-01T12:00:05.000000001Z stdout F starting again\n")
+
+	return root
+}
+
+func TestNodeLogCollector_GetLogs_ReadsRotationsInOrder(t *testing.T) {
+	root := nodeLogFixture(t)
+	c := NewNodeLogCollector(root, 100)
+
+	crash := domain.PodCrash{
+		Namespace:     "payments",
+		PodName:       "api-7d9f",
+		PodUID:        "uid-123",
+		ContainerName: "app",
+		RestartCount:  3,
+	}
+
+	previous, err := c.GetPreviousLogs(crash)
+	if err != nil {
+		t.Fatalf("GetPreviousLogs() error = %v", err)
+	}
+
+	want := []string{
+		"2024-01-01T10:00:00.000000001Z starting",
+		"2024-01-01T10:00:01.000000001Z connecting to redis",
+		"2024-01-01T11:00:00.000000001Z connection refused",
+		"2024-01-01T12:00:00.000000001Z panic: boom",
+	}
+	if len(previous) != len(want) {
+		t.Fatalf("GetPreviousLogs() = %v, want %v", previous, want)
+	}
+	for i := range want {
+		if previous[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, previous[i], want[i])
+		}
+	}
+
+	current, err := c.GetLogs(crash)
+	if err != nil {
+		t.Fatalf("GetLogs() error = %v", err)
+	}
+	if len(current) != 1 || !strings.HasSuffix(current[0], "starting again") {
+		t.Errorf("GetLogs() = %v, want single 'starting again' line", current)
+	}
+}
+
+func TestNodeLogCollector_TailLines(t *testing.T) {
+	root := nodeLogFixture(t)
+	c := NewNodeLogCollector(root, 2)
+
+	lines, err := c.GetLogs(domain.PodCrash{
+		Namespace:     "payments",
+		PodName:       "api-7d9f",
+		ContainerName: "app",
+		RestartCount:  2,
+	})
+	if err != nil {
+		t.Fatalf("GetLogs() error = %v", err)
+	}
+
+	if len(lines) != 2 {
+		t.Fatalf("GetLogs() returned %d lines, want 2", len(lines))
+	}
+	if !strings.HasSuffix(lines[1], "panic: boom") {
+		t.Errorf("last line = %q, want panic: boom", lines[1])
+	}
+}
+
+func TestNodeLogCollector_Errors(t *testing.T) {
+	root := nodeLogFixture(t)
+	c := NewNodeLogCollector(root, 100)
+
+	tests := []struct {
+		name  string
+		crash domain.PodCrash
+	}{
+		{"unknown pod", domain.PodCrash{Namespace: "payments", PodName: "other", ContainerName: "app"}},
+		{"unknown container", domain.PodCrash{Namespace: "payments", PodName: "api-7d9f", ContainerName: "sidecar"}},
+		{"missing attempt", domain.PodCrash{Namespace: "payments", PodName: "api-7d9f", ContainerName: "app", RestartCount: 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := c.GetLogs(tt.crash); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+
+	if _, err := c.GetPreviousLogs(domain.PodCrash{Namespace: "payments", PodName: "api-7d9f", ContainerName: "app"}); err == nil {
+		t.Error("Expected error for previous logs of first attempt")
+	}
+}
+
+func TestCollector_CollectForensics_NodeLogs(t *testing.T) {
+	root := nodeLogFixture(t)
+	client := fake.NewSimpleClientset()
+	collector := New(client, WithNodeLogs(root))
+
+	report, err := collector.CollectForensics(context.Background(), domain.PodCrash{
+		Namespace:     "payments",
+		PodName:       "api-7d9f",
+		PodUID:        "uid-123",
+		ContainerName: "app",
+		RestartCount:  3,
+	})
+	if err != nil {
+		t.Fatalf("CollectForensics() error = %v", err)
+	}
+
+	if len(report.PreviousLog) != 4 {
+		t.Errorf("PreviousLog count = %d, want 4", len(report.PreviousLog))
+	}
+	if len(report.Logs) != 1 {
+		t.Errorf("Logs count = %d, want 1", len(report.Logs))
+	}
+}
@@ -18,13 +18,95 @@ type Config struct {
 	API           APIConfig
 	Watch         WatchConfig
 	Elasticsearch ElasticsearchConfig
+	Agent         AgentConfig
+	Diagnosis     DiagnosisConfig
+	Severity      SeverityConfig
 }
 
 type ReportsConfig struct {
-	Path        string          `mapstructure:"path"`
-	Retention   time.Duration   `mapstructure:"retention"`
-	Compression string          `mapstructure:"compression"`
-	Redaction   RedactionConfig `mapstructure:"redaction"`
+	Path        string           `mapstructure:"path"`
+	Retention   time.Duration    `mapstructure:"retention"`
+	Compression string           `mapstructure:"compression"`
+	Backend     string           `mapstructure:"backend"`
+	SQLitePath  string           `mapstructure:"sqlite_path"`
+	S3          S3Config         `mapstructure:"s3"`
+	Redaction   RedactionConfig  `mapstructure:"redaction"`
+	Outbox      OutboxConfig     `mapstructure:"outbox"`
+	Reconcile   ReconcileConfig  `mapstructure:"reconcile"`
+	Encryption  EncryptionConfig `mapstructure:"encryption"`
+	Ledger      LedgerConfig     `mapstructure:"ledger"`
+	CRD         CRDConfig        `mapstructure:"crd"`
+
+	RetentionPolicy RetentionPolicyConfig `mapstructure:"retention_policy"`
+}
+
+// RetentionPolicyConfig adds quotas on top of Retention. MaxBytes is a
+// Kubernetes quantity such as 5Gi. Reports older than StripLogsAfter keep
+// their summary but lose their logs. Zero disables a rule.
+type RetentionPolicyConfig struct {
+	MaxBytes        string        `mapstructure:"max_bytes"`
+	MaxPerNamespace int           `mapstructure:"max_per_namespace"`
+	MaxPerWorkload  int           `mapstructure:"max_per_workload"`
+	StripLogsAfter  time.Duration `mapstructure:"strip_logs_after"`
+	DryRun          bool          `mapstructure:"dry_run"`
+}
+
+// CRDConfig mirrors reports into CrashReport custom resources in the
+// namespace of the crashed pod; backend crd stores them only there.
+// MaxBodyBytes embeds the compressed report when it fits, zero keeps the
+// summary only.
+type CRDConfig struct {
+	Enabled         bool `mapstructure:"enabled"`
+	MaxBodyBytes    int  `mapstructure:"max_body_bytes"`
+	OwnerReferences bool `mapstructure:"owner_references"`
+}
+
+// LedgerConfig enables the hash-chained ledger of report digests. An empty
+// Path means <path>/ledger.jsonl. SigningKey is an ed25519 private key file
+// and PublicKeys lists the public keys of earlier signing keys.
+type LedgerConfig struct {
+	Enabled    bool     `mapstructure:"enabled"`
+	Path       string   `mapstructure:"path"`
+	SigningKey string   `mapstructure:"signing_key"`
+	PublicKeys []string `mapstructure:"public_keys"`
+}
+
+// EncryptionConfig names the keys reports are encrypted with: a key file or
+// a directory of them, or a Secret given as <namespace>/<name> whose data
+// keys are the key IDs. KeyID selects the key for new reports.
+type EncryptionConfig struct {
+	Required bool   `mapstructure:"required"`
+	KeyFile  string `mapstructure:"key_file"`
+	Secret   string `mapstructure:"secret"`
+	KeyID    string `mapstructure:"key_id"`
+}
+
+// OutboxConfig controls where writes that failed on one of several report
+// backends wait to be retried. An empty Dir means <path>/outbox.
+type OutboxConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Dir           string        `mapstructure:"dir"`
+	RetryInterval time.Duration `mapstructure:"retry_interval"`
+	MaxBackoff    time.Duration `mapstructure:"max_backoff"`
+}
+
+type ReconcileConfig struct {
+	Interval time.Duration `mapstructure:"interval"`
+	Window   time.Duration `mapstructure:"window"`
+}
+
+type S3Config struct {
+	Endpoint             string        `mapstructure:"endpoint"`
+	Region               string        `mapstructure:"region"`
+	Bucket               string        `mapstructure:"bucket"`
+	Prefix               string        `mapstructure:"prefix"`
+	AccessKeyID          string        `mapstructure:"access_key_id"`
+	SecretAccessKey      string        `mapstructure:"secret_access_key"`
+	SessionToken         string        `mapstructure:"session_token"`
+	ServerSideEncryption string        `mapstructure:"server_side_encryption"`
+	KMSKeyID             string        `mapstructure:"kms_key_id"`
+	PartSize             int64         `mapstructure:"part_size"`
+	Timeout              time.Duration `mapstructure:"timeout"`
 }
 
 type RedactionConfig struct {
@@ -40,20 +122,65 @@ type APIConfig struct {
 	ReportsEnabled bool   `mapstructure:"reports_enabled"`
 	Token          string `mapstructure:"token"`
 	AllowFull      bool   `mapstructure:"allow_full"`
+	IngestEnabled  bool   `mapstructure:"ingest_enabled"`
+	TriageEnabled  bool   `mapstructure:"triage_enabled"`
 }
 
 type WatchConfig struct {
-	Reasons []string
+	Reasons   []string
+	LogBuffer LogBufferConfig `mapstructure:"log_buffer"`
+}
+
+type LogBufferConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	Annotation    string `mapstructure:"annotation"`
+	Selector      string `mapstructure:"selector"`
+	MaxLines      int    `mapstructure:"max_lines"`
+	MaxBytes      int    `mapstructure:"max_bytes"`
+	MaxContainers int    `mapstructure:"max_containers"`
 }
 
 type ElasticsearchConfig struct {
-	Enabled   bool     `mapstructure:"enabled"`
-	Addresses []string `mapstructure:"addresses"`
-	Username  string   `mapstructure:"username"`
-	Password  string   `mapstructure:"password"`
-	Index     string   `mapstructure:"index"`
-	CloudID   string   `mapstructure:"cloud_id"`
-	APIKey    string   `mapstructure:"api_key"`
+	Enabled        bool                    `mapstructure:"enabled"`
+	Required       bool                    `mapstructure:"required"`
+	Addresses      []string                `mapstructure:"addresses"`
+	Username       string                  `mapstructure:"username"`
+	Password       string                  `mapstructure:"password"`
+	Index          string                  `mapstructure:"index"`
+	CloudID        string                  `mapstructure:"cloud_id"`
+	APIKey         string                  `mapstructure:"api_key"`
+	IndexPeriod    string                  `mapstructure:"index_period"`
+	ILMPolicy      string                  `mapstructure:"ilm_policy"`
+	ILMDeleteAfter string                  `mapstructure:"ilm_delete_after"`
+	Bulk           ElasticsearchBulkConfig `mapstructure:"bulk"`
+}
+
+type ElasticsearchBulkConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Actions       int           `mapstructure:"actions"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	MaxBuffered   int           `mapstructure:"max_buffered"`
+	SpoolDir      string        `mapstructure:"spool_dir"`
+	MaxRetries    int           `mapstructure:"max_retries"`
+}
+
+type DiagnosisConfig struct {
+	Enabled       bool     `mapstructure:"enabled"`
+	DisabledRules []string `mapstructure:"disabled_rules"`
+}
+
+type SeverityConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	CriticalityLabel string        `mapstructure:"criticality_label"`
+	FrequencyWindow  time.Duration `mapstructure:"frequency_window"`
+}
+
+type AgentConfig struct {
+	NodeName     string `mapstructure:"node_name"`
+	LogRoot      string `mapstructure:"log_root"`
+	ForwardURL   string `mapstructure:"forward_url"`
+	ForwardToken string `mapstructure:"forward_token"`
+	KmsgSource   string `mapstructure:"kmsg_source"`
 }
 
 func Load(cfgFile string) (*Config, error) {
@@ -78,24 +205,87 @@ func Load(cfgFile string) (*Config, error) {
 	v.SetDefault("namespace", "")
 	v.SetDefault("reports.path", "reports")
 	v.SetDefault("reports.retention", "168h")
+	v.SetDefault("reports.retention_policy.max_bytes", "")
+	v.SetDefault("reports.retention_policy.max_per_namespace", 0)
+	v.SetDefault("reports.retention_policy.max_per_workload", 0)
+	v.SetDefault("reports.retention_policy.strip_logs_after", "0s")
+	v.SetDefault("reports.retention_policy.dry_run", false)
 	v.SetDefault("reports.compression", "none")
+	v.SetDefault("reports.crd.enabled", false)
+	v.SetDefault("reports.crd.max_body_bytes", 0)
+	v.SetDefault("reports.crd.owner_references", true)
+	v.SetDefault("reports.backend", "file")
+	v.SetDefault("reports.sqlite_path", "")
+	v.SetDefault("reports.s3.endpoint", "")
+	v.SetDefault("reports.s3.region", "us-east-1")
+	v.SetDefault("reports.s3.bucket", "")
+	v.SetDefault("reports.s3.prefix", "")
+	v.SetDefault("reports.s3.access_key_id", "")
+	v.SetDefault("reports.s3.secret_access_key", "")
+	v.SetDefault("reports.s3.session_token", "")
+	v.SetDefault("reports.s3.server_side_encryption", "")
+	v.SetDefault("reports.s3.kms_key_id", "")
+	v.SetDefault("reports.s3.part_size", 16<<20)
+	v.SetDefault("reports.s3.timeout", "30s")
 	v.SetDefault("reports.redaction.enabled", false)
 	v.SetDefault("reports.redaction.env_allowlist", []string{})
 	v.SetDefault("reports.redaction.env_denylist", []string{})
 	v.SetDefault("reports.redaction.log_patterns", []string{})
 	v.SetDefault("reports.redaction.replacement", "***")
 	v.SetDefault("reports.redaction.redact_from_source", false)
+	v.SetDefault("reports.outbox.enabled", true)
+	v.SetDefault("reports.outbox.dir", "")
+	v.SetDefault("reports.outbox.retry_interval", "30s")
+	v.SetDefault("reports.outbox.max_backoff", "10m")
+	v.SetDefault("reports.reconcile.interval", "1h")
+	v.SetDefault("reports.reconcile.window", "24h")
+	v.SetDefault("reports.encryption.required", false)
+	v.SetDefault("reports.encryption.key_file", "")
+	v.SetDefault("reports.encryption.secret", "")
+	v.SetDefault("reports.encryption.key_id", "")
+	v.SetDefault("reports.ledger.enabled", false)
+	v.SetDefault("reports.ledger.path", "")
+	v.SetDefault("reports.ledger.signing_key", "")
+	v.SetDefault("reports.ledger.public_keys", []string{})
 	v.SetDefault("api.reports_enabled", false)
 	v.SetDefault("api.token", "")
 	v.SetDefault("api.allow_full", false)
+	v.SetDefault("api.ingest_enabled", false)
+	v.SetDefault("api.triage_enabled", false)
 	v.SetDefault("watch.reasons", []string{"OOMKilled", "Error", "CrashLoopBackOff"})
+	v.SetDefault("watch.log_buffer.enabled", false)
+	v.SetDefault("watch.log_buffer.annotation", "kubecrsh.io/log-buffer")
+	v.SetDefault("watch.log_buffer.selector", "")
+	v.SetDefault("watch.log_buffer.max_lines", 5000)
+	v.SetDefault("watch.log_buffer.max_bytes", 1048576)
+	v.SetDefault("watch.log_buffer.max_containers", 200)
 	v.SetDefault("elasticsearch.enabled", false)
+	v.SetDefault("elasticsearch.required", false)
 	v.SetDefault("elasticsearch.addresses", []string{"http://localhost:9200"})
 	v.SetDefault("elasticsearch.username", "")
 	v.SetDefault("elasticsearch.password", "")
 	v.SetDefault("elasticsearch.index", "kubecrsh-reports")
 	v.SetDefault("elasticsearch.cloud_id", "")
 	v.SetDefault("elasticsearch.api_key", "")
+	v.SetDefault("elasticsearch.index_period", "")
+	v.SetDefault("elasticsearch.ilm_policy", "")
+	v.SetDefault("elasticsearch.ilm_delete_after", "")
+	v.SetDefault("elasticsearch.bulk.enabled", true)
+	v.SetDefault("elasticsearch.bulk.actions", 500)
+	v.SetDefault("elasticsearch.bulk.flush_interval", "5s")
+	v.SetDefault("elasticsearch.bulk.max_buffered", 10000)
+	v.SetDefault("elasticsearch.bulk.spool_dir", "")
+	v.SetDefault("elasticsearch.bulk.max_retries", 5)
+	v.SetDefault("diagnosis.enabled", true)
+	v.SetDefault("diagnosis.disabled_rules", []string{})
+	v.SetDefault("severity.enabled", true)
+	v.SetDefault("severity.criticality_label", "kubecrsh.io/criticality")
+	v.SetDefault("severity.frequency_window", time.Hour)
+	v.SetDefault("agent.node_name", "")
+	v.SetDefault("agent.log_root", "/var/log/pods")
+	v.SetDefault("agent.forward_url", "")
+	v.SetDefault("agent.forward_token", "")
+	v.SetDefault("agent.kmsg_source", "")
 
 	v.AutomaticEnv()
 	v.SetEnvPrefix("KUBECRSH")
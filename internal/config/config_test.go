@@ -20,6 +20,13 @@ func TestLoad_Defaults(t *testing.T) {
 		t.Errorf("Reports.Retention = %v, want 168h", cfg.Reports.Retention)
 	}
 
+	if cfg.Agent.LogRoot != "/var/log/pods" {
+		t.Errorf("Agent.LogRoot = %v, want /var/log/pods", cfg.Agent.LogRoot)
+	}
+	if cfg.API.IngestEnabled {
+		t.Error("API.IngestEnabled should default to false")
+	}
+
 	expectedReasons := []string{"OOMKilled", "Error", "CrashLoopBackOff"}
 	if len(cfg.Watch.Reasons) != len(expectedReasons) {
 		t.Errorf("Watch.Reasons length = %d, want %d", len(cfg.Watch.Reasons), len(expectedReasons))
@@ -0,0 +1,65 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/debugpod"
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (s *Server) debugManifestHandler(w http.ResponseWriter, r *http.Request, id string) {
+	if id == "" || strings.Contains(id, "/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !s.apiAllowFull {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	rep, err := s.store.Load(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	pod, err := s.findPod(r.Context(), rep.Crash)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	data, err := debugpod.YAML(debugpod.Build(pod, rep))
+	if err != nil {
+		http.Error(w, "failed to build debug manifest", http.StatusInternalServerError)
+		fmt.Printf("Failed to build debug manifest: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+func (s *Server) findPod(ctx context.Context, crash domain.PodCrash) (*corev1.Pod, error) {
+	cached, cachedOK := s.pods.Get(crash.Namespace, crash.PodName)
+
+	live, err := s.client.CoreV1().Pods(crash.Namespace).Get(ctx, crash.PodName, metav1.GetOptions{})
+	if err == nil {
+		if !cachedOK || crash.PodUID == "" || string(live.UID) == crash.PodUID || string(cached.UID) != crash.PodUID {
+			return live, nil
+		}
+	}
+
+	if cachedOK {
+		return cached, nil
+	}
+
+	return nil, fmt.Errorf("pod %s/%s no longer exists and was not observed by this daemon", crash.Namespace, crash.PodName)
+}
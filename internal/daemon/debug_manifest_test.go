@@ -0,0 +1,60 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestServer_debugManifest(t *testing.T) {
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api", ContainerName: "main", PodUID: "uid-1"})
+	storage := &mockStorage{saved: []*domain.ForensicReport{report}}
+
+	server := &Server{
+		client:            fake.NewSimpleClientset(),
+		store:             storage,
+		pods:              newPodCache(),
+		apiReportsEnabled: true,
+	}
+
+	path := "/reports/" + report.ID + "/debug-manifest"
+
+	w := httptest.NewRecorder()
+	server.reportGetHandler(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Status without allowFull = %d, want %d", w.Code, http.StatusForbidden)
+	}
+
+	server.apiAllowFull = true
+	w = httptest.NewRecorder()
+	server.reportGetHandler(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Status for unknown pod = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	server.pods.Observe(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default", UID: "uid-1", Labels: map[string]string{"app": "api"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "api:1", Command: []string{"/app"}}}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:                 "main",
+			LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}},
+		}}},
+	})
+
+	w = httptest.NewRecorder()
+	server.reportGetHandler(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	body := w.Body.String()
+	if !strings.Contains(body, "name: api-debug") || !strings.Contains(body, "- infinity") || strings.Contains(body, "app: api") {
+		t.Errorf("unexpected manifest:\n%s", body)
+	}
+}
@@ -0,0 +1,66 @@
+package daemon
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/export"
+)
+
+// exportHandler serves GET /reports/{id}/export?format=markdown|html|bundle.
+// Exports carry logs and environment, so like ?full=true they need
+// api.allow_full. Bundles include the pod spec when the pod can be found,
+// with its environment redacted like the report.
+func (s *Server) exportHandler(w http.ResponseWriter, r *http.Request, id string) {
+	if id == "" || strings.Contains(id, "/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if !s.apiAllowFull {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	format := export.FormatMarkdown
+	if name := r.URL.Query().Get("format"); name != "" {
+		var err error
+		if format, err = export.ParseFormat(name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	rep, err := s.store.Load(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var opts []export.Option
+	if format == export.FormatBundle {
+		if pod, err := s.findPod(r.Context(), rep.Crash); err == nil {
+			opts = append(opts, export.WithPod(pod))
+		}
+		if r, ok := s.redactor.(export.EnvRedactor); ok {
+			opts = append(opts, export.WithRedactor(r))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := export.Write(&buf, rep, format, opts...); err != nil {
+		http.Error(w, "failed to export report", http.StatusInternalServerError)
+		fmt.Printf("Failed to export report %s: %v\n", id, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": export.FileName(rep, format)}))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,90 @@
+package daemon
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/config"
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	"github.com/kadirbelkuyu/kubecrsh/internal/redaction"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestServer_export(t *testing.T) {
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api", ContainerName: "main", PodUID: "uid-1"})
+	report.SetLogs([]string{"panic: boom"})
+	storage := &mockStorage{saved: []*domain.ForensicReport{report}}
+
+	server := &Server{
+		client:            fake.NewSimpleClientset(),
+		store:             storage,
+		pods:              newPodCache(),
+		apiReportsEnabled: true,
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.reportGetHandler(w, httptest.NewRequest(http.MethodGet, "/reports/"+report.ID+"/export"+query, nil))
+		return w
+	}
+
+	if w := get(""); w.Code != http.StatusForbidden {
+		t.Errorf("Status without allowFull = %d, want %d", w.Code, http.StatusForbidden)
+	}
+
+	server.apiAllowFull = true
+	if w := get("?format=pdf"); w.Code != http.StatusBadRequest {
+		t.Errorf("Status for unknown format = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	w := get("")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/markdown") {
+		t.Fatalf("markdown export = %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Body.String(), "panic: boom") {
+		t.Errorf("markdown export missing logs:\n%s", w.Body.String())
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "attachment") || !strings.Contains(cd, report.ID+"-default-api.md") {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+
+	server.client = fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default", UID: "uid-1"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Env: []corev1.EnvVar{{Name: "API_TOKEN", Value: "s3cr3t"}}}}},
+	})
+	redactor, err := redaction.New(config.RedactionConfig{Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.redactor = redactor
+	w = get("?format=bundle")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/gzip" {
+		t.Fatalf("bundle export = %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, hdr.Name)
+		if data, _ := io.ReadAll(tr); strings.Contains(string(data), "s3cr3t") {
+			t.Errorf("%s contains the pod's env value", hdr.Name)
+		}
+	}
+	if !strings.Contains(strings.Join(names, " "), report.ID+"/pod.yaml") {
+		t.Errorf("bundle entries = %v", names)
+	}
+}
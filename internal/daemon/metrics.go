@@ -8,6 +8,8 @@ type Metrics struct {
 	CrashesTotal      *prometheus.CounterVec
 	ReportSize        prometheus.Histogram
 	NotificationsSent *prometheus.CounterVec
+	RetentionReports  *prometheus.CounterVec
+	RetentionBytes    *prometheus.CounterVec
 }
 
 func NewMetrics() *Metrics {
@@ -17,7 +19,7 @@ func NewMetrics() *Metrics {
 				Name: "kubecrsh_crashes_total",
 				Help: "Total number of pod crashes detected",
 			},
-			[]string{"namespace", "reason"},
+			[]string{"namespace", "reason", "severity"},
 		),
 		ReportSize: prometheus.NewHistogram(
 			prometheus.HistogramOpts{
@@ -33,5 +35,19 @@ func NewMetrics() *Metrics {
 			},
 			[]string{"notifier", "status"},
 		),
+		RetentionReports: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kubecrsh_retention_reports_total",
+				Help: "Total number of reports deleted or stripped by retention",
+			},
+			[]string{"action", "reason"},
+		),
+		RetentionBytes: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kubecrsh_retention_reclaimed_bytes_total",
+				Help: "Total number of bytes reclaimed by retention",
+			},
+			[]string{"action"},
+		),
 	}
 }
@@ -29,8 +29,8 @@ func TestMetrics_CrashesTotal(t *testing.T) {
 	registry := prometheus.NewRegistry()
 	registry.MustRegister(metrics.CrashesTotal)
 
-	metrics.CrashesTotal.WithLabelValues("default", "OOMKilled").Inc()
-	metrics.CrashesTotal.WithLabelValues("production", "Error").Add(5)
+	metrics.CrashesTotal.WithLabelValues("default", "OOMKilled", "medium").Inc()
+	metrics.CrashesTotal.WithLabelValues("production", "Error", "high").Add(5)
 
 	metricFamilies, err := registry.Gather()
 	if err != nil {
@@ -116,16 +116,16 @@ func TestMetrics_LabelConsistency(t *testing.T) {
 	registry := prometheus.NewRegistry()
 	registry.MustRegister(metrics.CrashesTotal)
 
-	metrics.CrashesTotal.WithLabelValues("ns1", "reason1").Inc()
-	metrics.CrashesTotal.WithLabelValues("ns2", "reason2").Inc()
-	metrics.CrashesTotal.WithLabelValues("ns1", "reason1").Inc()
+	metrics.CrashesTotal.WithLabelValues("ns1", "reason1", "low").Inc()
+	metrics.CrashesTotal.WithLabelValues("ns2", "reason2", "low").Inc()
+	metrics.CrashesTotal.WithLabelValues("ns1", "reason1", "low").Inc()
 
 	metricFamilies, _ := registry.Gather()
 	for _, mf := range metricFamilies {
 		if mf.GetName() == "kubecrsh_crashes_total" {
 			for _, m := range mf.GetMetric() {
-				if len(m.GetLabel()) != 2 {
-					t.Errorf("Expected 2 labels, got %d", len(m.GetLabel()))
+				if len(m.GetLabel()) != 3 {
+					t.Errorf("Expected 3 labels, got %d", len(m.GetLabel()))
 				}
 			}
 		}
@@ -139,7 +139,7 @@ func BenchmarkMetrics_CrashesTotal(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		metrics.CrashesTotal.WithLabelValues("default", "Error").Inc()
+		metrics.CrashesTotal.WithLabelValues("default", "Error", "low").Inc()
 	}
 }
 
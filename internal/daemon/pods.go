@@ -0,0 +1,72 @@
+package daemon
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const defaultPodCacheTTL = 24 * time.Hour
+
+type podCache struct {
+	pods     map[string]*corev1.Pod
+	lastSeen map[string]time.Time
+	mu       sync.Mutex
+}
+
+func newPodCache() *podCache {
+	return &podCache{
+		pods:     make(map[string]*corev1.Pod),
+		lastSeen: make(map[string]time.Time),
+	}
+}
+
+func (c *podCache) Observe(pod *corev1.Pod) {
+	if !hasTerminated(pod) {
+		return
+	}
+
+	key := pod.Namespace + "/" + pod.Name
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pods[key] = pod
+	c.lastSeen[key] = time.Now()
+}
+
+func (c *podCache) Get(namespace, name string) (*corev1.Pod, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pod, ok := c.pods[namespace+"/"+name]
+	return pod, ok
+}
+
+func (c *podCache) Cleanup(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, seen := range c.lastSeen {
+		if now.Sub(seen) > ttl {
+			delete(c.lastSeen, key)
+			delete(c.pods, key)
+		}
+	}
+}
+
+func hasTerminated(pod *corev1.Pod) bool {
+	for _, cs := range pod.Status.InitContainerStatuses {
+		if cs.State.Terminated != nil && cs.State.Terminated.ExitCode != 0 || cs.LastTerminationState.Terminated != nil {
+			return true
+		}
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Terminated != nil || cs.LastTerminationState.Terminated != nil {
+			return true
+		}
+	}
+	return false
+}
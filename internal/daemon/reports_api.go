@@ -3,26 +3,75 @@ package daemon
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	"github.com/kadirbelkuyu/kubecrsh/internal/reporter"
 )
 
+const maxIngestBytes = 32 << 20
+
 type reportSummary struct {
-	ID          string    `json:"id"`
-	Namespace   string    `json:"namespace"`
-	PodName     string    `json:"podName"`
-	Container   string    `json:"container"`
-	Reason      string    `json:"reason"`
-	CollectedAt time.Time `json:"collectedAt"`
-	Warnings    int       `json:"warnings"`
-	HasLogs     bool      `json:"hasLogs"`
-	HasPrevLogs bool      `json:"hasPreviousLogs"`
-	HasEvents   bool      `json:"hasEvents"`
+	ID           string    `json:"id"`
+	Namespace    string    `json:"namespace"`
+	PodName      string    `json:"podName"`
+	Workload     string    `json:"workload"`
+	Container    string    `json:"container"`
+	Cluster      string    `json:"cluster,omitempty"`
+	Reason       string    `json:"reason"`
+	ExitCode     int32     `json:"exitCode"`
+	Signal       string    `json:"signal,omitempty"`
+	Cause        string    `json:"cause"`
+	Initiator    string    `json:"initiator"`
+	Fingerprint  string    `json:"fingerprint"`
+	Signature    string    `json:"signature,omitempty"`
+	Diagnosis    string    `json:"diagnosis,omitempty"`
+	Findings     int       `json:"findings"`
+	Severity     string    `json:"severity"`
+	Status       string    `json:"status"`
+	Assignee     string    `json:"assignee,omitempty"`
+	CollectedAt  time.Time `json:"collectedAt"`
+	Warnings     int       `json:"warnings"`
+	HasLogs      bool      `json:"hasLogs"`
+	HasPrevLogs  bool      `json:"hasPreviousLogs"`
+	HasLastWords bool      `json:"hasLastWords"`
+	HasEvents    bool      `json:"hasEvents"`
+
+	Integrity *reporter.ReportIntegrity `json:"integrity,omitempty"`
+}
+
+type searchHit struct {
+	reportSummary
+	Highlights []string `json:"highlights,omitempty"`
+}
+
+type groupSummary struct {
+	Fingerprint    string    `json:"fingerprint"`
+	Namespace      string    `json:"namespace"`
+	Workload       string    `json:"workload"`
+	Container      string    `json:"container"`
+	Reason         string    `json:"reason"`
+	ExitCode       int32     `json:"exitCode"`
+	Signature      string    `json:"signature"`
+	Count          int       `json:"count"`
+	FirstSeen      time.Time `json:"firstSeen"`
+	LastSeen       time.Time `json:"lastSeen"`
+	LatestReportID string    `json:"latestReportId"`
+}
+
+func (s *Server) reportsHandler(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && s.apiReportsEnabled:
+		s.reportsListHandler(w, r)
+	case r.Method == http.MethodPost && s.apiIngestEnabled:
+		s.reportIngestHandler(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
 }
 
 func (s *Server) reportsListHandler(w http.ResponseWriter, r *http.Request) {
@@ -36,50 +85,110 @@ func (s *Server) reportsListHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	limit := parseIntQuery(r, "limit", 200)
-	if limit <= 0 {
-		limit = 200
-	}
-	if limit > 1000 {
-		limit = 1000
-	}
-
-	offset := parseIntQuery(r, "offset", 0)
-	if offset < 0 {
-		offset = 0
+	q, err := parseReportQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	reports, err := s.store.List()
+	res, err := reporter.QueryReports(s.store, q)
 	if err != nil {
 		http.Error(w, "failed to list reports", http.StatusInternalServerError)
 		fmt.Printf("Failed to list reports: %v\n", err)
 		return
 	}
 
-	sort.Slice(reports, func(i, j int) bool {
-		return reports[i].CollectedAt.After(reports[j].CollectedAt)
-	})
+	items := make([]reportSummary, 0, len(res.Reports))
+	for _, rep := range res.Reports {
+		items = append(items, summarizeReport(rep))
+	}
 
-	if offset >= len(reports) {
-		writeJSON(w, http.StatusOK, map[string]any{"items": []reportSummary{}, "total": len(reports)})
-		return
+	resp := map[string]any{"items": items, "total": res.Total}
+	if res.NextCursor != "" {
+		resp["nextCursor"] = res.NextCursor
 	}
+	writeJSON(w, http.StatusOK, resp)
+}
 
-	end := offset + limit
-	if end > len(reports) {
-		end = len(reports)
+func parseReportQuery(r *http.Request) (reporter.Query, error) {
+	values := r.URL.Query()
+	get := func(key string) string {
+		return strings.TrimSpace(values.Get(key))
 	}
 
-	items := make([]reportSummary, 0, end-offset)
-	for _, rep := range reports[offset:end] {
-		items = append(items, summarizeReport(rep))
+	q := reporter.Query{
+		Namespace:   get("namespace"),
+		Workload:    get("workload"),
+		PodName:     get("pod"),
+		Reason:      get("reason"),
+		Fingerprint: get("fingerprint"),
+		Status:      get("status"),
+		MinSeverity: strings.ToLower(get("minSeverity")),
+		Sort:        get("sort"),
+		Cursor:      get("cursor"),
+		Limit:       parseIntQuery(r, "limit", reporter.DefaultQueryLimit),
+		Offset:      parseIntQuery(r, "offset", 0),
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = reporter.DefaultQueryLimit
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	if severity := get("severity"); severity != "" {
+		for _, level := range strings.Split(severity, ",") {
+			q.Severities = append(q.Severities, strings.ToLower(strings.TrimSpace(level)))
+		}
+	}
+
+	if raw := get("exitCode"); raw != "" {
+		code, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil {
+			return q, fmt.Errorf("invalid exitCode %q", raw)
+		}
+		exitCode := int32(code)
+		q.ExitCode = &exitCode
+	}
+
+	for key, dst := range map[string]*time.Time{"since": &q.Since, "until": &q.Until} {
+		raw := get(key)
+		if raw == "" {
+			continue
+		}
+		t, err := parseTimeQuery(raw)
+		if err != nil {
+			return q, fmt.Errorf("invalid %s %q", key, raw)
+		}
+		*dst = t
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{"items": items, "total": len(reports)})
+	return q, q.Validate()
+}
+
+// parseTimeQuery accepts an RFC 3339 timestamp or a duration such as 24h,
+// meaning that long ago.
+func parseTimeQuery(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().Add(-d), nil
 }
 
 func (s *Server) reportGetHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	id := strings.TrimPrefix(r.URL.Path, "/reports/")
+	id = strings.TrimSpace(id)
+	if reportID, ok := strings.CutSuffix(id, "/triage"); ok {
+		s.triageHandler(w, r, reportID)
+		return
+	}
+
+	if r.Method != http.MethodGet || !s.apiReportsEnabled {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
@@ -89,8 +198,22 @@ func (s *Server) reportGetHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id := strings.TrimPrefix(r.URL.Path, "/reports/")
-	id = strings.TrimSpace(id)
+	if reportID, ok := strings.CutSuffix(id, "/debug-manifest"); ok {
+		s.debugManifestHandler(w, r, reportID)
+		return
+	}
+	if reportID, ok := strings.CutSuffix(id, "/export"); ok {
+		s.exportHandler(w, r, reportID)
+		return
+	}
+	if id == "groups" {
+		s.groupsHandler(w)
+		return
+	}
+	if id == "search" {
+		s.searchHandler(w, r)
+		return
+	}
 	if id == "" || strings.Contains(id, "/") {
 		w.WriteHeader(http.StatusNotFound)
 		return
@@ -113,7 +236,133 @@ func (s *Server) reportGetHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, summarizeReport(rep))
+	summary := summarizeReport(rep)
+	if s.ledger != nil {
+		if res, err := s.ledger.VerifyReport(rep); err == nil {
+			summary.Integrity = &res
+		}
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func (s *Server) searchHandler(w http.ResponseWriter, r *http.Request) {
+	q, err := parseReportQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sq := reporter.SearchQuery{Query: q, Text: strings.TrimSpace(r.URL.Query().Get("q"))}
+	if err := sq.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := reporter.SearchReports(s.store, sq)
+	if err != nil {
+		http.Error(w, "failed to search reports", http.StatusInternalServerError)
+		fmt.Printf("Failed to search reports: %v\n", err)
+		return
+	}
+
+	items := make([]searchHit, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		items = append(items, searchHit{reportSummary: summarizeReport(hit.Report), Highlights: hit.Highlights})
+	}
+
+	resp := map[string]any{"items": items, "total": res.Total}
+	if res.NextCursor != "" {
+		resp["nextCursor"] = res.NextCursor
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) groupsHandler(w http.ResponseWriter) {
+	groups, err := reporter.ListGroups(s.store)
+	if err != nil {
+		http.Error(w, "failed to list groups", http.StatusInternalServerError)
+		fmt.Printf("Failed to list groups: %v\n", err)
+		return
+	}
+
+	items := make([]groupSummary, 0, len(groups))
+	for _, g := range groups {
+		items = append(items, groupSummary(g))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"items": items, "total": len(items)})
+}
+
+func (s *Server) reportIngestHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Ingested reports are written to disk, so they are never accepted
+	// anonymously.
+	if strings.TrimSpace(s.apiToken) == "" {
+		http.Error(w, "ingest requires an API token", http.StatusForbidden)
+		return
+	}
+	if !s.authorize(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIngestBytes))
+	if err != nil {
+		http.Error(w, "invalid report", http.StatusBadRequest)
+		return
+	}
+
+	rep, err := reporter.DecodeUntrustedReport(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if rep.Fingerprint == "" {
+		rep.UpdateFingerprint()
+	}
+
+	// A report is never replaced through ingest: resending the same report
+	// is accepted without saving it again, and different content under a
+	// stored ID is refused.
+	if existing, err := s.store.Load(rep.ID); err == nil {
+		same, err := sameContent(existing, rep)
+		if err != nil {
+			http.Error(w, "invalid report", http.StatusBadRequest)
+			return
+		}
+		if !same {
+			http.Error(w, fmt.Sprintf("report %s already exists with different content", rep.ID), http.StatusConflict)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"id": rep.ID})
+		return
+	}
+
+	if err := s.store.Save(rep); err != nil {
+		http.Error(w, "failed to save report", http.StatusInternalServerError)
+		fmt.Printf("Failed to save ingested report: %v\n", err)
+		return
+	}
+	s.record(rep)
+
+	writeJSON(w, http.StatusCreated, map[string]string{"id": rep.ID})
+}
+
+func sameContent(a, b *domain.ForensicReport) (bool, error) {
+	da, err := a.ContentDigest()
+	if err != nil {
+		return false, err
+	}
+	db, err := b.ContentDigest()
+	if err != nil {
+		return false, err
+	}
+	return da == db, nil
 }
 
 func (s *Server) authorize(r *http.Request) bool {
@@ -139,17 +388,42 @@ func summarizeReport(r *domain.ForensicReport) reportSummary {
 		return reportSummary{}
 	}
 
+	exit := r.ExitInfo()
+	diagnosis := ""
+	if f, ok := r.TopFinding(); ok {
+		diagnosis = f.Title
+	}
+
+	assignee := ""
+	if r.Triage != nil {
+		assignee = r.Triage.Assignee
+	}
+
 	return reportSummary{
-		ID:          r.ID,
-		Namespace:   r.Crash.Namespace,
-		PodName:     r.Crash.PodName,
-		Container:   r.Crash.ContainerName,
-		Reason:      r.Crash.Reason,
-		CollectedAt: r.CollectedAt,
-		Warnings:    len(r.Warnings),
-		HasLogs:     len(r.Logs) > 0,
-		HasPrevLogs: len(r.PreviousLog) > 0,
-		HasEvents:   len(r.Events) > 0,
+		ID:           r.ID,
+		Namespace:    r.Crash.Namespace,
+		PodName:      r.Crash.PodName,
+		Workload:     r.Crash.WorkloadName(),
+		Container:    r.Crash.ContainerName,
+		Cluster:      r.SourceCluster(),
+		Reason:       r.Crash.Reason,
+		ExitCode:     exit.ExitCode,
+		Signal:       exit.SignalName,
+		Cause:        exit.Cause,
+		Initiator:    exit.Initiator,
+		Fingerprint:  r.GroupKey(),
+		Signature:    r.Signature,
+		Diagnosis:    diagnosis,
+		Findings:     len(r.Findings),
+		Severity:     r.SeverityLevel(),
+		Status:       r.TriageStatus(),
+		Assignee:     assignee,
+		CollectedAt:  r.CollectedAt,
+		Warnings:     len(r.Warnings),
+		HasLogs:      len(r.Logs) > 0,
+		HasPrevLogs:  len(r.PreviousLog) > 0,
+		HasLastWords: len(r.LastWords) > 0,
+		HasEvents:    len(r.Events) > 0,
 	}
 }
 
@@ -0,0 +1,423 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	"github.com/kadirbelkuyu/kubecrsh/internal/reporter"
+	"github.com/kadirbelkuyu/kubecrsh/pkg/schema"
+)
+
+func TestServer_reportsHandler_Ingest(t *testing.T) {
+	storage := &mockStorage{}
+	server := &Server{store: storage, apiIngestEnabled: true, apiToken: "secret"}
+
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api", ContainerName: "main", NodeName: "node-1"})
+	body, _ := json.Marshal(report)
+
+	req := httptest.NewRequest(http.MethodPost, "/reports", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	server.reportsHandler(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Status without token = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/reports", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	server.reportsHandler(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	if len(storage.saved) != 1 || storage.saved[0].ID != report.ID {
+		t.Fatalf("saved = %v, want report %s", storage.saved, report.ID)
+	}
+	if storage.saved[0].Crash.NodeName != "node-1" {
+		t.Errorf("NodeName = %v, want node-1", storage.saved[0].Crash.NodeName)
+	}
+}
+
+func TestServer_reportsHandler_IngestExisting(t *testing.T) {
+	storage := &mockStorage{}
+	server := &Server{store: storage, apiIngestEnabled: true, apiToken: "secret"}
+
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api", ContainerName: "main"})
+	report.SetLogs([]string{"panic: boom"})
+	body, _ := json.Marshal(report)
+	ingest := func(body []byte) int {
+		w := httptest.NewRecorder()
+		server.reportsHandler(w, newIngestRequest(string(body)))
+		return w.Code
+	}
+
+	if code := ingest(body); code != http.StatusCreated {
+		t.Fatalf("Status = %d, want %d", code, http.StatusCreated)
+	}
+	if code := ingest(body); code != http.StatusOK || len(storage.saved) != 1 {
+		t.Errorf("resend Status = %d, saved %d, want %d and 1", code, len(storage.saved), http.StatusOK)
+	}
+
+	forged := *report
+	forged.SetLogs([]string{"all good"})
+	body, _ = json.Marshal(&forged)
+	if code := ingest(body); code != http.StatusConflict {
+		t.Errorf("overwrite Status = %d, want %d", code, http.StatusConflict)
+	}
+	if len(storage.saved) != 1 || storage.saved[0].Logs[0] != "panic: boom" {
+		t.Errorf("stored report was replaced: %+v", storage.saved)
+	}
+}
+
+func TestServer_reportsHandler_IngestWithoutToken(t *testing.T) {
+	storage := &mockStorage{}
+	server := &Server{store: storage, apiIngestEnabled: true}
+
+	body, _ := json.Marshal(domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"}))
+	w := httptest.NewRecorder()
+	server.reportsHandler(w, httptest.NewRequest(http.MethodPost, "/reports", bytes.NewReader(body)))
+	if w.Code != http.StatusForbidden || len(storage.saved) != 0 {
+		t.Errorf("Status without an API token = %d, saved %d, want %d", w.Code, len(storage.saved), http.StatusForbidden)
+	}
+}
+
+func newIngestRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/reports", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	return req
+}
+
+func TestServer_reportsHandler_IngestLegacySchema(t *testing.T) {
+	storage := &mockStorage{}
+	server := &Server{store: storage, apiIngestEnabled: true, apiToken: "secret"}
+
+	body := `{"ID":"abc","Findings":null,"Crash":{"Namespace":"default","PodName":"api","PodUID":"","NodeName":"node-1",` +
+		`"ContainerName":"main","ContainerID":"","Workload":null,"ExitCode":1,"Reason":"Error","Signal":0,"RestartCount":2,` +
+		`"StartedAt":"2024-01-01T00:00:00Z","FinishedAt":"2024-01-01T00:05:00Z"},"Exit":null,"Fingerprint":"","Logs":["panic: boom"],` +
+		`"PreviousLog":null,"LastWords":null,"Events":null,"EnvVars":null,"Warnings":null,"Timeline":null,"OOMKills":null,` +
+		`"CollectedAt":"2024-01-01T00:05:01Z"}`
+	req := newIngestRequest(body)
+	w := httptest.NewRecorder()
+	server.reportsHandler(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	if got := storage.saved[0]; got.SchemaVersion != domain.SchemaVersion || got.Crash.NodeName != "node-1" {
+		t.Errorf("saved = %+v, want a migrated report", got)
+	}
+}
+
+func TestServer_reportSchemaHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	(&Server{}).reportSchemaHandler(w, httptest.NewRequest(http.MethodGet, "/schema/report.json", nil))
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != schema.ReportContentType {
+		t.Fatalf("Status = %d, Content-Type = %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil || doc["$schema"] == nil {
+		t.Errorf("body is not a JSON schema: %v", err)
+	}
+}
+
+func TestServer_reportsHandler_IngestValidation(t *testing.T) {
+	storage := &mockStorage{}
+	server := &Server{store: storage, apiIngestEnabled: true, apiToken: "secret"}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", "{"},
+		{"missing id", `{"Crash":{"Namespace":"default","PodName":"api"}}`},
+		{"missing pod", `{"ID":"abc","Crash":{"Namespace":"default"}}`},
+		{"path in id", `{"ID":"../abc","Crash":{"Namespace":"default","PodName":"api"}}`},
+		{"glob in id", `{"ID":"a*","Crash":{"Namespace":"default","PodName":"api"}}`},
+		{"path in namespace", `{"ID":"abc","Crash":{"Namespace":"a/../../../etc/x","PodName":"api"}}`},
+		{"path in pod", `{"ID":"abc","Crash":{"Namespace":"default","PodName":"../../x"}}`},
+		{"unknown field", `{"schemaVersion":2,"id":"abc","collectedAt":"2024-01-01T00:00:00Z","extra":1,` +
+			`"crash":{"namespace":"default","podName":"api","containerName":"main","exitCode":1,"reason":"Error"}}`},
+		{"wrong type", `{"schemaVersion":2,"id":"abc","collectedAt":"2024-01-01T00:00:00Z","logs":"boom",` +
+			`"crash":{"namespace":"default","podName":"api","containerName":"main","exitCode":1,"reason":"Error"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			server.reportsHandler(w, newIngestRequest(tt.body))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+
+	if len(storage.saved) != 0 {
+		t.Errorf("saved = %d, want 0", len(storage.saved))
+	}
+}
+
+func TestServer_reportsHandler_MethodGating(t *testing.T) {
+	server := &Server{store: &mockStorage{}, apiIngestEnabled: true}
+
+	req := httptest.NewRequest(http.MethodGet, "/reports", nil)
+	w := httptest.NewRecorder()
+	server.reportsHandler(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET with listing disabled = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+
+	server = &Server{store: &mockStorage{}, apiReportsEnabled: true}
+	req = httptest.NewRequest(http.MethodPost, "/reports", bytes.NewBufferString("{}"))
+	w = httptest.NewRecorder()
+	server.reportsHandler(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST with ingest disabled = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestServer_reportGetHandler_Groups(t *testing.T) {
+	storage := &mockStorage{}
+	for i, pod := range []string{"api-7d9f8b6c5-abcde", "api-7d9f8b6c5-fghij", "worker-0"} {
+		report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: pod, ContainerName: "app", Reason: "Error", ExitCode: 1})
+		report.ID = pod
+		report.CollectedAt = report.CollectedAt.Add(time.Duration(i) * time.Minute)
+		report.SetLogs([]string{"panic: timeout after 30s"})
+		report.UpdateFingerprint()
+		storage.saved = append(storage.saved, report)
+	}
+	server := &Server{store: storage, apiReportsEnabled: true}
+
+	req := httptest.NewRequest(http.MethodGet, "/reports/groups", nil)
+	w := httptest.NewRecorder()
+	server.reportGetHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var groups struct {
+		Items []groupSummary `json:"items"`
+		Total int            `json:"total"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&groups); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if groups.Total != 2 {
+		t.Fatalf("Total = %d, want 2", groups.Total)
+	}
+	api := groups.Items[1]
+	if api.Workload != "api" || api.Count != 2 || api.LatestReportID != "api-7d9f8b6c5-fghij" {
+		t.Errorf("api group = %+v", api)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/reports?fingerprint="+api.Fingerprint, nil)
+	w = httptest.NewRecorder()
+	server.reportsListHandler(w, req)
+
+	var list struct {
+		Items []reportSummary `json:"items"`
+		Total int             `json:"total"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if list.Total != 2 {
+		t.Errorf("filtered Total = %d, want 2", list.Total)
+	}
+	for _, item := range list.Items {
+		if item.Fingerprint != api.Fingerprint {
+			t.Errorf("item %s fingerprint = %s, want %s", item.ID, item.Fingerprint, api.Fingerprint)
+		}
+	}
+}
+
+func TestServer_reportsListHandler_Severity(t *testing.T) {
+	storage := &mockStorage{}
+	for _, level := range []string{domain.SeverityLow, domain.SeverityHigh, domain.SeverityCritical} {
+		report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: level, Reason: "Error", ExitCode: 1})
+		report.ID = level
+		report.SetSeverity(domain.Severity{Level: level})
+		storage.saved = append(storage.saved, report)
+	}
+	server := &Server{store: storage, apiReportsEnabled: true}
+
+	list := func(query string) (int, []string) {
+		req := httptest.NewRequest(http.MethodGet, "/reports?"+query, nil)
+		w := httptest.NewRecorder()
+		server.reportsListHandler(w, req)
+
+		var resp struct {
+			Items []reportSummary `json:"items"`
+		}
+		_ = json.NewDecoder(w.Body).Decode(&resp)
+		var levels []string
+		for _, item := range resp.Items {
+			levels = append(levels, item.Severity)
+		}
+		sort.Strings(levels)
+		return w.Code, levels
+	}
+
+	tests := []struct {
+		query string
+		code  int
+		want  []string
+	}{
+		{"severity=low", http.StatusOK, []string{"low"}},
+		{"severity=low,critical", http.StatusOK, []string{"critical", "low"}},
+		{"minSeverity=high", http.StatusOK, []string{"critical", "high"}},
+		{"minSeverity=urgent", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		code, levels := list(tt.query)
+		if code != tt.code {
+			t.Errorf("%s: Status = %d, want %d", tt.query, code, tt.code)
+			continue
+		}
+		if strings.Join(levels, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: severities = %v, want %v", tt.query, levels, tt.want)
+		}
+	}
+}
+
+func TestServer_reportsListHandler_QueryAndCursor(t *testing.T) {
+	storage := &mockStorage{}
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, ns := range []string{"payments", "search", "payments", "payments"} {
+		report := domain.NewForensicReport(domain.PodCrash{Namespace: ns, PodName: "api", Reason: "Error", ExitCode: 1})
+		report.ID = string(rune('a' + i))
+		report.CollectedAt = base.Add(time.Duration(i) * time.Minute)
+		storage.saved = append(storage.saved, report)
+	}
+	server := &Server{store: storage, apiReportsEnabled: true}
+
+	type page struct {
+		Items      []reportSummary `json:"items"`
+		Total      int             `json:"total"`
+		NextCursor string          `json:"nextCursor"`
+	}
+	get := func(query string) (int, page) {
+		req := httptest.NewRequest(http.MethodGet, "/reports?"+query, nil)
+		w := httptest.NewRecorder()
+		server.reportsListHandler(w, req)
+		var p page
+		_ = json.NewDecoder(w.Body).Decode(&p)
+		return w.Code, p
+	}
+
+	code, first := get("namespace=payments&limit=2")
+	if code != http.StatusOK || first.Total != 3 || len(first.Items) != 2 || first.Items[0].ID != "d" || first.NextCursor == "" {
+		t.Fatalf("first page = %d %+v", code, first)
+	}
+
+	_, second := get("namespace=payments&limit=2&cursor=" + first.NextCursor)
+	if len(second.Items) != 1 || second.Items[0].ID != "a" || second.NextCursor != "" {
+		t.Errorf("second page = %+v, want only report a", second)
+	}
+
+	_, ranged := get("since=2024-03-01T12:01:00Z&until=2024-03-01T12:03:00Z&sort=oldest")
+	if ranged.Total != 2 || ranged.Items[0].ID != "b" {
+		t.Errorf("time range = %+v, want b and c oldest first", ranged)
+	}
+
+	for _, bad := range []string{"exitCode=x", "since=yesterday", "sort=up", "cursor=%21", "status=done"} {
+		if code, _ := get(bad); code != http.StatusBadRequest {
+			t.Errorf("%s: Status = %d, want %d", bad, code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestServer_reportGetHandler_Search(t *testing.T) {
+	storage := &mockStorage{}
+	for i, logs := range [][]string{
+		{"dial tcp 10.0.0.7:6379: connect: connection refused", "redis unavailable"},
+		{"dial tcp 10.0.0.9:5432: connect: connection refused"},
+		{"listening on :8080"},
+	} {
+		report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api", Reason: "Error"})
+		report.ID = "r" + string(rune('0'+i))
+		report.SetLogs(logs)
+		storage.saved = append(storage.saved, report)
+	}
+	server := &Server{store: storage, apiReportsEnabled: true}
+
+	req := httptest.NewRequest(http.MethodGet, `/reports/search?q="connection+refused"+redis&since=168h`, nil)
+	w := httptest.NewRecorder()
+	server.reportGetHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var res struct {
+		Items []searchHit `json:"items"`
+		Total int         `json:"total"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if res.Total != 1 || res.Items[0].ID != "r0" {
+		t.Fatalf("search = %+v, want only r0", res)
+	}
+	if len(res.Items[0].Highlights) != 2 || !strings.Contains(res.Items[0].Highlights[0], "connection refused") {
+		t.Errorf("Highlights = %v", res.Items[0].Highlights)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/reports/search", nil)
+	w = httptest.NewRecorder()
+	server.reportGetHandler(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Status without q = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestServer_reportGetHandler_Integrity(t *testing.T) {
+	ledger, err := reporter.OpenLedger(filepath.Join(t.TempDir(), reporter.LedgerFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := &mockStorage{}
+	server := &Server{store: storage, ledger: ledger, apiIngestEnabled: true, apiReportsEnabled: true, apiToken: "secret"}
+
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api", ContainerName: "main"})
+	body, _ := json.Marshal(report)
+	w := httptest.NewRecorder()
+	server.reportsHandler(w, newIngestRequest(string(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("ingest status = %d", w.Code)
+	}
+
+	get := func() reporter.ReportIntegrity {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/reports/"+report.ID, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		server.reportGetHandler(w, req)
+		var resp reportSummary
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Integrity == nil {
+			t.Fatalf("response %s has no integrity: %v", w.Body.String(), err)
+		}
+		return *resp.Integrity
+	}
+
+	if got := get(); got.Status != reporter.IntegrityIntact || !got.LedgerIntact || got.Seq != 1 {
+		t.Errorf("integrity = %+v, want intact", got)
+	}
+
+	storage.saved[0].AddWarning("edited after collection")
+	if got := get(); got.Status != reporter.IntegrityModified {
+		t.Errorf("integrity of an edited report = %+v, want modified", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/kadirbelkuyu/kubecrsh/internal/collector"
@@ -12,26 +13,42 @@ import (
 	"github.com/kadirbelkuyu/kubecrsh/internal/notifier"
 	"github.com/kadirbelkuyu/kubecrsh/internal/reporter"
 	"github.com/kadirbelkuyu/kubecrsh/internal/watcher"
+	"github.com/kadirbelkuyu/kubecrsh/pkg/schema"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
+const lastWordsWait = 3 * time.Second
+
 type Server struct {
 	client    kubernetes.Interface
 	watcher   *watcher.Watcher
 	collector *collector.Collector
-	store     reporter.Storage
-	pruner    interface {
-		Prune(retention time.Duration) (reporter.PruneResult, error)
+	timeline  *timelineTracker
+	logBuffer *collector.LogBuffer
+	pods      *podCache
+	oomKills  interface {
+		Match(crash domain.PodCrash) []domain.OOMKill
+	}
+	diagnoser interface {
+		Diagnose(report *domain.ForensicReport, pod *corev1.Pod) []domain.Finding
+	}
+	classifier interface {
+		Classify(ctx context.Context, report *domain.ForensicReport, pod *corev1.Pod) domain.Severity
 	}
+	store             reporter.Storage
+	ledger            *reporter.Ledger
 	notifiers         []notifier.Notifier
 	metrics           *Metrics
 	httpAddr          string
 	apiReportsEnabled bool
+	apiIngestEnabled  bool
+	apiTriageEnabled  bool
 	apiToken          string
 	apiAllowFull      bool
-	reportRetention   time.Duration
+	retention         reporter.RetentionPolicy
 	pruneInterval     time.Duration
 	collectTimeout    time.Duration
 	redactor          interface {
@@ -42,53 +59,83 @@ type Server struct {
 type Config struct {
 	Namespace         string
 	Reasons           []string
+	NodeName          string
+	NodeLogRoot       string
 	HTTPAddr          string
 	Notifiers         []notifier.Notifier
 	Storage           reporter.Storage
+	Ledger            *reporter.Ledger
 	APIReportsEnabled bool
+	APIIngestEnabled  bool
+	APITriageEnabled  bool
 	APIToken          string
 	APIAllowFull      bool
-	ReportRetention   time.Duration
+	Retention         reporter.RetentionPolicy
 	PruneInterval     time.Duration
 	CollectTimeout    time.Duration
-	Redactor          interface {
+	LogBuffer         *collector.LogBuffer
+	OOMMatcher        interface {
+		Match(crash domain.PodCrash) []domain.OOMKill
+	}
+	Diagnoser interface {
+		Diagnose(report *domain.ForensicReport, pod *corev1.Pod) []domain.Finding
+	}
+	Classifier interface {
+		Classify(ctx context.Context, report *domain.ForensicReport, pod *corev1.Pod) domain.Severity
+	}
+	Redactor interface {
 		Apply(report *domain.ForensicReport)
 	}
 }
 
 func New(client kubernetes.Interface, cfg Config) *Server {
 	metrics := NewMetrics()
-	prometheus.MustRegister(metrics.CrashesTotal, metrics.ReportSize, metrics.NotificationsSent)
+	prometheus.MustRegister(metrics.CrashesTotal, metrics.ReportSize, metrics.NotificationsSent, metrics.RetentionReports, metrics.RetentionBytes)
+
+	var collectorOpts []collector.Option
+	if cfg.NodeLogRoot != "" {
+		collectorOpts = append(collectorOpts, collector.WithNodeLogs(cfg.NodeLogRoot))
+	}
 
 	srv := &Server{
 		client:            client,
-		collector:         collector.New(client),
+		collector:         collector.New(client, collectorOpts...),
+		timeline:          newTimelineTracker(0),
+		logBuffer:         cfg.LogBuffer,
+		pods:              newPodCache(),
+		oomKills:          cfg.OOMMatcher,
+		diagnoser:         cfg.Diagnoser,
+		classifier:        cfg.Classifier,
 		store:             cfg.Storage,
+		ledger:            cfg.Ledger,
 		notifiers:         cfg.Notifiers,
 		metrics:           metrics,
 		httpAddr:          cfg.HTTPAddr,
 		apiReportsEnabled: cfg.APIReportsEnabled,
+		apiIngestEnabled:  cfg.APIIngestEnabled,
+		apiTriageEnabled:  cfg.APITriageEnabled,
 		apiToken:          cfg.APIToken,
 		apiAllowFull:      cfg.APIAllowFull,
-		reportRetention:   cfg.ReportRetention,
+		retention:         cfg.Retention,
 		pruneInterval:     cfg.PruneInterval,
 		collectTimeout:    cfg.CollectTimeout,
 		redactor:          cfg.Redactor,
 	}
 
-	opts := []watcher.Option{watcher.WithReasons(cfg.Reasons)}
+	opts := []watcher.Option{
+		watcher.WithReasons(cfg.Reasons),
+		watcher.WithTerminationHandler(srv.timeline.Record),
+		watcher.WithPodObserver(srv.observePod),
+	}
 	if cfg.Namespace != "" {
 		opts = append(opts, watcher.WithNamespace(cfg.Namespace))
 	}
+	if cfg.NodeName != "" {
+		opts = append(opts, watcher.WithNodeName(cfg.NodeName))
+	}
 
 	srv.watcher = watcher.New(client, srv.handleCrash, opts...)
 
-	if p, ok := cfg.Storage.(interface {
-		Prune(retention time.Duration) (reporter.PruneResult, error)
-	}); ok {
-		srv.pruner = p
-	}
-
 	return srv
 }
 
@@ -97,8 +144,11 @@ func (s *Server) Start(ctx context.Context) error {
 	mux.HandleFunc("/health", s.healthHandler)
 	mux.HandleFunc("/ready", s.readyHandler)
 	mux.Handle("/metrics", promhttp.Handler())
-	if s.apiReportsEnabled {
-		mux.HandleFunc("/reports", s.reportsListHandler)
+	mux.HandleFunc("/schema/report.json", s.reportSchemaHandler)
+	if s.apiReportsEnabled || s.apiIngestEnabled {
+		mux.HandleFunc("/reports", s.reportsHandler)
+	}
+	if s.apiReportsEnabled || s.apiTriageEnabled {
 		mux.HandleFunc("/reports/", s.reportGetHandler)
 	}
 
@@ -115,6 +165,10 @@ func (s *Server) Start(ctx context.Context) error {
 		}
 	}()
 
+	if s.logBuffer != nil {
+		s.logBuffer.Start(ctx)
+	}
+
 	go func() {
 		if err := s.watcher.Start(ctx); err != nil {
 			errCh <- fmt.Errorf("watcher error: %w", err)
@@ -122,6 +176,7 @@ func (s *Server) Start(ctx context.Context) error {
 	}()
 
 	go s.pruneLoop(ctx)
+	go s.timelineCleanupLoop(ctx)
 
 	select {
 	case err := <-errCh:
@@ -148,6 +203,38 @@ func (s *Server) handleCrash(crash domain.PodCrash) {
 		return
 	}
 
+	if s.logBuffer != nil {
+		if lines := s.logBuffer.LastWords(crash, lastWordsWait); len(lines) > 0 {
+			report.SetLastWords(lines)
+		}
+	}
+
+	if s.timeline != nil {
+		s.timeline.Record(crash)
+		report.SetTimeline(s.timeline.Get(crash))
+	}
+
+	if s.oomKills != nil {
+		if kills := s.oomKills.Match(crash); len(kills) > 0 {
+			report.SetOOMKills(kills)
+		}
+	}
+
+	report.UpdateFingerprint()
+
+	var pod *corev1.Pod
+	if s.diagnoser != nil || s.classifier != nil {
+		pod, _ = s.findPod(ctx, crash)
+	}
+
+	if s.diagnoser != nil {
+		s.diagnoser.Diagnose(report, pod)
+	}
+
+	if s.classifier != nil {
+		s.classifier.Classify(ctx, report, pod)
+	}
+
 	if s.redactor != nil {
 		s.redactor.Apply(report)
 	}
@@ -155,6 +242,7 @@ func (s *Server) handleCrash(crash domain.PodCrash) {
 	s.metrics.CrashesTotal.WithLabelValues(
 		crash.Namespace,
 		crash.Reason,
+		report.SeverityLevel(),
 	).Inc()
 
 	for _, n := range s.notifiers {
@@ -180,10 +268,13 @@ func (s *Server) handleCrash(crash domain.PodCrash) {
 			fmt.Printf("Failed to save report: %v\n", err)
 		} else {
 			savedBytes = res.BytesWritten
+			s.record(report)
 		}
 	} else {
 		if err := s.store.Save(report); err != nil {
 			fmt.Printf("Failed to save report: %v\n", err)
+		} else {
+			s.record(report)
 		}
 	}
 
@@ -201,8 +292,21 @@ func (s *Server) handleCrash(crash domain.PodCrash) {
 	s.metrics.ReportSize.Observe(float64(len(data)))
 }
 
+// record adds a saved report to the ledger, when there is one.
+func (s *Server) record(report *domain.ForensicReport) {
+	if s.ledger == nil {
+		return
+	}
+	if _, err := s.ledger.Record(report); err != nil {
+		fmt.Printf("Failed to record report %s in ledger: %v\n", report.ID, err)
+	}
+}
+
 func (s *Server) pruneLoop(ctx context.Context) {
-	if s.pruner == nil || s.reportRetention <= 0 {
+	if s.retention.IsZero() {
+		return
+	}
+	if _, ok := s.store.(reporter.Pruner); !ok {
 		return
 	}
 
@@ -211,9 +315,7 @@ func (s *Server) pruneLoop(ctx context.Context) {
 		interval = time.Hour
 	}
 
-	if _, err := s.pruner.Prune(s.reportRetention); err != nil {
-		fmt.Printf("Failed to prune reports: %v\n", err)
-	}
+	s.prune()
 
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
@@ -223,13 +325,86 @@ func (s *Server) pruneLoop(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			if _, err := s.pruner.Prune(s.reportRetention); err != nil {
-				fmt.Printf("Failed to prune reports: %v\n", err)
+			s.prune()
+		}
+	}
+}
+
+// prune applies the retention policy. Reports whose logs were stripped are
+// recorded in the ledger again as superseded, since their content changed.
+func (s *Server) prune() {
+	res, err := reporter.ApplyRetention(s.store, s.retention)
+	if err != nil {
+		fmt.Printf("Failed to prune reports: %v\n", err)
+	}
+
+	if s.retention.DryRun {
+		for _, a := range res.Actions {
+			fmt.Printf("Retention dry run: would %s report %s (%s)\n", a.Action, a.ID, a.Reason)
+		}
+		if len(res.Actions) > 0 {
+			fmt.Printf("Retention dry run: would delete %d and strip %d reports, reclaiming %d bytes\n", res.Deleted, res.Stripped, res.BytesReclaimed)
+		}
+		return
+	}
+
+	names := make([]string, 0, len(res.Backends))
+	for name := range res.Backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if b := res.Backends[name]; b.Deleted+b.Stripped+b.Failed > 0 {
+			fmt.Printf("Retention: %s deleted %d, stripped %d, failed %d reports\n", name, b.Deleted, b.Stripped, b.Failed)
+		}
+	}
+
+	if len(res.Actions) == 0 && res.Deleted > 0 {
+		// Stores that only prune by age report counts, not actions.
+		s.metrics.RetentionReports.WithLabelValues(reporter.RetentionDelete, reporter.ReasonAge).Add(float64(res.Deleted))
+	}
+	for _, a := range res.Actions {
+		s.metrics.RetentionReports.WithLabelValues(a.Action, a.Reason).Inc()
+		s.metrics.RetentionBytes.WithLabelValues(a.Action).Add(float64(a.Bytes))
+		if a.Action == reporter.RetentionStrip && s.ledger != nil {
+			report, err := s.store.Load(a.ID)
+			if err != nil {
+				fmt.Printf("Failed to load report %s: %v\n", a.ID, err)
+				continue
+			}
+			if _, err := s.ledger.Supersede(report, reporter.LedgerReasonStripped); err != nil {
+				fmt.Printf("Failed to record report %s in ledger: %v\n", a.ID, err)
 			}
 		}
 	}
 }
 
+func (s *Server) timelineCleanupLoop(ctx context.Context) {
+	if s.timeline == nil {
+		return
+	}
+
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.timeline.Cleanup(defaultTimelineTTL)
+			s.pods.Cleanup(defaultPodCacheTTL)
+		}
+	}
+}
+
+func (s *Server) observePod(pod *corev1.Pod) {
+	s.pods.Observe(pod)
+	if s.logBuffer != nil {
+		s.logBuffer.Observe(pod)
+	}
+}
+
 func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte("OK"))
@@ -239,3 +414,9 @@ func (s *Server) readyHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte("Ready"))
 }
+
+func (s *Server) reportSchemaHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", schema.ReportContentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(schema.Report())
+}
@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -22,7 +23,15 @@ func (m *mockStorage) Save(report *domain.ForensicReport) error {
 }
 
 func (m *mockStorage) Load(id string) (*domain.ForensicReport, error) {
-	return nil, m.loadErr
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	for _, r := range m.saved {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("report not found: %s", id)
 }
 
 func (m *mockStorage) List() ([]*domain.ForensicReport, error) {
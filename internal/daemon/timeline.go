@@ -0,0 +1,86 @@
+package daemon
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+const (
+	defaultTimelineEntries = 20
+	defaultTimelineTTL     = 24 * time.Hour
+)
+
+type timelineTracker struct {
+	entries    map[string][]domain.Termination
+	lastSeen   map[string]time.Time
+	maxEntries int
+	mu         sync.Mutex
+}
+
+func newTimelineTracker(maxEntries int) *timelineTracker {
+	if maxEntries <= 0 {
+		maxEntries = defaultTimelineEntries
+	}
+	return &timelineTracker{
+		entries:    make(map[string][]domain.Termination),
+		lastSeen:   make(map[string]time.Time),
+		maxEntries: maxEntries,
+	}
+}
+
+func (t *timelineTracker) Record(crash domain.PodCrash) {
+	if crash.FinishedAt.IsZero() {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := crash.ContainerKey()
+	term := crash.Termination()
+	t.lastSeen[key] = time.Now()
+
+	timeline := t.entries[key]
+	for _, existing := range timeline {
+		if existing.FinishedAt.Equal(term.FinishedAt) {
+			return
+		}
+	}
+
+	timeline = append(timeline, term)
+	sort.Slice(timeline, func(i, j int) bool {
+		return timeline[i].FinishedAt.Before(timeline[j].FinishedAt)
+	})
+
+	if len(timeline) > t.maxEntries {
+		timeline = timeline[len(timeline)-t.maxEntries:]
+	}
+
+	t.entries[key] = timeline
+}
+
+func (t *timelineTracker) Get(crash domain.PodCrash) []domain.Termination {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timeline := t.entries[crash.ContainerKey()]
+	out := make([]domain.Termination, len(timeline))
+	copy(out, timeline)
+	return out
+}
+
+func (t *timelineTracker) Cleanup(ttl time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	for key, seen := range t.lastSeen {
+		if now.Sub(seen) > ttl {
+			delete(t.lastSeen, key)
+			delete(t.entries, key)
+		}
+	}
+}
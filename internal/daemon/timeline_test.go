@@ -0,0 +1,77 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+func TestTimelineTracker_RecordOrdersAndDeduplicates(t *testing.T) {
+	tracker := newTimelineTracker(0)
+	base := time.Now()
+
+	crash := func(finished time.Time, reason string) domain.PodCrash {
+		return domain.PodCrash{
+			Namespace:     "default",
+			PodName:       "api",
+			ContainerName: "main",
+			Reason:        reason,
+			StartedAt:     finished.Add(-3 * time.Second),
+			FinishedAt:    finished,
+		}
+	}
+
+	tracker.Record(crash(base.Add(20*time.Second), "OOMKilled"))
+	tracker.Record(crash(base, "Error"))
+	tracker.Record(crash(base.Add(10*time.Second), "Error"))
+	tracker.Record(crash(base.Add(20*time.Second), "CrashLoopBackOff"))
+	tracker.Record(domain.PodCrash{Namespace: "default", PodName: "api", ContainerName: "main"})
+
+	timeline := tracker.Get(crash(base, ""))
+	if len(timeline) != 3 {
+		t.Fatalf("timeline length = %d, want 3", len(timeline))
+	}
+	if !timeline[0].FinishedAt.Equal(base) {
+		t.Errorf("timeline[0] not oldest termination")
+	}
+	if timeline[2].Reason != "OOMKilled" {
+		t.Errorf("timeline[2].Reason = %v, want OOMKilled (first record wins)", timeline[2].Reason)
+	}
+}
+
+func TestTimelineTracker_CapsEntries(t *testing.T) {
+	tracker := newTimelineTracker(2)
+	base := time.Now()
+
+	for i := 0; i < 5; i++ {
+		tracker.Record(domain.PodCrash{
+			Namespace:     "default",
+			PodName:       "api",
+			ContainerName: "main",
+			RestartCount:  int32(i),
+			FinishedAt:    base.Add(time.Duration(i) * time.Second),
+		})
+	}
+
+	timeline := tracker.Get(domain.PodCrash{Namespace: "default", PodName: "api", ContainerName: "main"})
+	if len(timeline) != 2 {
+		t.Fatalf("timeline length = %d, want 2", len(timeline))
+	}
+	if timeline[1].RestartCount != 4 {
+		t.Errorf("newest RestartCount = %d, want 4", timeline[1].RestartCount)
+	}
+}
+
+func TestTimelineTracker_Cleanup(t *testing.T) {
+	tracker := newTimelineTracker(0)
+	crash := domain.PodCrash{Namespace: "default", PodName: "api", ContainerName: "main", FinishedAt: time.Now()}
+	tracker.Record(crash)
+
+	tracker.lastSeen[crash.ContainerKey()] = time.Now().Add(-2 * time.Hour)
+	tracker.Cleanup(time.Hour)
+
+	if got := tracker.Get(crash); len(got) != 0 {
+		t.Errorf("timeline length after cleanup = %d, want 0", len(got))
+	}
+}
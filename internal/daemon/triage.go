@@ -0,0 +1,104 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	"github.com/kadirbelkuyu/kubecrsh/internal/reporter"
+)
+
+const (
+	actorHeader    = "X-Kubecrsh-Actor"
+	maxTriageBytes = 64 << 10
+
+	// tokenActor is the principal of requests authenticated with the API
+	// token. The token is shared, so it does not name a person.
+	tokenActor = "api-token"
+)
+
+type triageResponse struct {
+	ID     string         `json:"id"`
+	Triage *domain.Triage `json:"triage"`
+}
+
+func (s *Server) triageHandler(w http.ResponseWriter, r *http.Request, id string) {
+	if !s.apiTriageEnabled {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Triage changes are audited, so they are never accepted anonymously.
+	if r.Method != http.MethodGet && strings.TrimSpace(s.apiToken) == "" {
+		http.Error(w, "triage updates require an API token", http.StatusForbidden)
+		return
+	}
+	if !s.authorize(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if id == "" || strings.Contains(id, "/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		rep, err := s.store.Load(id)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, triageResponse{ID: rep.ID, Triage: triageOf(rep)})
+		return
+	}
+
+	if r.Method != http.MethodPost && r.Method != http.MethodPatch {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var update domain.TriageUpdate
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTriageBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&update); err != nil {
+		http.Error(w, "invalid triage update", http.StatusBadRequest)
+		return
+	}
+	if err := update.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.store.Load(id); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	actor := domain.TriageActor{Name: tokenActor, Hint: actorHint(r), Source: "api"}
+	rep, err := reporter.UpdateTriage(s.store, id, update, actor)
+	if err != nil {
+		http.Error(w, "failed to update triage", http.StatusInternalServerError)
+		fmt.Printf("Failed to update triage for report %s: %v\n", id, err)
+		return
+	}
+
+	fmt.Printf("Triage of report %s updated by %s (claims %q) from %s: status=%s assignee=%q\n",
+		id, actor.Name, actor.Hint, r.RemoteAddr, rep.TriageStatus(), rep.Triage.Assignee)
+
+	writeJSON(w, http.StatusOK, triageResponse{ID: rep.ID, Triage: rep.Triage})
+}
+
+// actorHint is the unverified name the client sent in the actor header.
+func actorHint(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get(actorHeader))
+}
+
+func triageOf(r *domain.ForensicReport) *domain.Triage {
+	if r.Triage != nil {
+		return r.Triage
+	}
+	return &domain.Triage{Status: domain.TriageNew}
+}
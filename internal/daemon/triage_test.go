@@ -0,0 +1,105 @@
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+func TestServer_triageHandler(t *testing.T) {
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+	storage := &mockStorage{saved: []*domain.ForensicReport{report}}
+	server := &Server{store: storage, apiReportsEnabled: true, apiTriageEnabled: true, apiToken: "secret"}
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/reports/"+report.ID+"/triage", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set(actorHeader, "alice")
+		w := httptest.NewRecorder()
+		server.reportGetHandler(w, req)
+		return w
+	}
+
+	w := post(`{"status":"acknowledged","assignee":"alice","note":"on it","link":{"title":"OPS-1","url":"https://tickets.example.com/OPS-1"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var resp triageResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Triage.Status != domain.TriageAcknowledged || resp.Triage.Assignee != "alice" || len(resp.Triage.Links) != 1 {
+		t.Errorf("Triage = %+v", resp.Triage)
+	}
+	if h := resp.Triage.History; len(h) != 4 || h[0].Actor != tokenActor || h[0].ActorHint != "alice" || h[0].Source != "api" {
+		t.Errorf("History = %+v, want audited entries", h)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/reports?status=acknowledged", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	server.reportsListHandler(w, req)
+	if !strings.Contains(w.Body.String(), `"status":"acknowledged"`) || !strings.Contains(w.Body.String(), `"assignee":"alice"`) {
+		t.Errorf("list body = %s, want the triage state in the summary", w.Body)
+	}
+
+	for _, tt := range []struct {
+		name string
+		body string
+		want int
+	}{
+		{"invalid status", `{"status":"done"}`, http.StatusBadRequest},
+		{"empty update", `{}`, http.StatusBadRequest},
+		{"unknown field", `{"state":"resolved"}`, http.StatusBadRequest},
+		{"bad link", `{"link":{"url":"ftp://x"}}`, http.StatusBadRequest},
+	} {
+		if w := post(tt.body); w.Code != tt.want {
+			t.Errorf("%s: Status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestServer_triageHandler_Gating(t *testing.T) {
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+	body := `{"status":"resolved"}`
+
+	tests := []struct {
+		name   string
+		server *Server
+		id     string
+		token  string
+		want   int
+	}{
+		{"disabled", &Server{apiReportsEnabled: true}, report.ID, "", http.StatusMethodNotAllowed},
+		{"unauthorized", &Server{apiTriageEnabled: true, apiToken: "secret"}, report.ID, "wrong", http.StatusUnauthorized},
+		{"no token configured", &Server{apiTriageEnabled: true}, report.ID, "", http.StatusForbidden},
+		{"missing report", &Server{apiTriageEnabled: true, apiToken: "secret"}, "nope", "secret", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.server.store = &mockStorage{saved: []*domain.ForensicReport{report}}
+			req := httptest.NewRequest(http.MethodPost, "/reports/"+tt.id+"/triage", strings.NewReader(body))
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			tt.server.reportGetHandler(w, req)
+			if w.Code != tt.want {
+				t.Errorf("Status = %d, want %d", w.Code, tt.want)
+			}
+			if saved := tt.server.store.(*mockStorage).saved; len(saved) != 1 || saved[0].Triage != nil {
+				t.Error("triage was updated")
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	(&Server{apiTriageEnabled: true}).reportGetHandler(w, httptest.NewRequest(http.MethodGet, "/reports/"+report.ID, nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("read endpoints with only triage enabled: Status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,109 @@
+package debugpod
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	AnnotationDebugOf         = "kubecrsh.io/debug-of"
+	AnnotationReportID        = "kubecrsh.io/report-id"
+	AnnotationOriginalCommand = "kubecrsh.io/original-command"
+
+	maxNameLength = 63
+)
+
+var sleepCommand = []string{"sleep", "infinity"}
+
+func Build(pod *corev1.Pod, report *domain.ForensicReport) *corev1.Pod {
+	src := pod.DeepCopy()
+	target := report.Crash.ContainerName
+
+	digests := make(map[string]string)
+	for _, cs := range src.Status.InitContainerStatuses {
+		digests[cs.Name] = cs.ImageID
+	}
+	for _, cs := range src.Status.ContainerStatuses {
+		digests[cs.Name] = cs.ImageID
+	}
+
+	annotations := map[string]string{
+		AnnotationDebugOf:  src.Name,
+		AnnotationReportID: report.ID,
+	}
+
+	spec := src.Spec
+	spec.NodeName = ""
+	spec.EphemeralContainers = nil
+	spec.RestartPolicy = corev1.RestartPolicyNever
+
+	for i := range spec.InitContainers {
+		prepareContainer(&spec.InitContainers[i], digests, target, annotations)
+	}
+	for i := range spec.Containers {
+		prepareContainer(&spec.Containers[i], digests, target, annotations)
+	}
+
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Pod",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        debugName(src.Name),
+			Namespace:   src.Namespace,
+			Annotations: annotations,
+		},
+		Spec: spec,
+	}
+}
+
+func YAML(pod *corev1.Pod) ([]byte, error) {
+	data, err := yaml.Marshal(pod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal debug manifest: %w", err)
+	}
+	return data, nil
+}
+
+func prepareContainer(c *corev1.Container, digests map[string]string, target string, annotations map[string]string) {
+	c.LivenessProbe = nil
+	c.ReadinessProbe = nil
+	c.StartupProbe = nil
+	c.Lifecycle = nil
+
+	if image := pinnedImage(digests[c.Name]); image != "" {
+		c.Image = image
+	}
+
+	if c.Name != target {
+		return
+	}
+
+	if original := strings.Join(append(append([]string{}, c.Command...), c.Args...), " "); original != "" {
+		annotations[AnnotationOriginalCommand] = original
+	}
+	c.Command = append([]string{}, sleepCommand...)
+	c.Args = nil
+}
+
+func pinnedImage(imageID string) string {
+	imageID = strings.TrimPrefix(imageID, "docker-pullable://")
+	if strings.Index(imageID, "@sha256:") > 0 {
+		return imageID
+	}
+	return ""
+}
+
+func debugName(name string) string {
+	const suffix = "-debug"
+	if len(name)+len(suffix) > maxNameLength {
+		name = strings.TrimRight(name[:maxNameLength-len(suffix)], "-.")
+	}
+	return name + suffix
+}
@@ -0,0 +1,113 @@
+package debugpod
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func crashedPod() *corev1.Pod {
+	probe := &corev1.Probe{ProbeHandler: corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: []string{"true"}}}}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "api-7d9f",
+			Namespace:       "prod",
+			UID:             "uid-1",
+			ResourceVersion: "42",
+			Labels:          map[string]string{"app": "api"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "api"}},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node-1",
+			Containers: []corev1.Container{
+				{
+					Name:           "main",
+					Image:          "registry.local/api:1.2",
+					Command:        []string{"/app"},
+					Args:           []string{"--port=8080"},
+					Env:            []corev1.EnvVar{{Name: "MODE", Value: "prod"}},
+					LivenessProbe:  probe,
+					ReadinessProbe: probe,
+					VolumeMounts:   []corev1.VolumeMount{{Name: "data", MountPath: "/data"}},
+				},
+				{
+					Name:          "sidecar",
+					Image:         "registry.local/proxy:3",
+					StartupProbe:  probe,
+					LivenessProbe: probe,
+				},
+			},
+			Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "main", ImageID: "registry.local/api@sha256:abc"},
+				{Name: "sidecar", ImageID: "sha256:def"},
+			},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	pod := crashedPod()
+	report := &domain.ForensicReport{ID: "r1", Crash: domain.PodCrash{Namespace: "prod", PodName: "api-7d9f", ContainerName: "main"}}
+
+	debug := Build(pod, report)
+
+	if debug.Name != "api-7d9f-debug" || debug.Namespace != "prod" {
+		t.Errorf("name = %s/%s", debug.Namespace, debug.Name)
+	}
+	if len(debug.Labels) != 0 || len(debug.OwnerReferences) != 0 || debug.UID != "" || debug.ResourceVersion != "" {
+		t.Errorf("metadata not stripped: %+v", debug.ObjectMeta)
+	}
+	if debug.Annotations[AnnotationReportID] != "r1" || debug.Annotations[AnnotationOriginalCommand] != "/app --port=8080" {
+		t.Errorf("annotations = %v", debug.Annotations)
+	}
+	if debug.Spec.NodeName != "" || debug.Spec.RestartPolicy != corev1.RestartPolicyNever {
+		t.Errorf("spec = nodeName %q restartPolicy %q", debug.Spec.NodeName, debug.Spec.RestartPolicy)
+	}
+
+	main := debug.Spec.Containers[0]
+	if strings.Join(main.Command, " ") != "sleep infinity" || main.Args != nil {
+		t.Errorf("main command = %v %v", main.Command, main.Args)
+	}
+	if main.Image != "registry.local/api@sha256:abc" {
+		t.Errorf("main image = %s", main.Image)
+	}
+	if main.LivenessProbe != nil || main.ReadinessProbe != nil {
+		t.Error("probes should be removed")
+	}
+	if len(main.Env) != 1 || len(main.VolumeMounts) != 1 || len(debug.Spec.Volumes) != 1 {
+		t.Error("env and volumes should be preserved")
+	}
+
+	sidecar := debug.Spec.Containers[1]
+	if sidecar.Image != "registry.local/proxy:3" || sidecar.Command != nil {
+		t.Errorf("sidecar = %s %v", sidecar.Image, sidecar.Command)
+	}
+	if sidecar.StartupProbe != nil || sidecar.LivenessProbe != nil {
+		t.Error("sidecar probes should be removed")
+	}
+
+	if pod.Spec.Containers[0].LivenessProbe == nil || pod.Labels["app"] != "api" {
+		t.Error("source pod must not be modified")
+	}
+
+	data, err := YAML(debug)
+	if err != nil {
+		t.Fatalf("YAML() error = %v", err)
+	}
+	if !strings.Contains(string(data), "kind: Pod") {
+		t.Errorf("manifest missing kind:\n%s", data)
+	}
+}
+
+func TestDebugName_Truncates(t *testing.T) {
+	name := debugName(strings.Repeat("a", 70))
+	if len(name) > maxNameLength || !strings.HasSuffix(name, "-debug") {
+		t.Errorf("debugName() = %s", name)
+	}
+}
@@ -0,0 +1,134 @@
+package diagnosis
+
+import (
+	"fmt"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type Input struct {
+	Report *domain.ForensicReport
+	Pod    *corev1.Pod
+}
+
+type Rule interface {
+	Name() string
+	Evaluate(in Input) []domain.Finding
+}
+
+type ruleFunc struct {
+	name string
+	fn   func(in Input) []domain.Finding
+}
+
+func NewRule(name string, fn func(in Input) []domain.Finding) Rule {
+	return ruleFunc{name: name, fn: fn}
+}
+
+func (r ruleFunc) Name() string {
+	return r.name
+}
+
+func (r ruleFunc) Evaluate(in Input) []domain.Finding {
+	return r.fn(in)
+}
+
+type Engine struct {
+	rules    []Rule
+	disabled map[string]bool
+}
+
+type Option func(*Engine)
+
+func WithRules(rules ...Rule) Option {
+	return func(e *Engine) {
+		e.rules = append(e.rules, rules...)
+	}
+}
+
+func WithDisabledRules(names ...string) Option {
+	return func(e *Engine) {
+		for _, name := range names {
+			e.disabled[name] = true
+		}
+	}
+}
+
+func New(opts ...Option) *Engine {
+	e := &Engine{
+		rules:    DefaultRules(),
+		disabled: make(map[string]bool),
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+func (e *Engine) Diagnose(report *domain.ForensicReport, pod *corev1.Pod) []domain.Finding {
+	in := Input{Report: report, Pod: pod}
+
+	var findings []domain.Finding
+	for _, rule := range e.rules {
+		if e.disabled[rule.Name()] {
+			continue
+		}
+
+		results, err := evaluate(rule, in)
+		if err != nil {
+			report.AddWarning(err.Error())
+			continue
+		}
+
+		for _, f := range results {
+			if f.Rule == "" {
+				f.Rule = rule.Name()
+			}
+			findings = append(findings, f)
+		}
+	}
+
+	report.SetFindings(findings)
+	return report.Findings
+}
+
+func evaluate(rule Rule, in Input) (findings []domain.Finding, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("diagnosis rule %s panicked: %v", rule.Name(), r)
+		}
+	}()
+
+	return rule.Evaluate(in), nil
+}
+
+func (in Input) Lines() []string {
+	r := in.Report
+	lines := make([]string, 0, len(r.LastWords)+len(r.Logs)+len(r.PreviousLog))
+	lines = append(lines, r.LastWords...)
+	lines = append(lines, r.Logs...)
+	lines = append(lines, r.PreviousLog...)
+	return lines
+}
+
+func (in Input) Container() *corev1.Container {
+	if in.Pod == nil {
+		return nil
+	}
+
+	name := in.Report.Crash.ContainerName
+	for i := range in.Pod.Spec.Containers {
+		if in.Pod.Spec.Containers[i].Name == name {
+			return &in.Pod.Spec.Containers[i]
+		}
+	}
+	for i := range in.Pod.Spec.InitContainers {
+		if in.Pod.Spec.InitContainers[i].Name == name {
+			return &in.Pod.Spec.InitContainers[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package diagnosis
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func testPod(c corev1.Container) *corev1.Pod {
+	return &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{c}}}
+}
+
+func testReport(reason string, exitCode int32, logs ...string) *domain.ForensicReport {
+	r := domain.NewForensicReport(domain.PodCrash{
+		Namespace:     "payments",
+		PodName:       "api-7d9f8b6c5-abcde",
+		NodeName:      "node-1",
+		ContainerName: "app",
+		Reason:        reason,
+		ExitCode:      exitCode,
+	})
+	r.SetLogs(logs)
+	return r
+}
+
+func TestEngine_Diagnose(t *testing.T) {
+	tests := []struct {
+		name   string
+		report func() *domain.ForensicReport
+		pod    *corev1.Pod
+		rule   string
+		title  string
+		detail string
+	}{
+		{
+			name: "memory limit with steady growth",
+			report: func() *domain.ForensicReport {
+				r := testReport("OOMKilled", 137)
+				r.Crash.StartedAt = time.Now().Add(-3 * time.Hour)
+				r.Crash.FinishedAt = time.Now()
+				r.SetOOMKills([]domain.OOMKill{{Constraint: domain.ConstraintMemcg, AnonRSSKB: 260000, FileRSSKB: 1000}})
+				return r
+			},
+			pod: testPod(corev1.Container{Name: "app", Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+			}}),
+			rule:   RuleMemoryLimit,
+			title:  "Memory limit 256Mi reached",
+			detail: "grew steadily",
+		},
+		{
+			name: "missing secret behind env",
+			report: func() *domain.ForensicReport {
+				r := testReport("CreateContainerConfigError", 0)
+				r.AddEvent(*domain.NewEvent("Warning", "Failed", `Error: secret "db-creds" not found`))
+				return r
+			},
+			pod: testPod(corev1.Container{Name: "app", Env: []corev1.EnvVar{{
+				Name: "DATABASE_URL",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "db-creds"},
+					Key:                  "url",
+				}},
+			}}}),
+			rule:  RuleMissingConfig,
+			title: `Missing env DATABASE_URL: referenced secret "db-creds" doesn't exist`,
+		},
+		{
+			name: "missing env reported by the application",
+			report: func() *domain.ForensicReport {
+				return testReport("Error", 1, "fatal: environment variable DATABASE_URL is not set")
+			},
+			pod:    testPod(corev1.Container{Name: "app"}),
+			rule:   RuleMissingEnv,
+			title:  "Missing env DATABASE_URL",
+			detail: "not defined in the pod spec",
+		},
+		{
+			name:   "exec format error",
+			report: func() *domain.ForensicReport { return testReport("Error", 1, "exec /app/server: exec format error") },
+			pod:    testPod(corev1.Container{Name: "app", Image: "registry/api:1.2"}),
+			rule:   RuleExecFormat,
+			title:  "exec format error: wrong image architecture",
+			detail: "registry/api:1.2",
+		},
+		{
+			name: "port in use",
+			report: func() *domain.ForensicReport {
+				return testReport("Error", 1, "listen tcp 0.0.0.0:8080: bind: address already in use")
+			},
+			rule:  RulePortInUse,
+			title: "Port 8080 already in use",
+		},
+		{
+			name:   "command not found",
+			report: func() *domain.ForensicReport { return testReport("StartError", 127) },
+			rule:   RuleCommandMissing,
+			title:  "Entrypoint not found in the image",
+		},
+		{
+			name: "liveness probe",
+			report: func() *domain.ForensicReport {
+				r := testReport("Error", 137)
+				r.AddEvent(*domain.NewEvent("Warning", "Unhealthy", "Liveness probe failed: HTTP probe failed with statuscode: 500"))
+				return r
+			},
+			rule:  RuleLivenessProbe,
+			title: "Restarted by the kubelet after failing its liveness probe",
+		},
+		{
+			name: "read-only filesystem",
+			report: func() *domain.ForensicReport {
+				return testReport("Error", 1, "open /var/cache/app: read-only file system")
+			},
+			rule:  RulePermission,
+			title: "Write to a read-only filesystem",
+		},
+		{
+			name: "dependency unreachable",
+			report: func() *domain.ForensicReport {
+				return testReport("Error", 1, "dial tcp postgres:5432: connect: connection refused")
+			},
+			rule:  RuleDependency,
+			title: "Dependency unreachable: postgres:5432 (connection refused)",
+		},
+	}
+
+	engine := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := tt.report()
+			findings := engine.Diagnose(report, tt.pod)
+
+			var found *domain.Finding
+			for i := range findings {
+				if findings[i].Rule == tt.rule {
+					found = &findings[i]
+				}
+			}
+			if found == nil {
+				t.Fatalf("no %s finding in %+v", tt.rule, findings)
+			}
+			if found.Title != tt.title {
+				t.Errorf("Title = %q, want %q", found.Title, tt.title)
+			}
+			if !strings.Contains(found.Detail, tt.detail) {
+				t.Errorf("Detail = %q, want it to contain %q", found.Detail, tt.detail)
+			}
+			if found.Action == "" || found.Confidence <= 0 {
+				t.Errorf("finding missing action or confidence: %+v", found)
+			}
+			if len(report.Findings) != len(findings) {
+				t.Errorf("report.Findings has %d entries, want %d", len(report.Findings), len(findings))
+			}
+		})
+	}
+}
+
+func TestEngine_NoFindings(t *testing.T) {
+	report := testReport("Error", 1, "shutting down")
+	if findings := New().Diagnose(report, nil); len(findings) != 0 {
+		t.Errorf("findings = %+v, want none", findings)
+	}
+}
+
+func TestEngine_OrdersBySeverityAndConfidence(t *testing.T) {
+	report := testReport("Error", 1,
+		"panic: dial tcp postgres:5432: connect: connection refused",
+		"listen tcp :8080: bind: address already in use",
+	)
+
+	findings := New().Diagnose(report, nil)
+	if len(findings) != 3 {
+		t.Fatalf("len(findings) = %d, want 3: %+v", len(findings), findings)
+	}
+
+	want := []string{RulePortInUse, RuleDependency, RulePanic}
+	for i, rule := range want {
+		if findings[i].Rule != rule {
+			t.Errorf("findings[%d].Rule = %s, want %s", i, findings[i].Rule, rule)
+		}
+	}
+}
+
+func TestEngine_CustomAndDisabledRules(t *testing.T) {
+	custom := NewRule("custom", func(in Input) []domain.Finding {
+		return []domain.Finding{{Severity: domain.FindingWarning, Confidence: 1, Title: "custom"}}
+	})
+	broken := NewRule("broken", func(in Input) []domain.Finding {
+		panic("boom")
+	})
+
+	report := testReport("Error", 1, "exec format error")
+	engine := New(WithRules(custom, broken), WithDisabledRules(RuleExecFormat))
+	findings := engine.Diagnose(report, nil)
+
+	if len(findings) != 1 || findings[0].Rule != "custom" {
+		t.Errorf("findings = %+v, want only the custom finding", findings)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "broken") {
+		t.Errorf("Warnings = %v, want the panicking rule reported", report.Warnings)
+	}
+}
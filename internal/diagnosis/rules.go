@@ -0,0 +1,480 @@
+package diagnosis
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	RuleMemoryLimit    = "memory-limit"
+	RuleMissingConfig  = "missing-config"
+	RuleMissingEnv     = "missing-env"
+	RuleExecFormat     = "exec-format"
+	RulePortInUse      = "port-in-use"
+	RuleCommandMissing = "command-not-found"
+	RuleLivenessProbe  = "liveness-probe"
+	RulePermission     = "permission-denied"
+	RuleDependency     = "dependency-unreachable"
+	RulePanic          = "application-panic"
+
+	maxEvidence = 3
+)
+
+var (
+	missingObjectRe  = regexp.MustCompile(`(secret|configmap) "([^"]+)" not found`)
+	missingKeyRe     = regexp.MustCompile(`couldn't find key (\S+) in (Secret|ConfigMap) [^/\s]+/(\S+)`)
+	missingEnvLogRe  = regexp.MustCompile(`(?i:environment variable|env var(?:iable)?)\s+["'$]?([A-Z][A-Z0-9_]{2,})["']?\s+(?:is\s+)?(?i:not set|missing|required|undefined|empty)|\b([A-Z][A-Z0-9_]{2,})\s+(?:is not set|must be set|is required|not defined)`)
+	execFormatRe     = regexp.MustCompile(`(?i)exec format error`)
+	portInUseRe      = regexp.MustCompile(`(?i)address already in use|EADDRINUSE`)
+	portRe           = regexp.MustCompile(`:(\d{2,5})\b`)
+	commandMissingRe = regexp.MustCompile(`(?i)executable file not found in \$PATH|exec: "[^"]+": (?:stat .*: )?no such file or directory|command not found`)
+	livenessFailedRe = regexp.MustCompile(`(?i)liveness probe failed|failed liveness probe`)
+	permissionRe     = regexp.MustCompile(`(?i)permission denied|EACCES|operation not permitted`)
+	readOnlyFSRe     = regexp.MustCompile(`(?i)read-only file system|EROFS`)
+	dependencyRe     = regexp.MustCompile(`(?i)(connection refused|no such host|i/o timeout|connection timed out|ECONNREFUSED|ENOTFOUND|ETIMEDOUT)`)
+	hostPortRe       = regexp.MustCompile(`(?:tcp|dial|connect(?:ing)? to|host)\s+([A-Za-z0-9.\-]+:\d{2,5})|(?:lookup)\s+([A-Za-z0-9.\-]+)`)
+	panicRe          = regexp.MustCompile(`^(?:panic: |fatal error: |Traceback \(most recent call last\)|Exception in thread |Unhandled exception|thread '.*' panicked at|FATAL(?:\s|:))`)
+)
+
+func DefaultRules() []Rule {
+	return []Rule{
+		NewRule(RuleMemoryLimit, memoryLimitRule),
+		NewRule(RuleMissingConfig, missingConfigRule),
+		NewRule(RuleMissingEnv, missingEnvRule),
+		NewRule(RuleExecFormat, execFormatRule),
+		NewRule(RulePortInUse, portInUseRule),
+		NewRule(RuleCommandMissing, commandMissingRule),
+		NewRule(RuleLivenessProbe, livenessProbeRule),
+		NewRule(RulePermission, permissionRule),
+		NewRule(RuleDependency, dependencyRule),
+		NewRule(RulePanic, panicRule),
+	}
+}
+
+func memoryLimitRule(in Input) []domain.Finding {
+	crash := in.Report.Crash
+
+	var kill *domain.OOMKill
+	for i := range in.Report.OOMKills {
+		if in.Report.OOMKills[i].IsCgroupLimit() || kill == nil {
+			kill = &in.Report.OOMKills[i]
+		}
+	}
+
+	if !crash.IsOOMKilled() && kill == nil {
+		return nil
+	}
+
+	f := domain.Finding{
+		Severity:   domain.FindingCritical,
+		Confidence: 0.95,
+		Title:      "Container ran out of memory",
+		Action:     "Raise resources.limits.memory above the observed peak, or profile the heap if usage keeps climbing",
+	}
+	if !crash.IsOOMKilled() {
+		f.Confidence = 0.85
+	}
+
+	var details []string
+	if c := in.Container(); c != nil {
+		if limit, ok := c.Resources.Limits[corev1.ResourceMemory]; ok {
+			f.Title = fmt.Sprintf("Memory limit %s reached", limit.String())
+		} else {
+			f.Title = "Killed by the OOM killer without a memory limit"
+			details = append(details, "the container has no memory limit, so the node itself ran out of memory")
+			f.Action = "Set resources.requests.memory and resources.limits.memory so the scheduler accounts for the real usage"
+		}
+	}
+
+	if kill != nil {
+		details = append(details, fmt.Sprintf("RSS at kill was %s (anon %s)", formatKiB(kill.RSSKB()), formatKiB(kill.AnonRSSKB)))
+	}
+
+	if ran := crash.FinishedAt.Sub(crash.StartedAt); !crash.StartedAt.IsZero() && ran > 0 {
+		switch {
+		case ran >= 10*time.Minute:
+			details = append(details, fmt.Sprintf("it ran for %s before hitting the limit, so usage grew steadily (a leak or an unbounded cache)", ran.Round(time.Second)))
+		case ran < time.Minute:
+			details = append(details, fmt.Sprintf("it hit the limit %s after starting, so the startup working set alone exceeds the limit", ran.Round(time.Second)))
+		}
+	}
+
+	f.Detail = strings.Join(details, "; ")
+	return []domain.Finding{f}
+}
+
+func missingConfigRule(in Input) []domain.Finding {
+	var findings []domain.Finding
+	seen := make(map[string]bool)
+
+	for _, e := range in.Report.Events {
+		var kind, name, key string
+		if m := missingKeyRe.FindStringSubmatch(e.Message); m != nil {
+			key, kind, name = m[1], strings.ToLower(m[2]), m[3]
+		} else if m := missingObjectRe.FindStringSubmatch(e.Message); m != nil {
+			kind, name = m[1], m[2]
+		} else {
+			continue
+		}
+
+		if seen[kind+"/"+name+"/"+key] {
+			continue
+		}
+		seen[kind+"/"+name+"/"+key] = true
+
+		f := domain.Finding{
+			Severity:   domain.FindingCritical,
+			Confidence: 0.9,
+			Evidence:   []string{e.Message},
+		}
+
+		vars := envReferencing(in.Container(), kind, name, key)
+		missing := fmt.Sprintf("%s %q doesn't exist", kind, name)
+		if key != "" {
+			missing = fmt.Sprintf("%s %q has no key %q", kind, name, key)
+		}
+
+		if len(vars) > 0 {
+			f.Title = fmt.Sprintf("Missing env %s: referenced %s", strings.Join(vars, ", "), missing)
+		} else {
+			f.Title = "Referenced " + missing
+		}
+		f.Action = fmt.Sprintf("Create the %s %s in namespace %s, or mark the reference optional", kind, name, in.Report.Crash.Namespace)
+		if key != "" {
+			f.Action = fmt.Sprintf("Add key %s to %s %s, or fix the key name in the pod spec", key, kind, name)
+		}
+
+		findings = append(findings, f)
+	}
+
+	return findings
+}
+
+func envReferencing(c *corev1.Container, kind, name, key string) []string {
+	if c == nil {
+		return nil
+	}
+
+	var vars []string
+	for _, env := range c.Env {
+		if env.ValueFrom == nil {
+			continue
+		}
+
+		var refName, refKey string
+		switch {
+		case kind == "secret" && env.ValueFrom.SecretKeyRef != nil:
+			refName, refKey = env.ValueFrom.SecretKeyRef.Name, env.ValueFrom.SecretKeyRef.Key
+		case kind == "configmap" && env.ValueFrom.ConfigMapKeyRef != nil:
+			refName, refKey = env.ValueFrom.ConfigMapKeyRef.Name, env.ValueFrom.ConfigMapKeyRef.Key
+		default:
+			continue
+		}
+
+		if refName == name && (key == "" || refKey == key) {
+			vars = append(vars, env.Name)
+		}
+	}
+	return vars
+}
+
+func missingEnvRule(in Input) []domain.Finding {
+	name, line := "", ""
+	for _, l := range in.Lines() {
+		if m := missingEnvLogRe.FindStringSubmatch(l); m != nil {
+			name, line = m[1]+m[2], l
+			break
+		}
+	}
+	if name == "" {
+		return nil
+	}
+
+	f := domain.Finding{
+		Severity:   domain.FindingCritical,
+		Confidence: 0.75,
+		Title:      fmt.Sprintf("Missing env %s", name),
+		Detail:     "the application reported a required environment variable as unset",
+		Action:     fmt.Sprintf("Define %s in the container's env or envFrom", name),
+		Evidence:   []string{line},
+	}
+
+	if c := in.Container(); c != nil {
+		for _, env := range c.Env {
+			if env.Name != name {
+				continue
+			}
+			switch {
+			case env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil:
+				f.Detail = fmt.Sprintf("%s comes from key %q of secret %q, which is empty or missing", name, env.ValueFrom.SecretKeyRef.Key, env.ValueFrom.SecretKeyRef.Name)
+				f.Action = fmt.Sprintf("Check that secret %s has a non-empty %s key", env.ValueFrom.SecretKeyRef.Name, env.ValueFrom.SecretKeyRef.Key)
+			case env.ValueFrom != nil && env.ValueFrom.ConfigMapKeyRef != nil:
+				f.Detail = fmt.Sprintf("%s comes from key %q of configmap %q, which is empty or missing", name, env.ValueFrom.ConfigMapKeyRef.Key, env.ValueFrom.ConfigMapKeyRef.Name)
+				f.Action = fmt.Sprintf("Check that configmap %s has a non-empty %s key", env.ValueFrom.ConfigMapKeyRef.Name, env.ValueFrom.ConfigMapKeyRef.Key)
+			default:
+				f.Detail = fmt.Sprintf("%s is set in the pod spec but the application still considers it missing or empty", name)
+				f.Confidence = 0.5
+			}
+			return []domain.Finding{f}
+		}
+		if len(c.EnvFrom) == 0 {
+			f.Detail = fmt.Sprintf("%s is not defined in the pod spec", name)
+			f.Confidence = 0.85
+		}
+	}
+
+	return []domain.Finding{f}
+}
+
+func execFormatRule(in Input) []domain.Finding {
+	evidence := matchingLines(in, execFormatRe)
+	if len(evidence) == 0 {
+		return nil
+	}
+
+	f := domain.Finding{
+		Severity:   domain.FindingCritical,
+		Confidence: 0.9,
+		Title:      "exec format error: wrong image architecture",
+		Detail:     "the entrypoint binary was built for a different CPU architecture than the node",
+		Action:     "Publish a multi-arch image, or pin the pod to matching nodes with a kubernetes.io/arch nodeSelector",
+		Evidence:   evidence,
+	}
+	if c := in.Container(); c != nil {
+		f.Detail = fmt.Sprintf("image %s was built for a different CPU architecture than node %s", c.Image, in.Report.Crash.NodeName)
+	}
+	return []domain.Finding{f}
+}
+
+func portInUseRule(in Input) []domain.Finding {
+	evidence := matchingLines(in, portInUseRe)
+	if len(evidence) == 0 {
+		return nil
+	}
+
+	port := ""
+	if m := portRe.FindStringSubmatch(evidence[0]); m != nil {
+		port = m[1]
+	}
+
+	f := domain.Finding{
+		Severity:   domain.FindingCritical,
+		Confidence: 0.85,
+		Title:      "Port already in use",
+		Detail:     "another process in the pod already listens on the port the application binds to",
+		Action:     "Change the listen port or remove the conflicting listener",
+		Evidence:   evidence,
+	}
+	if port != "" {
+		f.Title = fmt.Sprintf("Port %s already in use", port)
+	}
+
+	if in.Pod != nil {
+		if in.Pod.Spec.HostNetwork {
+			f.Detail = "the pod uses hostNetwork, so a process on the node or another hostNetwork pod can hold the port"
+			f.Action = "Pick a free host port, or drop hostNetwork and expose the port through a Service"
+		} else if port != "" {
+			if owners := containersDeclaringPort(in.Pod, port); len(owners) > 1 {
+				f.Detail = fmt.Sprintf("containers %s all declare port %s", strings.Join(owners, ", "), port)
+			}
+		}
+	}
+
+	return []domain.Finding{f}
+}
+
+func containersDeclaringPort(pod *corev1.Pod, port string) []string {
+	var owners []string
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			if fmt.Sprint(p.ContainerPort) == port {
+				owners = append(owners, c.Name)
+				break
+			}
+		}
+	}
+	return owners
+}
+
+func commandMissingRule(in Input) []domain.Finding {
+	evidence := matchingLines(in, commandMissingRe)
+	if len(evidence) == 0 && in.Report.Crash.ExitCode != 127 {
+		return nil
+	}
+
+	f := domain.Finding{
+		Severity:   domain.FindingCritical,
+		Confidence: 0.85,
+		Title:      "Entrypoint not found in the image",
+		Detail:     "the command or one of its interpreters does not exist in the image or on PATH",
+		Action:     "Check command/args against the image, or rebuild the image with the binary included",
+		Evidence:   evidence,
+	}
+	if len(evidence) == 0 {
+		f.Confidence = 0.6
+	}
+	if c := in.Container(); c != nil && len(c.Command) > 0 {
+		f.Detail = fmt.Sprintf("%q is missing from image %s or not on PATH", c.Command[0], c.Image)
+	}
+	return []domain.Finding{f}
+}
+
+func livenessProbeRule(in Input) []domain.Finding {
+	var evidence []string
+	for _, e := range in.Report.Events {
+		if livenessFailedRe.MatchString(e.Message) {
+			evidence = appendEvidence(evidence, e.Message)
+		}
+	}
+	if len(evidence) == 0 {
+		return nil
+	}
+
+	f := domain.Finding{
+		Severity:   domain.FindingWarning,
+		Confidence: 0.8,
+		Title:      "Restarted by the kubelet after failing its liveness probe",
+		Action:     "Fix the health endpoint, or raise initialDelaySeconds/failureThreshold or add a startupProbe for slow starts",
+		Evidence:   evidence,
+	}
+
+	if code := in.Report.Crash.ExitCode; code == 137 || code == 143 {
+		f.Confidence = 0.9
+	}
+	if c := in.Container(); c != nil && c.LivenessProbe != nil {
+		p := c.LivenessProbe
+		f.Detail = fmt.Sprintf("probe allows %ds before the first check and %d failures %ds apart (timeout %ds)",
+			p.InitialDelaySeconds, p.FailureThreshold, p.PeriodSeconds, p.TimeoutSeconds)
+	}
+
+	return []domain.Finding{f}
+}
+
+func permissionRule(in Input) []domain.Finding {
+	if evidence := matchingLines(in, readOnlyFSRe); len(evidence) > 0 {
+		f := domain.Finding{
+			Severity:   domain.FindingWarning,
+			Confidence: 0.75,
+			Title:      "Write to a read-only filesystem",
+			Detail:     "the application writes to a path on a read-only filesystem",
+			Action:     "Mount an emptyDir at the path the application writes to",
+			Evidence:   evidence,
+		}
+		if c := in.Container(); c != nil && c.SecurityContext != nil && c.SecurityContext.ReadOnlyRootFilesystem != nil && *c.SecurityContext.ReadOnlyRootFilesystem {
+			f.Detail = "the container sets readOnlyRootFilesystem and the application writes outside its mounted volumes"
+			f.Confidence = 0.85
+		}
+		return []domain.Finding{f}
+	}
+
+	evidence := matchingLines(in, permissionRe)
+	if len(evidence) == 0 && in.Report.Crash.ExitCode != 126 {
+		return nil
+	}
+
+	f := domain.Finding{
+		Severity:   domain.FindingWarning,
+		Confidence: 0.65,
+		Title:      "Permission denied",
+		Detail:     "the process lacks permission for a file, socket or syscall it needs",
+		Action:     "Check file ownership in the image against runAsUser/fsGroup, and the capabilities the process needs",
+		Evidence:   evidence,
+	}
+	if in.Pod != nil && in.Pod.Spec.SecurityContext != nil && in.Pod.Spec.SecurityContext.RunAsNonRoot != nil && *in.Pod.Spec.SecurityContext.RunAsNonRoot {
+		f.Detail = "the pod runs as non-root and the process touches a file or port that needs root"
+	}
+	return []domain.Finding{f}
+}
+
+func dependencyRule(in Input) []domain.Finding {
+	evidence := matchingLines(in, dependencyRe)
+	if len(evidence) == 0 {
+		return nil
+	}
+
+	errText := strings.ToLower(dependencyRe.FindString(evidence[0]))
+	target := ""
+	if m := hostPortRe.FindStringSubmatch(evidence[0]); m != nil {
+		target = m[1] + m[2]
+	}
+
+	f := domain.Finding{
+		Severity:   domain.FindingWarning,
+		Confidence: 0.65,
+		Title:      fmt.Sprintf("Dependency unreachable (%s)", errText),
+		Detail:     "the application exited after failing to reach a dependency",
+		Action:     "Check that the dependency's Service has ready endpoints, and retry with backoff instead of exiting on startup",
+		Evidence:   evidence,
+	}
+	if target != "" {
+		f.Title = fmt.Sprintf("Dependency unreachable: %s (%s)", target, errText)
+	}
+	return []domain.Finding{f}
+}
+
+func panicRule(in Input) []domain.Finding {
+	for _, line := range in.Lines() {
+		trimmed := strings.TrimSpace(line)
+		if !panicRe.MatchString(trimmed) {
+			continue
+		}
+
+		title := trimmed
+		if len(title) > 120 {
+			title = title[:117] + "..."
+		}
+
+		return []domain.Finding{{
+			Severity:   domain.FindingInfo,
+			Confidence: 0.5,
+			Title:      "Application crashed: " + title,
+			Detail:     "the process terminated on an unhandled error; the stack trace in the logs points at the failing code",
+			Action:     "Inspect the stack trace in the logs",
+			Evidence:   []string{line},
+		}}
+	}
+	return nil
+}
+
+func matchingLines(in Input, re *regexp.Regexp) []string {
+	var evidence []string
+	for _, line := range in.Lines() {
+		if re.MatchString(line) {
+			evidence = appendEvidence(evidence, line)
+		}
+	}
+	for _, e := range in.Report.Events {
+		if re.MatchString(e.Message) {
+			evidence = appendEvidence(evidence, e.Message)
+		}
+	}
+	return evidence
+}
+
+func appendEvidence(evidence []string, line string) []string {
+	if len(evidence) >= maxEvidence {
+		return evidence
+	}
+	for _, e := range evidence {
+		if e == line {
+			return evidence
+		}
+	}
+	return append(evidence, line)
+}
+
+func formatKiB(kb int64) string {
+	switch {
+	case kb >= 1<<20:
+		return fmt.Sprintf("%.1fGi", float64(kb)/(1<<20))
+	case kb >= 1<<10:
+		return fmt.Sprintf("%.1fMi", float64(kb)/(1<<10))
+	default:
+		return fmt.Sprintf("%dKi", kb)
+	}
+}
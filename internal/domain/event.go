@@ -3,13 +3,13 @@ package domain
 import "time"
 
 type Event struct {
-	Type      string
-	Reason    string
-	Message   string
-	Count     int32
-	FirstSeen time.Time
-	LastSeen  time.Time
-	Source    string
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Count     int32     `json:"count"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Source    string    `json:"source"`
 }
 
 func NewEvent(eventType, reason, message string) *Event {
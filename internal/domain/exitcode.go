@@ -0,0 +1,176 @@
+package domain
+
+import "fmt"
+
+const (
+	InitiatorApplication = "application"
+	InitiatorKernel      = "kernel"
+	InitiatorKubelet     = "kubelet"
+	InitiatorRuntime     = "runtime"
+	InitiatorUnknown     = "unknown"
+)
+
+type ExitInterpretation struct {
+	ExitCode   int32  `json:"exitCode"`
+	Signal     int32  `json:"signal"`
+	SignalName string `json:"signalName,omitempty"`
+	Cause      string `json:"cause"`
+	Initiator  string `json:"initiator"`
+}
+
+var signalNames = map[int32]string{
+	1:  "SIGHUP",
+	2:  "SIGINT",
+	3:  "SIGQUIT",
+	4:  "SIGILL",
+	5:  "SIGTRAP",
+	6:  "SIGABRT",
+	7:  "SIGBUS",
+	8:  "SIGFPE",
+	9:  "SIGKILL",
+	10: "SIGUSR1",
+	11: "SIGSEGV",
+	12: "SIGUSR2",
+	13: "SIGPIPE",
+	14: "SIGALRM",
+	15: "SIGTERM",
+	16: "SIGSTKFLT",
+	17: "SIGCHLD",
+	18: "SIGCONT",
+	19: "SIGSTOP",
+	20: "SIGTSTP",
+	21: "SIGTTIN",
+	22: "SIGTTOU",
+	23: "SIGURG",
+	24: "SIGXCPU",
+	25: "SIGXFSZ",
+	26: "SIGVTALRM",
+	27: "SIGPROF",
+	28: "SIGWINCH",
+	29: "SIGIO",
+	30: "SIGPWR",
+	31: "SIGSYS",
+}
+
+var signalCauses = map[int32]struct {
+	cause     string
+	initiator string
+}{
+	1:  {"Hangup: the controlling terminal closed or the process was asked to reload", InitiatorUnknown},
+	2:  {"Interrupted (Ctrl-C or SIGINT sent by another process)", InitiatorUnknown},
+	3:  {"Quit requested; the process may have dumped core", InitiatorUnknown},
+	4:  {"Illegal instruction: binary built for a different CPU or corrupted", InitiatorApplication},
+	6:  {"Aborted: the process called abort(), usually a failed assertion or fatal runtime error", InitiatorApplication},
+	7:  {"Bus error: misaligned memory access or a truncated memory-mapped file", InitiatorApplication},
+	8:  {"Arithmetic error such as integer division by zero", InitiatorApplication},
+	9:  {"Killed with SIGKILL: the termination grace period ran out (liveness probe failure, deletion, eviction) or an external kill", InitiatorKubelet},
+	11: {"Segmentation fault: invalid memory access in the application or a native library", InitiatorApplication},
+	13: {"Broken pipe: wrote to a closed pipe or socket without handling SIGPIPE", InitiatorApplication},
+	15: {"Terminated with SIGTERM: pod deletion, eviction, rollout or failed liveness probe, and the process did not exit cleanly", InitiatorKubelet},
+	24: {"CPU time limit exceeded", InitiatorKernel},
+	25: {"File size limit exceeded", InitiatorKernel},
+	31: {"Bad system call: blocked by a seccomp profile or unsupported by the kernel", InitiatorKernel},
+}
+
+var runtimeReasons = map[string]bool{
+	"ContainerCannotRun":   true,
+	"StartError":           true,
+	"CreateContainerError": true,
+	"RunContainerError":    true,
+}
+
+func SignalName(signal int32) string {
+	if name, ok := signalNames[signal]; ok {
+		return name
+	}
+	return fmt.Sprintf("signal %d", signal)
+}
+
+func InterpretExit(crash PodCrash) ExitInterpretation {
+	in := ExitInterpretation{
+		ExitCode:  crash.ExitCode,
+		Signal:    crash.Signal,
+		Initiator: InitiatorUnknown,
+	}
+
+	if in.Signal == 0 && crash.ExitCode > 128 && crash.ExitCode < 128+65 {
+		in.Signal = crash.ExitCode - 128
+	}
+	if in.Signal != 0 {
+		in.SignalName = SignalName(in.Signal)
+	}
+
+	switch {
+	case crash.IsOOMKilled():
+		in.Cause = "Killed by the kernel OOM killer for exceeding the memory limit"
+		in.Initiator = InitiatorKernel
+		if in.Signal == 0 {
+			in.Signal = 9
+			in.SignalName = SignalName(9)
+		}
+	case crash.Reason == "DeadlineExceeded":
+		in.Cause = "Stopped by the kubelet after activeDeadlineSeconds elapsed"
+		in.Initiator = InitiatorKubelet
+	case runtimeReasons[crash.Reason]:
+		in.Cause = "The container runtime failed to start the process (" + crash.Reason + ")"
+		in.Initiator = InitiatorRuntime
+	case in.Signal != 0:
+		if known, ok := signalCauses[in.Signal]; ok {
+			in.Cause = known.cause
+			in.Initiator = known.initiator
+		} else {
+			in.Cause = "Terminated by " + in.SignalName
+		}
+	default:
+		in.Cause, in.Initiator = interpretExitCode(crash.ExitCode)
+	}
+
+	return in
+}
+
+func interpretExitCode(code int32) (string, string) {
+	switch code {
+	case 0:
+		return "Exited successfully", InitiatorApplication
+	case 1:
+		return "General application error; check the logs for the failure", InitiatorApplication
+	case 2:
+		return "Invalid arguments or misuse of a shell builtin", InitiatorApplication
+	case 125:
+		return "The container runtime failed to run the container", InitiatorRuntime
+	case 126:
+		return "Command found but not executable (permission denied or wrong architecture)", InitiatorRuntime
+	case 127:
+		return "Command not found: the entrypoint or command is missing from the image or PATH", InitiatorRuntime
+	case 128:
+		return "Invalid exit code passed to exit()", InitiatorApplication
+	case 255:
+		return "Exited with 255: exit(-1) in the application, or an out-of-range exit status from some runtimes", InitiatorApplication
+	default:
+		return fmt.Sprintf("Application exited with code %d", code), InitiatorApplication
+	}
+}
+
+func (e ExitInterpretation) ExitLabel() string {
+	if e.SignalName != "" {
+		return fmt.Sprintf("%d (%s)", e.ExitCode, e.SignalName)
+	}
+	return fmt.Sprintf("%d", e.ExitCode)
+}
+
+func (e ExitInterpretation) String() string {
+	if e.Cause == "" {
+		return ""
+	}
+	if e.SignalName != "" {
+		return fmt.Sprintf("%s [%s, %s]", e.Cause, e.SignalName, e.Initiator)
+	}
+	return fmt.Sprintf("%s [%s]", e.Cause, e.Initiator)
+}
+
+func (r *ForensicReport) ExitInfo() ExitInterpretation {
+	if r.Exit.Cause != "" {
+		return r.Exit
+	}
+	return InterpretExit(r.Crash)
+}
@@ -0,0 +1,60 @@
+package domain
+
+import "testing"
+
+func TestInterpretExit(t *testing.T) {
+	tests := []struct {
+		name      string
+		crash     PodCrash
+		signal    string
+		initiator string
+	}{
+		{"oom", PodCrash{Reason: "OOMKilled", ExitCode: 137}, "SIGKILL", InitiatorKernel},
+		{"sigkill", PodCrash{Reason: "Error", ExitCode: 137}, "SIGKILL", InitiatorKubelet},
+		{"sigterm", PodCrash{Reason: "Error", ExitCode: 143}, "SIGTERM", InitiatorKubelet},
+		{"segfault", PodCrash{Reason: "Error", ExitCode: 139}, "SIGSEGV", InitiatorApplication},
+		{"explicit signal", PodCrash{Reason: "Error", Signal: 6}, "SIGABRT", InitiatorApplication},
+		{"not executable", PodCrash{Reason: "Error", ExitCode: 126}, "", InitiatorRuntime},
+		{"not found", PodCrash{Reason: "Error", ExitCode: 127}, "", InitiatorRuntime},
+		{"start error", PodCrash{Reason: "StartError", ExitCode: 128}, "", InitiatorRuntime},
+		{"app error", PodCrash{Reason: "Error", ExitCode: 1}, "", InitiatorApplication},
+		{"exit 255", PodCrash{Reason: "Error", ExitCode: 255}, "", InitiatorApplication},
+		{"deadline", PodCrash{Reason: "DeadlineExceeded", ExitCode: 143}, "SIGTERM", InitiatorKubelet},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := InterpretExit(tt.crash)
+			if got.SignalName != tt.signal {
+				t.Errorf("SignalName = %q, want %q", got.SignalName, tt.signal)
+			}
+			if got.Initiator != tt.initiator {
+				t.Errorf("Initiator = %q, want %q", got.Initiator, tt.initiator)
+			}
+			if got.Cause == "" {
+				t.Error("Cause should not be empty")
+			}
+		})
+	}
+}
+
+func TestExitInterpretation_ExitLabel(t *testing.T) {
+	if got := InterpretExit(PodCrash{ExitCode: 137}).ExitLabel(); got != "137 (SIGKILL)" {
+		t.Errorf("ExitLabel() = %q, want 137 (SIGKILL)", got)
+	}
+	if got := InterpretExit(PodCrash{ExitCode: 1}).ExitLabel(); got != "1" {
+		t.Errorf("ExitLabel() = %q, want 1", got)
+	}
+}
+
+func TestForensicReport_ExitInfo(t *testing.T) {
+	report := NewForensicReport(PodCrash{Reason: "OOMKilled", ExitCode: 137})
+	if report.Exit.Initiator != InitiatorKernel {
+		t.Errorf("Exit.Initiator = %q, want %q", report.Exit.Initiator, InitiatorKernel)
+	}
+
+	legacy := &ForensicReport{Crash: PodCrash{Reason: "Error", ExitCode: 139}}
+	if legacy.ExitInfo().SignalName != "SIGSEGV" {
+		t.Errorf("ExitInfo() for stored report without interpretation = %+v", legacy.ExitInfo())
+	}
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	FindingCritical = "critical"
+	FindingWarning  = "warning"
+	FindingInfo     = "info"
+)
+
+var findingRank = map[string]int{
+	FindingCritical: 3,
+	FindingWarning:  2,
+	FindingInfo:     1,
+}
+
+type Finding struct {
+	Rule       string   `json:"rule"`
+	Severity   string   `json:"severity"`
+	Confidence float64  `json:"confidence"`
+	Title      string   `json:"title"`
+	Detail     string   `json:"detail,omitempty"`
+	Action     string   `json:"action,omitempty"`
+	Evidence   []string `json:"evidence,omitempty"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("[%s] %s (%.0f%% confidence)", f.Severity, f.Title, f.Confidence*100)
+}
+
+func SortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		ri, rj := findingRank[findings[i].Severity], findingRank[findings[j].Severity]
+		if ri != rj {
+			return ri > rj
+		}
+		return findings[i].Confidence > findings[j].Confidence
+	})
+}
+
+func (r *ForensicReport) SetFindings(findings []Finding) {
+	SortFindings(findings)
+	r.Findings = findings
+}
+
+func (r *ForensicReport) TopFinding() (Finding, bool) {
+	if len(r.Findings) == 0 {
+		return Finding{}, false
+	}
+	return r.Findings[0], true
+}
@@ -0,0 +1,186 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	maxSignatureLines = 10
+	fallbackTailLines = 5
+)
+
+var (
+	leadingTimestampRe = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?\s*`)
+	timestampRe        = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`)
+	uuidRe             = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	hexAddrRe          = regexp.MustCompile(`(?i)\b0x[0-9a-f]+\b`)
+	longHexRe          = regexp.MustCompile(`(?i)\b[0-9a-f]{8,}\b`)
+	ipRe               = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?\b`)
+	numberRe           = regexp.MustCompile(`\d+`)
+	spaceRe            = regexp.MustCompile(`\s+`)
+	errorLineRe        = regexp.MustCompile(`(?i)(error|panic|exception|fatal|traceback|caused by|segmentation|killed|^\s+at\s|^goroutine\s|\.go:\d+|\.py", line)`)
+)
+
+type CrashGroup struct {
+	Fingerprint    string
+	Namespace      string
+	Workload       string
+	Container      string
+	Reason         string
+	ExitCode       int32
+	Signature      string
+	Count          int
+	FirstSeen      time.Time
+	LastSeen       time.Time
+	LatestReportID string
+}
+
+func (r *ForensicReport) UpdateFingerprint() {
+	r.Fingerprint, r.Signature = computeFingerprint(r)
+}
+
+func (r *ForensicReport) GroupKey() string {
+	fingerprint, _ := r.fingerprintAndSignature()
+	return fingerprint
+}
+
+func (r *ForensicReport) GroupSignature() string {
+	_, signature := r.fingerprintAndSignature()
+	return signature
+}
+
+func (r *ForensicReport) fingerprintAndSignature() (string, string) {
+	if r.Fingerprint != "" {
+		return r.Fingerprint, r.Signature
+	}
+	return computeFingerprint(r)
+}
+
+func computeFingerprint(r *ForensicReport) (string, string) {
+	lines := signatureLines(r.fingerprintLogs())
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%d\x00", r.Crash.Namespace, r.Crash.WorkloadName(), r.Crash.ContainerName, r.Crash.Reason, r.Crash.ExitCode)
+	for _, line := range lines {
+		h.Write([]byte(line))
+		h.Write([]byte{0})
+	}
+
+	signature := ""
+	if len(lines) > 0 {
+		signature = lines[0]
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16], signature
+}
+
+func (r *ForensicReport) fingerprintLogs() []string {
+	switch {
+	case len(r.LastWords) > 0:
+		return r.LastWords
+	case len(r.Logs) > 0:
+		return r.Logs
+	default:
+		return r.PreviousLog
+	}
+}
+
+func signatureLines(logs []string) []string {
+	var matched []string
+	for _, line := range logs {
+		if errorLineRe.MatchString(line) {
+			matched = append(matched, line)
+		}
+	}
+
+	if len(matched) == 0 {
+		matched = logs
+		if len(matched) > fallbackTailLines {
+			matched = matched[len(matched)-fallbackTailLines:]
+		}
+	}
+	if len(matched) > maxSignatureLines {
+		matched = matched[len(matched)-maxSignatureLines:]
+	}
+
+	out := make([]string, 0, len(matched))
+	for _, line := range matched {
+		if normalized := NormalizeLogLine(line); normalized != "" {
+			out = append(out, normalized)
+		}
+	}
+	return out
+}
+
+func NormalizeLogLine(line string) string {
+	line = leadingTimestampRe.ReplaceAllString(line, "")
+	line = timestampRe.ReplaceAllString(line, "<ts>")
+	line = uuidRe.ReplaceAllString(line, "<uuid>")
+	line = hexAddrRe.ReplaceAllString(line, "<addr>")
+	line = ipRe.ReplaceAllString(line, "<ip>")
+	line = longHexRe.ReplaceAllStringFunc(line, func(s string) string {
+		if strings.IndexAny(s, "0123456789") < 0 {
+			return s
+		}
+		return "<hex>"
+	})
+	line = numberRe.ReplaceAllString(line, "<n>")
+	line = spaceRe.ReplaceAllString(line, " ")
+	return strings.TrimSpace(line)
+}
+
+func GroupReports(reports []*ForensicReport) []CrashGroup {
+	index := make(map[string]int)
+	var groups []CrashGroup
+
+	for _, r := range reports {
+		key, signature := r.fingerprintAndSignature()
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, CrashGroup{
+				Fingerprint: key,
+				FirstSeen:   r.CollectedAt,
+			})
+		}
+
+		g := &groups[i]
+		g.Count++
+		if r.CollectedAt.Before(g.FirstSeen) {
+			g.FirstSeen = r.CollectedAt
+		}
+		if g.LatestReportID == "" || !r.CollectedAt.Before(g.LastSeen) {
+			g.LastSeen = r.CollectedAt
+			g.LatestReportID = r.ID
+			g.Namespace = r.Crash.Namespace
+			g.Workload = r.Crash.WorkloadName()
+			g.Container = r.Crash.ContainerName
+			g.Reason = r.Crash.Reason
+			g.ExitCode = r.Crash.ExitCode
+			g.Signature = signature
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].LastSeen.After(groups[j].LastSeen)
+	})
+
+	return groups
+}
+
+func FilterByFingerprint(reports []*ForensicReport, fingerprint string) []*ForensicReport {
+	out := make([]*ForensicReport, 0)
+	for _, r := range reports {
+		if r.GroupKey() == fingerprint {
+			out = append(out, r)
+		}
+	}
+	return out
+}
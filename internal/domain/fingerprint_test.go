@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeLogLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"2024-05-01T12:00:00.123Z panic: runtime error", "panic: runtime error"},
+		{"request 3f2b1c9e-1a2b-4c3d-8e9f-0a1b2c3d4e5f failed", "request <uuid> failed"},
+		{"dial tcp 10.0.3.17:5432: connection refused", "dial tcp <ip>: connection refused"},
+		{"goroutine 42 [running]: 0xc000123456", "goroutine <n> [running]: <addr>"},
+		{"object 9f86d081884c7d65 not found", "object <hex> not found"},
+		{"deadbeefcafe   retried  3 times", "deadbeefcafe retried <n> times"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeLogLine(tt.line); got != tt.want {
+			t.Errorf("NormalizeLogLine(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestPodCrash_WorkloadName(t *testing.T) {
+	tests := []struct {
+		crash PodCrash
+		want  string
+	}{
+		{PodCrash{PodName: "api-7d9f8b6c5-abcde", Workload: "Deployment/api"}, "Deployment/api"},
+		{PodCrash{PodName: "api-7d9f8b6c5-abcde"}, "api"},
+		{PodCrash{PodName: "agent-x7k2p"}, "agent"},
+		{PodCrash{PodName: "db-2"}, "db"},
+		{PodCrash{PodName: "standalone"}, "standalone"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.crash.WorkloadName(); got != tt.want {
+			t.Errorf("WorkloadName(%q) = %q, want %q", tt.crash.PodName, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateFingerprint_SameAcrossReplicas(t *testing.T) {
+	newReport := func(pod string, logs ...string) *ForensicReport {
+		r := NewForensicReport(PodCrash{
+			Namespace:     "payments",
+			PodName:       pod,
+			Workload:      "Deployment/api",
+			ContainerName: "app",
+			Reason:        "Error",
+			ExitCode:      2,
+		})
+		r.SetLogs(logs)
+		r.UpdateFingerprint()
+		return r
+	}
+
+	a := newReport("api-7d9f8b6c5-abcde",
+		"2024-05-01T12:00:00Z starting server on :8080",
+		"2024-05-01T12:00:05Z panic: dial tcp 10.0.3.17:5432: connection refused",
+		"goroutine 1 [running]:",
+	)
+	b := newReport("api-7d9f8b6c5-fghij",
+		"2024-05-02T08:30:00Z starting server on :8080",
+		"2024-05-02T08:30:09Z panic: dial tcp 10.0.9.2:5432: connection refused",
+		"goroutine 7 [running]:",
+	)
+	c := newReport("api-7d9f8b6c5-klmno",
+		"2024-05-02T08:30:09Z panic: invalid memory address or nil pointer dereference",
+	)
+
+	if a.Fingerprint == "" || a.Fingerprint != b.Fingerprint {
+		t.Errorf("replica fingerprints differ: %s vs %s", a.Fingerprint, b.Fingerprint)
+	}
+	if a.Fingerprint == c.Fingerprint {
+		t.Error("different panics should not share a fingerprint")
+	}
+	if a.Signature != "panic: dial tcp <ip>: connection refused" {
+		t.Errorf("Signature = %q", a.Signature)
+	}
+}
+
+func TestGroupReports(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var reports []*ForensicReport
+	for i, pod := range []string{"api-1", "api-2", "worker-1", "api-3"} {
+		r := NewForensicReport(PodCrash{Namespace: "default", PodName: pod, ContainerName: "app", Reason: "Error", ExitCode: 1})
+		r.ID = pod
+		r.CollectedAt = base.Add(time.Duration(i) * time.Minute)
+		reports = append(reports, r)
+	}
+
+	groups := GroupReports(reports)
+	if len(groups) != 2 {
+		t.Fatalf("len(groups) = %d, want 2", len(groups))
+	}
+
+	api := groups[0]
+	if api.Workload != "api" || api.Count != 3 {
+		t.Errorf("api group = %+v, want 3 reports", api)
+	}
+	if api.LatestReportID != "api-3" || !api.FirstSeen.Equal(base) || !api.LastSeen.Equal(base.Add(3*time.Minute)) {
+		t.Errorf("api group bounds = %+v", api)
+	}
+
+	if got := FilterByFingerprint(reports, api.Fingerprint); len(got) != 3 {
+		t.Errorf("FilterByFingerprint() returned %d reports, want 3", len(got))
+	}
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+const ConstraintMemcg = "CONSTRAINT_MEMCG"
+
+type OOMKill struct {
+	Time        time.Time `json:"time"`
+	PID         int       `json:"pid"`
+	Comm        string    `json:"comm"`
+	CgroupPath  string    `json:"cgroupPath,omitempty"`
+	Constraint  string    `json:"constraint,omitempty"`
+	TotalVMKB   int64     `json:"totalVmKB"`
+	AnonRSSKB   int64     `json:"anonRssKB"`
+	FileRSSKB   int64     `json:"fileRssKB"`
+	ShmemRSSKB  int64     `json:"shmemRssKB"`
+	OOMScoreAdj int       `json:"oomScoreAdj"`
+	PodUID      string    `json:"podUID,omitempty"`
+	ContainerID string    `json:"containerID,omitempty"`
+}
+
+func (o OOMKill) RSSKB() int64 {
+	return o.AnonRSSKB + o.FileRSSKB + o.ShmemRSSKB
+}
+
+func (o OOMKill) IsCgroupLimit() bool {
+	return o.Constraint == ConstraintMemcg
+}
+
+func (r *ForensicReport) SetOOMKills(kills []OOMKill) {
+	r.OOMKills = kills
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// ReportOrigin marks a report imported from somewhere else: the cluster it
+// was collected in, the ID it had there when it had to be renamed, and the
+// file it came from.
+type ReportOrigin struct {
+	Cluster    string    `json:"cluster,omitempty"`
+	OriginalID string    `json:"originalID,omitempty"`
+	Source     string    `json:"source,omitempty"`
+	ImportedAt time.Time `json:"importedAt"`
+}
+
+func (r *ForensicReport) SourceCluster() string {
+	if r.Origin == nil {
+		return ""
+	}
+	return r.Origin.Cluster
+}
@@ -1,17 +1,30 @@
 package domain
 
-import "time"
+import (
+	"regexp"
+	"time"
+)
+
+var (
+	replicaSetPodSuffixRe = regexp.MustCompile(`-[a-z0-9]{6,10}-[a-z0-9]{5}$`)
+	generatedPodSuffixRe  = regexp.MustCompile(`-[a-z0-9]{5}$`)
+	ordinalPodSuffixRe    = regexp.MustCompile(`-\d+$`)
+)
 
 type PodCrash struct {
-	Namespace     string
-	PodName       string
-	ContainerName string
-	ExitCode      int32
-	Reason        string
-	Signal        int32
-	RestartCount  int32
-	StartedAt     time.Time
-	FinishedAt    time.Time
+	Namespace     string    `json:"namespace"`
+	PodName       string    `json:"podName"`
+	PodUID        string    `json:"podUID,omitempty"`
+	NodeName      string    `json:"nodeName,omitempty"`
+	ContainerName string    `json:"containerName"`
+	ContainerID   string    `json:"containerID,omitempty"`
+	Workload      string    `json:"workload,omitempty"`
+	ExitCode      int32     `json:"exitCode"`
+	Reason        string    `json:"reason"`
+	Signal        int32     `json:"signal"`
+	RestartCount  int32     `json:"restartCount"`
+	StartedAt     time.Time `json:"startedAt"`
+	FinishedAt    time.Time `json:"finishedAt"`
 }
 
 func NewPodCrash(namespace, podName, containerName string) *PodCrash {
@@ -33,3 +46,16 @@ func (p *PodCrash) IsCrashLoopBackOff() bool {
 func (p *PodCrash) FullName() string {
 	return p.Namespace + "/" + p.PodName
 }
+
+func (p *PodCrash) WorkloadName() string {
+	if p.Workload != "" {
+		return p.Workload
+	}
+
+	for _, re := range []*regexp.Regexp{replicaSetPodSuffixRe, generatedPodSuffixRe, ordinalPodSuffixRe} {
+		if name := re.ReplaceAllString(p.PodName, ""); name != p.PodName && name != "" {
+			return name
+		}
+	}
+	return p.PodName
+}
@@ -2,29 +2,45 @@ package domain
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"time"
 )
 
 type ForensicReport struct {
-	ID          string
-	Crash       PodCrash
-	Logs        []string
-	PreviousLog []string
-	Events      []Event
-	EnvVars     map[string]string
-	Warnings    []string
-	CollectedAt time.Time
+	SchemaVersion int                `json:"schemaVersion"`
+	ID            string             `json:"id"`
+	Findings      []Finding          `json:"findings,omitempty"`
+	Severity      *Severity          `json:"severity,omitempty"`
+	Triage        *Triage            `json:"triage,omitempty"`
+	Origin        *ReportOrigin      `json:"origin,omitempty"`
+	Crash         PodCrash           `json:"crash"`
+	Exit          ExitInterpretation `json:"exit"`
+	Fingerprint   string             `json:"fingerprint,omitempty"`
+	Signature     string             `json:"signature,omitempty"`
+	Logs          []string           `json:"logs"`
+	PreviousLog   []string           `json:"previousLogs"`
+	LastWords     []string           `json:"lastWords,omitempty"`
+	Events        []Event            `json:"events"`
+	EnvVars       map[string]string  `json:"envVars"`
+	Warnings      []string           `json:"warnings"`
+	Timeline      []Termination      `json:"timeline,omitempty"`
+	OOMKills      []OOMKill          `json:"oomKills,omitempty"`
+	CollectedAt   time.Time          `json:"collectedAt"`
 }
 
 func NewForensicReport(crash PodCrash) *ForensicReport {
 	return &ForensicReport{
-		ID:          generateID(),
-		Crash:       crash,
-		EnvVars:     make(map[string]string),
-		Events:      make([]Event, 0),
-		Warnings:    make([]string, 0),
-		CollectedAt: time.Now(),
+		SchemaVersion: SchemaVersion,
+		ID:            generateID(),
+		Crash:         crash,
+		Exit:          InterpretExit(crash),
+		EnvVars:       make(map[string]string),
+		Events:        make([]Event, 0),
+		Warnings:      make([]string, 0),
+		Timeline:      make([]Termination, 0),
+		CollectedAt:   time.Now(),
 	}
 }
 
@@ -46,6 +62,10 @@ func (r *ForensicReport) SetPreviousLogs(logs []string) {
 	r.PreviousLog = logs
 }
 
+func (r *ForensicReport) SetLastWords(lines []string) {
+	r.LastWords = lines
+}
+
 func (r *ForensicReport) SetEnvVar(key, value string) {
 	r.EnvVars[key] = value
 }
@@ -67,6 +87,21 @@ func (r *ForensicReport) WarningCount() int {
 	return count
 }
 
+// ContentDigest returns the SHA-256 of the report's JSON encoding as
+// "sha256:<hex>". Triage is left out: it records the investigation and
+// changes after collection, while everything else is evidence.
+func (r *ForensicReport) ContentDigest() (string, error) {
+	evidence := *r
+	evidence.Triage = nil
+
+	data, err := json.Marshal(&evidence)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
 func (r *ForensicReport) Summary() string {
 	return r.Crash.FullName() + " - " + r.Crash.Reason
 }
@@ -1,8 +1,10 @@
 package domain
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewForensicReport(t *testing.T) {
@@ -157,6 +159,38 @@ func TestForensicReport_Summary(t *testing.T) {
 	}
 }
 
+func TestForensicReport_ContentDigest(t *testing.T) {
+	report := NewForensicReport(PodCrash{Namespace: "default", PodName: "api", Reason: "Error"})
+	report.SetLogs([]string{"panic: nil map"})
+
+	digest, err := report.ContentDigest()
+	if err != nil || !strings.HasPrefix(digest, "sha256:") {
+		t.Fatalf("ContentDigest() = %q, %v", digest, err)
+	}
+
+	data, _ := json.Marshal(report)
+	decoded, err := DecodeReport(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := decoded.ContentDigest(); got != digest {
+		t.Errorf("digest after a round trip = %s, want %s", got, digest)
+	}
+
+	acked := TriageAcknowledged
+	if err := decoded.ApplyTriage(TriageUpdate{Status: &acked}, TriageActor{Name: "alice"}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := decoded.ContentDigest(); got != digest {
+		t.Error("triage changed the digest")
+	}
+
+	decoded.Logs[0] = "panic: edited"
+	if got, _ := decoded.ContentDigest(); got == digest {
+		t.Error("edited logs kept the digest")
+	}
+}
+
 func TestGenerateID_Uniqueness(t *testing.T) {
 	ids := make(map[string]bool)
 	for i := 0; i < 100; i++ {
@@ -0,0 +1,231 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+const SchemaVersion = 2
+
+type fieldRenames map[string]string
+
+var reportMigrations = map[int]func(doc map[string]any){
+	1: migrateV1,
+}
+
+var (
+	v1ReportFields = fieldRenames{
+		"ID":          "id",
+		"Findings":    "findings",
+		"Crash":       "crash",
+		"Exit":        "exit",
+		"Fingerprint": "fingerprint",
+		"Signature":   "signature",
+		"Logs":        "logs",
+		"PreviousLog": "previousLogs",
+		"LastWords":   "lastWords",
+		"Events":      "events",
+		"EnvVars":     "envVars",
+		"Warnings":    "warnings",
+		"Timeline":    "timeline",
+		"OOMKills":    "oomKills",
+		"CollectedAt": "collectedAt",
+	}
+	v1CrashFields = fieldRenames{
+		"Namespace":     "namespace",
+		"PodName":       "podName",
+		"PodUID":        "podUID",
+		"NodeName":      "nodeName",
+		"ContainerName": "containerName",
+		"ContainerID":   "containerID",
+		"Workload":      "workload",
+		"ExitCode":      "exitCode",
+		"Reason":        "reason",
+		"Signal":        "signal",
+		"RestartCount":  "restartCount",
+		"StartedAt":     "startedAt",
+		"FinishedAt":    "finishedAt",
+	}
+	v1ExitFields = fieldRenames{
+		"ExitCode":   "exitCode",
+		"Signal":     "signal",
+		"SignalName": "signalName",
+		"Cause":      "cause",
+		"Initiator":  "initiator",
+	}
+	v1EventFields = fieldRenames{
+		"Type":      "type",
+		"Reason":    "reason",
+		"Message":   "message",
+		"Count":     "count",
+		"FirstSeen": "firstSeen",
+		"LastSeen":  "lastSeen",
+		"Source":    "source",
+	}
+	v1TerminationFields = fieldRenames{
+		"StartedAt":    "startedAt",
+		"FinishedAt":   "finishedAt",
+		"ExitCode":     "exitCode",
+		"Signal":       "signal",
+		"Reason":       "reason",
+		"RestartCount": "restartCount",
+	}
+	v1OOMKillFields = fieldRenames{
+		"Time":        "time",
+		"PID":         "pid",
+		"Comm":        "comm",
+		"CgroupPath":  "cgroupPath",
+		"Constraint":  "constraint",
+		"TotalVMKB":   "totalVmKB",
+		"AnonRSSKB":   "anonRssKB",
+		"FileRSSKB":   "fileRssKB",
+		"ShmemRSSKB":  "shmemRssKB",
+		"OOMScoreAdj": "oomScoreAdj",
+		"PodUID":      "podUID",
+		"ContainerID": "containerID",
+	}
+	v1FindingFields = fieldRenames{
+		"Rule":       "rule",
+		"Severity":   "severity",
+		"Confidence": "confidence",
+		"Title":      "title",
+		"Detail":     "detail",
+		"Action":     "action",
+		"Evidence":   "evidence",
+	}
+)
+
+func DecodeReport(data []byte) (*ForensicReport, error) {
+	data, err := MigrateReportJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var report ForensicReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to decode report: %w", err)
+	}
+
+	return &report, nil
+}
+
+// MigrateReportJSON returns the report document in data at the current
+// schema version, migrating it if it was written by an older version.
+func MigrateReportJSON(data []byte) ([]byte, error) {
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode report: %w", err)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("failed to decode report: empty document")
+	}
+
+	version, err := documentVersion(doc)
+	if err != nil {
+		return nil, err
+	}
+	if version == SchemaVersion {
+		return data, nil
+	}
+
+	if err := MigrateReport(doc, version); err != nil {
+		return nil, err
+	}
+	if data, err = json.Marshal(doc); err != nil {
+		return nil, fmt.Errorf("failed to re-encode migrated report: %w", err)
+	}
+	return data, nil
+}
+
+func MigrateReport(doc map[string]any, from int) error {
+	if from > SchemaVersion {
+		return fmt.Errorf("report schema version %d is newer than supported version %d", from, SchemaVersion)
+	}
+
+	for v := from; v < SchemaVersion; v++ {
+		migrate, ok := reportMigrations[v]
+		if !ok {
+			return fmt.Errorf("no migration from report schema version %d", v)
+		}
+		migrate(doc)
+	}
+
+	doc["schemaVersion"] = SchemaVersion
+	return nil
+}
+
+func documentVersion(doc map[string]any) (int, error) {
+	raw, ok := doc["schemaVersion"]
+	if !ok {
+		return 1, nil
+	}
+
+	n, ok := raw.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("invalid report schemaVersion %v", raw)
+	}
+
+	v, err := n.Int64()
+	if err != nil || v < 1 {
+		return 0, fmt.Errorf("invalid report schemaVersion %v", raw)
+	}
+
+	return int(v), nil
+}
+
+func migrateV1(doc map[string]any) {
+	// Version 1 wrote every field, so empty slices and pointers were null.
+	dropNulls(doc)
+	renameFields(doc, v1ReportFields)
+	renameObject(doc["crash"], v1CrashFields)
+	renameObject(doc["exit"], v1ExitFields)
+	renameEach(doc["events"], v1EventFields)
+	renameEach(doc["timeline"], v1TerminationFields)
+	renameEach(doc["oomKills"], v1OOMKillFields)
+	renameEach(doc["findings"], v1FindingFields)
+}
+
+func renameObject(v any, renames fieldRenames) {
+	if obj, ok := v.(map[string]any); ok {
+		renameFields(obj, renames)
+	}
+}
+
+func renameEach(v any, renames fieldRenames) {
+	items, ok := v.([]any)
+	if !ok {
+		return
+	}
+	for _, item := range items {
+		renameObject(item, renames)
+	}
+}
+
+func renameFields(obj map[string]any, renames fieldRenames) {
+	for from, to := range renames {
+		if v, ok := obj[from]; ok {
+			delete(obj, from)
+			obj[to] = v
+		}
+	}
+}
+
+func dropNulls(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, item := range v {
+			if item == nil {
+				delete(v, k)
+				continue
+			}
+			dropNulls(item)
+		}
+	case []any:
+		for _, item := range v {
+			dropNulls(item)
+		}
+	}
+}
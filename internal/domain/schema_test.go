@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const v1Report = `{
+	"ID": "abc",
+	"Crash": {"Namespace": "default", "PodName": "api-1", "ContainerName": "app", "ExitCode": 137, "Reason": "OOMKilled"},
+	"Exit": {"ExitCode": 137, "Signal": 9, "SignalName": "SIGKILL", "Cause": "killed", "Initiator": "kernel"},
+	"Logs": ["line 1"],
+	"PreviousLog": ["previous"],
+	"Events": [{"Type": "Warning", "Reason": "BackOff", "Message": "back-off"}],
+	"EnvVars": {"Namespace": "keep-as-is"},
+	"Timeline": [{"ExitCode": 1, "Reason": "Error"}],
+	"OOMKills": [{"PID": 42, "Comm": "app", "AnonRSSKB": 1024}],
+	"CollectedAt": "2024-01-02T03:04:05Z"
+}`
+
+func TestDecodeReport_MigratesV1(t *testing.T) {
+	r, err := DecodeReport([]byte(v1Report))
+	if err != nil {
+		t.Fatalf("DecodeReport() error = %v", err)
+	}
+
+	if r.SchemaVersion != SchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", r.SchemaVersion, SchemaVersion)
+	}
+	if r.ID != "abc" || r.Crash.PodName != "api-1" || r.Crash.ExitCode != 137 {
+		t.Errorf("crash not migrated: %+v", r.Crash)
+	}
+	if r.Exit.SignalName != "SIGKILL" || r.Exit.Initiator != InitiatorKernel {
+		t.Errorf("exit not migrated: %+v", r.Exit)
+	}
+	if len(r.PreviousLog) != 1 || len(r.Events) != 1 || r.Events[0].Reason != "BackOff" {
+		t.Errorf("logs or events not migrated: %+v", r)
+	}
+	if r.EnvVars["Namespace"] != "keep-as-is" {
+		t.Errorf("EnvVars keys must not be renamed: %v", r.EnvVars)
+	}
+	if len(r.Timeline) != 1 || r.Timeline[0].Reason != "Error" {
+		t.Errorf("timeline not migrated: %+v", r.Timeline)
+	}
+	if len(r.OOMKills) != 1 || r.OOMKills[0].PID != 42 || r.OOMKills[0].AnonRSSKB != 1024 {
+		t.Errorf("OOM kills not migrated: %+v", r.OOMKills)
+	}
+	if r.CollectedAt.IsZero() {
+		t.Error("CollectedAt not migrated")
+	}
+}
+
+func TestDecodeReport_RoundTrip(t *testing.T) {
+	r := NewForensicReport(PodCrash{Namespace: "default", PodName: "api-1", Reason: "Error"})
+	r.SetLogs([]string{"boom"})
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"schemaVersion":2`) || !strings.Contains(string(data), `"podName":"api-1"`) {
+		t.Errorf("unexpected encoding: %s", data)
+	}
+
+	got, err := DecodeReport(data)
+	if err != nil {
+		t.Fatalf("DecodeReport() error = %v", err)
+	}
+	if got.ID != r.ID || got.Crash.PodName != "api-1" || len(got.Logs) != 1 {
+		t.Errorf("round trip mismatch: %+v", got)
+	}
+}
+
+func TestDecodeReport_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"newer version", `{"schemaVersion": 99, "id": "abc"}`},
+		{"invalid version", `{"schemaVersion": "two"}`},
+		{"not an object", `[]`},
+		{"null", `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeReport([]byte(tt.data)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+)
+
+var severityRanks = map[string]int{
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+type Severity struct {
+	Level   string   `json:"level"`
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+type SeverityContext struct {
+	Criticality       string
+	CriticalitySource string
+	RecentCrashes     int
+	FrequencyWindow   time.Duration
+}
+
+func ValidSeverity(level string) bool {
+	_, ok := severityRanks[level]
+	return ok
+}
+
+func SeverityRank(level string) int {
+	return severityRanks[level]
+}
+
+func SeverityAtLeast(level, min string) bool {
+	return SeverityRank(level) >= SeverityRank(min)
+}
+
+func FilterByMinSeverity(reports []*ForensicReport, min string) []*ForensicReport {
+	out := make([]*ForensicReport, 0)
+	for _, r := range reports {
+		if SeverityAtLeast(r.SeverityLevel(), min) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func (r *ForensicReport) SetSeverity(s Severity) {
+	r.Severity = &s
+}
+
+func (r *ForensicReport) SeverityLevel() string {
+	if r.Severity != nil && ValidSeverity(r.Severity.Level) {
+		return r.Severity.Level
+	}
+	return ClassifySeverity(r, SeverityContext{}).Level
+}
+
+func ClassifySeverity(r *ForensicReport, ctx SeverityContext) Severity {
+	var s Severity
+	add := func(points int, reason string) {
+		s.Score += points
+		s.Reasons = append(s.Reasons, reason)
+	}
+
+	switch r.Crash.Reason {
+	case "OOMKilled":
+		add(40, "killed for running out of memory")
+	case "CrashLoopBackOff":
+		add(35, "container is in CrashLoopBackOff")
+	case "Completed":
+		add(5, "container completed")
+	default:
+		if r.Crash.ExitCode == 0 && r.Crash.Signal == 0 {
+			add(10, fmt.Sprintf("%s with exit code 0", orUnknown(r.Crash.Reason)))
+		} else {
+			add(20, fmt.Sprintf("%s (exit %s)", orUnknown(r.Crash.Reason), r.ExitInfo().ExitLabel()))
+		}
+	}
+
+	switch restarts := r.Crash.RestartCount; {
+	case restarts >= 10:
+		add(20, fmt.Sprintf("%d restarts", restarts))
+	case restarts >= 5:
+		add(15, fmt.Sprintf("%d restarts", restarts))
+	case restarts >= 3:
+		add(10, fmt.Sprintf("%d restarts", restarts))
+	}
+
+	src := ctx.CriticalitySource
+	if src == "" {
+		src = "workload"
+	}
+	switch strings.ToLower(ctx.Criticality) {
+	case SeverityCritical:
+		add(30, src+" is labelled critical")
+	case SeverityHigh:
+		add(15, src+" is labelled high criticality")
+	case SeverityLow:
+		add(-20, src+" is labelled low criticality")
+	}
+
+	if n := ctx.RecentCrashes; n >= 2 {
+		points := 5
+		switch {
+		case n >= 10:
+			points = 20
+		case n >= 5:
+			points = 15
+		}
+		window := ""
+		if ctx.FrequencyWindow > 0 {
+			window = " in the last " + ctx.FrequencyWindow.String()
+		}
+		add(points, fmt.Sprintf("crashed %d times%s", n, window))
+	}
+
+	if f, ok := r.TopFinding(); ok {
+		switch f.Severity {
+		case FindingCritical:
+			add(15, "diagnosis: "+f.Title)
+		case FindingWarning:
+			add(5, "diagnosis: "+f.Title)
+		}
+	}
+
+	switch {
+	case s.Score >= 80:
+		s.Level = SeverityCritical
+	case s.Score >= 55:
+		s.Level = SeverityHigh
+	case s.Score >= 30:
+		s.Level = SeverityMedium
+	default:
+		s.Level = SeverityLow
+	}
+
+	return s
+}
+
+func orUnknown(reason string) string {
+	if reason == "" {
+		return "Terminated"
+	}
+	return reason
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClassifySeverity(t *testing.T) {
+	tests := []struct {
+		name      string
+		crash     PodCrash
+		ctx       SeverityContext
+		findings  []Finding
+		wantLevel string
+		wantScore int
+	}{
+		{"clean exit", PodCrash{Reason: "Completed"}, SeverityContext{}, nil, SeverityLow, 5},
+		{"error with a few restarts", PodCrash{Reason: "Error", ExitCode: 1, RestartCount: 3}, SeverityContext{}, nil, SeverityMedium, 30},
+		{"error in low criticality namespace", PodCrash{Reason: "Error", ExitCode: 1}, SeverityContext{Criticality: "low", CriticalitySource: "namespace"}, nil, SeverityLow, 0},
+		{
+			"frequent crash loop",
+			PodCrash{Reason: "CrashLoopBackOff", ExitCode: 1, RestartCount: 5},
+			SeverityContext{RecentCrashes: 5, FrequencyWindow: time.Hour},
+			nil,
+			SeverityHigh, 65,
+		},
+		{
+			"OOM loop in critical workload",
+			PodCrash{Reason: "OOMKilled", ExitCode: 137, RestartCount: 10},
+			SeverityContext{Criticality: "Critical"},
+			[]Finding{{Rule: "memory-limit", Severity: FindingCritical, Title: "Memory limit reached"}},
+			SeverityCritical, 105,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewForensicReport(tt.crash)
+			r.SetFindings(tt.findings)
+
+			s := ClassifySeverity(r, tt.ctx)
+			if s.Level != tt.wantLevel || s.Score != tt.wantScore {
+				t.Errorf("ClassifySeverity() = %s/%d (%v), want %s/%d", s.Level, s.Score, s.Reasons, tt.wantLevel, tt.wantScore)
+			}
+			if len(s.Reasons) == 0 {
+				t.Error("Reasons should explain the score")
+			}
+		})
+	}
+}
+
+func TestForensicReport_SeverityLevel(t *testing.T) {
+	r := NewForensicReport(PodCrash{Reason: "OOMKilled", ExitCode: 137})
+	if got := r.SeverityLevel(); got != SeverityMedium {
+		t.Errorf("SeverityLevel() without a stored severity = %s, want %s", got, SeverityMedium)
+	}
+
+	r.SetSeverity(Severity{Level: SeverityCritical, Score: 90})
+	if got := r.SeverityLevel(); got != SeverityCritical {
+		t.Errorf("SeverityLevel() = %s, want the stored %s", got, SeverityCritical)
+	}
+}
+
+func TestFilterByMinSeverity(t *testing.T) {
+	var reports []*ForensicReport
+	for _, level := range []string{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical} {
+		r := NewForensicReport(PodCrash{PodName: level})
+		r.SetSeverity(Severity{Level: level})
+		reports = append(reports, r)
+	}
+
+	got := FilterByMinSeverity(reports, SeverityHigh)
+	if len(got) != 2 || got[0].Crash.PodName != SeverityHigh || got[1].Crash.PodName != SeverityCritical {
+		t.Errorf("FilterByMinSeverity(high) returned %d reports", len(got))
+	}
+	if !SeverityAtLeast(SeverityCritical, SeverityLow) || SeverityAtLeast(SeverityLow, SeverityMedium) {
+		t.Error("SeverityAtLeast ordering is wrong")
+	}
+}
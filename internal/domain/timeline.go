@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Termination struct {
+	StartedAt    time.Time `json:"startedAt"`
+	FinishedAt   time.Time `json:"finishedAt"`
+	ExitCode     int32     `json:"exitCode"`
+	Signal       int32     `json:"signal"`
+	Reason       string    `json:"reason"`
+	RestartCount int32     `json:"restartCount"`
+}
+
+func (t Termination) RunDuration() time.Duration {
+	if t.StartedAt.IsZero() || t.FinishedAt.IsZero() || t.FinishedAt.Before(t.StartedAt) {
+		return 0
+	}
+	return t.FinishedAt.Sub(t.StartedAt)
+}
+
+func (p *PodCrash) Termination() Termination {
+	return Termination{
+		StartedAt:    p.StartedAt,
+		FinishedAt:   p.FinishedAt,
+		ExitCode:     p.ExitCode,
+		Signal:       p.Signal,
+		Reason:       p.Reason,
+		RestartCount: p.RestartCount,
+	}
+}
+
+func (p *PodCrash) ContainerKey() string {
+	return p.Namespace + "/" + p.PodName + "/" + p.ContainerName
+}
+
+func (r *ForensicReport) SetTimeline(timeline []Termination) {
+	r.Timeline = timeline
+}
+
+func (r *ForensicReport) TimelineSummary() string {
+	if len(r.Timeline) == 0 {
+		return ""
+	}
+
+	runs := make([]string, 0, len(r.Timeline))
+	for _, t := range r.Timeline {
+		runs = append(runs, formatRunDuration(t.RunDuration()))
+	}
+
+	last := r.Timeline[len(r.Timeline)-1]
+	reason := last.Reason
+	if reason == "" {
+		reason = fmt.Sprintf("exit %d", last.ExitCode)
+	}
+
+	return fmt.Sprintf("ran %s then %s", strings.Join(runs, ", "), reason)
+}
+
+func formatRunDuration(d time.Duration) string {
+	if d <= 0 {
+		return "?"
+	}
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTermination_RunDuration(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		term Termination
+		want time.Duration
+	}{
+		{"normal run", Termination{StartedAt: now.Add(-3 * time.Second), FinishedAt: now}, 3 * time.Second},
+		{"missing start", Termination{FinishedAt: now}, 0},
+		{"missing finish", Termination{StartedAt: now}, 0},
+		{"finish before start", Termination{StartedAt: now, FinishedAt: now.Add(-time.Second)}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.term.RunDuration(); got != tt.want {
+				t.Errorf("RunDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPodCrash_Termination(t *testing.T) {
+	now := time.Now()
+	crash := &PodCrash{
+		Namespace:     "default",
+		PodName:       "api",
+		ContainerName: "main",
+		ExitCode:      137,
+		Signal:        9,
+		Reason:        "OOMKilled",
+		RestartCount:  4,
+		StartedAt:     now.Add(-time.Minute),
+		FinishedAt:    now,
+	}
+
+	term := crash.Termination()
+
+	if term.ExitCode != 137 || term.Signal != 9 || term.Reason != "OOMKilled" || term.RestartCount != 4 {
+		t.Errorf("Termination() = %+v, fields not copied", term)
+	}
+	if term.RunDuration() != time.Minute {
+		t.Errorf("RunDuration() = %v, want 1m", term.RunDuration())
+	}
+	if crash.ContainerKey() != "default/api/main" {
+		t.Errorf("ContainerKey() = %v, want default/api/main", crash.ContainerKey())
+	}
+}
+
+func TestForensicReport_TimelineSummary(t *testing.T) {
+	base := time.Now()
+	run := func(offset, length time.Duration, reason string) Termination {
+		start := base.Add(offset)
+		return Termination{StartedAt: start, FinishedAt: start.Add(length), Reason: reason, ExitCode: 1}
+	}
+
+	report := NewForensicReport(PodCrash{})
+	if got := report.TimelineSummary(); got != "" {
+		t.Errorf("TimelineSummary() on empty timeline = %q, want empty", got)
+	}
+
+	report.SetTimeline([]Termination{
+		run(0, 3*time.Second, "Error"),
+		run(10*time.Second, 3*time.Second, "Error"),
+		run(20*time.Second, 4*time.Second, "Error"),
+		run(30*time.Second, 120*time.Second, "OOMKilled"),
+	})
+
+	want := "ran 3s, 3s, 4s, 2m0s then OOMKilled"
+	if got := report.TimelineSummary(); got != want {
+		t.Errorf("TimelineSummary() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	TriageNew          = "new"
+	TriageAcknowledged = "acknowledged"
+	TriageResolved     = "resolved"
+	TriageIgnored      = "ignored"
+)
+
+var triageStatuses = []string{TriageNew, TriageAcknowledged, TriageResolved, TriageIgnored}
+
+type Triage struct {
+	Status    string        `json:"status"`
+	Assignee  string        `json:"assignee,omitempty"`
+	Notes     []TriageNote  `json:"notes,omitempty"`
+	Links     []TriageLink  `json:"links,omitempty"`
+	History   []TriageEvent `json:"history,omitempty"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+	UpdatedBy string        `json:"updatedBy,omitempty"`
+}
+
+type TriageNote struct {
+	Author    string    `json:"author,omitempty"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type TriageLink struct {
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url"`
+}
+
+// TriageEvent is one change to the triage state. ActorHint is the name the
+// client gave for itself; unlike Actor it is not verified.
+type TriageEvent struct {
+	At        time.Time `json:"at"`
+	Actor     string    `json:"actor,omitempty"`
+	ActorHint string    `json:"actorHint,omitempty"`
+	Source    string    `json:"source,omitempty"`
+	Action    string    `json:"action"`
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to,omitempty"`
+}
+
+type TriageUpdate struct {
+	Status   *string     `json:"status,omitempty"`
+	Assignee *string     `json:"assignee,omitempty"`
+	Note     string      `json:"note,omitempty"`
+	Link     *TriageLink `json:"link,omitempty"`
+}
+
+type TriageActor struct {
+	Name   string
+	Hint   string
+	Source string
+}
+
+func ValidTriageStatus(status string) bool {
+	for _, s := range triageStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (u TriageUpdate) Validate() error {
+	if u.Status == nil && u.Assignee == nil && strings.TrimSpace(u.Note) == "" && u.Link == nil {
+		return fmt.Errorf("empty triage update")
+	}
+
+	if u.Status != nil && !ValidTriageStatus(*u.Status) {
+		return fmt.Errorf("invalid triage status %q (want one of %s)", *u.Status, strings.Join(triageStatuses, ", "))
+	}
+
+	if u.Link != nil {
+		parsed, err := url.Parse(strings.TrimSpace(u.Link.URL))
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid triage link %q: must be an http(s) URL", u.Link.URL)
+		}
+	}
+
+	return nil
+}
+
+func (r *ForensicReport) TriageStatus() string {
+	if r.Triage == nil || r.Triage.Status == "" {
+		return TriageNew
+	}
+	return r.Triage.Status
+}
+
+func (r *ForensicReport) ApplyTriage(u TriageUpdate, actor TriageActor, at time.Time) error {
+	if err := u.Validate(); err != nil {
+		return err
+	}
+
+	if r.Triage == nil {
+		r.Triage = &Triage{Status: TriageNew}
+	}
+	t := r.Triage
+
+	record := func(action, from, to string) {
+		t.History = append(t.History, TriageEvent{
+			At:        at,
+			Actor:     actor.Name,
+			ActorHint: actor.Hint,
+			Source:    actor.Source,
+			Action:    action,
+			From:      from,
+			To:        to,
+		})
+	}
+
+	if u.Status != nil && *u.Status != r.TriageStatus() {
+		record("status", r.TriageStatus(), *u.Status)
+		t.Status = *u.Status
+	}
+
+	if u.Assignee != nil {
+		assignee := strings.TrimSpace(*u.Assignee)
+		if assignee != t.Assignee {
+			record("assign", t.Assignee, assignee)
+			t.Assignee = assignee
+		}
+	}
+
+	if note := strings.TrimSpace(u.Note); note != "" {
+		t.Notes = append(t.Notes, TriageNote{Author: actor.Name, Text: note, CreatedAt: at})
+		record("note", "", note)
+	}
+
+	if u.Link != nil {
+		link := TriageLink{Title: strings.TrimSpace(u.Link.Title), URL: strings.TrimSpace(u.Link.URL)}
+		t.Links = append(t.Links, link)
+		record("link", "", link.URL)
+	}
+
+	t.UpdatedAt = at
+	t.UpdatedBy = actor.Name
+	return nil
+}
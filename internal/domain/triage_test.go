@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestForensicReport_ApplyTriage(t *testing.T) {
+	r := NewForensicReport(PodCrash{Namespace: "default", PodName: "api"})
+	if r.TriageStatus() != TriageNew {
+		t.Fatalf("TriageStatus() = %s, want %s", r.TriageStatus(), TriageNew)
+	}
+
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	actor := TriageActor{Name: "alice", Source: "api"}
+	acked := TriageAcknowledged
+	assignee := " alice "
+
+	err := r.ApplyTriage(TriageUpdate{
+		Status:   &acked,
+		Assignee: &assignee,
+		Note:     "rolling back",
+		Link:     &TriageLink{Title: "OPS-1", URL: "https://tickets.example.com/OPS-1"},
+	}, actor, at)
+	if err != nil {
+		t.Fatalf("ApplyTriage() error = %v", err)
+	}
+
+	tr := r.Triage
+	if tr.Status != TriageAcknowledged || tr.Assignee != "alice" || tr.UpdatedBy != "alice" || !tr.UpdatedAt.Equal(at) {
+		t.Errorf("Triage = %+v", tr)
+	}
+	if len(tr.Notes) != 1 || tr.Notes[0].Author != "alice" || len(tr.Links) != 1 {
+		t.Errorf("notes/links = %+v / %+v", tr.Notes, tr.Links)
+	}
+
+	want := []TriageEvent{
+		{At: at, Actor: "alice", Source: "api", Action: "status", From: TriageNew, To: TriageAcknowledged},
+		{At: at, Actor: "alice", Source: "api", Action: "assign", To: "alice"},
+		{At: at, Actor: "alice", Source: "api", Action: "note", To: "rolling back"},
+		{At: at, Actor: "alice", Source: "api", Action: "link", To: "https://tickets.example.com/OPS-1"},
+	}
+	if len(tr.History) != len(want) {
+		t.Fatalf("History = %+v, want %d entries", tr.History, len(want))
+	}
+	for i := range want {
+		if tr.History[i] != want[i] {
+			t.Errorf("History[%d] = %+v, want %+v", i, tr.History[i], want[i])
+		}
+	}
+
+	if err := r.ApplyTriage(TriageUpdate{Status: &acked}, actor, at); err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.History) != len(want) {
+		t.Errorf("unchanged status should not be recorded: %+v", tr.History)
+	}
+}
+
+func TestTriageUpdate_Validate(t *testing.T) {
+	bogus := "done"
+	tests := []struct {
+		name   string
+		update TriageUpdate
+	}{
+		{"empty", TriageUpdate{}},
+		{"blank note", TriageUpdate{Note: "  "}},
+		{"unknown status", TriageUpdate{Status: &bogus}},
+		{"non-http link", TriageUpdate{Link: &TriageLink{URL: "javascript:alert(1)"}}},
+		{"relative link", TriageUpdate{Link: &TriageLink{URL: "/tickets/1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.update.Validate(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const maxReportIDLength = 64
+
+var (
+	reportIDRe         = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+	dns1123LabelRe     = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	dns1123SubdomainRe = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// Validate checks the fields that stores use to name and index a report:
+// the ID may only hold letters, digits and dashes, the namespace and
+// container must be DNS-1123 labels and the pod a DNS-1123 subdomain, as
+// Kubernetes requires, and the collection time must be set. Reports
+// received from outside, such as from agents or imports, must pass before
+// they are saved.
+func (r *ForensicReport) Validate() error {
+	if err := ValidateReportID(r.ID); err != nil {
+		return err
+	}
+	if !isDNS1123Label(r.Crash.Namespace) {
+		return fmt.Errorf("invalid namespace %q", r.Crash.Namespace)
+	}
+	if len(r.Crash.PodName) > 253 || !dns1123SubdomainRe.MatchString(r.Crash.PodName) {
+		return fmt.Errorf("invalid pod name %q", r.Crash.PodName)
+	}
+	if !isDNS1123Label(r.Crash.ContainerName) {
+		return fmt.Errorf("invalid container name %q", r.Crash.ContainerName)
+	}
+	if r.CollectedAt.IsZero() {
+		return fmt.Errorf("report has no collection time")
+	}
+	return nil
+}
+
+func ValidateReportID(id string) error {
+	if id == "" {
+		return fmt.Errorf("report id is required")
+	}
+	if len(id) > maxReportIDLength || !reportIDRe.MatchString(id) {
+		return fmt.Errorf("invalid report id %q", id)
+	}
+	return nil
+}
+
+func isDNS1123Label(s string) bool {
+	return len(s) <= 63 && dns1123LabelRe.MatchString(s)
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestForensicReport_Validate(t *testing.T) {
+	valid := func() *ForensicReport {
+		return NewForensicReport(PodCrash{Namespace: "payments", PodName: "api-7d9f8c6b5d-x2k4q", ContainerName: "app"})
+	}
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(r *ForensicReport)
+	}{
+		{"missing id", func(r *ForensicReport) { r.ID = "" }},
+		{"path in id", func(r *ForensicReport) { r.ID = "../abc" }},
+		{"underscore in id", func(r *ForensicReport) { r.ID = "a_b" }},
+		{"glob in id", func(r *ForensicReport) { r.ID = "a*" }},
+		{"bracket in id", func(r *ForensicReport) { r.ID = "a[" }},
+		{"long id", func(r *ForensicReport) { r.ID = string(make([]byte, 65)) }},
+		{"missing namespace", func(r *ForensicReport) { r.Crash.Namespace = "" }},
+		{"path in namespace", func(r *ForensicReport) { r.Crash.Namespace = "a/../../../etc/x" }},
+		{"underscore in namespace", func(r *ForensicReport) { r.Crash.Namespace = "a_b" }},
+		{"missing pod", func(r *ForensicReport) { r.Crash.PodName = "" }},
+		{"path in pod", func(r *ForensicReport) { r.Crash.PodName = "../x" }},
+		{"uppercase pod", func(r *ForensicReport) { r.Crash.PodName = "API" }},
+		{"missing container", func(r *ForensicReport) { r.Crash.ContainerName = "" }},
+		{"slash in container", func(r *ForensicReport) { r.Crash.ContainerName = "a/b" }},
+		{"missing collection time", func(r *ForensicReport) { r.CollectedAt = time.Time{} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid()
+			tt.modify(r)
+			if err := r.Validate(); err == nil {
+				t.Error("Validate() = nil, want an error")
+			}
+		})
+	}
+}
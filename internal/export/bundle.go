@@ -0,0 +1,152 @@
+package export
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// BundleManifest is written to manifest.json, the last entry of a bundle,
+// so the receiver can check that nothing was lost or changed in transit.
+type BundleManifest struct {
+	ReportID  string       `json:"reportID"`
+	Namespace string       `json:"namespace"`
+	Pod       string       `json:"pod"`
+	CreatedAt time.Time    `json:"createdAt"`
+	Generator string       `json:"generator"`
+	Files     []BundleFile `json:"files"`
+}
+
+type BundleFile struct {
+	Name   string `json:"name"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// lastAppliedAnnotation holds the whole manifest kubectl apply was given,
+// literal environment values included.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// Bundle writes report as a tar.gz archive with everything under a
+// <report-id>/ directory: report.json, logs/*.log, events.json, pod.yaml
+// when WithPod is given, and manifest.json listing the hash of every file.
+func Bundle(w io.Writer, report *domain.ForensicReport, opts ...Option) error {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	type entry struct {
+		name string
+		data []byte
+	}
+
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	entries := []entry{{"report.json", reportJSON}}
+
+	for _, section := range logSections(report) {
+		entries = append(entries, entry{"logs/" + section.file, []byte(strings.Join(section.lines, "\n") + "\n")})
+	}
+
+	events := report.Events
+	if events == nil {
+		events = []domain.Event{}
+	}
+	eventsJSON, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal events: %w", err)
+	}
+	entries = append(entries, entry{"events.json", eventsJSON})
+
+	if o.pod != nil {
+		podYAML, err := yaml.Marshal(sanitizePod(o.pod, o.redactor))
+		if err != nil {
+			return fmt.Errorf("failed to marshal pod: %w", err)
+		}
+		entries = append(entries, entry{"pod.yaml", podYAML})
+	}
+
+	now := time.Now().UTC()
+	manifest := BundleManifest{
+		ReportID:  report.ID,
+		Namespace: report.Crash.Namespace,
+		Pod:       report.Crash.PodName,
+		CreatedAt: now,
+		Generator: "kubecrsh",
+	}
+	for _, e := range entries {
+		sum := sha256.Sum256(e.data)
+		manifest.Files = append(manifest.Files, BundleFile{Name: e.name, Size: len(e.data), SHA256: hex.EncodeToString(sum[:])})
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	entries = append(entries, entry{"manifest.json", manifestJSON})
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	dir := unsafeFileChars.ReplaceAllString(report.ID, "_") + "/"
+	for _, e := range entries {
+		hdr := &tar.Header{
+			Name:    dir + e.name,
+			Mode:    0o644,
+			Size:    int64(len(e.data)),
+			ModTime: now,
+			Format:  tar.FormatPAX,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("failed to write %s: %w", e.name, err)
+		}
+		if _, err := tw.Write(e.data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", e.name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	return gz.Close()
+}
+
+// sanitizePod returns a copy of pod without managed fields and the
+// last-applied configuration, with its literal environment values redacted.
+func sanitizePod(pod *corev1.Pod, redactor EnvRedactor) *corev1.Pod {
+	pod = pod.DeepCopy()
+	pod.APIVersion, pod.Kind = "v1", "Pod"
+	pod.ManagedFields = nil
+	delete(pod.Annotations, lastAppliedAnnotation)
+
+	if redactor == nil {
+		return pod
+	}
+	redact := func(env []corev1.EnvVar) {
+		for i := range env {
+			if env[i].Value != "" {
+				env[i].Value = redactor.RedactEnv(env[i].Name, env[i].Value)
+			}
+		}
+	}
+	for i := range pod.Spec.InitContainers {
+		redact(pod.Spec.InitContainers[i].Env)
+	}
+	for i := range pod.Spec.Containers {
+		redact(pod.Spec.Containers[i].Env)
+	}
+	for i := range pod.Spec.EphemeralContainers {
+		redact(pod.Spec.EphemeralContainers[i].Env)
+	}
+	return pod
+}
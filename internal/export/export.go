@@ -0,0 +1,106 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatBundle   = "bundle"
+)
+
+var Formats = []string{FormatMarkdown, FormatHTML, FormatBundle}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+type options struct {
+	pod      *corev1.Pod
+	redactor EnvRedactor
+}
+
+type Option func(*options)
+
+// EnvRedactor redacts the value of an environment variable with the rules
+// that were applied to the report.
+type EnvRedactor interface {
+	RedactEnv(name, value string) string
+}
+
+// WithPod adds the spec of the crashed pod to bundles.
+func WithPod(pod *corev1.Pod) Option {
+	return func(o *options) {
+		o.pod = pod
+	}
+}
+
+// WithRedactor redacts the literal environment values in the pod spec of
+// bundles. Without it they are written as they are.
+func WithRedactor(r EnvRedactor) Option {
+	return func(o *options) {
+		o.redactor = r
+	}
+}
+
+// ParseFormat accepts a format name or one of its file extensions.
+func ParseFormat(name string) (string, error) {
+	switch strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), ".")) {
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "html", "htm":
+		return FormatHTML, nil
+	case "bundle", "tar.gz", "tgz":
+		return FormatBundle, nil
+	}
+	return "", fmt.Errorf("unknown export format %q (want markdown, html or bundle)", name)
+}
+
+func Extension(format string) string {
+	switch format {
+	case FormatMarkdown:
+		return ".md"
+	case FormatHTML:
+		return ".html"
+	case FormatBundle:
+		return ".tar.gz"
+	}
+	return ""
+}
+
+func ContentType(format string) string {
+	switch format {
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatBundle:
+		return "application/gzip"
+	}
+	return "application/octet-stream"
+}
+
+// FileName is the default name of an export of report, such as
+// kubecrsh-<id>-<namespace>-<pod>.md.
+func FileName(report *domain.ForensicReport, format string) string {
+	name := strings.Join([]string{"kubecrsh", report.ID, report.Crash.Namespace, report.Crash.PodName}, "-")
+	return unsafeFileChars.ReplaceAllString(name, "_") + Extension(format)
+}
+
+// Write renders report to w in format.
+func Write(w io.Writer, report *domain.ForensicReport, format string, opts ...Option) error {
+	switch format {
+	case FormatMarkdown:
+		return Markdown(w, report)
+	case FormatHTML:
+		return HTML(w, report)
+	case FormatBundle:
+		return Bundle(w, report, opts...)
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
@@ -0,0 +1,210 @@
+package export
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/config"
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	"github.com/kadirbelkuyu/kubecrsh/internal/redaction"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestReport() *domain.ForensicReport {
+	report := domain.NewForensicReport(domain.PodCrash{
+		Namespace:     "payments",
+		PodName:       "api-7d9f8c6b5d-x2k4q",
+		ContainerName: "app",
+		Reason:        "Error",
+		ExitCode:      1,
+	})
+	report.SetLogs([]string{"starting", "panic: nil map | ```boom```", "<script>alert(1)</script>"})
+	report.Events = []domain.Event{{Type: "Warning", Reason: "BackOff", Message: "Back-off restarting failed container", Count: 3}}
+	report.EnvVars = map[string]string{"MODE": "prod", "DEBUG": "false"}
+	return report
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]string{"md": FormatMarkdown, "HTML": FormatHTML, ".tar.gz": FormatBundle, "tgz": FormatBundle} {
+		if got, err := ParseFormat(in); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("ParseFormat(pdf) should fail")
+	}
+}
+
+func TestFileName(t *testing.T) {
+	report := newTestReport()
+	report.Crash.PodName = "api/../x"
+	got := FileName(report, FormatBundle)
+	if strings.ContainsAny(got, "/ ") || !strings.HasSuffix(got, ".tar.gz") || !strings.HasPrefix(got, "kubecrsh-"+report.ID) {
+		t.Errorf("FileName() = %q", got)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, newTestReport(), FormatMarkdown); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# Crash report: payments/api-7d9f8c6b5d-x2k4q",
+		"| Workload | api |",
+		"## Events",
+		"| DEBUG | false |",
+		"````\nstarting\npanic: nil map | ```boom```\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, newTestReport(), FormatHTML); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	if strings.Contains(out, "<script>alert") {
+		t.Error("log lines not escaped")
+	}
+	for _, want := range []string{"<!DOCTYPE html>", "Back-off restarting failed container", "&lt;script&gt;alert(1)&lt;/script&gt;"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html missing %q", want)
+		}
+	}
+	if strings.Contains(out, "http://") || strings.Contains(out, "https://") {
+		t.Error("html references external resources")
+	}
+}
+
+// readBundle returns the files of a bundle by their name below the report
+// directory.
+func readBundle(t *testing.T, data []byte, id string) map[string][]byte {
+	t.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		name, ok := strings.CutPrefix(hdr.Name, id+"/")
+		if !ok {
+			t.Fatalf("entry %q outside the report directory", hdr.Name)
+		}
+		files[name], _ = io.ReadAll(tr)
+	}
+	return files
+}
+
+func TestBundle(t *testing.T) {
+	report := newTestReport()
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:          report.Crash.PodName,
+		Namespace:     report.Crash.Namespace,
+		ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+	}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report, FormatBundle, WithPod(pod)); err != nil {
+		t.Fatal(err)
+	}
+
+	files := readBundle(t, buf.Bytes(), report.ID)
+
+	for _, name := range []string{"report.json", "logs/current.log", "events.json", "pod.yaml", "manifest.json"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("bundle missing %s", name)
+		}
+	}
+	if !strings.HasPrefix(string(files["logs/current.log"]), "starting\n") {
+		t.Errorf("current.log = %q", files["logs/current.log"])
+	}
+	if pod := string(files["pod.yaml"]); !strings.Contains(pod, "kind: Pod") || strings.Contains(pod, "managedFields") {
+		t.Errorf("pod.yaml = %s", pod)
+	}
+
+	var manifest BundleManifest
+	if err := json.Unmarshal(files["manifest.json"], &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.ReportID != report.ID || len(manifest.Files) != len(files)-1 {
+		t.Fatalf("manifest = %+v", manifest)
+	}
+	for _, f := range manifest.Files {
+		sum := sha256.Sum256(files[f.Name])
+		if hex.EncodeToString(sum[:]) != f.SHA256 || len(files[f.Name]) != f.Size {
+			t.Errorf("manifest entry %s does not match its file", f.Name)
+		}
+	}
+}
+
+func TestBundle_RedactsPod(t *testing.T) {
+	report := newTestReport()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      report.Crash.PodName,
+			Namespace: report.Crash.Namespace,
+			Annotations: map[string]string{
+				lastAppliedAnnotation: `{"spec":{"containers":[{"env":[{"name":"DB_PASSWORD","value":"hunter2"}]}]}}`,
+				"team":                "payments",
+			},
+		},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "migrate", Env: []corev1.EnvVar{{Name: "DB_PASSWORD", Value: "hunter2"}}}},
+			Containers: []corev1.Container{{Name: "app", Env: []corev1.EnvVar{
+				{Name: "DB_PASSWORD", Value: "hunter2"},
+				{Name: "MODE", Value: "production"},
+			}}},
+		},
+	}
+	redactor, err := redaction.New(config.RedactionConfig{Enabled: true, EnvDenylist: []string{"*PASSWORD*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, report, FormatBundle, WithPod(pod), WithRedactor(redactor)); err != nil {
+		t.Fatal(err)
+	}
+
+	files := readBundle(t, buf.Bytes(), report.ID)
+	for name, data := range files {
+		if bytes.Contains(data, []byte("hunter2")) {
+			t.Errorf("%s contains the secret env value", name)
+		}
+	}
+	podYAML := string(files["pod.yaml"])
+	if strings.Contains(podYAML, "last-applied-configuration") || !strings.Contains(podYAML, "team: payments") {
+		t.Errorf("pod.yaml annotations not sanitized:\n%s", podYAML)
+	}
+	if !strings.Contains(podYAML, "value: production") {
+		t.Errorf("pod.yaml lost env values the redactor keeps:\n%s", podYAML)
+	}
+	if pod.Spec.Containers[0].Env[0].Value != "hunter2" {
+		t.Error("Bundle modified the pod it was given")
+	}
+}
@@ -0,0 +1,150 @@
+package export
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"time": formatTime,
+	"percent": func(f float64) string {
+		return fmt.Sprintf("%.0f%%", f*100)
+	},
+	"ran": func(t domain.Termination) string {
+		return t.RunDuration().Round(time.Second).String()
+	},
+	"lines": func(lines []string) string {
+		return strings.Join(lines, "\n")
+	},
+	"sortedKeys": sortedKeys,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Crash report {{.Report.Crash.Namespace}}/{{.Report.Crash.PodName}}</title>
+<style>
+body { font: 14px/1.5 -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; max-width: 1100px; margin: 2em auto; padding: 0 1em; }
+h1 { font-size: 1.6em; border-bottom: 1px solid #d0d7de; padding-bottom: .3em; }
+h2 { font-size: 1.25em; margin-top: 1.8em; border-bottom: 1px solid #d0d7de; padding-bottom: .2em; }
+table { border-collapse: collapse; width: 100%; margin: .5em 0; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+table.summary th { width: 12em; }
+td { word-break: break-word; }
+pre { background: #f6f8fa; border: 1px solid #d0d7de; padding: 8px; overflow-x: auto; font: 12px/1.45 ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }
+.sev { display: inline-block; padding: 0 .5em; border-radius: 1em; color: #fff; background: #57606a; }
+.sev-critical { background: #cf222e; } .sev-high { background: #bc4c00; } .sev-medium { background: #9a6700; } .sev-low { background: #1a7f37; }
+blockquote { margin: .5em 0; padding: 0 1em; color: #57606a; border-left: 4px solid #d0d7de; }
+footer { margin-top: 2em; color: #57606a; font-size: 12px; }
+</style>
+</head>
+<body>
+{{- $r := .Report}}
+<h1>Crash report: {{$r.Crash.Namespace}}/{{$r.Crash.PodName}} <span class="sev sev-{{$r.SeverityLevel}}">{{$r.SeverityLevel}}</span></h1>
+<table class="summary">
+{{- range .Summary}}
+<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{- end}}
+</table>
+{{- with $r.Findings}}
+<h2>Diagnosis</h2>
+<ul>
+{{- range .}}
+<li><strong>{{.Title}}</strong> ({{.Severity}}, {{percent .Confidence}} confidence){{with .Detail}}: {{.}}{{end}}
+{{- if or .Action .Evidence}}
+<ul>
+{{- with .Action}}<li>Action: {{.}}</li>{{end}}
+{{- range .Evidence}}<li>Evidence: <code>{{.}}</code></li>{{end}}
+</ul>
+{{- end}}
+</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- with $r.Severity}}{{if .Reasons}}
+<h2>Severity</h2>
+<p><strong>{{.Level}}</strong> (score {{.Score}})</p>
+<ul>{{range .Reasons}}<li>{{.}}</li>{{end}}</ul>
+{{- end}}{{end}}
+{{- with $r.Timeline}}
+<h2>Restart timeline</h2>
+<p>{{$r.TimelineSummary}}</p>
+<table>
+<tr><th>Started</th><th>Finished</th><th>Ran</th><th>Exit</th><th>Reason</th></tr>
+{{- range .}}
+<tr><td>{{time .StartedAt}}</td><td>{{time .FinishedAt}}</td><td>{{ran .}}</td><td>{{.ExitCode}}</td><td>{{.Reason}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with $r.OOMKills}}
+<h2>Kernel OOM kills</h2>
+<table>
+<tr><th>Time</th><th>Process</th><th>Anon RSS</th><th>File RSS</th><th>Constraint</th></tr>
+{{- range .}}
+<tr><td>{{time .Time}}</td><td>{{.Comm}} (pid {{.PID}})</td><td>{{.AnonRSSKB}} KiB</td><td>{{.FileRSSKB}} KiB</td><td>{{.Constraint}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with $r.Events}}
+<h2>Events</h2>
+<table>
+<tr><th>Last seen</th><th>Type</th><th>Reason</th><th>Count</th><th>Message</th></tr>
+{{- range .}}
+<tr><td>{{time .LastSeen}}</td><td>{{.Type}}</td><td>{{.Reason}}</td><td>{{.Count}}</td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with $r.EnvVars}}
+<h2>Environment</h2>
+<table>
+<tr><th>Name</th><th>Value</th></tr>
+{{- $env := .}}
+{{- range sortedKeys .}}
+<tr><td><code>{{.}}</code></td><td><code>{{index $env .}}</code></td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with $r.Warnings}}
+<h2>Collection warnings</h2>
+<ul>{{range .}}<li>{{.}}</li>{{end}}</ul>
+{{- end}}
+{{- range .Logs}}
+<h2>{{.title}}</h2>
+<pre>{{lines .lines}}</pre>
+{{- end}}
+{{- with $r.Triage}}
+<h2>Triage</h2>
+<p>Status: <strong>{{.Status}}</strong>{{with .Assignee}}, assigned to {{.}}{{end}}</p>
+{{- range .Notes}}
+<blockquote><p>{{.Text}}</p><p>— {{.Author}}, {{time .CreatedAt}}</p></blockquote>
+{{- end}}
+{{- with .Links}}
+<ul>{{range .}}<li><a href="{{.URL}}">{{if .Title}}{{.Title}}{{else}}{{.URL}}{{end}}</a></li>{{end}}</ul>
+{{- end}}
+{{- end}}
+<footer>Exported by kubecrsh from report {{$r.ID}}, collected {{time $r.CollectedAt}}.</footer>
+</body>
+</html>
+`))
+
+// HTML renders report as a single HTML page with inline styles and no
+// external resources, so it can be attached to a ticket or mailed as is.
+func HTML(w io.Writer, report *domain.ForensicReport) error {
+	var logs []map[string]any
+	for _, s := range logSections(report) {
+		logs = append(logs, map[string]any{"title": s.title, "lines": s.lines})
+	}
+
+	return htmlTemplate.Execute(w, map[string]any{
+		"Report":  report,
+		"Summary": summaryRows(report),
+		"Logs":    logs,
+	})
+}
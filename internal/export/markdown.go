@@ -0,0 +1,234 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+const timeLayout = "2006-01-02 15:04:05 MST"
+
+// Markdown renders report as a Markdown document for post-mortems and
+// tickets. Logs go into fenced code blocks, so they are shown verbatim.
+func Markdown(w io.Writer, report *domain.ForensicReport) error {
+	bw := bufio.NewWriter(w)
+	p := func(format string, args ...any) {
+		fmt.Fprintf(bw, format, args...)
+	}
+
+	crash := report.Crash
+	exit := report.ExitInfo()
+
+	p("# Crash report: %s/%s\n\n", crash.Namespace, crash.PodName)
+	p("| Field | Value |\n|---|---|\n")
+	for _, row := range summaryRows(report) {
+		p("| %s | %s |\n", row[0], mdCell(row[1]))
+	}
+
+	if len(report.Findings) > 0 {
+		p("\n## Diagnosis\n\n")
+		for _, f := range report.Findings {
+			p("- **%s** (%s, %.0f%% confidence)", mdInline(f.Title), f.Severity, f.Confidence*100)
+			if f.Detail != "" {
+				p(": %s", mdInline(f.Detail))
+			}
+			p("\n")
+			if f.Action != "" {
+				p("  - Action: %s\n", mdInline(f.Action))
+			}
+			for _, e := range f.Evidence {
+				p("  - Evidence: `%s`\n", strings.ReplaceAll(e, "`", "'"))
+			}
+		}
+	}
+
+	if report.Severity != nil && len(report.Severity.Reasons) > 0 {
+		p("\n## Severity\n\n**%s** (score %d)\n\n", report.Severity.Level, report.Severity.Score)
+		for _, r := range report.Severity.Reasons {
+			p("- %s\n", mdInline(r))
+		}
+	}
+
+	if exit.Cause != "" {
+		p("\n## Exit\n\n%s\n", mdInline(exit.String()))
+	}
+
+	if len(report.Timeline) > 0 {
+		p("\n## Restart timeline\n\n%s\n\n", mdInline(report.TimelineSummary()))
+		p("| Started | Finished | Ran | Exit | Reason |\n|---|---|---|---|---|\n")
+		for _, t := range report.Timeline {
+			p("| %s | %s | %s | %d | %s |\n", formatTime(t.StartedAt), formatTime(t.FinishedAt), t.RunDuration().Round(time.Second), t.ExitCode, mdCell(t.Reason))
+		}
+	}
+
+	if len(report.OOMKills) > 0 {
+		p("\n## Kernel OOM kills\n\n")
+		p("| Time | Process | Anon RSS | File RSS | Constraint |\n|---|---|---|---|---|\n")
+		for _, k := range report.OOMKills {
+			p("| %s | %s (pid %d) | %d KiB | %d KiB | %s |\n", formatTime(k.Time), mdCell(k.Comm), k.PID, k.AnonRSSKB, k.FileRSSKB, mdCell(k.Constraint))
+		}
+	}
+
+	if len(report.Events) > 0 {
+		p("\n## Events\n\n")
+		p("| Last seen | Type | Reason | Count | Message |\n|---|---|---|---|---|\n")
+		for _, e := range report.Events {
+			p("| %s | %s | %s | %d | %s |\n", formatTime(e.LastSeen), mdCell(e.Type), mdCell(e.Reason), e.Count, mdCell(e.Message))
+		}
+	}
+
+	if len(report.EnvVars) > 0 {
+		p("\n## Environment\n\n| Name | Value |\n|---|---|\n")
+		for _, name := range sortedKeys(report.EnvVars) {
+			p("| %s | %s |\n", mdCell(name), mdCell(report.EnvVars[name]))
+		}
+	}
+
+	if len(report.Warnings) > 0 {
+		p("\n## Collection warnings\n\n")
+		for _, warning := range report.Warnings {
+			p("- %s\n", mdInline(warning))
+		}
+	}
+
+	for _, section := range logSections(report) {
+		p("\n## %s\n\n", section.title)
+		fence := codeFence(section.lines)
+		p("%s\n%s\n%s\n", fence, strings.Join(section.lines, "\n"), fence)
+	}
+
+	if t := report.Triage; t != nil {
+		p("\n## Triage\n\n")
+		p("Status: **%s**", t.Status)
+		if t.Assignee != "" {
+			p(", assigned to %s", mdInline(t.Assignee))
+		}
+		p("\n")
+		for _, n := range t.Notes {
+			p("\n> %s\n>\n> — %s, %s\n", strings.ReplaceAll(mdInline(n.Text), "\n", "\n> "), mdInline(n.Author), formatTime(n.CreatedAt))
+		}
+		if len(t.Links) > 0 {
+			p("\n")
+			for _, l := range t.Links {
+				title := l.Title
+				if title == "" {
+					title = l.URL
+				}
+				p("- [%s](%s)\n", mdInline(title), l.URL)
+			}
+		}
+	}
+
+	p("\n---\nExported by kubecrsh from report %s, collected %s.\n", report.ID, formatTime(report.CollectedAt))
+
+	return bw.Flush()
+}
+
+// summaryRows lists the fields shown at the top of Markdown and HTML
+// exports, leaving out empty ones.
+func summaryRows(report *domain.ForensicReport) [][2]string {
+	crash := report.Crash
+	exit := report.ExitInfo()
+
+	rows := [][2]string{
+		{"Report ID", report.ID},
+		{"Source cluster", report.SourceCluster()},
+		{"Namespace", crash.Namespace},
+		{"Pod", crash.PodName},
+		{"Container", crash.ContainerName},
+		{"Workload", crash.WorkloadName()},
+		{"Node", crash.NodeName},
+		{"Reason", crash.Reason},
+		{"Exit code", exit.ExitLabel()},
+		{"Exit cause", exit.String()},
+		{"Restarts", fmt.Sprintf("%d", crash.RestartCount)},
+		{"Severity", severityLabel(report)},
+		{"Fingerprint", report.GroupKey()},
+		{"Started", formatTime(crash.StartedAt)},
+		{"Finished", formatTime(crash.FinishedAt)},
+		{"Collected", formatTime(report.CollectedAt)},
+		{"Triage", report.TriageStatus()},
+	}
+
+	kept := rows[:0]
+	for _, row := range rows {
+		if row[1] != "" {
+			kept = append(kept, row)
+		}
+	}
+	return kept
+}
+
+func severityLabel(report *domain.ForensicReport) string {
+	if report.Severity == nil {
+		return report.SeverityLevel()
+	}
+	return fmt.Sprintf("%s (score %d)", report.Severity.Level, report.Severity.Score)
+}
+
+type logSection struct {
+	title string
+	file  string
+	lines []string
+}
+
+func logSections(report *domain.ForensicReport) []logSection {
+	var sections []logSection
+	for _, s := range []logSection{
+		{"Last words", "last-words.log", report.LastWords},
+		{"Logs", "current.log", report.Logs},
+		{"Previous logs", "previous.log", report.PreviousLog},
+	} {
+		if len(s.lines) > 0 {
+			sections = append(sections, s)
+		}
+	}
+	return sections
+}
+
+// codeFence returns a backtick fence longer than any run of backticks in
+// lines, so that logs cannot close the block early.
+func codeFence(lines []string) string {
+	longest := 0
+	for _, line := range lines {
+		run := 0
+		for _, r := range line {
+			if r == '`' {
+				run++
+				longest = max(longest, run)
+			} else {
+				run = 0
+			}
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+func mdInline(s string) string {
+	return strings.NewReplacer("<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func mdCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(mdInline(s))
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(timeLayout)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
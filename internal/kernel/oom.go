@@ -0,0 +1,136 @@
+package kernel
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+var (
+	killedProcessRe = regexp.MustCompile(`Killed process (\d+) \(([^)]*)\)(?: total-vm:(\d+)kB, anon-rss:(\d+)kB, file-rss:(\d+)kB, shmem-rss:(\d+)kB)?(?:.*oom_score_adj:(-?\d+))?`)
+	legacyTaskRe    = regexp.MustCompile(`Task in (\S+) killed as a result of limit of (\S+)`)
+	podUIDRe        = regexp.MustCompile(`pod([0-9a-fA-F]{8}[-_][0-9a-fA-F]{4}[-_][0-9a-fA-F]{4}[-_][0-9a-fA-F]{4}[-_][0-9a-fA-F]{12})`)
+	containerIDRe   = regexp.MustCompile(`([0-9a-f]{64})(?:\.scope)?$`)
+)
+
+const maxPendingOOMs = 64
+
+type OOMParser struct {
+	pending      map[int]domain.OOMKill
+	legacyCgroup string
+}
+
+func NewOOMParser() *OOMParser {
+	return &OOMParser{pending: make(map[int]domain.OOMKill)}
+}
+
+func (p *OOMParser) Feed(message string, at time.Time) (domain.OOMKill, bool) {
+	if i := strings.Index(message, "oom-kill:"); i >= 0 {
+		p.feedOOMKill(message[i+len("oom-kill:"):], at)
+		return domain.OOMKill{}, false
+	}
+
+	if m := legacyTaskRe.FindStringSubmatch(message); m != nil {
+		p.legacyCgroup = m[1]
+		return domain.OOMKill{}, false
+	}
+
+	m := killedProcessRe.FindStringSubmatch(message)
+	if m == nil {
+		return domain.OOMKill{}, false
+	}
+
+	pid, _ := strconv.Atoi(m[1])
+	kill, ok := p.pending[pid]
+	if ok {
+		delete(p.pending, pid)
+	} else {
+		kill = domain.OOMKill{Time: at, PID: pid, CgroupPath: p.legacyCgroup}
+	}
+	p.legacyCgroup = ""
+
+	kill.Comm = m[2]
+	kill.TotalVMKB = parseKB(m[3])
+	kill.AnonRSSKB = parseKB(m[4])
+	kill.FileRSSKB = parseKB(m[5])
+	kill.ShmemRSSKB = parseKB(m[6])
+	if m[7] != "" {
+		kill.OOMScoreAdj, _ = strconv.Atoi(m[7])
+	}
+
+	if kill.Constraint == "" {
+		if strings.Contains(message, "Memory cgroup out of memory") {
+			kill.Constraint = domain.ConstraintMemcg
+		} else {
+			kill.Constraint = "CONSTRAINT_NONE"
+		}
+	}
+
+	kill.PodUID, kill.ContainerID = ParseCgroupPath(kill.CgroupPath)
+
+	return kill, true
+}
+
+func (p *OOMParser) feedOOMKill(fields string, at time.Time) {
+	values := parseKeyValues(fields)
+
+	pid, err := strconv.Atoi(values["pid"])
+	if err != nil {
+		return
+	}
+
+	cgroup := values["task_memcg"]
+	if cgroup == "" {
+		cgroup = values["oom_memcg"]
+	}
+
+	if len(p.pending) >= maxPendingOOMs {
+		p.pending = make(map[int]domain.OOMKill)
+	}
+
+	p.pending[pid] = domain.OOMKill{
+		Time:       at,
+		PID:        pid,
+		Comm:       values["task"],
+		CgroupPath: cgroup,
+		Constraint: values["constraint"],
+	}
+}
+
+func parseKeyValues(s string) map[string]string {
+	values := make(map[string]string)
+	var lastKey string
+	for _, part := range strings.Split(strings.TrimSpace(s), ",") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			if lastKey != "" {
+				values[lastKey] += "," + part
+			}
+			continue
+		}
+		lastKey = strings.TrimSpace(key)
+		values[lastKey] = strings.TrimSpace(value)
+	}
+	return values
+}
+
+func parseKB(s string) int64 {
+	if s == "" {
+		return 0
+	}
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+func ParseCgroupPath(path string) (podUID, containerID string) {
+	if m := podUIDRe.FindStringSubmatch(path); m != nil {
+		podUID = strings.ToLower(strings.ReplaceAll(m[1], "_", "-"))
+	}
+	if m := containerIDRe.FindStringSubmatch(path); m != nil {
+		containerID = m[1]
+	}
+	return podUID, containerID
+}
@@ -0,0 +1,121 @@
+package kernel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+func TestOOMParser_CgroupLimit(t *testing.T) {
+	p := NewOOMParser()
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	lines := []string{
+		"app invoked oom-killer: gfp_mask=0xcc0(GFP_KERNEL), order=0, oom_score_adj=999",
+		"oom-kill:constraint=CONSTRAINT_MEMCG,nodemask=(null),cpuset=cri-containerd-abc.scope,mems_allowed=0,oom_memcg=/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1f2e3d4c_5b6a_7980_a1b2_c3d4e5f60718.slice,task_memcg=/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod1f2e3d4c_5b6a_7980_a1b2_c3d4e5f60718.slice/cri-containerd-0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef.scope,task=java,pid=4242,uid=1000",
+		"Memory cgroup out of memory: Killed process 4242 (java) total-vm:2097152kB, anon-rss:524288kB, file-rss:1024kB, shmem-rss:0kB, UID:1000 pgtables:1200kB oom_score_adj:999",
+	}
+
+	var kill domain.OOMKill
+	var found bool
+	for _, line := range lines {
+		if k, ok := p.Feed(line, at); ok {
+			kill, found = k, true
+		}
+	}
+
+	if !found {
+		t.Fatal("expected OOM kill")
+	}
+	if kill.PID != 4242 || kill.Comm != "java" {
+		t.Errorf("pid/comm = %d/%s, want 4242/java", kill.PID, kill.Comm)
+	}
+	if !kill.IsCgroupLimit() {
+		t.Errorf("Constraint = %s, want %s", kill.Constraint, domain.ConstraintMemcg)
+	}
+	if kill.PodUID != "1f2e3d4c-5b6a-7980-a1b2-c3d4e5f60718" {
+		t.Errorf("PodUID = %s", kill.PodUID)
+	}
+	if kill.ContainerID != "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef" {
+		t.Errorf("ContainerID = %s", kill.ContainerID)
+	}
+	if kill.RSSKB() != 525312 {
+		t.Errorf("RSSKB() = %d, want 525312", kill.RSSKB())
+	}
+	if kill.OOMScoreAdj != 999 {
+		t.Errorf("OOMScoreAdj = %d, want 999", kill.OOMScoreAdj)
+	}
+	if !kill.Time.Equal(at) {
+		t.Errorf("Time = %v, want %v", kill.Time, at)
+	}
+}
+
+func TestOOMParser_NodeLevel(t *testing.T) {
+	p := NewOOMParser()
+	p.Feed("oom-kill:constraint=CONSTRAINT_NONE,nodemask=(null),cpuset=/,mems_allowed=0,global_oom,task_memcg=/kubepods/besteffort/pod0a1b2c3d-0000-1111-2222-333344445555/fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210,task=worker,pid=77,uid=0", time.Now())
+
+	kill, ok := p.Feed("Out of memory: Killed process 77 (worker) total-vm:1000kB, anon-rss:800kB, file-rss:0kB, shmem-rss:0kB, UID:0 pgtables:10kB oom_score_adj:1000", time.Now())
+	if !ok {
+		t.Fatal("expected OOM kill")
+	}
+	if kill.IsCgroupLimit() {
+		t.Error("expected node-level OOM")
+	}
+	if kill.PodUID != "0a1b2c3d-0000-1111-2222-333344445555" {
+		t.Errorf("PodUID = %s", kill.PodUID)
+	}
+	if kill.ContainerID != "fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210" {
+		t.Errorf("ContainerID = %s", kill.ContainerID)
+	}
+}
+
+func TestOOMParser_Legacy(t *testing.T) {
+	p := NewOOMParser()
+	p.Feed("Task in /kubepods/burstable/pod0a1b2c3d-0000-1111-2222-333344445555/fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210 killed as a result of limit of /kubepods/burstable/pod0a1b2c3d-0000-1111-2222-333344445555", time.Now())
+
+	kill, ok := p.Feed("Memory cgroup out of memory: Killed process 9 (node) total-vm:100kB, anon-rss:50kB, file-rss:0kB, shmem-rss:0kB", time.Now())
+	if !ok {
+		t.Fatal("expected OOM kill")
+	}
+	if !kill.IsCgroupLimit() {
+		t.Errorf("Constraint = %s, want %s", kill.Constraint, domain.ConstraintMemcg)
+	}
+	if kill.PodUID != "0a1b2c3d-0000-1111-2222-333344445555" || kill.ContainerID == "" {
+		t.Errorf("PodUID/ContainerID = %s/%s", kill.PodUID, kill.ContainerID)
+	}
+}
+
+func TestOOMParser_IgnoresOtherMessages(t *testing.T) {
+	p := NewOOMParser()
+	if _, ok := p.Feed("eth0: link up", time.Now()); ok {
+		t.Error("unexpected OOM kill")
+	}
+}
+
+func TestOOMTracker_Match(t *testing.T) {
+	now := time.Now()
+	tracker := NewOOMTracker()
+	tracker.Add(domain.OOMKill{Time: now, PID: 1, PodUID: "uid-a", ContainerID: "c1"})
+	tracker.Add(domain.OOMKill{Time: now, PID: 2, PodUID: "uid-a", ContainerID: "c2"})
+	tracker.Add(domain.OOMKill{Time: now, PID: 3, PodUID: "uid-b"})
+	tracker.Add(domain.OOMKill{Time: now.Add(-30 * time.Minute), PID: 4, PodUID: "uid-b"})
+
+	kills := tracker.Match(domain.PodCrash{PodUID: "uid-a", ContainerID: "c1"})
+	if len(kills) != 1 || kills[0].PID != 1 {
+		t.Errorf("Match by container = %+v", kills)
+	}
+
+	kills = tracker.Match(domain.PodCrash{
+		PodUID:     "uid-b",
+		StartedAt:  now.Add(-5 * time.Minute),
+		FinishedAt: now,
+	})
+	if len(kills) != 1 || kills[0].PID != 3 {
+		t.Errorf("Match by run window = %+v", kills)
+	}
+
+	if kills := tracker.Match(domain.PodCrash{}); kills != nil {
+		t.Errorf("Match without UID = %+v", kills)
+	}
+}
@@ -0,0 +1,165 @@
+package kernel
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+const DefaultSource = "/dev/kmsg"
+
+type Reader struct {
+	source       string
+	pollInterval time.Duration
+	bootTime     time.Time
+	parser       *OOMParser
+}
+
+func NewReader(source string) *Reader {
+	if source == "" {
+		source = DefaultSource
+	}
+	return &Reader{
+		source:       source,
+		pollInterval: time.Second,
+		bootTime:     readBootTime(),
+		parser:       NewOOMParser(),
+	}
+}
+
+func (r *Reader) Run(ctx context.Context, handler func(domain.OOMKill)) error {
+	f, err := os.Open(r.source)
+	if err != nil {
+		return fmt.Errorf("failed to open kernel log source: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat kernel log source: %w", err)
+	}
+
+	follow := info.Mode().IsRegular()
+	if follow {
+		if _, err := f.Seek(0, io.SeekEnd); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to seek kernel log source: %w", err)
+		}
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		f.Close()
+	}()
+
+	return r.readLoop(ctx, bufio.NewReader(f), follow, handler)
+}
+
+func (r *Reader) readLoop(ctx context.Context, br *bufio.Reader, follow bool, handler func(domain.OOMKill)) error {
+	var partial string
+	for {
+		chunk, err := br.ReadString('\n')
+		if chunk != "" {
+			if !strings.HasSuffix(chunk, "\n") {
+				partial += chunk
+			} else {
+				r.handleLine(partial+chunk, handler)
+				partial = ""
+			}
+		}
+
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		switch {
+		case errors.Is(err, io.EOF) && follow:
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(r.pollInterval):
+			}
+		case errors.Is(err, io.EOF):
+			if partial != "" {
+				r.handleLine(partial, handler)
+			}
+			return nil
+		case errors.Is(err, syscall.EPIPE):
+			continue
+		default:
+			return fmt.Errorf("failed to read kernel log source: %w", err)
+		}
+	}
+}
+
+func (r *Reader) handleLine(line string, handler func(domain.OOMKill)) {
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" || strings.HasPrefix(line, " ") {
+		return
+	}
+
+	message, at := r.splitRecord(line)
+	if kill, ok := r.parser.Feed(message, at); ok {
+		handler(kill)
+	}
+}
+
+func (r *Reader) splitRecord(line string) (string, time.Time) {
+	now := time.Now()
+
+	header, message, ok := strings.Cut(line, ";")
+	if !ok {
+		return line, now
+	}
+
+	fields := strings.Split(header, ",")
+	if len(fields) < 3 {
+		return line, now
+	}
+	if _, err := strconv.Atoi(fields[0]); err != nil {
+		return line, now
+	}
+
+	usec, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil || r.bootTime.IsZero() {
+		return message, now
+	}
+
+	return message, r.bootTime.Add(time.Duration(usec) * time.Microsecond)
+}
+
+func readBootTime() time.Time {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return time.Time{}
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return time.Time{}
+	}
+
+	uptime, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Now().Add(-time.Duration(uptime * float64(time.Second)))
+}
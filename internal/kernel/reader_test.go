@@ -0,0 +1,92 @@
+package kernel
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+const (
+	testOOMKillLine = "oom-kill:constraint=CONSTRAINT_MEMCG,task_memcg=/kubepods/pod0a1b2c3d-0000-1111-2222-333344445555/fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210,task=app,pid=10,uid=0"
+	testKilledLine  = "Memory cgroup out of memory: Killed process 10 (app) total-vm:100kB, anon-rss:50kB, file-rss:0kB, shmem-rss:0kB"
+)
+
+func TestReader_KmsgRecords(t *testing.T) {
+	boot := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewReader("")
+	r.bootTime = boot
+
+	input := strings.Join([]string{
+		"6,100,5000000,-;" + testOOMKillLine,
+		" SUBSYSTEM=memory",
+		"3,101,5000001,-;" + testKilledLine,
+		"",
+	}, "\n")
+
+	var kills []domain.OOMKill
+	err := r.readLoop(context.Background(), bufio.NewReader(strings.NewReader(input)), false, func(k domain.OOMKill) {
+		kills = append(kills, k)
+	})
+	if err != nil {
+		t.Fatalf("readLoop() error = %v", err)
+	}
+
+	if len(kills) != 1 {
+		t.Fatalf("got %d kills, want 1", len(kills))
+	}
+	if want := boot.Add(5 * time.Second); !kills[0].Time.Equal(want) {
+		t.Errorf("Time = %v, want %v", kills[0].Time, want)
+	}
+	if kills[0].PodUID != "0a1b2c3d-0000-1111-2222-333344445555" {
+		t.Errorf("PodUID = %s", kills[0].PodUID)
+	}
+}
+
+func TestReader_FollowsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kern.log")
+	if err := os.WriteFile(path, []byte(testOOMKillLine+"\n"+testKilledLine+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReader(path)
+	r.pollInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kills := make(chan domain.OOMKill, 4)
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Run(ctx, func(k domain.OOMKill) { kills <- k })
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("Jan  1 00:00:00 node kernel: " + strings.Replace(testOOMKillLine, "pid=10", "pid=11", 1) + "\n")
+	_, _ = f.WriteString("Jan  1 00:00:00 node kernel: " + strings.Replace(testKilledLine, "process 10", "process 11", 1) + "\n")
+	f.Close()
+
+	select {
+	case k := <-kills:
+		if k.PID != 11 {
+			t.Errorf("PID = %d, want 11 (existing content should be skipped)", k.PID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for OOM kill")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package kernel
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+const (
+	defaultTrackedOOMs = 256
+	defaultOOMTTL      = time.Hour
+	oomMatchSlack      = time.Minute
+)
+
+type OOMTracker struct {
+	kills []domain.OOMKill
+	max   int
+	ttl   time.Duration
+	mu    sync.Mutex
+}
+
+func NewOOMTracker() *OOMTracker {
+	return &OOMTracker{
+		max: defaultTrackedOOMs,
+		ttl: defaultOOMTTL,
+	}
+}
+
+func (t *OOMTracker) Add(kill domain.OOMKill) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cutoff := time.Now().Add(-t.ttl)
+	kept := t.kills[:0]
+	for _, k := range t.kills {
+		if k.Time.After(cutoff) {
+			kept = append(kept, k)
+		}
+	}
+
+	t.kills = append(kept, kill)
+	if len(t.kills) > t.max {
+		t.kills = t.kills[len(t.kills)-t.max:]
+	}
+}
+
+func (t *OOMTracker) Match(crash domain.PodCrash) []domain.OOMKill {
+	if crash.PodUID == "" {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var matches []domain.OOMKill
+	for _, k := range t.kills {
+		if !strings.EqualFold(k.PodUID, crash.PodUID) {
+			continue
+		}
+
+		if crash.ContainerID != "" && k.ContainerID != "" {
+			if k.ContainerID != crash.ContainerID {
+				continue
+			}
+		} else if !withinRun(k.Time, crash) {
+			continue
+		}
+
+		matches = append(matches, k)
+	}
+
+	return matches
+}
+
+func withinRun(at time.Time, crash domain.PodCrash) bool {
+	if !crash.StartedAt.IsZero() && at.Before(crash.StartedAt.Add(-oomMatchSlack)) {
+		return false
+	}
+	if !crash.FinishedAt.IsZero() && at.After(crash.FinishedAt.Add(oomMatchSlack)) {
+		return false
+	}
+	return true
+}
@@ -1,8 +1,35 @@
 package notifier
 
-import "github.com/kadirbelkuyu/kubecrsh/internal/domain"
+import (
+	"strings"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
 
 type Notifier interface {
 	Notify(report domain.ForensicReport) error
 	Name() string
 }
+
+const maxNotifiedFindings = 3
+
+func severityOf(report domain.ForensicReport) domain.Severity {
+	if report.Severity != nil && domain.ValidSeverity(report.Severity.Level) {
+		return *report.Severity
+	}
+	return domain.ClassifySeverity(&report, domain.SeverityContext{})
+}
+
+func severitySummary(s domain.Severity) string {
+	if len(s.Reasons) == 0 {
+		return s.Level
+	}
+	return s.Level + ": " + strings.Join(s.Reasons, ", ")
+}
+
+func topFindings(report domain.ForensicReport) []domain.Finding {
+	if len(report.Findings) > maxNotifiedFindings {
+		return report.Findings[:maxNotifiedFindings]
+	}
+	return report.Findings
+}
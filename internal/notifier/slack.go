@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
@@ -42,18 +43,32 @@ func NewSlackNotifier(webhookURL, channel string) *SlackNotifier {
 }
 
 func (s *SlackNotifier) Notify(report domain.ForensicReport) error {
+	exit := report.ExitInfo()
+	severity := severityOf(report)
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "🚨 *[%s] Pod Crash Detected: %s*", strings.ToUpper(severity.Level), report.Summary())
+	for _, f := range topFindings(report) {
+		fmt.Fprintf(&text, "\n• *%s*", f.String())
+		if f.Action != "" {
+			fmt.Fprintf(&text, "\n    → %s", f.Action)
+		}
+	}
+
 	msg := slackMessage{
 		Channel: s.channel,
-		Text:    fmt.Sprintf("🚨 *Pod Crash Detected: %s*", report.Summary()),
+		Text:    text.String(),
 		Attachments: []slackAttachment{{
-			Color: s.colorForReason(report.Crash.Reason),
+			Color: s.colorForSeverity(severity.Level),
 			Fields: []slackField{
+				{Title: "Severity", Value: severitySummary(severity), Short: false},
 				{Title: "Namespace", Value: report.Crash.Namespace, Short: true},
 				{Title: "Pod", Value: report.Crash.PodName, Short: true},
 				{Title: "Container", Value: report.Crash.ContainerName, Short: true},
 				{Title: "Reason", Value: report.Crash.Reason, Short: true},
-				{Title: "Exit Code", Value: fmt.Sprintf("%d", report.Crash.ExitCode), Short: true},
+				{Title: "Exit Code", Value: exit.ExitLabel(), Short: true},
 				{Title: "Restart Count", Value: fmt.Sprintf("%d", report.Crash.RestartCount), Short: true},
+				{Title: "Cause", Value: fmt.Sprintf("%s (initiated by %s)", exit.Cause, exit.Initiator), Short: false},
 				{Title: "Report ID", Value: report.ID, Short: false},
 				{Title: "Collected", Value: report.CollectedAt.Format("2006-01-02 15:04:05"), Short: true},
 			},
@@ -103,13 +118,15 @@ func (s *SlackNotifier) Name() string {
 	return "slack"
 }
 
-func (s *SlackNotifier) colorForReason(reason string) string {
-	switch reason {
-	case "OOMKilled":
+func (s *SlackNotifier) colorForSeverity(level string) string {
+	switch level {
+	case domain.SeverityCritical:
 		return "danger"
-	case "CrashLoopBackOff":
+	case domain.SeverityHigh:
+		return "#ff9500"
+	case domain.SeverityMedium:
 		return "warning"
 	default:
-		return "#ff9500"
+		return "#439fe0"
 	}
 }
@@ -19,23 +19,24 @@ func TestSlackNotifier_Name(t *testing.T) {
 	}
 }
 
-func TestSlackNotifier_colorForReason(t *testing.T) {
+func TestSlackNotifier_colorForSeverity(t *testing.T) {
 	notifier := NewSlackNotifier("", "")
 
 	tests := []struct {
-		reason string
-		want   string
+		level string
+		want  string
 	}{
-		{"OOMKilled", "danger"},
-		{"CrashLoopBackOff", "warning"},
-		{"Error", "#ff9500"},
-		{"Unknown", "#ff9500"},
+		{domain.SeverityCritical, "danger"},
+		{domain.SeverityHigh, "#ff9500"},
+		{domain.SeverityMedium, "warning"},
+		{domain.SeverityLow, "#439fe0"},
+		{"", "#439fe0"},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.reason, func(t *testing.T) {
-			if got := notifier.colorForReason(tt.reason); got != tt.want {
-				t.Errorf("colorForReason(%s) = %v, want %v", tt.reason, got, tt.want)
+		t.Run(tt.level, func(t *testing.T) {
+			if got := notifier.colorForSeverity(tt.level); got != tt.want {
+				t.Errorf("colorForSeverity(%s) = %v, want %v", tt.level, got, tt.want)
 			}
 		})
 	}
@@ -61,6 +62,7 @@ func TestSlackNotifier_Notify_Success(t *testing.T) {
 		RestartCount:  5,
 	}
 	report := *domain.NewForensicReport(crash)
+	report.SetSeverity(domain.Severity{Level: domain.SeverityCritical, Score: 90, Reasons: []string{"namespace is labelled critical"}})
 
 	err := notifier.Notify(report)
 	if err != nil {
@@ -82,7 +84,10 @@ func TestSlackNotifier_Notify_Success(t *testing.T) {
 		t.Fatalf("Expected 1 attachment, got %d", len(msg.Attachments))
 	}
 	if msg.Attachments[0].Color != "danger" {
-		t.Errorf("Color = %v, want danger (for OOMKilled)", msg.Attachments[0].Color)
+		t.Errorf("Color = %v, want danger (for critical severity)", msg.Attachments[0].Color)
+	}
+	if !strings.Contains(msg.Text, "[CRITICAL]") || msg.Attachments[0].Fields[0].Value != "critical: namespace is labelled critical" {
+		t.Errorf("severity missing from message: %s / %+v", msg.Text, msg.Attachments[0].Fields[0])
 	}
 }
 
@@ -125,6 +130,9 @@ func TestSlackNotifier_Notify_MessageFormat(t *testing.T) {
 	if fieldMap["Exit Code"] != "1" {
 		t.Errorf("Exit Code field = %v, want 1", fieldMap["Exit Code"])
 	}
+	if !strings.Contains(fieldMap["Cause"], "initiated by application") {
+		t.Errorf("Cause field = %v, want application-initiated cause", fieldMap["Cause"])
+	}
 	if fieldMap["Report ID"] != report.ID {
 		t.Errorf("Report ID field = %v, want %s", fieldMap["Report ID"], report.ID)
 	}
@@ -199,3 +207,34 @@ func BenchmarkSlackNotifier_Notify(b *testing.B) {
 		notifier.Notify(report)
 	}
 }
+
+func TestSlackNotifier_Notify_LeadsWithFindings(t *testing.T) {
+	var msg slackMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&msg)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	report := *domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api", Reason: "OOMKilled", ExitCode: 137})
+	report.SetFindings([]domain.Finding{
+		{Severity: domain.FindingWarning, Confidence: 0.6, Title: "Dependency unreachable"},
+		{Severity: domain.FindingCritical, Confidence: 0.95, Title: "Memory limit 256Mi reached", Action: "Raise resources.limits.memory"},
+	})
+
+	if err := NewSlackNotifier(server.URL, "").Notify(report); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	lines := strings.Split(msg.Text, "\n")
+	if len(lines) < 4 {
+		t.Fatalf("Text = %q, want title followed by findings", msg.Text)
+	}
+	if !strings.Contains(lines[1], "[critical] Memory limit 256Mi reached") || !strings.Contains(lines[2], "Raise resources.limits.memory") {
+		t.Errorf("Text does not lead with the critical finding: %q", msg.Text)
+	}
+	if !strings.Contains(lines[3], "Dependency unreachable") {
+		t.Errorf("Text missing second finding: %q", msg.Text)
+	}
+}
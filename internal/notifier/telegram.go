@@ -53,16 +53,32 @@ func NewTelegramNotifier(baseURL *string, token, chatID string) *TelegramNotifie
 }
 
 func (s *TelegramNotifier) Notify(report domain.ForensicReport) error {
+	exit := report.ExitInfo()
+	severity := severityOf(report)
+
+	var diagnosis strings.Builder
+	for _, f := range topFindings(report) {
+		fmt.Fprintf(&diagnosis, "- %s\n", f.String())
+		if f.Action != "" {
+			fmt.Fprintf(&diagnosis, "  Action: %s\n", f.Action)
+		}
+	}
+
 	msg := telegramSendMessageRequest{
 		ChatID: s.chatID,
 		Text: fmt.Sprintf(
-			"Pod crash detected: %s\nNamespace: %s\nPod: %s\nContainer: %s\nReason: %s\nExit code: %d\nRestart count: %d\nReport ID: %s\nCollected: %s",
+			"[%s] Pod crash detected: %s\n%sSeverity: %s\nNamespace: %s\nPod: %s\nContainer: %s\nReason: %s\nExit code: %s\nCause: %s (initiated by %s)\nRestart count: %d\nReport ID: %s\nCollected: %s",
+			strings.ToUpper(severity.Level),
 			report.Summary(),
+			diagnosis.String(),
+			severitySummary(severity),
 			report.Crash.Namespace,
 			report.Crash.PodName,
 			report.Crash.ContainerName,
 			report.Crash.Reason,
-			report.Crash.ExitCode,
+			exit.ExitLabel(),
+			exit.Cause,
+			exit.Initiator,
 			report.Crash.RestartCount,
 			report.ID,
 			report.CollectedAt.Format("2006-01-02 15:04:05"),
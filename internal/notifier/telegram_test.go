@@ -60,6 +60,7 @@ func TestTelegramNotifier_Notify_Success(t *testing.T) {
 		RestartCount:  5,
 	}
 	report := *domain.NewForensicReport(crash)
+	report.SetFindings([]domain.Finding{{Severity: domain.FindingCritical, Confidence: 0.95, Title: "Memory limit 512Mi reached", Action: "Raise resources.limits.memory"}})
 
 	if err := notifier.Notify(report); err != nil {
 		t.Fatalf("Notify() error = %v", err)
@@ -81,6 +82,18 @@ func TestTelegramNotifier_Notify_Success(t *testing.T) {
 	if !strings.Contains(received.Text, report.ID) {
 		t.Fatalf("text does not contain report ID")
 	}
+
+	if lines := strings.Split(received.Text, "\n"); len(lines) < 3 || !strings.Contains(lines[1], "Memory limit 512Mi reached") || !strings.Contains(lines[2], "Raise resources.limits.memory") {
+		t.Fatalf("text does not lead with the diagnosis: %s", received.Text)
+	}
+
+	if !strings.Contains(received.Text, "137 (SIGKILL)") || !strings.Contains(received.Text, "OOM killer") {
+		t.Fatalf("text does not contain decoded exit cause: %s", received.Text)
+	}
+
+	if !strings.HasPrefix(received.Text, "[HIGH] Pod crash detected") || !strings.Contains(received.Text, "Severity: high: killed for running out of memory, 5 restarts") {
+		t.Fatalf("text does not carry the severity: %s", received.Text)
+	}
 }
 
 func TestTelegramNotifier_Notify_ServerError(t *testing.T) {
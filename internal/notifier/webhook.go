@@ -5,11 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
 )
 
+const (
+	SchemaVersionHeader = "X-Kubecrsh-Schema-Version"
+	SeverityHeader      = "X-Kubecrsh-Severity"
+)
+
 type WebhookNotifier struct {
 	url     string
 	headers map[string]string
@@ -29,6 +35,7 @@ func (w *WebhookNotifier) Notify(report domain.ForensicReport) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal report: %w", err)
 	}
+	severity := severityOf(report).Level
 
 	var lastErr error
 	for attempt := 0; attempt < 3; attempt++ {
@@ -38,6 +45,8 @@ func (w *WebhookNotifier) Notify(report domain.ForensicReport) error {
 		}
 
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(SchemaVersionHeader, strconv.Itoa(report.SchemaVersion))
+		req.Header.Set(SeverityHeader, severity)
 		for k, v := range w.headers {
 			req.Header.Set(k, v)
 		}
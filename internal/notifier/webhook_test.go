@@ -49,6 +49,12 @@ func TestWebhookNotifier_Notify_Success(t *testing.T) {
 	if receivedHeaders.Get("Content-Type") != "application/json" {
 		t.Errorf("Content-Type = %v, want application/json", receivedHeaders.Get("Content-Type"))
 	}
+	if receivedHeaders.Get(SeverityHeader) != domain.SeverityMedium {
+		t.Errorf("%s = %q, want %s", SeverityHeader, receivedHeaders.Get(SeverityHeader), domain.SeverityMedium)
+	}
+	if receivedHeaders.Get(SchemaVersionHeader) != "2" {
+		t.Errorf("%s = %q, want 2", SchemaVersionHeader, receivedHeaders.Get(SchemaVersionHeader))
+	}
 	if receivedHeaders.Get("Authorization") != "Bearer token123" {
 		t.Errorf("Authorization header not set correctly")
 	}
@@ -65,30 +65,37 @@ func (r *Redactor) Apply(report *domain.ForensicReport) {
 		return
 	}
 
-	if report.EnvVars != nil {
-		for k, v := range report.EnvVars {
-			if !r.redactFromSource && v == "[from-source]" {
-				continue
-			}
-
-			if len(r.envAllowlist) > 0 && !matchAny(r.envAllowlist, k) {
-				report.EnvVars[k] = r.replacement
-				continue
-			}
-
-			if len(r.envAllowlist) == 0 && len(r.envDenylist) == 0 {
-				report.EnvVars[k] = r.replacement
-				continue
-			}
-
-			if len(r.envDenylist) > 0 && matchAny(r.envDenylist, k) {
-				report.EnvVars[k] = r.replacement
-			}
+	for k, v := range report.EnvVars {
+		if !r.redactFromSource && v == "[from-source]" {
+			continue
 		}
+		report.EnvVars[k] = r.RedactEnv(k, v)
 	}
 
 	report.Logs = r.redactLines(report.Logs)
 	report.PreviousLog = r.redactLines(report.PreviousLog)
+	report.LastWords = r.redactLines(report.LastWords)
+
+	for i := range report.Findings {
+		f := &report.Findings[i]
+		f.Title = r.redactLine(f.Title)
+		f.Detail = r.redactLine(f.Detail)
+		f.Evidence = r.redactLines(f.Evidence)
+	}
+}
+
+// RedactEnv returns the replacement for the value of an environment
+// variable the env allowlist and denylist do not let through.
+func (r *Redactor) RedactEnv(name, value string) string {
+	switch {
+	case len(r.envAllowlist) > 0 && !matchAny(r.envAllowlist, name):
+		return r.replacement
+	case len(r.envAllowlist) == 0 && len(r.envDenylist) == 0:
+		return r.replacement
+	case len(r.envDenylist) > 0 && matchAny(r.envDenylist, name):
+		return r.replacement
+	}
+	return value
 }
 
 func (r *Redactor) redactLines(lines []string) []string {
@@ -98,15 +105,18 @@ func (r *Redactor) redactLines(lines []string) []string {
 
 	out := make([]string, len(lines))
 	for i, line := range lines {
-		s := line
-		for _, rule := range r.logRules {
-			s = rule.re.ReplaceAllString(s, rule.repl)
-		}
-		out[i] = s
+		out[i] = r.redactLine(line)
 	}
 	return out
 }
 
+func (r *Redactor) redactLine(line string) string {
+	for _, rule := range r.logRules {
+		line = rule.re.ReplaceAllString(line, rule.repl)
+	}
+	return line
+}
+
 func matchAny(patterns []string, s string) bool {
 	for _, p := range patterns {
 		p = strings.TrimSpace(p)
@@ -0,0 +1,516 @@
+package reporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+)
+
+var _ Storage = (*CRDStore)(nil)
+var _ SaveWithResult = (*CRDStore)(nil)
+var _ Triager = (*CRDStore)(nil)
+var _ Pruner = (*CRDStore)(nil)
+
+// CrashReportGVR is the resource reports are stored as by CRDStore.
+var CrashReportGVR = schema.GroupVersionResource{Group: "kubecrsh.io", Version: "v1alpha1", Resource: "crashreports"}
+
+const (
+	CrashReportKind = "CrashReport"
+
+	crdReportIDLabel = "kubecrsh.io/report-id"
+	crdWorkloadLabel = "kubecrsh.io/workload"
+	crdReasonLabel   = "kubecrsh.io/reason"
+	crdSeverityLabel = "kubecrsh.io/severity"
+	crdManagedBy     = "app.kubernetes.io/managed-by"
+
+	crdListPageSize = 500
+)
+
+type CRDConfig struct {
+	// Namespace limits Load, List and Prune to one namespace; empty means
+	// all namespaces.
+	Namespace string
+	// MaxBodyBytes embeds the gzip-compressed report in the resource when it
+	// fits, leaving out the logs if that is what it takes. Zero stores the
+	// summary only.
+	MaxBodyBytes int
+	// OwnerReferences makes the crashed pod the owner of its reports, so
+	// they are garbage collected with it.
+	OwnerReferences bool
+	Timeout         time.Duration
+}
+
+// CRDStore keeps each report as a CrashReport custom resource named after
+// the report ID, in the namespace of the crashed pod. The spec holds a
+// summary of the crash and, optionally, the report itself; triage is kept
+// in the status.
+type CRDStore struct {
+	client    dynamic.Interface
+	namespace string
+	maxBody   int
+	owners    bool
+	timeout   time.Duration
+}
+
+type crashReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              crashReportSpec   `json:"spec"`
+	Status            crashReportStatus `json:"status,omitempty"`
+}
+
+type crashReportSpec struct {
+	ReportID      string               `json:"reportID"`
+	PodName       string               `json:"podName"`
+	PodUID        string               `json:"podUID,omitempty"`
+	NodeName      string               `json:"nodeName,omitempty"`
+	Container     string               `json:"container"`
+	Workload      string               `json:"workload,omitempty"`
+	SourceCluster string               `json:"sourceCluster,omitempty"`
+	Reason        string               `json:"reason"`
+	ExitCode      int32                `json:"exitCode"`
+	Signal        int32                `json:"signal,omitempty"`
+	RestartCount  int32                `json:"restartCount"`
+	Cause         string               `json:"cause,omitempty"`
+	Severity      string               `json:"severity,omitempty"`
+	SeverityScore int                  `json:"severityScore,omitempty"`
+	Fingerprint   string               `json:"fingerprint,omitempty"`
+	Findings      []crashReportFinding `json:"findings,omitempty"`
+	CollectedAt   time.Time            `json:"collectedAt"`
+	Body          string               `json:"body,omitempty"`
+	LogsOmitted   bool                 `json:"logsOmitted,omitempty"`
+}
+
+type crashReportFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Title    string `json:"title"`
+}
+
+type crashReportStatus struct {
+	Triage *domain.Triage `json:"triage,omitempty"`
+}
+
+func NewCRDStore(client dynamic.Interface, cfg CRDConfig) (*CRDStore, error) {
+	if client == nil {
+		return nil, fmt.Errorf("kubernetes client is required")
+	}
+	if cfg.MaxBodyBytes < 0 {
+		return nil, fmt.Errorf("max body bytes must not be negative")
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &CRDStore{
+		client:    client,
+		namespace: cfg.Namespace,
+		maxBody:   cfg.MaxBodyBytes,
+		owners:    cfg.OwnerReferences,
+		timeout:   timeout,
+	}, nil
+}
+
+func (s *CRDStore) Save(report *domain.ForensicReport) error {
+	_, err := s.SaveWithResult(report)
+	return err
+}
+
+// SaveWithResult creates or replaces the resource of report, then writes
+// its triage through the status subresource.
+func (s *CRDStore) SaveWithResult(report *domain.ForensicReport) (SaveResult, error) {
+	if report.Crash.Namespace == "" {
+		return SaveResult{}, fmt.Errorf("report %s has no namespace", report.ID)
+	}
+
+	desired, err := s.toObject(report)
+	if err != nil {
+		return SaveResult{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	resources := s.client.Resource(CrashReportGVR).Namespace(report.Crash.Namespace)
+	var saved *unstructured.Unstructured
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := resources.Get(ctx, report.ID, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			saved, err = resources.Create(ctx, desired, metav1.CreateOptions{})
+			return err
+		case err != nil:
+			return err
+		}
+
+		obj := desired.DeepCopy()
+		obj.SetResourceVersion(existing.GetResourceVersion())
+		obj.SetOwnerReferences(existing.GetOwnerReferences())
+		saved, err = resources.Update(ctx, obj, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return SaveResult{}, fmt.Errorf("failed to save crash report resource: %w", err)
+	}
+
+	if report.Triage != nil {
+		if err := s.writeTriage(ctx, saved, report.Triage); err != nil {
+			return SaveResult{}, err
+		}
+	}
+
+	size, _ := desired.MarshalJSON()
+	return SaveResult{
+		BytesWritten: int64(len(size)),
+		Path:         fmt.Sprintf("%s/%s/%s", CrashReportGVR.GroupResource(), report.Crash.Namespace, report.ID),
+	}, nil
+}
+
+func (s *CRDStore) Load(id string) (*domain.ForensicReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	obj, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return fromObject(obj)
+}
+
+func (s *CRDStore) List() ([]*domain.ForensicReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var reports []*domain.ForensicReport
+	err := s.each(ctx, metav1.ListOptions{}, func(obj *unstructured.Unstructured) {
+		report, err := fromObject(obj)
+		if err != nil {
+			return
+		}
+		reports = append(reports, report)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list crash report resources: %w", err)
+	}
+
+	sort.Slice(reports, func(i, j int) bool { return reportBefore(reports[j], reports[i]) })
+
+	return reports, nil
+}
+
+// UpdateTriage writes the triage of a report through the status
+// subresource and leaves the spec alone.
+func (s *CRDStore) UpdateTriage(id string, update domain.TriageUpdate, actor domain.TriageActor) (*domain.ForensicReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	var report *domain.ForensicReport
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj, err := s.get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if report, err = fromObject(obj); err != nil {
+			return err
+		}
+		if err := report.ApplyTriage(update, actor, time.Now()); err != nil {
+			return err
+		}
+		return s.writeTriage(ctx, obj, report.Triage)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Prune deletes resources of reports collected before the retention window.
+// Resources already removed by the garbage collector are not an error.
+func (s *CRDStore) Prune(retention time.Duration) (PruneResult, error) {
+	var res PruneResult
+	if retention <= 0 {
+		return res, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	cutoff := time.Now().Add(-retention)
+	var expired []*unstructured.Unstructured
+	err := s.each(ctx, metav1.ListOptions{}, func(obj *unstructured.Unstructured) {
+		var cr crashReport
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &cr); err != nil {
+			res.Failed++
+			return
+		}
+		if cr.Spec.CollectedAt.Before(cutoff) {
+			expired = append(expired, obj)
+		} else {
+			res.Kept++
+		}
+	})
+	if err != nil {
+		return res, fmt.Errorf("failed to list crash report resources: %w", err)
+	}
+
+	var firstErr error
+	for _, obj := range expired {
+		err := s.client.Resource(CrashReportGVR).Namespace(obj.GetNamespace()).Delete(ctx, obj.GetName(), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			res.Failed++
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to delete crash report resource: %w", err)
+			}
+			continue
+		}
+		res.Deleted++
+	}
+
+	return res, firstErr
+}
+
+// get finds the resource of a report by its ID label, since the namespace
+// is not known from the ID alone.
+func (s *CRDStore) get(ctx context.Context, id string) (*unstructured.Unstructured, error) {
+	if validation.IsValidLabelValue(id) != nil {
+		return nil, fmt.Errorf("report not found: %s", id)
+	}
+
+	var found *unstructured.Unstructured
+	err := s.each(ctx, metav1.ListOptions{LabelSelector: crdReportIDLabel + "=" + id}, func(obj *unstructured.Unstructured) {
+		if found == nil {
+			found = obj
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load crash report resource: %w", err)
+	}
+	if found == nil {
+		return nil, fmt.Errorf("report not found: %s", id)
+	}
+	return found, nil
+}
+
+func (s *CRDStore) each(ctx context.Context, opts metav1.ListOptions, fn func(obj *unstructured.Unstructured)) error {
+	opts.Limit = crdListPageSize
+	for {
+		list, err := s.client.Resource(CrashReportGVR).Namespace(s.namespace).List(ctx, opts)
+		if err != nil {
+			return err
+		}
+		for i := range list.Items {
+			fn(&list.Items[i])
+		}
+		if opts.Continue = list.GetContinue(); opts.Continue == "" {
+			return nil
+		}
+	}
+}
+
+func (s *CRDStore) writeTriage(ctx context.Context, obj *unstructured.Unstructured, triage *domain.Triage) error {
+	status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&crashReportStatus{Triage: triage})
+	if err != nil {
+		return fmt.Errorf("failed to encode triage: %w", err)
+	}
+
+	obj = obj.DeepCopy()
+	obj.Object["status"] = status
+	if _, err := s.client.Resource(CrashReportGVR).Namespace(obj.GetNamespace()).UpdateStatus(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to save crash report triage: %w", err)
+	}
+	return nil
+}
+
+func (s *CRDStore) toObject(report *domain.ForensicReport) (*unstructured.Unstructured, error) {
+	cr := crashReport{
+		TypeMeta: metav1.TypeMeta{APIVersion: CrashReportGVR.GroupVersion().String(), Kind: CrashReportKind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      report.ID,
+			Namespace: report.Crash.Namespace,
+			Labels: map[string]string{
+				crdManagedBy:     "kubecrsh",
+				crdReportIDLabel: report.ID,
+			},
+		},
+		Spec: crashReportSpec{
+			ReportID:      report.ID,
+			PodName:       report.Crash.PodName,
+			PodUID:        report.Crash.PodUID,
+			NodeName:      report.Crash.NodeName,
+			Container:     report.Crash.ContainerName,
+			Workload:      report.Crash.WorkloadName(),
+			SourceCluster: report.SourceCluster(),
+			Reason:        report.Crash.Reason,
+			ExitCode:      report.Crash.ExitCode,
+			Signal:        report.Crash.Signal,
+			RestartCount:  report.Crash.RestartCount,
+			Cause:         report.Exit.Cause,
+			Severity:      report.SeverityLevel(),
+			Fingerprint:   report.GroupKey(),
+			CollectedAt:   report.CollectedAt.UTC(),
+		},
+	}
+	if report.Severity != nil {
+		cr.Spec.SeverityScore = report.Severity.Score
+	}
+	for _, f := range report.Findings {
+		cr.Spec.Findings = append(cr.Spec.Findings, crashReportFinding{Rule: f.Rule, Severity: f.Severity, Title: f.Title})
+	}
+
+	for key, value := range map[string]string{
+		crdWorkloadLabel: cr.Spec.Workload,
+		crdReasonLabel:   cr.Spec.Reason,
+		crdSeverityLabel: cr.Spec.Severity,
+	} {
+		if value != "" && len(validation.IsValidLabelValue(value)) == 0 {
+			cr.Labels[key] = value
+		}
+	}
+
+	// An imported report's pod UID belongs to another cluster.
+	if s.owners && report.Crash.PodUID != "" && report.Origin == nil {
+		cr.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Name:       report.Crash.PodName,
+			UID:        types.UID(report.Crash.PodUID),
+		}}
+	}
+
+	if s.maxBody > 0 {
+		body, logsOmitted, err := s.encodeBody(report)
+		if err != nil {
+			return nil, err
+		}
+		cr.Spec.Body = body
+		cr.Spec.LogsOmitted = logsOmitted
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&cr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode crash report resource: %w", err)
+	}
+	// Triage is only written through the status subresource.
+	delete(obj, "status")
+	return &unstructured.Unstructured{Object: obj}, nil
+}
+
+// encodeBody returns the report gzip-compressed and base64 encoded, without
+// its logs if it is too large with them, or nothing if it is too large
+// either way.
+func (s *CRDStore) encodeBody(report *domain.ForensicReport) (string, bool, error) {
+	stored := *report
+	stored.Triage = nil
+
+	body, err := encodeCRDBody(&stored)
+	if err != nil || len(body) <= s.maxBody {
+		return body, false, err
+	}
+
+	if !hasLogs(&stored) {
+		return "", false, nil
+	}
+	stored.Logs, stored.PreviousLog, stored.LastWords = nil, nil, nil
+	body, err = encodeCRDBody(&stored)
+	if err != nil || len(body) > s.maxBody {
+		return "", false, err
+	}
+	return body, true, nil
+}
+
+func encodeCRDBody(report *domain.ForensicReport) (string, error) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(data); err != nil {
+		return "", fmt.Errorf("failed to compress report: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress report: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// fromObject returns the report embedded in a resource or, without one, a
+// report rebuilt from its summary. Triage always comes from the status.
+func fromObject(obj *unstructured.Unstructured) (*domain.ForensicReport, error) {
+	var cr crashReport
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &cr); err != nil {
+		return nil, fmt.Errorf("failed to decode crash report resource %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	var report *domain.ForensicReport
+	if cr.Spec.Body != "" {
+		data, err := base64.StdEncoding.DecodeString(cr.Spec.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode report body of %s/%s: %w", cr.Namespace, cr.Name, err)
+		}
+		if report, err = decodeCompressedReport(data); err != nil {
+			return nil, err
+		}
+		if cr.Spec.LogsOmitted {
+			report.AddWarning("logs were too large to store in the CrashReport resource")
+		}
+	} else {
+		report = summaryReport(cr)
+	}
+
+	report.Triage = cr.Status.Triage
+	return report, nil
+}
+
+func summaryReport(cr crashReport) *domain.ForensicReport {
+	crash := domain.PodCrash{
+		Namespace:     cr.Namespace,
+		PodName:       cr.Spec.PodName,
+		PodUID:        cr.Spec.PodUID,
+		NodeName:      cr.Spec.NodeName,
+		ContainerName: cr.Spec.Container,
+		Workload:      cr.Spec.Workload,
+		ExitCode:      cr.Spec.ExitCode,
+		Reason:        cr.Spec.Reason,
+		Signal:        cr.Spec.Signal,
+		RestartCount:  cr.Spec.RestartCount,
+	}
+
+	report := domain.NewForensicReport(crash)
+	report.ID = cr.Spec.ReportID
+	report.CollectedAt = cr.Spec.CollectedAt
+	report.Fingerprint = cr.Spec.Fingerprint
+	if cr.Spec.SourceCluster != "" {
+		report.Origin = &domain.ReportOrigin{Cluster: cr.Spec.SourceCluster}
+	}
+	if cr.Spec.Cause != "" {
+		report.Exit.Cause = cr.Spec.Cause
+	}
+	if cr.Spec.Severity != "" {
+		report.Severity = &domain.Severity{Level: cr.Spec.Severity, Score: cr.Spec.SeverityScore}
+	}
+	for _, f := range cr.Spec.Findings {
+		report.Findings = append(report.Findings, domain.Finding{Rule: f.Rule, Severity: f.Severity, Title: f.Title})
+	}
+	report.AddWarning("only the summary of this report is stored in the CrashReport resource")
+	return report
+}
@@ -0,0 +1,198 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newTestCRDStore(t *testing.T, cfg CRDConfig) (*CRDStore, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{CrashReportGVR: "CrashReportList"})
+	store, err := NewCRDStore(client, cfg)
+	if err != nil {
+		t.Fatalf("NewCRDStore() error = %v", err)
+	}
+	return store, client
+}
+
+func newCRDTestReport(ns, pod string) *domain.ForensicReport {
+	report := domain.NewForensicReport(domain.PodCrash{
+		Namespace:     ns,
+		PodName:       pod,
+		PodUID:        "uid-" + pod,
+		ContainerName: "app",
+		Reason:        "OOMKilled",
+		ExitCode:      137,
+	})
+	report.SetLogs([]string{"allocating buffer", "killed"})
+	report.Findings = []domain.Finding{{Rule: "memory-limit", Severity: "high", Title: "Container hit its memory limit"}}
+	return report
+}
+
+func TestCRDStore_SummaryOnly(t *testing.T) {
+	store, client := newTestCRDStore(t, CRDConfig{OwnerReferences: true})
+
+	report := newCRDTestReport("payments", "api-7d9f8c6b5d-x2k4q")
+	if err := store.Save(report); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	obj, err := client.Resource(CrashReportGVR).Namespace("payments").Get(context.Background(), report.ID, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("CrashReport not created: %v", err)
+	}
+	if obj.GetKind() != CrashReportKind {
+		t.Errorf("kind = %q", obj.GetKind())
+	}
+	labels := obj.GetLabels()
+	if labels[crdWorkloadLabel] != "api" || labels[crdReasonLabel] != "OOMKilled" || labels[crdReportIDLabel] != report.ID {
+		t.Errorf("labels = %v", labels)
+	}
+	owners := obj.GetOwnerReferences()
+	if len(owners) != 1 || owners[0].Kind != "Pod" || string(owners[0].UID) != "uid-api-7d9f8c6b5d-x2k4q" {
+		t.Errorf("owner references = %+v", owners)
+	}
+	if body, _, _ := unstructured.NestedString(obj.Object, "spec", "body"); body != "" {
+		t.Error("body stored without max body bytes")
+	}
+
+	loaded, err := store.Load(report.ID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Crash.Namespace != "payments" || loaded.Crash.ExitCode != 137 || len(loaded.Findings) != 1 || len(loaded.Logs) != 0 {
+		t.Errorf("Load() = %+v", loaded)
+	}
+	if !loaded.CollectedAt.Equal(report.CollectedAt) {
+		t.Errorf("CollectedAt = %v, want %v", loaded.CollectedAt, report.CollectedAt)
+	}
+
+	if _, err := store.Load("0123456789abcdef"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Load(missing) error = %v", err)
+	}
+}
+
+func TestCRDStore_BodyAndTriage(t *testing.T) {
+	store, client := newTestCRDStore(t, CRDConfig{MaxBodyBytes: 64 << 10})
+
+	report := newCRDTestReport("payments", "api-0")
+	if err := store.Save(report); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if owners, _ := client.Resource(CrashReportGVR).Namespace("payments").Get(context.Background(), report.ID, metav1.GetOptions{}); len(owners.GetOwnerReferences()) != 0 {
+		t.Error("owner references set with OwnerReferences off")
+	}
+
+	acked := domain.TriageAcknowledged
+	updated, err := store.UpdateTriage(report.ID, domain.TriageUpdate{Status: &acked}, domain.TriageActor{Name: "alice"})
+	if err != nil {
+		t.Fatalf("UpdateTriage() error = %v", err)
+	}
+	if updated.TriageStatus() != domain.TriageAcknowledged || len(updated.Logs) != 2 {
+		t.Errorf("UpdateTriage() = %+v", updated)
+	}
+
+	obj, _ := client.Resource(CrashReportGVR).Namespace("payments").Get(context.Background(), report.ID, metav1.GetOptions{})
+	if status, _, _ := unstructured.NestedString(obj.Object, "status", "triage", "status"); status != domain.TriageAcknowledged {
+		t.Errorf("status.triage.status = %q", status)
+	}
+
+	// Saving the report again keeps the triage in the status.
+	other := newCRDTestReport("checkout", "worker-1")
+	for _, r := range []*domain.ForensicReport{updated, other} {
+		if err := store.Save(r); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	reports, err := store.List()
+	if err != nil || len(reports) != 2 {
+		t.Fatalf("List() = %d reports, %v", len(reports), err)
+	}
+	loaded, _ := store.Load(report.ID)
+	if loaded.TriageStatus() != domain.TriageAcknowledged || loaded.Logs[1] != "killed" {
+		t.Errorf("Load() after triage = %+v", loaded)
+	}
+
+	scoped, _ := NewCRDStore(client, CRDConfig{Namespace: "checkout"})
+	if reports, _ := scoped.List(); len(reports) != 1 || reports[0].ID != other.ID {
+		t.Errorf("namespaced List() = %v", reports)
+	}
+}
+
+func TestCRDStore_BodyWithoutLogs(t *testing.T) {
+	store, _ := newTestCRDStore(t, CRDConfig{MaxBodyBytes: 1024})
+
+	report := newCRDTestReport("payments", "api-0")
+	logs := make([]string, 2000)
+	for i := range logs {
+		logs[i] = fmt.Sprintf("request %d failed: upstream timeout", i)
+	}
+	report.SetLogs(logs)
+	if err := store.Save(report); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := store.Load(report.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Logs) != 0 || len(loaded.Events) != 0 || loaded.Crash.ContainerName != "app" {
+		t.Errorf("Load() = %+v", loaded)
+	}
+	if len(loaded.Warnings) == 0 || !strings.Contains(loaded.Warnings[len(loaded.Warnings)-1], "logs") {
+		t.Errorf("warnings = %v", loaded.Warnings)
+	}
+}
+
+func TestCRDStore_Prune(t *testing.T) {
+	store, _ := newTestCRDStore(t, CRDConfig{})
+
+	old := newCRDTestReport("payments", "api-0")
+	old.CollectedAt = time.Now().Add(-48 * time.Hour)
+	fresh := newCRDTestReport("checkout", "api-1")
+	for _, r := range []*domain.ForensicReport{old, fresh} {
+		if err := store.Save(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := store.Prune(24 * time.Hour)
+	if err != nil || res.Deleted != 1 || res.Kept != 1 {
+		t.Fatalf("Prune() = %+v, %v", res, err)
+	}
+	if _, err := store.Load(old.ID); err == nil {
+		t.Error("expired report still stored")
+	}
+}
+
+func TestCRDStore_ImportedReport(t *testing.T) {
+	store, client := newTestCRDStore(t, CRDConfig{OwnerReferences: true})
+
+	report := newCRDTestReport("payments", "api-0")
+	report.Origin = &domain.ReportOrigin{Cluster: "prod-eu", ImportedAt: time.Now()}
+	if err := store.Save(report); err != nil {
+		t.Fatal(err)
+	}
+
+	obj, _ := client.Resource(CrashReportGVR).Namespace("payments").Get(context.Background(), report.ID, metav1.GetOptions{})
+	if len(obj.GetOwnerReferences()) != 0 {
+		t.Error("imported report owned by a pod of this cluster")
+	}
+	loaded, err := store.Load(report.ID)
+	if err != nil || loaded.SourceCluster() != "prod-eu" {
+		t.Errorf("Load() = %+v, %v", loaded, err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -15,6 +16,19 @@ import (
 )
 
 var _ Storage = (*ElasticStore)(nil)
+var _ Triager = (*ElasticStore)(nil)
+var _ Querier = (*ElasticStore)(nil)
+var _ Searcher = (*ElasticStore)(nil)
+
+const (
+	elasticPageSize     = 1000
+	elasticPITKeepAlive = "1m"
+)
+
+var elasticPeriodLayouts = map[string]string{
+	"day":   "2006.01.02",
+	"month": "2006.01",
+}
 
 type ElasticConfig struct {
 	Addresses []string
@@ -23,15 +37,37 @@ type ElasticConfig struct {
 	CloudID   string
 	APIKey    string
 	Index     string
+	// IndexPeriod writes reports into one index per "day" or "month" of
+	// collection, named <Index>-<date> and created from an index template.
+	// Empty keeps every report in the single <Index> index.
+	IndexPeriod string
+	// ILMPolicy attaches an ILM policy to the time-based indices. With
+	// ILMDeleteAfter set (e.g. "30d") the policy is created or updated to
+	// delete indices of that age; otherwise it must already exist.
+	ILMPolicy      string
+	ILMDeleteAfter string
+	Bulk           ElasticBulkConfig
 }
 
 type ElasticStore struct {
 	client    *elasticsearch.Client
 	indexName string
+	period    string
+	bulk      *elasticBulkIndexer
 	mu        sync.RWMutex
 }
 
 func NewElasticStore(cfg ElasticConfig) (*ElasticStore, error) {
+	if _, ok := elasticPeriodLayouts[cfg.IndexPeriod]; cfg.IndexPeriod != "" && !ok {
+		return nil, fmt.Errorf("invalid elasticsearch index period %q (want day or month)", cfg.IndexPeriod)
+	}
+	if cfg.ILMPolicy != "" && cfg.IndexPeriod == "" {
+		return nil, fmt.Errorf("elasticsearch ILM policy requires an index period")
+	}
+	if cfg.ILMDeleteAfter != "" && cfg.ILMPolicy == "" {
+		return nil, fmt.Errorf("elasticsearch ILM delete age requires an ILM policy name")
+	}
+
 	esCfg := elasticsearch.Config{
 		Addresses: cfg.Addresses,
 		Username:  cfg.Username,
@@ -63,15 +99,166 @@ func NewElasticStore(cfg ElasticConfig) (*ElasticStore, error) {
 	store := &ElasticStore{
 		client:    client,
 		indexName: indexName,
+		period:    cfg.IndexPeriod,
 	}
 
-	if err := store.ensureIndex(); err != nil {
-		return nil, fmt.Errorf("failed to ensure index: %w", err)
+	if store.period == "" {
+		if err := store.ensureIndex(); err != nil {
+			return nil, fmt.Errorf("failed to ensure index: %w", err)
+		}
+	} else {
+		if cfg.ILMPolicy != "" && cfg.ILMDeleteAfter != "" {
+			if err := store.putILMPolicy(cfg.ILMPolicy, cfg.ILMDeleteAfter); err != nil {
+				return nil, err
+			}
+		}
+		if err := store.putIndexTemplate(cfg.ILMPolicy); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Bulk.Enabled {
+		bulk, err := newElasticBulkIndexer(client, cfg.Bulk)
+		if err != nil {
+			return nil, err
+		}
+		store.bulk = bulk
 	}
 
 	return store, nil
 }
 
+const elasticMapping = `{
+	"properties": {
+		"schema_version": {"type": "integer"},
+		"id": {"type": "keyword"},
+		"fingerprint": {"type": "keyword"},
+		"workload": {"type": "keyword"},
+		"signature": {"type": "keyword", "ignore_above": 1024},
+		"crash": {
+			"properties": {
+				"namespace": {"type": "keyword"},
+				"pod_name": {"type": "keyword"},
+				"pod_uid": {"type": "keyword"},
+				"node_name": {"type": "keyword"},
+				"workload": {"type": "keyword"},
+				"container_name": {"type": "keyword"},
+				"exit_code": {"type": "integer"},
+				"reason": {"type": "keyword"},
+				"signal": {"type": "integer"},
+				"restart_count": {"type": "integer"},
+				"started_at": {"type": "date"},
+				"finished_at": {"type": "date"}
+			}
+		},
+		"logs": {"type": "text"},
+		"previous_log": {"type": "text"},
+		"last_words": {"type": "text"},
+		"event_messages": {"type": "text"},
+		"events": {
+			"type": "nested",
+			"properties": {
+				"type": {"type": "keyword"},
+				"reason": {"type": "keyword"},
+				"message": {"type": "text"},
+				"count": {"type": "integer"},
+				"first_seen": {"type": "date"},
+				"last_seen": {"type": "date"},
+				"source": {"type": "keyword"}
+			}
+		},
+		"env_vars": {"type": "object", "enabled": false},
+		"warnings": {"type": "text"},
+		"timeline": {
+			"properties": {
+				"started_at": {"type": "date"},
+				"finished_at": {"type": "date"},
+				"exit_code": {"type": "integer"},
+				"signal": {"type": "integer"},
+				"reason": {"type": "keyword"},
+				"restart_count": {"type": "integer"}
+			}
+		},
+		"oom_kills": {
+			"properties": {
+				"time": {"type": "date"},
+				"pid": {"type": "integer"},
+				"comm": {"type": "keyword"},
+				"cgroup_path": {"type": "keyword"},
+				"constraint": {"type": "keyword"},
+				"total_vm_kb": {"type": "long"},
+				"anon_rss_kb": {"type": "long"},
+				"file_rss_kb": {"type": "long"},
+				"shmem_rss_kb": {"type": "long"},
+				"oom_score_adj": {"type": "integer"},
+				"pod_uid": {"type": "keyword"},
+				"container_id": {"type": "keyword"}
+			}
+		},
+		"findings": {
+			"type": "nested",
+			"properties": {
+				"rule": {"type": "keyword"},
+				"severity": {"type": "keyword"},
+				"confidence": {"type": "float"},
+				"title": {"type": "text"},
+				"detail": {"type": "text"},
+				"action": {"type": "text"},
+				"evidence": {"type": "text"}
+			}
+		},
+		"severity": {
+			"properties": {
+				"level": {"type": "keyword"},
+				"score": {"type": "integer"},
+				"reasons": {"type": "text"}
+			}
+		},
+		"triage": {
+			"properties": {
+				"status": {"type": "keyword"},
+				"assignee": {"type": "keyword"},
+				"notes": {
+					"properties": {
+						"author": {"type": "keyword"},
+						"text": {"type": "text"},
+						"created_at": {"type": "date"}
+					}
+				},
+				"links": {
+					"properties": {
+						"title": {"type": "text"},
+						"url": {"type": "keyword"}
+					}
+				},
+				"history": {
+					"properties": {
+						"at": {"type": "date"},
+						"actor": {"type": "keyword"},
+						"actor_hint": {"type": "keyword"},
+						"source": {"type": "keyword"},
+						"action": {"type": "keyword"},
+						"from": {"type": "keyword"},
+						"to": {"type": "keyword"}
+					}
+				},
+				"updated_at": {"type": "date"},
+				"updated_by": {"type": "keyword"}
+			}
+		},
+		"exit": {
+			"properties": {
+				"exit_code": {"type": "integer"},
+				"signal": {"type": "integer"},
+				"signal_name": {"type": "keyword"},
+				"cause": {"type": "text"},
+				"initiator": {"type": "keyword"}
+			}
+		},
+		"collected_at": {"type": "date"}
+	}
+}`
+
 func (s *ElasticStore) ensureIndex() error {
 	res, err := s.client.Indices.Exists([]string{s.indexName})
 	if err != nil {
@@ -83,47 +270,9 @@ func (s *ElasticStore) ensureIndex() error {
 		return nil
 	}
 
-	mapping := `{
-		"mappings": {
-			"properties": {
-				"id": {"type": "keyword"},
-				"crash": {
-					"properties": {
-						"namespace": {"type": "keyword"},
-						"pod_name": {"type": "keyword"},
-						"container_name": {"type": "keyword"},
-						"exit_code": {"type": "integer"},
-						"reason": {"type": "keyword"},
-						"signal": {"type": "integer"},
-						"restart_count": {"type": "integer"},
-						"started_at": {"type": "date"},
-						"finished_at": {"type": "date"}
-					}
-				},
-				"logs": {"type": "text"},
-				"previous_log": {"type": "text"},
-				"events": {
-					"type": "nested",
-					"properties": {
-						"type": {"type": "keyword"},
-						"reason": {"type": "keyword"},
-						"message": {"type": "text"},
-						"count": {"type": "integer"},
-						"first_seen": {"type": "date"},
-						"last_seen": {"type": "date"},
-						"source": {"type": "keyword"}
-					}
-				},
-				"env_vars": {"type": "object", "enabled": false},
-				"warnings": {"type": "text"},
-				"collected_at": {"type": "date"}
-			}
-		}
-	}`
-
 	createRes, err := s.client.Indices.Create(
 		s.indexName,
-		s.client.Indices.Create.WithBody(strings.NewReader(mapping)),
+		s.client.Indices.Create.WithBody(strings.NewReader(`{"mappings": `+elasticMapping+`}`)),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create index: %w", err)
@@ -137,10 +286,88 @@ func (s *ElasticStore) ensureIndex() error {
 	return nil
 }
 
-func (s *ElasticStore) Save(report *domain.ForensicReport) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *ElasticStore) putILMPolicy(name, deleteAfter string) error {
+	body, err := json.Marshal(map[string]any{
+		"policy": map[string]any{
+			"phases": map[string]any{
+				"hot":    map[string]any{"min_age": "0ms", "actions": map[string]any{}},
+				"delete": map[string]any{"min_age": deleteAfter, "actions": map[string]any{"delete": map[string]any{}}},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build ILM policy: %w", err)
+	}
 
+	res, err := s.client.ILM.PutLifecycle(name, s.client.ILM.PutLifecycle.WithBody(bytes.NewReader(body)))
+	if err != nil {
+		return fmt.Errorf("failed to put ILM policy: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to put ILM policy: %s", res.Status())
+	}
+
+	return nil
+}
+
+// putIndexTemplate makes every <index>-* index pick up the report mapping
+// and, when given, the ILM policy. Daily indices carry their date in the
+// name, so ILM ages them from the collection date rather than creation.
+func (s *ElasticStore) putIndexTemplate(policy string) error {
+	settings := map[string]any{}
+	if policy != "" {
+		settings["index.lifecycle.name"] = policy
+		if s.period == "day" {
+			settings["index.lifecycle.parse_origination_date"] = true
+		}
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"index_patterns": []string{s.indexName + "-*"},
+		"priority":       100,
+		"template": map[string]any{
+			"settings": settings,
+			"mappings": json.RawMessage(elasticMapping),
+		},
+		"_meta": map[string]any{"managed_by": "kubecrsh"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build index template: %w", err)
+	}
+
+	res, err := s.client.Indices.PutIndexTemplate(s.indexName, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to put index template: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to put index template: %s", res.Status())
+	}
+
+	return nil
+}
+
+// writeIndex is the index a report is stored in.
+func (s *ElasticStore) writeIndex(report *domain.ForensicReport) string {
+	if s.period == "" {
+		return s.indexName
+	}
+	return s.indexName + "-" + report.CollectedAt.UTC().Format(elasticPeriodLayouts[s.period])
+}
+
+// readIndices covers every index reports may live in, including the single
+// index written before time-based indices were enabled.
+func (s *ElasticStore) readIndices() []string {
+	if s.period == "" {
+		return []string{s.indexName}
+	}
+	return []string{s.indexName, s.indexName + "-*"}
+}
+
+func (s *ElasticStore) Save(report *domain.ForensicReport) error {
 	doc := s.toDocument(report)
 
 	data, err := json.Marshal(doc)
@@ -148,8 +375,15 @@ func (s *ElasticStore) Save(report *domain.ForensicReport) error {
 		return fmt.Errorf("failed to marshal report: %w", err)
 	}
 
+	if s.bulk != nil {
+		return s.bulk.add(elasticBulkItem{Index: s.writeIndex(report), ID: report.ID, Doc: data})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	req := esapi.IndexRequest{
-		Index:      s.indexName,
+		Index:      s.writeIndex(report),
 		DocumentID: report.ID,
 		Body:       bytes.NewReader(data),
 		Refresh:    "false",
@@ -171,14 +405,207 @@ func (s *ElasticStore) Save(report *domain.ForensicReport) error {
 	return nil
 }
 
+// Flush indexes every report buffered by the bulk indexer.
+func (s *ElasticStore) Flush(ctx context.Context) error {
+	if s.bulk == nil {
+		return nil
+	}
+	return s.bulk.flush(ctx, true)
+}
+
+// onDropped registers fn to be called with the IDs of reports that Save
+// accepted but the bulk indexer could not index.
+func (s *ElasticStore) onDropped(fn func(ids []string)) {
+	if s.bulk != nil {
+		s.bulk.setDropHandler(fn)
+	}
+}
+
+// Close flushes the bulk indexer and stops it. Reports that still cannot be
+// indexed are spooled to disk when a spool directory is configured.
+func (s *ElasticStore) Close() error {
+	if s.bulk == nil {
+		return nil
+	}
+	return s.bulk.close()
+}
+
 func (s *ElasticStore) Load(id string) (*domain.ForensicReport, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	doc, err := s.locate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.fromDocument(&doc.Source), nil
+}
+
+// List returns every report, newest first, paging through a point in time
+// so that reports indexed meanwhile do not shift the pages. Clusters that
+// cannot open one fall back to plain search_after.
+func (s *ElasticStore) List() ([]*domain.ForensicReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	pit := s.openPIT(ctx)
+	defer func() { s.closePIT(pit) }()
+
+	var reports []*domain.ForensicReport
+	var after []json.RawMessage
+	for {
+		body := map[string]any{
+			"query":            map[string]any{"match_all": map[string]any{}},
+			"size":             elasticPageSize,
+			"sort":             []any{map[string]any{"collected_at": "desc"}, map[string]any{"id": "desc"}},
+			"track_total_hits": false,
+		}
+		if pit != "" {
+			body["pit"] = map[string]any{"id": pit, "keep_alive": elasticPITKeepAlive}
+		}
+		if after != nil {
+			body["search_after"] = after
+		}
+
+		page, err := s.search(ctx, body, pit == "")
+		if err != nil {
+			return nil, err
+		}
+		if page.PitID != "" {
+			pit = page.PitID
+		}
+
+		for _, hit := range page.Hits.Hits {
+			reports = append(reports, s.fromDocument(&hit.Source))
+		}
+		if len(page.Hits.Hits) < elasticPageSize {
+			return reports, nil
+		}
+		after = page.Hits.Hits[len(page.Hits.Hits)-1].Sort
+	}
+}
+
+type elasticHit struct {
+	Index       string              `json:"_index"`
+	SeqNo       int                 `json:"_seq_no"`
+	PrimaryTerm int                 `json:"_primary_term"`
+	Sort        []json.RawMessage   `json:"sort"`
+	Source      elasticDocument     `json:"_source"`
+	Highlight   map[string][]string `json:"highlight"`
+}
+
+type elasticSearchResult struct {
+	PitID string `json:"pit_id"`
+	Hits  struct {
+		Total struct {
+			Value int `json:"value"`
+		} `json:"total"`
+		Hits []elasticHit `json:"hits"`
+	} `json:"hits"`
+}
+
+// search runs a search body against the report indices, or against the
+// point in time named in the body when withIndex is false.
+func (s *ElasticStore) search(ctx context.Context, body map[string]any, withIndex bool) (*elasticSearchResult, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	opts := []func(*esapi.SearchRequest){
+		s.client.Search.WithContext(ctx),
+		s.client.Search.WithBody(bytes.NewReader(data)),
+	}
+	if withIndex {
+		opts = append(opts,
+			s.client.Search.WithIndex(s.readIndices()...),
+			s.client.Search.WithIgnoreUnavailable(true),
+			s.client.Search.WithAllowNoIndices(true),
+		)
+	}
+
+	res, err := s.client.Search(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search reports: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("search failed: %s", res.Status())
+	}
+
+	var result elasticSearchResult
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (s *ElasticStore) openPIT(ctx context.Context) string {
+	res, err := s.client.OpenPointInTime(s.readIndices(), elasticPITKeepAlive,
+		s.client.OpenPointInTime.WithContext(ctx),
+		s.client.OpenPointInTime.WithIgnoreUnavailable(true),
+	)
+	if err != nil {
+		return ""
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return ""
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return ""
+	}
+	return result.ID
+}
+
+func (s *ElasticStore) closePIT(id string) {
+	if id == "" {
+		return
+	}
+
+	body, _ := json.Marshal(map[string]string{"id": id})
+	res, err := s.client.ClosePointInTime(s.client.ClosePointInTime.WithBody(bytes.NewReader(body)))
+	if err == nil {
+		res.Body.Close()
+	}
+}
+
+// locate finds a report along with the index holding it and its version.
+// With time-based indices the ID alone does not say which index to read, so
+// the index is searched for first; the realtime get that follows sees
+// writes the search may not have caught up with yet.
+func (s *ElasticStore) locate(id string) (*elasticHit, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	res, err := s.client.Get(s.indexName, id, s.client.Get.WithContext(ctx))
+	index := s.indexName
+	if s.period != "" {
+		result, err := s.search(ctx, map[string]any{
+			"query":   map[string]any{"ids": map[string]any{"values": []string{id}}},
+			"size":    1,
+			"_source": false,
+		}, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get report: %w", err)
+		}
+		if len(result.Hits.Hits) == 0 {
+			return nil, fmt.Errorf("report not found: %s", id)
+		}
+		index = result.Hits.Hits[0].Index
+	}
+
+	res, err := s.client.Get(index, id, s.client.Get.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get report: %w", err)
 	}
@@ -187,84 +614,505 @@ func (s *ElasticStore) Load(id string) (*domain.ForensicReport, error) {
 	if res.StatusCode == 404 {
 		return nil, fmt.Errorf("report not found: %s", id)
 	}
-
 	if res.IsError() {
 		return nil, fmt.Errorf("failed to get report: %s", res.Status())
 	}
 
-	var result struct {
-		Source elasticDocument `json:"_source"`
+	var hit elasticHit
+	if err := json.NewDecoder(res.Body).Decode(&hit); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	return &hit, nil
+}
+
+func (s *ElasticStore) Query(q Query) (QueryResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := s.search(ctx, elasticSearchBody(q), true)
+	if err != nil {
+		return QueryResult{}, err
+	}
+
+	out := QueryResult{Total: result.Hits.Total.Value}
+	for _, hit := range result.Hits.Hits {
+		out.Reports = append(out.Reports, s.fromDocument(&hit.Source))
 	}
 
-	return s.fromDocument(&result.Source), nil
+	if len(out.Reports) > q.limit() {
+		out.Reports = out.Reports[:q.limit()]
+		last := out.Reports[len(out.Reports)-1]
+		out.NextCursor = EncodeCursor(last.CollectedAt, last.ID)
+	}
+
+	return out, nil
 }
 
-func (s *ElasticStore) List() ([]*domain.ForensicReport, error) {
+// Search runs the text as a query_string over the text fields, within the
+// filters of the query.
+func (s *ElasticStore) Search(q SearchQuery) (SearchResult, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	query := `{
-		"query": {"match_all": {}},
-		"size": 1000,
-		"sort": [{"collected_at": {"order": "desc"}}]
-	}`
+	body := elasticSearchBody(q.Query)
+	query := body["query"].(map[string]any)["bool"].(map[string]any)
+	query["must"] = map[string]any{"query_string": map[string]any{
+		"query":            q.Text,
+		"fields":           searchFields,
+		"default_operator": "AND",
+	}}
+
+	highlight := map[string]any{}
+	for _, field := range searchFields {
+		highlight[field] = map[string]any{}
+	}
+	body["highlight"] = map[string]any{
+		"fields":              highlight,
+		"pre_tags":            []string{""},
+		"post_tags":           []string{""},
+		"number_of_fragments": maxSearchHighlights,
+		"fragment_size":       maxSearchHighlightSize,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := s.search(ctx, body, true)
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	out := SearchResult{Total: result.Hits.Total.Value}
+	for _, hit := range result.Hits.Hits {
+		var lines []string
+		for _, field := range searchFields {
+			for _, fragment := range hit.Highlight[field] {
+				if len(lines) < maxSearchHighlights {
+					lines = append(lines, truncateHighlight(fragment))
+				}
+			}
+		}
+		out.Hits = append(out.Hits, SearchHit{Report: s.fromDocument(&hit.Source), Highlights: lines})
+	}
+
+	if len(out.Hits) > q.limit() {
+		out.Hits = out.Hits[:q.limit()]
+		last := out.Hits[len(out.Hits)-1].Report
+		out.NextCursor = EncodeCursor(last.CollectedAt, last.ID)
+	}
+
+	return out, nil
+}
+
+func elasticSearchBody(q Query) map[string]any {
+	filters := []any{}
+	term := func(field string, value any) {
+		filters = append(filters, map[string]any{"term": map[string]any{field: value}})
+	}
+
+	if q.Namespace != "" {
+		term("crash.namespace", q.Namespace)
+	}
+	if q.Workload != "" {
+		term("workload", q.Workload)
+	}
+	if q.PodName != "" {
+		term("crash.pod_name", q.PodName)
+	}
+	if q.Reason != "" {
+		term("crash.reason", q.Reason)
+	}
+	if q.ExitCode != nil {
+		term("crash.exit_code", *q.ExitCode)
+	}
+	if q.Fingerprint != "" {
+		term("fingerprint", q.Fingerprint)
+	}
+	switch q.Status {
+	case "":
+	case domain.TriageNew:
+		// Reports that were never triaged have no triage object at all.
+		filters = append(filters, map[string]any{"bool": map[string]any{
+			"should": []any{
+				map[string]any{"term": map[string]any{"triage.status": domain.TriageNew}},
+				map[string]any{"bool": map[string]any{"must_not": map[string]any{"exists": map[string]any{"field": "triage.status"}}}},
+			},
+			"minimum_should_match": 1,
+		}})
+	default:
+		term("triage.status", q.Status)
+	}
+	if levels := q.severityLevels(); levels != nil {
+		filters = append(filters, map[string]any{"terms": map[string]any{"severity.level": levels}})
+	}
+	if !q.Since.IsZero() || !q.Until.IsZero() {
+		r := map[string]any{}
+		if !q.Since.IsZero() {
+			r["gte"] = q.Since.UTC().Format(time.RFC3339Nano)
+		}
+		if !q.Until.IsZero() {
+			r["lt"] = q.Until.UTC().Format(time.RFC3339Nano)
+		}
+		filters = append(filters, map[string]any{"range": map[string]any{"collected_at": r}})
+	}
+
+	order := "desc"
+	if q.Sort == SortOldest {
+		order = "asc"
+	}
+
+	body := map[string]any{
+		"query":            map[string]any{"bool": map[string]any{"filter": filters}},
+		"size":             q.limit() + 1,
+		"sort":             []any{map[string]any{"collected_at": order}, map[string]any{"id": order}},
+		"track_total_hits": true,
+	}
+
+	if at, id, err := decodeCursor(q.Cursor); err == nil && id != "" {
+		body["search_after"] = []any{at.UnixMilli(), id}
+	} else if q.Offset > 0 {
+		body["from"] = q.Offset
+	}
+
+	return body
+}
+
+// elasticGroupsPageSize is the number of groups fetched per composite
+// aggregation page.
+const elasticGroupsPageSize = 500
+
+// Groups pages through every fingerprint with a composite aggregation, so
+// no group is cut off, and sorts them most recently seen first as the other
+// stores do.
+func (s *ElasticStore) Groups() ([]domain.CrashGroup, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var groups []domain.CrashGroup
+	var after map[string]any
+	for {
+		page, next, err := s.groupsPage(after)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, page...)
+		if len(page) == 0 || next == nil {
+			break
+		}
+		after = next
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].LastSeen.After(groups[j].LastSeen)
+	})
+	return groups, nil
+}
+
+func (s *ElasticStore) groupsPage(after map[string]any) ([]domain.CrashGroup, map[string]any, error) {
+	composite := map[string]any{
+		"size":    elasticGroupsPageSize,
+		"sources": []any{map[string]any{"fingerprint": map[string]any{"terms": map[string]any{"field": "fingerprint"}}}},
+	}
+	if after != nil {
+		composite["after"] = after
+	}
+	query, err := json.Marshal(map[string]any{
+		"size": 0,
+		"aggs": map[string]any{
+			"groups": map[string]any{
+				"composite": composite,
+				"aggs": map[string]any{
+					"first_seen": map[string]any{"min": map[string]any{"field": "collected_at"}},
+					"last_seen":  map[string]any{"max": map[string]any{"field": "collected_at"}},
+					"latest": map[string]any{
+						"top_hits": map[string]any{
+							"size":    1,
+							"sort":    []any{map[string]any{"collected_at": map[string]any{"order": "desc"}}},
+							"_source": map[string]any{"includes": []string{"id", "fingerprint", "signature", "crash"}},
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	res, err := s.client.Search(
 		s.client.Search.WithContext(ctx),
-		s.client.Search.WithIndex(s.indexName),
-		s.client.Search.WithBody(strings.NewReader(query)),
+		s.client.Search.WithIndex(s.readIndices()...),
+		s.client.Search.WithIgnoreUnavailable(true),
+		s.client.Search.WithAllowNoIndices(true),
+		s.client.Search.WithBody(bytes.NewReader(query)),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search reports: %w", err)
+		return nil, nil, fmt.Errorf("failed to aggregate reports: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return nil, fmt.Errorf("search failed: %s", res.Status())
+		return nil, nil, fmt.Errorf("aggregation failed: %s", res.Status())
 	}
 
 	var result struct {
-		Hits struct {
-			Hits []struct {
-				Source elasticDocument `json:"_source"`
-			} `json:"hits"`
-		} `json:"hits"`
+		Aggregations struct {
+			Groups struct {
+				AfterKey map[string]any `json:"after_key"`
+				Buckets  []struct {
+					Key struct {
+						Fingerprint string `json:"fingerprint"`
+					} `json:"key"`
+					DocCount  int `json:"doc_count"`
+					FirstSeen struct {
+						Value float64 `json:"value"`
+					} `json:"first_seen"`
+					LastSeen struct {
+						Value float64 `json:"value"`
+					} `json:"last_seen"`
+					Latest struct {
+						Hits struct {
+							Hits []struct {
+								Source elasticDocument `json:"_source"`
+							} `json:"hits"`
+						} `json:"hits"`
+					} `json:"latest"`
+				} `json:"buckets"`
+			} `json:"groups"`
+		} `json:"aggregations"`
 	}
 
 	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	groups := make([]domain.CrashGroup, 0, len(result.Aggregations.Groups.Buckets))
+	for _, b := range result.Aggregations.Groups.Buckets {
+		g := domain.CrashGroup{
+			Fingerprint: b.Key.Fingerprint,
+			Count:       b.DocCount,
+			FirstSeen:   time.UnixMilli(int64(b.FirstSeen.Value)).UTC(),
+			LastSeen:    time.UnixMilli(int64(b.LastSeen.Value)).UTC(),
+		}
+
+		if hits := b.Latest.Hits.Hits; len(hits) > 0 {
+			latest := s.fromDocument(&hits[0].Source)
+			g.LatestReportID = latest.ID
+			g.Signature = latest.Signature
+			g.Namespace = latest.Crash.Namespace
+			g.Workload = latest.Crash.WorkloadName()
+			g.Container = latest.Crash.ContainerName
+			g.Reason = latest.Crash.Reason
+			g.ExitCode = latest.Crash.ExitCode
+		}
+
+		groups = append(groups, g)
+	}
+
+	return groups, result.Aggregations.Groups.AfterKey, nil
+}
+
+// Prune deletes reports collected before the retention window. Time-based
+// indices that lie entirely before the cutoff are dropped whole; the rest,
+// including the single legacy index, are pruned with a delete-by-query.
+func (s *ElasticStore) Prune(retention time.Duration) (PruneResult, error) {
+	var res PruneResult
+	if retention <= 0 {
+		return res, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	cutoff := time.Now().Add(-retention)
+
+	if s.period != "" {
+		indices, err := s.periodIndices(ctx)
+		if err != nil {
+			return res, err
+		}
+
+		for _, index := range indices {
+			start, err := time.Parse(elasticPeriodLayouts[s.period], strings.TrimPrefix(index, s.indexName+"-"))
+			if err != nil {
+				continue
+			}
+			end := start.AddDate(0, 1, 0)
+			if s.period == "day" {
+				end = start.AddDate(0, 0, 1)
+			}
+			if end.After(cutoff) {
+				continue
+			}
+
+			count, err := s.count(ctx, []string{index})
+			if err != nil {
+				return res, err
+			}
+			if err := s.deleteIndex(ctx, index); err != nil {
+				return res, err
+			}
+			res.Deleted += count
+		}
+	}
+
+	expired := map[string]any{"range": map[string]any{"collected_at": map[string]any{"lt": cutoff.UTC().Format(time.RFC3339Nano)}}}
+	body, err := json.Marshal(map[string]any{"query": expired})
+	if err != nil {
+		return res, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	dbq, err := s.client.DeleteByQuery(s.readIndices(), bytes.NewReader(body),
+		s.client.DeleteByQuery.WithContext(ctx),
+		s.client.DeleteByQuery.WithConflicts("proceed"),
+		s.client.DeleteByQuery.WithRefresh(true),
+		s.client.DeleteByQuery.WithIgnoreUnavailable(true),
+		s.client.DeleteByQuery.WithAllowNoIndices(true),
+	)
+	if err != nil {
+		return res, fmt.Errorf("failed to delete reports: %w", err)
+	}
+	defer dbq.Body.Close()
+
+	if dbq.IsError() {
+		return res, fmt.Errorf("failed to delete reports: %s", dbq.Status())
+	}
+
+	var deleted struct {
+		Deleted  int   `json:"deleted"`
+		Failures []any `json:"failures"`
+	}
+	if err := json.NewDecoder(dbq.Body).Decode(&deleted); err != nil {
+		return res, fmt.Errorf("failed to decode response: %w", err)
+	}
+	res.Deleted += deleted.Deleted
+	res.Failed += len(deleted.Failures)
+
+	kept, err := s.count(ctx, s.readIndices())
+	if err != nil {
+		return res, err
+	}
+	res.Kept = kept
+
+	if res.Failed > 0 {
+		return res, fmt.Errorf("failed to delete %d reports", res.Failed)
+	}
+	return res, nil
+}
+
+func (s *ElasticStore) periodIndices(ctx context.Context) ([]string, error) {
+	res, err := s.client.Cat.Indices(
+		s.client.Cat.Indices.WithContext(ctx),
+		s.client.Cat.Indices.WithIndex(s.indexName+"-*"),
+		s.client.Cat.Indices.WithFormat("json"),
+		s.client.Cat.Indices.WithH("index"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indices: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return nil, nil
+	}
+	if res.IsError() {
+		return nil, fmt.Errorf("failed to list indices: %s", res.Status())
+	}
+
+	var rows []struct {
+		Index string `json:"index"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&rows); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	reports := make([]*domain.ForensicReport, 0, len(result.Hits.Hits))
-	for _, hit := range result.Hits.Hits {
-		reports = append(reports, s.fromDocument(&hit.Source))
+	indices := make([]string, 0, len(rows))
+	for _, row := range rows {
+		indices = append(indices, row.Index)
 	}
+	return indices, nil
+}
 
-	return reports, nil
+func (s *ElasticStore) count(ctx context.Context, indices []string) (int, error) {
+	res, err := s.client.Count(
+		s.client.Count.WithContext(ctx),
+		s.client.Count.WithIndex(indices...),
+		s.client.Count.WithIgnoreUnavailable(true),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count reports: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return 0, fmt.Errorf("failed to count reports: %s", res.Status())
+	}
+
+	var result struct {
+		Count int `json:"count"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result.Count, nil
+}
+
+func (s *ElasticStore) deleteIndex(ctx context.Context, index string) error {
+	res, err := s.client.Indices.Delete([]string{index}, s.client.Indices.Delete.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to delete index %s: %w", index, err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() && res.StatusCode != 404 {
+		return fmt.Errorf("failed to delete index %s: %s", index, res.Status())
+	}
+	return nil
 }
 
 type elasticDocument struct {
-	ID          string            `json:"id"`
-	Crash       elasticCrash      `json:"crash"`
-	Logs        []string          `json:"logs"`
-	PreviousLog []string          `json:"previous_log"`
-	Events      []elasticEvent    `json:"events"`
-	EnvVars     map[string]string `json:"env_vars"`
-	Warnings    []string          `json:"warnings"`
-	CollectedAt time.Time         `json:"collected_at"`
+	SchemaVersion int              `json:"schema_version"`
+	ID            string           `json:"id"`
+	Fingerprint   string           `json:"fingerprint,omitempty"`
+	Workload      string           `json:"workload,omitempty"`
+	Signature     string           `json:"signature,omitempty"`
+	Crash         elasticCrash     `json:"crash"`
+	Exit          *elasticExit     `json:"exit,omitempty"`
+	Findings      []elasticFinding `json:"findings,omitempty"`
+	Severity      *elasticSeverity `json:"severity,omitempty"`
+	Triage        *elasticTriage   `json:"triage,omitempty"`
+	Logs          []string         `json:"logs"`
+	PreviousLog   []string         `json:"previous_log"`
+	LastWords     []string         `json:"last_words,omitempty"`
+	Events        []elasticEvent   `json:"events"`
+	// EventText copies the event messages out of the nested events, so a
+	// query_string over the text fields can match them too.
+	EventText   []string             `json:"event_messages,omitempty"`
+	EnvVars     map[string]string    `json:"env_vars"`
+	Warnings    []string             `json:"warnings"`
+	Timeline    []elasticTermination `json:"timeline,omitempty"`
+	OOMKills    []elasticOOMKill     `json:"oom_kills,omitempty"`
+	CollectedAt time.Time            `json:"collected_at"`
 }
 
 type elasticCrash struct {
 	Namespace     string    `json:"namespace"`
 	PodName       string    `json:"pod_name"`
+	PodUID        string    `json:"pod_uid,omitempty"`
+	NodeName      string    `json:"node_name,omitempty"`
+	Workload      string    `json:"workload,omitempty"`
 	ContainerName string    `json:"container_name"`
+	ContainerID   string    `json:"container_id,omitempty"`
 	ExitCode      int32     `json:"exit_code"`
 	Reason        string    `json:"reason"`
 	Signal        int32     `json:"signal"`
@@ -273,6 +1121,85 @@ type elasticCrash struct {
 	FinishedAt    time.Time `json:"finished_at"`
 }
 
+type elasticTermination struct {
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at"`
+	ExitCode     int32     `json:"exit_code"`
+	Signal       int32     `json:"signal"`
+	Reason       string    `json:"reason"`
+	RestartCount int32     `json:"restart_count"`
+}
+
+type elasticExit struct {
+	ExitCode   int32  `json:"exit_code"`
+	Signal     int32  `json:"signal"`
+	SignalName string `json:"signal_name,omitempty"`
+	Cause      string `json:"cause"`
+	Initiator  string `json:"initiator"`
+}
+
+type elasticFinding struct {
+	Rule       string   `json:"rule"`
+	Severity   string   `json:"severity"`
+	Confidence float64  `json:"confidence"`
+	Title      string   `json:"title"`
+	Detail     string   `json:"detail,omitempty"`
+	Action     string   `json:"action,omitempty"`
+	Evidence   []string `json:"evidence,omitempty"`
+}
+
+type elasticSeverity struct {
+	Level   string   `json:"level"`
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+type elasticTriage struct {
+	Status    string               `json:"status"`
+	Assignee  string               `json:"assignee,omitempty"`
+	Notes     []elasticTriageNote  `json:"notes,omitempty"`
+	Links     []elasticTriageLink  `json:"links,omitempty"`
+	History   []elasticTriageEvent `json:"history,omitempty"`
+	UpdatedAt time.Time            `json:"updated_at"`
+	UpdatedBy string               `json:"updated_by,omitempty"`
+}
+
+type elasticTriageNote struct {
+	Author    string    `json:"author,omitempty"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type elasticTriageLink struct {
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url"`
+}
+
+type elasticTriageEvent struct {
+	At        time.Time `json:"at"`
+	Actor     string    `json:"actor,omitempty"`
+	ActorHint string    `json:"actor_hint,omitempty"`
+	Source    string    `json:"source,omitempty"`
+	Action    string    `json:"action"`
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to,omitempty"`
+}
+
+type elasticOOMKill struct {
+	Time        time.Time `json:"time"`
+	PID         int       `json:"pid"`
+	Comm        string    `json:"comm"`
+	CgroupPath  string    `json:"cgroup_path,omitempty"`
+	Constraint  string    `json:"constraint,omitempty"`
+	TotalVMKB   int64     `json:"total_vm_kb"`
+	AnonRSSKB   int64     `json:"anon_rss_kb"`
+	FileRSSKB   int64     `json:"file_rss_kb"`
+	ShmemRSSKB  int64     `json:"shmem_rss_kb"`
+	OOMScoreAdj int       `json:"oom_score_adj"`
+	PodUID      string    `json:"pod_uid,omitempty"`
+	ContainerID string    `json:"container_id,omitempty"`
+}
+
 type elasticEvent struct {
 	Type      string    `json:"type"`
 	Reason    string    `json:"reason"`
@@ -297,12 +1224,48 @@ func (s *ElasticStore) toDocument(report *domain.ForensicReport) *elasticDocumen
 		})
 	}
 
+	timeline := make([]elasticTermination, 0, len(report.Timeline))
+	for _, t := range report.Timeline {
+		timeline = append(timeline, elasticTermination{
+			StartedAt:    t.StartedAt,
+			FinishedAt:   t.FinishedAt,
+			ExitCode:     t.ExitCode,
+			Signal:       t.Signal,
+			Reason:       t.Reason,
+			RestartCount: t.RestartCount,
+		})
+	}
+
+	oomKills := make([]elasticOOMKill, 0, len(report.OOMKills))
+	for _, k := range report.OOMKills {
+		oomKills = append(oomKills, elasticOOMKill(k))
+	}
+
+	findings := make([]elasticFinding, 0, len(report.Findings))
+	for _, f := range report.Findings {
+		findings = append(findings, elasticFinding(f))
+	}
+
+	exit := elasticExit(report.ExitInfo())
+
 	return &elasticDocument{
-		ID: report.ID,
+		SchemaVersion: domain.SchemaVersion,
+		ID:            report.ID,
+		Fingerprint:   report.Fingerprint,
+		Workload:      report.Crash.WorkloadName(),
+		Signature:     report.Signature,
+		Exit:          &exit,
+		Findings:      findings,
+		Severity:      (*elasticSeverity)(report.Severity),
+		Triage:        toElasticTriage(report.Triage),
 		Crash: elasticCrash{
 			Namespace:     report.Crash.Namespace,
 			PodName:       report.Crash.PodName,
+			PodUID:        report.Crash.PodUID,
+			NodeName:      report.Crash.NodeName,
+			Workload:      report.Crash.Workload,
 			ContainerName: report.Crash.ContainerName,
+			ContainerID:   report.Crash.ContainerID,
 			ExitCode:      report.Crash.ExitCode,
 			Reason:        report.Crash.Reason,
 			Signal:        report.Crash.Signal,
@@ -312,9 +1275,13 @@ func (s *ElasticStore) toDocument(report *domain.ForensicReport) *elasticDocumen
 		},
 		Logs:        report.Logs,
 		PreviousLog: report.PreviousLog,
+		LastWords:   report.LastWords,
 		Events:      events,
+		EventText:   eventMessages(report.Events),
 		EnvVars:     report.EnvVars,
 		Warnings:    report.Warnings,
+		Timeline:    timeline,
+		OOMKills:    oomKills,
 		CollectedAt: report.CollectedAt,
 	}
 }
@@ -333,12 +1300,50 @@ func (s *ElasticStore) fromDocument(doc *elasticDocument) *domain.ForensicReport
 		})
 	}
 
+	timeline := make([]domain.Termination, 0, len(doc.Timeline))
+	for _, t := range doc.Timeline {
+		timeline = append(timeline, domain.Termination{
+			StartedAt:    t.StartedAt,
+			FinishedAt:   t.FinishedAt,
+			ExitCode:     t.ExitCode,
+			Signal:       t.Signal,
+			Reason:       t.Reason,
+			RestartCount: t.RestartCount,
+		})
+	}
+
+	oomKills := make([]domain.OOMKill, 0, len(doc.OOMKills))
+	for _, k := range doc.OOMKills {
+		oomKills = append(oomKills, domain.OOMKill(k))
+	}
+
+	var findings []domain.Finding
+	for _, f := range doc.Findings {
+		findings = append(findings, domain.Finding(f))
+	}
+
+	var exit domain.ExitInterpretation
+	if doc.Exit != nil {
+		exit = domain.ExitInterpretation(*doc.Exit)
+	}
+
 	return &domain.ForensicReport{
-		ID: doc.ID,
+		SchemaVersion: domain.SchemaVersion,
+		ID:            doc.ID,
+		Fingerprint:   doc.Fingerprint,
+		Signature:     doc.Signature,
+		Exit:          exit,
+		Findings:      findings,
+		Severity:      (*domain.Severity)(doc.Severity),
+		Triage:        fromElasticTriage(doc.Triage),
 		Crash: domain.PodCrash{
 			Namespace:     doc.Crash.Namespace,
 			PodName:       doc.Crash.PodName,
+			PodUID:        doc.Crash.PodUID,
+			NodeName:      doc.Crash.NodeName,
+			Workload:      doc.Crash.Workload,
 			ContainerName: doc.Crash.ContainerName,
+			ContainerID:   doc.Crash.ContainerID,
 			ExitCode:      doc.Crash.ExitCode,
 			Reason:        doc.Crash.Reason,
 			Signal:        doc.Crash.Signal,
@@ -348,9 +1353,112 @@ func (s *ElasticStore) fromDocument(doc *elasticDocument) *domain.ForensicReport
 		},
 		Logs:        doc.Logs,
 		PreviousLog: doc.PreviousLog,
+		LastWords:   doc.LastWords,
 		Events:      events,
 		EnvVars:     doc.EnvVars,
 		Warnings:    doc.Warnings,
+		Timeline:    timeline,
+		OOMKills:    oomKills,
 		CollectedAt: doc.CollectedAt,
 	}
 }
+
+func toElasticTriage(t *domain.Triage) *elasticTriage {
+	if t == nil {
+		return nil
+	}
+
+	doc := &elasticTriage{
+		Status:    t.Status,
+		Assignee:  t.Assignee,
+		UpdatedAt: t.UpdatedAt,
+		UpdatedBy: t.UpdatedBy,
+	}
+	for _, n := range t.Notes {
+		doc.Notes = append(doc.Notes, elasticTriageNote(n))
+	}
+	for _, l := range t.Links {
+		doc.Links = append(doc.Links, elasticTriageLink(l))
+	}
+	for _, e := range t.History {
+		doc.History = append(doc.History, elasticTriageEvent(e))
+	}
+	return doc
+}
+
+func fromElasticTriage(doc *elasticTriage) *domain.Triage {
+	if doc == nil {
+		return nil
+	}
+
+	t := &domain.Triage{
+		Status:    doc.Status,
+		Assignee:  doc.Assignee,
+		UpdatedAt: doc.UpdatedAt,
+		UpdatedBy: doc.UpdatedBy,
+	}
+	for _, n := range doc.Notes {
+		t.Notes = append(t.Notes, domain.TriageNote(n))
+	}
+	for _, l := range doc.Links {
+		t.Links = append(t.Links, domain.TriageLink(l))
+	}
+	for _, e := range doc.History {
+		t.History = append(t.History, domain.TriageEvent(e))
+	}
+	return t
+}
+
+func (s *ElasticStore) UpdateTriage(id string, update domain.TriageUpdate, actor domain.TriageActor) (*domain.ForensicReport, error) {
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for attempt := 0; attempt < 3; attempt++ {
+		hit, err := s.locate(id)
+		if err != nil {
+			return nil, err
+		}
+		report := s.fromDocument(&hit.Source)
+
+		if err := report.ApplyTriage(update, actor, time.Now()); err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(s.toDocument(report))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal report: %w", err)
+		}
+
+		req := esapi.IndexRequest{
+			Index:         hit.Index,
+			DocumentID:    id,
+			Body:          bytes.NewReader(data),
+			IfSeqNo:       &hit.SeqNo,
+			IfPrimaryTerm: &hit.PrimaryTerm,
+			Refresh:       "false",
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		res, err := req.Do(ctx, s.client)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to update triage: %w", err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode == 409 {
+			continue
+		}
+		if res.IsError() {
+			return nil, fmt.Errorf("failed to update triage: %s", res.Status())
+		}
+
+		return report, nil
+	}
+
+	return nil, fmt.Errorf("failed to update triage: report %s was modified concurrently", id)
+}
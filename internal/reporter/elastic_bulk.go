@@ -0,0 +1,785 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	DefaultElasticBulkActions       = 500
+	DefaultElasticBulkFlushInterval = 5 * time.Second
+	DefaultElasticBulkMaxBuffered   = 10000
+	DefaultElasticBulkMaxRetries    = 5
+
+	elasticBulkBackoff      = 500 * time.Millisecond
+	elasticBulkMaxBackoff   = 30 * time.Second
+	elasticBulkCloseTimeout = 30 * time.Second
+	elasticSpoolBase        = uint64(1) << 32
+)
+
+var errElasticBulkClosed = errors.New("elasticsearch bulk indexer is closed")
+
+// ElasticBulkConfig makes Save queue reports and index them in batches
+// through the _bulk API instead of one request per report.
+type ElasticBulkConfig struct {
+	Enabled bool
+	// Actions is the number of documents sent per _bulk request. A flush
+	// starts as soon as that many are buffered.
+	Actions       int
+	FlushInterval time.Duration
+	// MaxBuffered caps the documents held in memory. Beyond it documents
+	// are spooled to SpoolDir when set, and dropped otherwise.
+	MaxBuffered int
+	SpoolDir    string
+	// SpoolKeys seals the documents written to SpoolDir.
+	SpoolKeys *Keyring
+	// MaxRetries is how often a document rejected with 429 or 5xx is
+	// retried before it is given up on and handed to the drop handler.
+	MaxRetries int
+	Metrics    *ElasticBulkMetrics
+}
+
+type ElasticBulkMetrics struct {
+	Indexed prometheus.Counter
+	Retried prometheus.Counter
+	Dropped prometheus.Counter
+}
+
+func NewElasticBulkMetrics() *ElasticBulkMetrics {
+	return &ElasticBulkMetrics{
+		Indexed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "kubecrsh_elasticsearch_documents_indexed_total",
+			Help: "Total number of reports indexed into Elasticsearch by the bulk indexer",
+		}),
+		Retried: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "kubecrsh_elasticsearch_documents_retried_total",
+			Help: "Total number of report index attempts retried after a 429 or 5xx response",
+		}),
+		Dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "kubecrsh_elasticsearch_documents_dropped_total",
+			Help: "Total number of reports dropped by the bulk indexer",
+		}),
+	}
+}
+
+func (m *ElasticBulkMetrics) indexed(n int) {
+	if m != nil && n > 0 {
+		m.Indexed.Add(float64(n))
+	}
+}
+
+func (m *ElasticBulkMetrics) retried(n int) {
+	if m != nil && n > 0 {
+		m.Retried.Add(float64(n))
+	}
+}
+
+func (m *ElasticBulkMetrics) dropped(n int) {
+	if m != nil && n > 0 {
+		m.Dropped.Add(float64(n))
+	}
+}
+
+type elasticBulkItem struct {
+	Index    string          `json:"index"`
+	ID       string          `json:"id"`
+	Doc      json.RawMessage `json:"doc"`
+	Attempts int             `json:"attempts,omitempty"`
+
+	// segments are the spool files holding this document, or versions of
+	// it that it replaced. They are removed once it is indexed or dropped.
+	segments []uint64
+}
+
+type elasticBulkIndexer struct {
+	client  *elasticsearch.Client
+	cfg     ElasticBulkConfig
+	metrics *ElasticBulkMetrics
+	backoff time.Duration
+
+	mu      sync.Mutex
+	pending []elasticBulkItem
+	spool   *elasticSpool
+	closed  bool
+	// onDrop is called with the IDs of accepted documents that could not
+	// be indexed, so that they can be written again later.
+	onDrop func(ids []string)
+
+	// flushMu serialises flushes from the background loop and Flush.
+	flushMu sync.Mutex
+	wake    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func newElasticBulkIndexer(client *elasticsearch.Client, cfg ElasticBulkConfig) (*elasticBulkIndexer, error) {
+	if cfg.Actions <= 0 {
+		cfg.Actions = DefaultElasticBulkActions
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultElasticBulkFlushInterval
+	}
+	if cfg.MaxBuffered <= 0 {
+		cfg.MaxBuffered = DefaultElasticBulkMaxBuffered
+	}
+	if cfg.MaxBuffered < cfg.Actions {
+		cfg.MaxBuffered = cfg.Actions
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = DefaultElasticBulkMaxRetries
+	}
+
+	b := &elasticBulkIndexer{
+		client:  client,
+		cfg:     cfg,
+		metrics: cfg.Metrics,
+		backoff: elasticBulkBackoff,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	if cfg.SpoolDir != "" {
+		spool, err := openElasticSpool(cfg.SpoolDir, cfg.Actions, cfg.SpoolKeys)
+		if err != nil {
+			return nil, err
+		}
+		b.spool = spool
+	}
+
+	go b.run()
+	return b, nil
+}
+
+func (b *elasticBulkIndexer) add(item elasticBulkItem) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return errElasticBulkClosed
+	}
+
+	// Once documents have been spooled, newer ones follow them to disk so
+	// that a later save of a report is never indexed before an earlier one.
+	if len(b.pending) >= b.cfg.MaxBuffered || (b.spool != nil && !b.spool.empty()) {
+		if b.spool == nil {
+			b.metrics.dropped(1)
+			return fmt.Errorf("elasticsearch bulk buffer full, dropped report %s", item.ID)
+		}
+		if err := b.spool.append([]elasticBulkItem{item}); err != nil {
+			b.metrics.dropped(1)
+			return fmt.Errorf("failed to spool report %s: %w", item.ID, err)
+		}
+		return nil
+	}
+
+	b.pending = append(b.pending, item)
+	if len(b.pending) >= b.cfg.Actions {
+		select {
+		case b.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (b *elasticBulkIndexer) run() {
+	defer close(b.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-b.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(b.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-b.wake:
+		}
+		_ = b.flush(ctx, true)
+	}
+}
+
+// flush sends every buffered document, backing off between rounds that end
+// with retryable failures. It returns early only when ctx is done, or after
+// the first retryable failure when wait is false.
+func (b *elasticBulkIndexer) flush(ctx context.Context, wait bool) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	for {
+		batch := b.take()
+		if len(batch) == 0 {
+			return nil
+		}
+
+		retry := b.send(ctx, batch)
+		if len(retry) == 0 {
+			continue
+		}
+
+		attempts := 0
+		for _, item := range retry {
+			attempts = max(attempts, item.Attempts)
+		}
+		b.requeue(retry)
+		if !wait {
+			return fmt.Errorf("%d reports left to retry", len(retry))
+		}
+
+		timer := time.NewTimer(elasticBulkRetryDelay(b.backoff, attempts))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// take removes the next batch from the buffer, refilling it from the spool
+// first. Only the last buffered version of each report is kept.
+func (b *elasticBulkIndexer) take() []elasticBulkItem {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.spool != nil && len(b.pending) < b.cfg.Actions && !b.spool.empty() {
+		items, err := b.spool.shift()
+		if err != nil {
+			fmt.Printf("Warning: failed to read elasticsearch spool: %v\n", err)
+		}
+		b.pending = append(b.pending, items...)
+	}
+
+	n := min(len(b.pending), b.cfg.Actions)
+	batch := make([]elasticBulkItem, n)
+	copy(batch, b.pending)
+	b.pending = b.pending[n:]
+
+	last := make(map[string]int, len(batch))
+	for i, item := range batch {
+		last[item.Index+"/"+item.ID] = i
+	}
+	for i, item := range batch {
+		if j := last[item.Index+"/"+item.ID]; j != i {
+			batch[j].segments = append(batch[j].segments, item.segments...)
+		}
+	}
+	deduped := batch[:0]
+	for i, item := range batch {
+		if last[item.Index+"/"+item.ID] == i {
+			deduped = append(deduped, item)
+		}
+	}
+	return deduped
+}
+
+func (b *elasticBulkIndexer) setDropHandler(fn func(ids []string)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onDrop = fn
+}
+
+// giveUp drops items that are still retryable but could not be indexed,
+// handing their IDs to the drop handler.
+func (b *elasticBulkIndexer) giveUp(items []elasticBulkItem, reason string) {
+	if len(items) == 0 {
+		return
+	}
+	b.release(items...)
+	b.metrics.dropped(len(items))
+
+	b.mu.Lock()
+	onDrop := b.onDrop
+	b.mu.Unlock()
+
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	if onDrop == nil {
+		fmt.Printf("Warning: dropped %d reports after %d elasticsearch retries: %s\n", len(items), b.cfg.MaxRetries, reason)
+		return
+	}
+	fmt.Printf("Warning: gave up indexing %d reports after %d elasticsearch retries, handing them back: %s\n", len(items), b.cfg.MaxRetries, reason)
+	onDrop(ids)
+}
+
+// release frees the spool segments of documents that were indexed or
+// dropped for good.
+func (b *elasticBulkIndexer) release(items ...elasticBulkItem) {
+	if b.spool == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, item := range items {
+		if err := b.spool.done(item.segments); err != nil {
+			fmt.Printf("Warning: failed to remove elasticsearch spool segment: %v\n", err)
+		}
+	}
+}
+
+func (b *elasticBulkIndexer) requeue(items []elasticBulkItem) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(items, b.pending...)
+}
+
+type elasticBulkResponse struct {
+	Items []map[string]struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// send indexes batch with a single _bulk request and returns the documents
+// that should be retried.
+func (b *elasticBulkIndexer) send(ctx context.Context, batch []elasticBulkItem) []elasticBulkItem {
+	var body bytes.Buffer
+	for _, item := range batch {
+		action, _ := json.Marshal(map[string]any{
+			"index": map[string]string{"_index": item.Index, "_id": item.ID},
+		})
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(item.Doc)
+		body.WriteByte('\n')
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req := esapi.BulkRequest{Body: &body, Refresh: "false"}
+	res, err := req.Do(reqCtx, b.client)
+	if err != nil {
+		// Interrupted by shutdown: the batch goes back untouched.
+		if ctx.Err() != nil {
+			return batch
+		}
+		return b.retryAll(batch, err.Error())
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if elasticRetryable(res.StatusCode) {
+			return b.retryAll(batch, res.Status())
+		}
+		fmt.Printf("Warning: elasticsearch rejected bulk request: %s\n", res.Status())
+		b.metrics.dropped(len(batch))
+		b.release(batch...)
+		return nil
+	}
+
+	var out elasticBulkResponse
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxRemoteResponseBytes)).Decode(&out); err != nil {
+		return b.retryAll(batch, fmt.Sprintf("failed to decode bulk response: %v", err))
+	}
+	if len(out.Items) != len(batch) {
+		return b.retryAll(batch, fmt.Sprintf("bulk response has %d items, want %d", len(out.Items), len(batch)))
+	}
+
+	var retry []elasticBulkItem
+	for i, result := range out.Items {
+		item := batch[i]
+		for _, r := range result {
+			switch {
+			case r.Status >= 200 && r.Status < 300:
+				b.metrics.indexed(1)
+				b.release(item)
+			case elasticRetryable(r.Status):
+				retry = append(retry, b.retryAll([]elasticBulkItem{item}, strconv.Itoa(r.Status))...)
+			default:
+				reason := strconv.Itoa(r.Status)
+				if r.Error != nil {
+					reason = r.Error.Type + ": " + r.Error.Reason
+				}
+				fmt.Printf("Warning: elasticsearch rejected report %s: %s\n", item.ID, reason)
+				b.metrics.dropped(1)
+				b.release(item)
+			}
+		}
+	}
+	return retry
+}
+
+// retryAll bumps the attempt count of items and returns those that may be
+// retried, giving up on the ones that used up their retries.
+func (b *elasticBulkIndexer) retryAll(items []elasticBulkItem, reason string) []elasticBulkItem {
+	var retry, exhausted []elasticBulkItem
+	for _, item := range items {
+		item.Attempts++
+		if item.Attempts > b.cfg.MaxRetries {
+			exhausted = append(exhausted, item)
+			continue
+		}
+		retry = append(retry, item)
+	}
+
+	b.giveUp(exhausted, reason)
+	b.metrics.retried(len(retry))
+	return retry
+}
+
+func (b *elasticBulkIndexer) close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	close(b.stop)
+	<-b.done
+
+	// With a spool there is no point in waiting out retries: whatever is
+	// left is written to disk and indexed after the next start.
+	ctx, cancel := context.WithTimeout(context.Background(), elasticBulkCloseTimeout)
+	defer cancel()
+	flushErr := b.flush(ctx, b.spool == nil)
+
+	b.mu.Lock()
+	rest := b.pending
+	b.pending = nil
+	if b.spool != nil {
+		// Documents read from the spool are still in their segments.
+		var unspooled []elasticBulkItem
+		for _, item := range rest {
+			if len(item.segments) == 0 {
+				unspooled = append(unspooled, item)
+			}
+		}
+		rest = unspooled
+	}
+	if len(rest) == 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	if b.spool != nil {
+		if err := b.spool.prepend(rest); err == nil {
+			b.mu.Unlock()
+			return nil
+		}
+	}
+	onDrop := b.onDrop
+	b.mu.Unlock()
+
+	b.giveUp(rest, "closing")
+	if onDrop != nil {
+		return nil
+	}
+	return fmt.Errorf("dropped %d unindexed reports on close: %w", len(rest), flushErr)
+}
+
+func elasticRetryable(status int) bool {
+	return status == 429 || status >= 500
+}
+
+func elasticBulkRetryDelay(base time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < elasticBulkMaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, elasticBulkMaxBackoff)
+}
+
+// elasticSpool is an on-disk queue of segment files holding JSON lines of
+// buffered documents. Segments are named by a hex sequence number and read
+// oldest first. A segment that was read stays on disk until every document
+// in it has been indexed or dropped, so a crash in between loses nothing;
+// its documents are sent again after the restart. With keys, each line
+// holds a document sealed for its report ID.
+type elasticSpool struct {
+	dir       string
+	perFile   int
+	keys      *Keyring
+	segments  []uint64
+	inflight  map[uint64]int
+	lastCount int
+}
+
+type elasticSpoolLine struct {
+	ID     string `json:"id"`
+	Sealed []byte `json:"sealed,omitempty"`
+}
+
+func openElasticSpool(dir string, perFile int, keys *Keyring) (*elasticSpool, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create elasticsearch spool directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read elasticsearch spool directory: %w", err)
+	}
+
+	s := &elasticSpool{dir: dir, perFile: perFile, keys: keys, inflight: make(map[uint64]int)}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".jsonl")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		seq, err := strconv.ParseUint(name, 16, 64)
+		if err != nil {
+			continue
+		}
+		s.segments = append(s.segments, seq)
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i] < s.segments[j] })
+
+	if len(s.segments) > 0 {
+		items, err := s.read(s.segments[len(s.segments)-1])
+		if err != nil {
+			return nil, err
+		}
+		s.lastCount = len(items)
+	}
+
+	return s, nil
+}
+
+func (s *elasticSpool) empty() bool {
+	return len(s.segments) == 0
+}
+
+func (s *elasticSpool) path(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%016x.jsonl", seq))
+}
+
+// bounds returns the lowest and highest sequence number on disk, counting
+// segments that are being indexed.
+func (s *elasticSpool) bounds() (lo, hi uint64, ok bool) {
+	for _, seq := range s.segments {
+		lo, hi, ok = minSeq(lo, seq, ok), max(hi, seq), true
+	}
+	for seq := range s.inflight {
+		lo, hi, ok = minSeq(lo, seq, ok), max(hi, seq), true
+	}
+	return lo, hi, ok
+}
+
+func minSeq(lo, seq uint64, ok bool) uint64 {
+	if !ok {
+		return seq
+	}
+	return min(lo, seq)
+}
+
+func (s *elasticSpool) append(items []elasticBulkItem) error {
+	// Segments being indexed are never appended to: they are removed
+	// once their documents are done.
+	if len(s.segments) == 0 {
+		seq := elasticSpoolBase
+		if _, hi, ok := s.bounds(); ok {
+			seq = hi + 1
+		}
+		s.segments = append(s.segments, seq)
+		s.lastCount = 0
+	} else if s.lastCount >= s.perFile {
+		s.segments = append(s.segments, s.segments[len(s.segments)-1]+1)
+		s.lastCount = 0
+	}
+
+	if err := s.write(s.segments[len(s.segments)-1], items, os.O_APPEND); err != nil {
+		return err
+	}
+	s.lastCount += len(items)
+	return nil
+}
+
+// prepend stores items ahead of everything already spooled.
+func (s *elasticSpool) prepend(items []elasticBulkItem) error {
+	seq := elasticSpoolBase
+	if lo, _, ok := s.bounds(); ok {
+		seq = lo - 1
+	}
+
+	if err := s.write(seq, items, os.O_TRUNC); err != nil {
+		return err
+	}
+	if len(s.segments) == 0 {
+		s.lastCount = len(items)
+	}
+	s.segments = append([]uint64{seq}, s.segments...)
+	return nil
+}
+
+// shift returns the documents of the oldest segment. The segment file is
+// kept until done has been called for all of them.
+func (s *elasticSpool) shift() ([]elasticBulkItem, error) {
+	seq := s.segments[0]
+	items, err := s.read(seq)
+	if err != nil {
+		return nil, err
+	}
+
+	s.segments = s.segments[1:]
+	if len(s.segments) == 0 {
+		s.lastCount = 0
+	}
+	for i := range items {
+		items[i].segments = []uint64{seq}
+	}
+	s.inflight[seq] = len(items)
+	if len(items) == 0 {
+		return nil, s.done([]uint64{seq})
+	}
+	return items, nil
+}
+
+// done marks one document of each segment as indexed or dropped, removing
+// segments that have none left.
+func (s *elasticSpool) done(segments []uint64) error {
+	removed := false
+	for _, seq := range segments {
+		n, ok := s.inflight[seq]
+		if !ok {
+			continue
+		}
+		if n > 1 {
+			s.inflight[seq] = n - 1
+			continue
+		}
+		delete(s.inflight, seq)
+		if err := os.Remove(s.path(seq)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		removed = true
+	}
+	if removed {
+		return syncDir(s.dir)
+	}
+	return nil
+}
+
+func (s *elasticSpool) write(seq uint64, items []elasticBulkItem, mode int) error {
+	path := s.path(seq)
+	_, statErr := os.Stat(path)
+	created := os.IsNotExist(statErr)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|mode, 0644)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, item := range items {
+		line, err := s.encode(item)
+		if err == nil {
+			err = enc.Encode(line)
+		}
+		if err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if created {
+		return syncDir(s.dir)
+	}
+	return nil
+}
+
+func (s *elasticSpool) read(seq uint64) ([]elasticBulkItem, error) {
+	data, err := os.ReadFile(s.path(seq))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var items []elasticBulkItem
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var sealed elasticSpoolLine
+		// A torn last line from a crash mid-write is skipped.
+		if err := json.Unmarshal(line, &sealed); err != nil {
+			continue
+		}
+		if sealed.Sealed != nil {
+			if s.keys == nil {
+				return nil, fmt.Errorf("elasticsearch spool segment %s is encrypted and no encryption key is configured", filepath.Base(s.path(seq)))
+			}
+			if line, err = s.keys.open(sealed.Sealed, sealed.ID); err != nil {
+				return nil, err
+			}
+		}
+		var item elasticBulkItem
+		if err := json.Unmarshal(line, &item); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// encode returns the spool line of item, sealed when the spool has keys.
+func (s *elasticSpool) encode(item elasticBulkItem) (any, error) {
+	if s.keys == nil {
+		return item, nil
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.keys.seal(data, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	return elasticSpoolLine{ID: item.ID, Sealed: sealed}, nil
+}
+
+// syncDir makes file creations and removals in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,833 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeElastic is an in-process stand-in for the parts of the Elasticsearch
+// API the store uses: documents, search with search_after and point in
+// time, count, delete-by-query, index templates, ILM policies and
+// _cat/indices, and _bulk.
+type fakeElastic struct {
+	mu        sync.Mutex
+	indices   map[string]map[string]*fakeDoc
+	templates map[string]json.RawMessage
+	policies  map[string]json.RawMessage
+	pits      map[string]bool
+	seq       int
+	searches  int
+	bulks     int
+	bulkSize  int
+	// bulkDown fails the next n _bulk requests with 429, or all of them
+	// when negative. itemStatus holds the statuses returned for successive
+	// attempts to index a document ID.
+	bulkDown   int
+	itemStatus map[string][]int
+}
+
+type fakeDoc struct {
+	source      json.RawMessage
+	collectedAt time.Time
+	seqNo       int
+}
+
+func newFakeElastic(t *testing.T) (*fakeElastic, string) {
+	f := &fakeElastic{
+		indices:    map[string]map[string]*fakeDoc{},
+		templates:  map[string]json.RawMessage{},
+		policies:   map[string]json.RawMessage{},
+		pits:       map[string]bool{},
+		itemStatus: map[string][]int{},
+	}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server.URL
+}
+
+func (f *fakeElastic) put(index string, report *domain.ForensicReport) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	source, _ := json.Marshal((&ElasticStore{}).toDocument(report))
+	if f.indices[index] == nil {
+		f.indices[index] = map[string]*fakeDoc{}
+	}
+	f.seq++
+	f.indices[index][report.ID] = &fakeDoc{source: source, collectedAt: report.CollectedAt, seqNo: f.seq}
+}
+
+func (f *fakeElastic) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	w.Header().Set("Content-Type", "application/json")
+
+	body, _ := io.ReadAll(r.Body)
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	reply := func(status int, v any) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+
+	switch {
+	case r.URL.Path == "/":
+		reply(200, map[string]any{"version": map[string]any{"number": "8.19.0"}})
+	case parts[0] == "_ilm":
+		f.policies[parts[2]] = body
+		reply(200, map[string]any{"acknowledged": true})
+	case parts[0] == "_index_template":
+		f.templates[parts[1]] = body
+		reply(200, map[string]any{"acknowledged": true})
+	case parts[0] == "_pit":
+		var req struct{ ID string }
+		json.Unmarshal(body, &req)
+		delete(f.pits, req.ID)
+		reply(200, map[string]any{"succeeded": true})
+	case parts[0] == "_search":
+		var req struct {
+			Pit struct{ ID string }
+		}
+		json.Unmarshal(body, &req)
+		if !f.pits[req.Pit.ID] {
+			reply(404, map[string]any{"error": "search_context_missing_exception"})
+			return
+		}
+		f.search(w, []string{"kubecrsh-reports", "kubecrsh-reports-*"}, body, req.Pit.ID)
+	case parts[0] == "_bulk":
+		f.bulk(w, body)
+	case parts[0] == "_cat":
+		var rows []map[string]string
+		for name := range f.indices {
+			if ok, _ := path.Match(parts[2], name); ok {
+				rows = append(rows, map[string]string{"index": name})
+			}
+		}
+		reply(200, rows)
+	case len(parts) == 1 && r.Method == http.MethodHead:
+		if _, ok := f.indices[parts[0]]; !ok {
+			w.WriteHeader(404)
+		}
+	case len(parts) == 1 && r.Method == http.MethodPut:
+		f.indices[parts[0]] = map[string]*fakeDoc{}
+		reply(200, map[string]any{"acknowledged": true})
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		delete(f.indices, parts[0])
+		reply(200, map[string]any{"acknowledged": true})
+	case parts[1] == "_pit":
+		id := "pit-" + strconv.Itoa(len(f.pits)+1)
+		f.pits[id] = true
+		reply(200, map[string]any{"id": id})
+	case parts[1] == "_search":
+		f.search(w, strings.Split(parts[0], ","), body, "")
+	case parts[1] == "_count":
+		reply(200, map[string]any{"count": len(f.match(strings.Split(parts[0], ","), nil))})
+	case parts[1] == "_delete_by_query":
+		var req struct {
+			Query struct {
+				Range struct {
+					CollectedAt struct {
+						Lt time.Time `json:"lt"`
+					} `json:"collected_at"`
+				} `json:"range"`
+			} `json:"query"`
+		}
+		json.Unmarshal(body, &req)
+		deleted := 0
+		for _, hit := range f.match(strings.Split(parts[0], ","), nil) {
+			if hit.doc.collectedAt.Before(req.Query.Range.CollectedAt.Lt) {
+				delete(f.indices[hit.index], hit.id)
+				deleted++
+			}
+		}
+		reply(200, map[string]any{"deleted": deleted, "failures": []any{}})
+	case parts[1] == "_doc" && r.Method == http.MethodGet:
+		doc, ok := f.indices[parts[0]][parts[2]]
+		if !ok {
+			reply(404, map[string]any{"found": false})
+			return
+		}
+		reply(200, map[string]any{"_index": parts[0], "_id": parts[2], "_seq_no": doc.seqNo, "_primary_term": 1, "found": true, "_source": doc.source})
+	case parts[1] == "_doc":
+		if seq := r.URL.Query().Get("if_seq_no"); seq != "" {
+			doc, ok := f.indices[parts[0]][parts[2]]
+			if !ok || strconv.Itoa(doc.seqNo) != seq {
+				reply(409, map[string]any{"error": "version_conflict_engine_exception"})
+				return
+			}
+		}
+		var doc elasticDocument
+		json.Unmarshal(body, &doc)
+		if f.indices[parts[0]] == nil {
+			f.indices[parts[0]] = map[string]*fakeDoc{}
+		}
+		f.seq++
+		f.indices[parts[0]][parts[2]] = &fakeDoc{source: body, collectedAt: doc.CollectedAt, seqNo: f.seq}
+		reply(200, map[string]any{"_index": parts[0], "_id": parts[2], "result": "created"})
+	default:
+		reply(400, map[string]any{"error": "unsupported " + r.Method + " " + r.URL.Path})
+	}
+}
+
+func (f *fakeElastic) bulk(w http.ResponseWriter, body []byte) {
+	f.bulks++
+	if f.bulkDown != 0 {
+		f.bulkDown--
+		w.WriteHeader(429)
+		return
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	f.bulkSize = max(f.bulkSize, len(lines)/2)
+	var items []map[string]any
+	for i := 0; i+1 < len(lines); i += 2 {
+		var action struct {
+			Index struct {
+				Index string `json:"_index"`
+				ID    string `json:"_id"`
+			} `json:"index"`
+		}
+		json.Unmarshal([]byte(lines[i]), &action)
+		index, id := action.Index.Index, action.Index.ID
+
+		status := 201
+		if statuses := f.itemStatus[id]; len(statuses) > 0 {
+			status = statuses[0]
+			if len(statuses) > 1 {
+				f.itemStatus[id] = statuses[1:]
+			}
+		}
+		if status == 201 {
+			var doc elasticDocument
+			json.Unmarshal([]byte(lines[i+1]), &doc)
+			if f.indices[index] == nil {
+				f.indices[index] = map[string]*fakeDoc{}
+			}
+			f.seq++
+			f.indices[index][id] = &fakeDoc{source: json.RawMessage(lines[i+1]), collectedAt: doc.CollectedAt, seqNo: f.seq}
+		}
+
+		result := map[string]any{"_index": index, "_id": id, "status": status}
+		if status >= 300 {
+			result["error"] = map[string]any{"type": "mapper_parsing_exception", "reason": "failed to parse"}
+		}
+		items = append(items, map[string]any{"index": result})
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{"errors": false, "items": items})
+}
+
+type fakeHit struct {
+	index string
+	id    string
+	doc   *fakeDoc
+}
+
+// match returns the documents of the named indices (wildcards allowed),
+// newest first, optionally limited to ids.
+func (f *fakeElastic) match(patterns []string, ids []string) []fakeHit {
+	var hits []fakeHit
+	for name, docs := range f.indices {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, name); !ok {
+				continue
+			}
+			for id, doc := range docs {
+				if ids == nil || contains(ids, id) {
+					hits = append(hits, fakeHit{name, id, doc})
+				}
+			}
+			break
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		if !a.doc.collectedAt.Equal(b.doc.collectedAt) {
+			return a.doc.collectedAt.After(b.doc.collectedAt)
+		}
+		return a.id > b.id
+	})
+	return hits
+}
+
+func (f *fakeElastic) search(w http.ResponseWriter, patterns []string, body []byte, pit string) {
+	f.searches++
+
+	var req struct {
+		Query struct {
+			IDs *struct {
+				Values []string `json:"values"`
+			} `json:"ids"`
+		} `json:"query"`
+		Size        int   `json:"size"`
+		SearchAfter []any `json:"search_after"`
+		Aggs        struct {
+			Groups struct {
+				Composite *struct {
+					Size  int `json:"size"`
+					After *struct {
+						Fingerprint string `json:"fingerprint"`
+					} `json:"after"`
+				} `json:"composite"`
+			} `json:"groups"`
+		} `json:"aggs"`
+	}
+	json.Unmarshal(body, &req)
+
+	var ids []string
+	if req.Query.IDs != nil {
+		ids = req.Query.IDs.Values
+	}
+	hits := f.match(patterns, ids)
+
+	if c := req.Aggs.Groups.Composite; c != nil {
+		after := ""
+		if c.After != nil {
+			after = c.After.Fingerprint
+		}
+		json.NewEncoder(w).Encode(map[string]any{"aggregations": map[string]any{"groups": compositeGroups(hits, after, c.Size)}})
+		return
+	}
+
+	if len(req.SearchAfter) == 2 {
+		at := int64(req.SearchAfter[0].(float64))
+		id := req.SearchAfter[1].(string)
+		start := sort.Search(len(hits), func(i int) bool {
+			ms := hits[i].doc.collectedAt.UnixMilli()
+			return ms < at || ms == at && hits[i].id < id
+		})
+		hits = hits[start:]
+	}
+
+	total := len(hits)
+	if req.Size > 0 && len(hits) > req.Size {
+		hits = hits[:req.Size]
+	}
+
+	out := make([]map[string]any, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, map[string]any{
+			"_index":        h.index,
+			"_id":           h.id,
+			"_seq_no":       h.doc.seqNo,
+			"_primary_term": 1,
+			"sort":          []any{h.doc.collectedAt.UnixMilli(), h.id},
+			"_source":       h.doc.source,
+		})
+	}
+
+	resp := map[string]any{"hits": map[string]any{"total": map[string]any{"value": total}, "hits": out}}
+	if pit != "" {
+		resp["pit_id"] = pit
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// compositeGroups answers a composite terms aggregation on fingerprint:
+// buckets in key order after the given key, with an after_key whenever the
+// page is not empty.
+func compositeGroups(hits []fakeHit, after string, size int) map[string]any {
+	byKey := map[string][]fakeHit{}
+	var keys []string
+	for _, h := range hits {
+		var doc struct {
+			Fingerprint string `json:"fingerprint"`
+		}
+		json.Unmarshal(h.doc.source, &doc)
+		if doc.Fingerprint <= after {
+			continue
+		}
+		if byKey[doc.Fingerprint] == nil {
+			keys = append(keys, doc.Fingerprint)
+		}
+		byKey[doc.Fingerprint] = append(byKey[doc.Fingerprint], h)
+	}
+	sort.Strings(keys)
+	if len(keys) > size {
+		keys = keys[:size]
+	}
+
+	buckets := make([]map[string]any, 0, len(keys))
+	for _, k := range keys {
+		// Hits are newest first, so the first is the latest report.
+		group := byKey[k]
+		buckets = append(buckets, map[string]any{
+			"key":        map[string]any{"fingerprint": k},
+			"doc_count":  len(group),
+			"first_seen": map[string]any{"value": group[len(group)-1].doc.collectedAt.UnixMilli()},
+			"last_seen":  map[string]any{"value": group[0].doc.collectedAt.UnixMilli()},
+			"latest":     map[string]any{"hits": map[string]any{"hits": []any{map[string]any{"_source": group[0].doc.source}}}},
+		})
+	}
+
+	out := map[string]any{"buckets": buckets}
+	if len(keys) > 0 {
+		out["after_key"] = map[string]any{"fingerprint": keys[len(keys)-1]}
+	}
+	return out
+}
+
+func TestElasticStore_ListPagesThroughEveryReport(t *testing.T) {
+	fake, url := newFakeElastic(t)
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	for i := 0; i < 2500; i++ {
+		report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+		report.ID = strconv.Itoa(10000 + i)
+		// Several reports share a millisecond, so pages must break ties by ID.
+		report.CollectedAt = base.Add(time.Duration(i/3) * time.Millisecond)
+		fake.put("kubecrsh-reports", report)
+	}
+
+	store, err := NewElasticStore(ElasticConfig{Addresses: []string{url}})
+	if err != nil {
+		t.Fatalf("NewElasticStore() error = %v", err)
+	}
+
+	reports, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(reports) != 2500 {
+		t.Fatalf("List() returned %d reports, want 2500", len(reports))
+	}
+
+	seen := map[string]bool{}
+	for i, r := range reports {
+		if seen[r.ID] {
+			t.Fatalf("report %s listed twice", r.ID)
+		}
+		seen[r.ID] = true
+		if i > 0 && reportBefore(reports[i-1], r) {
+			t.Fatalf("List() not newest first at %d", i)
+		}
+	}
+	if fake.searches != 3 {
+		t.Errorf("List() issued %d searches, want 3 pages", fake.searches)
+	}
+	if len(fake.pits) != 0 {
+		t.Errorf("point in time left open: %v", fake.pits)
+	}
+}
+
+func TestElasticStore_GroupsPagesThroughEveryFingerprint(t *testing.T) {
+	fake, url := newFakeElastic(t)
+
+	groups := elasticGroupsPageSize*2 + 10
+	base := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	for i := 0; i < groups; i++ {
+		for j := 0; j < 2; j++ {
+			report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api", ContainerName: "app"})
+			report.ID = fmt.Sprintf("%d-%d", 10000+i, j)
+			report.Fingerprint = fmt.Sprintf("fp-%05d", i)
+			report.CollectedAt = base.Add(time.Duration(2*i+j) * time.Millisecond)
+			fake.put("kubecrsh-reports", report)
+		}
+	}
+
+	store, err := NewElasticStore(ElasticConfig{Addresses: []string{url}})
+	if err != nil {
+		t.Fatalf("NewElasticStore() error = %v", err)
+	}
+
+	fake.searches = 0
+	got, err := store.Groups()
+	if err != nil {
+		t.Fatalf("Groups() error = %v", err)
+	}
+	if len(got) != groups {
+		t.Fatalf("Groups() returned %d groups, want %d", len(got), groups)
+	}
+	if fake.searches != 4 {
+		t.Errorf("Groups() issued %d searches, want 3 pages and an empty one", fake.searches)
+	}
+
+	seen := map[string]bool{}
+	for i, g := range got {
+		if seen[g.Fingerprint] {
+			t.Fatalf("group %s returned twice", g.Fingerprint)
+		}
+		seen[g.Fingerprint] = true
+		if g.Count != 2 {
+			t.Errorf("group %s count = %d, want 2", g.Fingerprint, g.Count)
+		}
+		if i > 0 && g.LastSeen.After(got[i-1].LastSeen) {
+			t.Fatalf("Groups() not most recently seen first at %d", i)
+		}
+	}
+	last := groups - 1
+	if got[0].Fingerprint != fmt.Sprintf("fp-%05d", last) || got[0].LatestReportID != fmt.Sprintf("%d-1", 10000+last) {
+		t.Errorf("first group = %s latest %s", got[0].Fingerprint, got[0].LatestReportID)
+	}
+}
+
+func TestElasticStore_TimeBasedIndicesAndPrune(t *testing.T) {
+	fake, url := newFakeElastic(t)
+
+	legacy := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "legacy"})
+	legacy.CollectedAt = time.Now().Add(-20 * 24 * time.Hour)
+	fake.put("kubecrsh-reports", legacy)
+
+	store, err := NewElasticStore(ElasticConfig{
+		Addresses:      []string{url},
+		IndexPeriod:    "day",
+		ILMPolicy:      "kubecrsh-reports",
+		ILMDeleteAfter: "30d",
+	})
+	if err != nil {
+		t.Fatalf("NewElasticStore() error = %v", err)
+	}
+
+	for _, want := range []string{`"index_patterns":["kubecrsh-reports-*"]`, `"index.lifecycle.name":"kubecrsh-reports"`, `"index.lifecycle.parse_origination_date":true`} {
+		if !strings.Contains(string(fake.templates["kubecrsh-reports"]), want) {
+			t.Errorf("index template does not contain %s: %s", want, fake.templates["kubecrsh-reports"])
+		}
+	}
+	if !strings.Contains(string(fake.policies["kubecrsh-reports"]), `"delete":{"actions":{"delete":{}},"min_age":"30d"}`) {
+		t.Errorf("ILM policy = %s", fake.policies["kubecrsh-reports"])
+	}
+
+	var saved []*domain.ForensicReport
+	for _, age := range []time.Duration{time.Minute, 3 * 24 * time.Hour, 10 * 24 * time.Hour} {
+		report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+		report.CollectedAt = time.Now().Add(-age)
+		if err := store.Save(report); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if _, ok := fake.indices[store.writeIndex(report)][report.ID]; !ok {
+			t.Errorf("report not written to %s", store.writeIndex(report))
+		}
+		saved = append(saved, report)
+	}
+
+	acked := domain.TriageAcknowledged
+	updated, err := store.UpdateTriage(saved[1].ID, domain.TriageUpdate{Status: &acked}, domain.TriageActor{Name: "alice"})
+	if err != nil {
+		t.Fatalf("UpdateTriage() error = %v", err)
+	}
+	if updated.TriageStatus() != acked {
+		t.Errorf("TriageStatus() = %s", updated.TriageStatus())
+	}
+	if loaded, err := store.Load(legacy.ID); err != nil || loaded.Crash.PodName != "legacy" {
+		t.Errorf("Load() of a report in the legacy index = %v, %v", loaded, err)
+	}
+
+	res, err := store.Prune(5 * 24 * time.Hour)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if res.Deleted != 2 || res.Kept != 2 {
+		t.Errorf("Prune() = %+v, want 2 deleted and 2 kept", res)
+	}
+	if _, ok := fake.indices[store.writeIndex(saved[2])]; ok {
+		t.Errorf("expired index %s was not deleted", store.writeIndex(saved[2]))
+	}
+
+	reports, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids(reports) != saved[0].ID+","+saved[1].ID {
+		t.Errorf("List() after prune = %s", ids(reports))
+	}
+}
+
+func TestNewElasticStore_InvalidIndexSettings(t *testing.T) {
+	for _, cfg := range []ElasticConfig{
+		{IndexPeriod: "week"},
+		{ILMPolicy: "kubecrsh"},
+		{IndexPeriod: "day", ILMDeleteAfter: "30d"},
+	} {
+		if _, err := NewElasticStore(cfg); err == nil || strings.Contains(err.Error(), "ping") {
+			t.Errorf("NewElasticStore(%+v) error = %v, want a config error", cfg, err)
+		}
+	}
+}
+
+func newBulkTestStore(t *testing.T, url string, cfg ElasticBulkConfig) *ElasticStore {
+	t.Helper()
+
+	cfg.Enabled = true
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = time.Hour
+	}
+	store, err := NewElasticStore(ElasticConfig{Addresses: []string{url}, Bulk: cfg})
+	if err != nil {
+		t.Fatalf("NewElasticStore() error = %v", err)
+	}
+	store.bulk.backoff = time.Millisecond
+	return store
+}
+
+func TestElasticStore_BulkIndexesInBatches(t *testing.T) {
+	fake, url := newFakeElastic(t)
+	metrics := NewElasticBulkMetrics()
+	store := newBulkTestStore(t, url, ElasticBulkConfig{Actions: 10, MaxBuffered: 100, Metrics: metrics})
+
+	var first *domain.ForensicReport
+	for i := 0; i < 25; i++ {
+		report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api-" + strconv.Itoa(i)})
+		if err := store.Save(report); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if first == nil {
+			first = report
+		}
+	}
+	if err := store.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	// A later save of a buffered report replaces it.
+	for _, name := range []string{"api-stale", "api-updated"} {
+		first.Crash.PodName = name
+		if err := store.Save(first); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if got := len(fake.indices["kubecrsh-reports"]); got != 25 {
+		t.Errorf("indexed %d reports, want 25", got)
+	}
+	if fake.bulks < 4 || fake.bulkSize > 10 {
+		t.Errorf("sent %d bulk requests of up to %d documents, want at least 4 of at most 10", fake.bulks, fake.bulkSize)
+	}
+	if !strings.Contains(string(fake.indices["kubecrsh-reports"][first.ID].source), "api-updated") {
+		t.Errorf("report indexed from a stale save: %s", fake.indices["kubecrsh-reports"][first.ID].source)
+	}
+	if got := testutil.ToFloat64(metrics.Indexed); got != 26 {
+		t.Errorf("indexed metric = %v, want 26", got)
+	}
+	if err := store.Save(first); err == nil {
+		t.Error("Save() after Close() succeeded")
+	}
+}
+
+func TestElasticStore_BulkRetriesAndDrops(t *testing.T) {
+	fake, url := newFakeElastic(t)
+	metrics := NewElasticBulkMetrics()
+	store := newBulkTestStore(t, url, ElasticBulkConfig{Actions: 10, MaxRetries: 2, Metrics: metrics})
+
+	var reports []*domain.ForensicReport
+	for i := 0; i < 4; i++ {
+		report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api-" + strconv.Itoa(i)})
+		reports = append(reports, report)
+		if err := store.Save(report); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	fake.mu.Lock()
+	fake.itemStatus[reports[1].ID] = []int{429, 201}
+	fake.itemStatus[reports[2].ID] = []int{400}
+	fake.itemStatus[reports[3].ID] = []int{503}
+	fake.bulkDown = 1
+	fake.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := store.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	for i, want := range []bool{true, true, false, false} {
+		if _, ok := fake.indices["kubecrsh-reports"][reports[i].ID]; ok != want {
+			t.Errorf("report %d indexed = %v, want %v", i, ok, want)
+		}
+	}
+	if got := testutil.ToFloat64(metrics.Indexed); got != 2 {
+		t.Errorf("indexed metric = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(metrics.Dropped); got != 2 {
+		t.Errorf("dropped metric = %v, want 2", got)
+	}
+	// All 4 after the failed request, then reports 1 and 3; report 3 is
+	// dropped on its third failure.
+	if got := testutil.ToFloat64(metrics.Retried); got != 6 {
+		t.Errorf("retried metric = %v, want 6", got)
+	}
+}
+
+func TestElasticStore_BulkHandsDroppedReportsToOutbox(t *testing.T) {
+	fake, url := newFakeElastic(t)
+	es := newBulkTestStore(t, url, ElasticBulkConfig{Actions: 10, MaxRetries: 1})
+	primary := newFlakyStorage()
+	m, err := NewMultiStore([]Backend{
+		{Name: "file", Store: primary, Required: true},
+		{Name: "elasticsearch", Store: es},
+	}, WithOutbox(t.TempDir()), WithOutboxRetry(time.Hour, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+	if err := m.Save(report); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	fake.mu.Lock()
+	fake.itemStatus[report.ID] = []int{503, 503}
+	fake.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := es.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if ids := m.outbox.ids("elasticsearch"); len(ids) != 1 || ids[0] != report.ID {
+		t.Errorf("outbox = %v, want the dropped report", ids)
+	}
+}
+
+func TestElasticStore_BulkSpoolsToDisk(t *testing.T) {
+	keys, err := NewKeyring(map[string][]byte{"k": testKey(1)}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name string
+		keys *Keyring
+	}{
+		{"plaintext", nil},
+		{"encrypted", keys},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, url := newFakeElastic(t)
+			spool := t.TempDir()
+			fake.bulkDown = -1
+
+			cfg := ElasticBulkConfig{Actions: 2, MaxBuffered: 2, SpoolDir: spool, SpoolKeys: tt.keys}
+			store := newBulkTestStore(t, url, cfg)
+			for i := 0; i < 5; i++ {
+				report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api-" + strconv.Itoa(i)})
+				if err := store.Save(report); err != nil {
+					t.Fatalf("Save() error = %v", err)
+				}
+			}
+			if err := store.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			entries, _ := os.ReadDir(spool)
+			if len(entries) == 0 {
+				t.Fatal("nothing spooled while elasticsearch was down")
+			}
+			for _, entry := range entries {
+				data, _ := os.ReadFile(filepath.Join(spool, entry.Name()))
+				if plain := bytes.Contains(data, []byte("api-")); plain != (tt.keys == nil) {
+					t.Errorf("%s holds plaintext reports = %v", entry.Name(), plain)
+				}
+			}
+
+			fake.mu.Lock()
+			fake.bulkDown = 0
+			fake.mu.Unlock()
+
+			store = newBulkTestStore(t, url, cfg)
+			if err := store.Flush(context.Background()); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			defer store.Close()
+
+			fake.mu.Lock()
+			defer fake.mu.Unlock()
+			if got := len(fake.indices["kubecrsh-reports"]); got != 5 {
+				t.Errorf("indexed %d reports after restart, want 5", got)
+			}
+			if entries, _ := os.ReadDir(spool); len(entries) != 0 {
+				t.Errorf("spool not drained: %d files left", len(entries))
+			}
+		})
+	}
+}
+
+func TestElasticStore_BulkKeepsSpoolUntilIndexed(t *testing.T) {
+	fake, url := newFakeElastic(t)
+	spool := t.TempDir()
+	fake.bulkDown = -1
+
+	spooled := func() int {
+		t.Helper()
+		entries, err := os.ReadDir(spool)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for _, entry := range entries {
+			data, err := os.ReadFile(filepath.Join(spool, entry.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			n += bytes.Count(data, []byte("\n"))
+		}
+		return n
+	}
+
+	store := newBulkTestStore(t, url, ElasticBulkConfig{Actions: 2, MaxBuffered: 2, SpoolDir: spool})
+	for i := 0; i < 5; i++ {
+		report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api-" + strconv.Itoa(i)})
+		if err := store.Save(report); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := spooled(); got != 5 {
+		t.Fatalf("spooled %d reports, want 5", got)
+	}
+
+	// A failed bulk request must leave the segments it read on disk, as
+	// if the daemon crashed before the documents were indexed.
+	store = newBulkTestStore(t, url, ElasticBulkConfig{Actions: 2, MaxBuffered: 2, SpoolDir: spool})
+	if err := store.bulk.flush(context.Background(), false); err == nil {
+		t.Fatal("flush() succeeded while elasticsearch was down")
+	}
+	if got := spooled(); got != 5 {
+		t.Errorf("spool holds %d reports after a failed flush, want 5", got)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := spooled(); got != 5 {
+		t.Errorf("spool holds %d reports after Close, want 5", got)
+	}
+
+	fake.mu.Lock()
+	fake.bulkDown = 0
+	fake.mu.Unlock()
+
+	store = newBulkTestStore(t, url, ElasticBulkConfig{Actions: 2, MaxBuffered: 2, SpoolDir: spool})
+	defer store.Close()
+	if err := store.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if got := spooled(); got != 0 {
+		t.Errorf("spool holds %d reports after indexing, want 0", got)
+	}
+}
@@ -154,6 +154,8 @@ func TestElasticStore_documentRoundTrip(t *testing.T) {
 	original := domain.NewForensicReport(crash)
 	original.SetLogs([]string{"log entry"})
 	original.AddEvent(*domain.NewEvent("Normal", "Pulled", "Container image pulled"))
+	original.SetFindings([]domain.Finding{{Rule: "memory-limit", Severity: domain.FindingCritical, Confidence: 0.95, Title: "Memory limit 170Mi reached"}})
+	original.SetSeverity(domain.ClassifySeverity(original, domain.SeverityContext{}))
 
 	doc := store.toDocument(original)
 	restored := store.fromDocument(doc)
@@ -173,6 +175,12 @@ func TestElasticStore_documentRoundTrip(t *testing.T) {
 	if len(restored.Events) != len(original.Events) {
 		t.Errorf("Events count mismatch after round trip")
 	}
+	if len(restored.Findings) != 1 || restored.Findings[0].Title != "Memory limit 170Mi reached" {
+		t.Errorf("Findings = %+v, want the memory limit finding", restored.Findings)
+	}
+	if restored.Severity == nil || restored.Severity.Level != original.Severity.Level || restored.Severity.Score != original.Severity.Score {
+		t.Errorf("Severity = %+v, want %+v", restored.Severity, original.Severity)
+	}
 }
 
 func TestNewElasticStore_ConnectionError(t *testing.T) {
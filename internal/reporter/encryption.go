@@ -0,0 +1,408 @@
+package reporter
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// encryptedExt marks report files written with envelope encryption.
+const encryptedExt = ".enc"
+
+// An encrypted report starts with encryptionMagic and a version byte,
+// followed by the key ID, the data key wrapped by that key, the data nonce
+// and the AES-256-GCM ciphertext of the (possibly gzipped) report. The
+// ciphertext authenticates the key ID and the report ID as well, so a
+// sealed report cannot be passed off as another one.
+var encryptionMagic = []byte("KCRSHENC")
+
+const (
+	encryptionVersion = 2
+	encryptionKeySize = 32
+)
+
+// Keyring holds the key-encryption keys reports are sealed with. Every
+// report gets its own random data key, wrapped by the active key and
+// stored next to the ciphertext along with that key's ID, so older keys
+// kept in the keyring still open reports written before a rotation.
+type Keyring struct {
+	active string
+	keys   map[string][]byte
+}
+
+// NewKeyring builds a keyring from key IDs and 32-byte keys. The active key
+// seals new reports and may be left empty when there is only one key.
+func NewKeyring(keys map[string][]byte, active string) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no encryption keys")
+	}
+
+	k := &Keyring{active: active, keys: make(map[string][]byte, len(keys))}
+	for id, key := range keys {
+		if id == "" || len(id) > 255 {
+			return nil, fmt.Errorf("invalid encryption key id %q", id)
+		}
+		if len(key) != encryptionKeySize {
+			return nil, fmt.Errorf("encryption key %q must be %d bytes, got %d", id, encryptionKeySize, len(key))
+		}
+		k.keys[id] = append([]byte(nil), key...)
+	}
+
+	if k.active == "" {
+		if len(k.keys) > 1 {
+			return nil, fmt.Errorf("several encryption keys loaded; set the key id to encrypt new reports with")
+		}
+		for id := range k.keys {
+			k.active = id
+		}
+	}
+	if _, ok := k.keys[k.active]; !ok {
+		return nil, fmt.Errorf("encryption key %q not found", k.active)
+	}
+
+	return k, nil
+}
+
+// LoadKeyring reads keys from path. A directory holds one key per file,
+// named after its key ID, which is how a mounted Kubernetes Secret looks; a
+// single file is one key whose ID is the file name. Keys are 32 raw bytes
+// or their base64 or hex encoding.
+func LoadKeyring(path, active string) (*Keyring, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption keys: %w", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption keys: %w", err)
+		}
+		files = files[:0]
+		for _, e := range entries {
+			// Skip the ..data links of a mounted Secret and other dot files.
+			if strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			files = append(files, filepath.Join(path, e.Name()))
+		}
+	}
+
+	keys := make(map[string][]byte, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption key: %w", err)
+		}
+		key, err := ParseEncryptionKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %s: %w", filepath.Base(file), err)
+		}
+		keys[filepath.Base(file)] = key
+	}
+
+	return NewKeyring(keys, active)
+}
+
+// ParseEncryptionKey accepts a 32-byte key as raw bytes, base64 or hex.
+func ParseEncryptionKey(data []byte) ([]byte, error) {
+	if len(data) == encryptionKeySize {
+		return data, nil
+	}
+
+	text := strings.TrimSpace(string(data))
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == encryptionKeySize {
+		return key, nil
+	}
+	if key, err := hex.DecodeString(text); err == nil && len(key) == encryptionKeySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("want %d bytes, raw or base64 or hex encoded", encryptionKeySize)
+}
+
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// KeyIDs returns the IDs of every key in the keyring, sorted.
+func (k *Keyring) KeyIDs() []string {
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (k *Keyring) seal(plaintext []byte, reportID string) ([]byte, error) {
+	dataKey := make([]byte, encryptionKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	wrapped, err := k.wrap(k.active, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := encryptionHeader(k.active, wrapped)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, encryptionAAD(k.active, reportID)), nil
+}
+
+func (k *Keyring) open(data []byte, reportID string) ([]byte, error) {
+	env, err := parseEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := k.unwrap(env.keyID, env.wrapped)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.rest) < aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted report is truncated")
+	}
+
+	nonce, ciphertext := env.rest[:aead.NonceSize()], env.rest[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, encryptionAAD(env.keyID, reportID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt report: %w", err)
+	}
+	return plaintext, nil
+}
+
+// reseal moves an encrypted report under the active key. The key ID is
+// authenticated along with the data, so the report is sealed again rather
+// than only getting its data key rewrapped. It reports false when the
+// report was already sealed with the active key.
+func (k *Keyring) reseal(data []byte, reportID string) ([]byte, bool, error) {
+	env, err := parseEnvelope(data)
+	if err != nil {
+		return nil, false, err
+	}
+	if env.keyID == k.active {
+		return data, false, nil
+	}
+
+	plaintext, err := k.open(data, reportID)
+	if err != nil {
+		return nil, false, err
+	}
+	out, err := k.seal(plaintext, reportID)
+	if err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
+
+func (k *Keyring) wrap(keyID string, dataKey []byte) ([]byte, error) {
+	aead, err := newGCM(k.keys[keyID])
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, dataKey, []byte(keyID)), nil
+}
+
+func (k *Keyring) unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	kek, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("report is encrypted with unknown key %q", keyID)
+	}
+	aead, err := newGCM(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, fmt.Errorf("wrapped data key is truncated")
+	}
+
+	dataKey, err := aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with key %q: %w", keyID, err)
+	}
+	return dataKey, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func encryptionAAD(keyID, reportID string) []byte {
+	aad := append(append([]byte(nil), encryptionMagic...), encryptionVersion)
+	aad = append(aad, byte(len(keyID)))
+	aad = append(aad, keyID...)
+	return append(aad, reportID...)
+}
+
+func encryptionHeader(keyID string, wrapped []byte) []byte {
+	out := append(append([]byte(nil), encryptionMagic...), encryptionVersion)
+	out = append(out, byte(len(keyID)))
+	out = append(out, keyID...)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(wrapped)))
+	return append(out, wrapped...)
+}
+
+type envelope struct {
+	keyID   string
+	wrapped []byte
+	// rest is the data nonce followed by the ciphertext.
+	rest []byte
+}
+
+func parseEnvelope(data []byte) (envelope, error) {
+	var env envelope
+	if !isEncrypted(data) {
+		return env, fmt.Errorf("report is not encrypted")
+	}
+	if version := data[len(encryptionMagic)]; version != encryptionVersion {
+		return env, fmt.Errorf("unsupported encryption version %d", version)
+	}
+
+	rest := data[len(encryptionMagic)+1:]
+	if len(rest) < 1 || len(rest) < 1+int(rest[0])+2 {
+		return env, fmt.Errorf("encrypted report header is truncated")
+	}
+	env.keyID = string(rest[1 : 1+rest[0]])
+	rest = rest[1+rest[0]:]
+
+	n := int(binary.BigEndian.Uint16(rest))
+	if len(rest) < 2+n {
+		return env, fmt.Errorf("encrypted report header is truncated")
+	}
+	env.wrapped = rest[2 : 2+n]
+	env.rest = rest[2+n:]
+	return env, nil
+}
+
+func isEncrypted(data []byte) bool {
+	return len(data) > len(encryptionMagic) && bytes.HasPrefix(data, encryptionMagic)
+}
+
+// ReencryptResult counts what Reencrypt did with each report file.
+type ReencryptResult struct {
+	Encrypted int
+	Resealed  int
+	Unchanged int
+	Failed    int
+}
+
+// Reencrypt moves every report under the active key. Plaintext reports are
+// encrypted, and reports sealed with an older key are sealed again, so the
+// older key can be dropped from the keyring afterwards.
+func (s *Store) Reencrypt() (ReencryptResult, error) {
+	var res ReencryptResult
+	if s.keys == nil {
+		return res, fmt.Errorf("no encryption key configured")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.allSummaries()
+	if err != nil {
+		return res, err
+	}
+
+	var firstErr error
+	fail := func(file string, err error) {
+		res.Failed++
+		if firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	for _, e := range entries {
+		path := filepath.Join(s.baseDir, e.File)
+		if !strings.HasSuffix(e.File, encryptedExt) {
+			report, err := readReportFile(path, s.keys)
+			if err != nil {
+				fail(e.File, err)
+				continue
+			}
+			// Saving removes the plaintext file.
+			if _, err := s.saveLocked(report); err != nil {
+				fail(e.File, err)
+				continue
+			}
+			res.Encrypted++
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fail(e.File, err)
+			continue
+		}
+		out, changed, err := s.keys.reseal(data, reportFileID(e.File))
+		if err != nil {
+			fail(e.File, err)
+			continue
+		}
+		if !changed {
+			res.Unchanged++
+			continue
+		}
+		if err := writeFileAtomic(path, out); err != nil {
+			fail(e.File, err)
+			continue
+		}
+		res.Resealed++
+	}
+
+	return res, firstErr
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".reencrypt_*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = replaceFile(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+	}
+	return err
+}
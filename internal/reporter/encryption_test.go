@@ -0,0 +1,173 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, encryptionKeySize)
+}
+
+func TestKeyring_SealOpenAndReseal(t *testing.T) {
+	old, err := NewKeyring(map[string][]byte{"2025": testKey(1)}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := old.seal([]byte("crash logs"), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, []byte("crash logs")) {
+		t.Fatal("sealed data contains the plaintext")
+	}
+	if _, err := old.open(sealed, "def"); err == nil {
+		t.Error("open() as another report = nil error")
+	}
+
+	rotated, err := NewKeyring(map[string][]byte{"2025": testKey(1), "2026": testKey(2)}, "2026")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := rotated.open(sealed, "abc"); err != nil || string(got) != "crash logs" {
+		t.Fatalf("open() with an older key = %q, %v", got, err)
+	}
+
+	resealed, changed, err := rotated.reseal(sealed, "abc")
+	if err != nil || !changed {
+		t.Fatalf("reseal() = %v, %v", changed, err)
+	}
+	current, _ := NewKeyring(map[string][]byte{"2026": testKey(2)}, "")
+	if got, err := current.open(resealed, "abc"); err != nil || string(got) != "crash logs" {
+		t.Errorf("open() after reseal = %q, %v", got, err)
+	}
+	if _, changed, _ := current.reseal(resealed, "abc"); changed {
+		t.Error("reseal() with the active key changed the report")
+	}
+
+	if _, err := current.open(sealed, "abc"); err == nil || !strings.Contains(err.Error(), "unknown key") {
+		t.Errorf("open() with a missing key error = %v", err)
+	}
+	sealed[len(sealed)-1] ^= 0xff
+	if _, err := old.open(sealed, "abc"); err == nil {
+		t.Error("open() of tampered data = nil error")
+	}
+}
+
+func TestKeyring_RejectsOtherVersions(t *testing.T) {
+	keys, _ := NewKeyring(map[string][]byte{"k": testKey(1)}, "")
+	sealed, err := keys.seal([]byte("crash logs"), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An envelope relabelled with another version is refused, so the key
+	// and report ID binding cannot be stripped.
+	sealed[len(encryptionMagic)] = 1
+	if _, err := keys.open(sealed, "abc"); err == nil || !strings.Contains(err.Error(), "unsupported encryption version") {
+		t.Errorf("open() of a version 1 envelope error = %v", err)
+	}
+	if _, _, err := keys.reseal(sealed, "abc"); err == nil {
+		t.Error("reseal() of a version 1 envelope = nil error")
+	}
+}
+
+func TestNewKeyring_Validation(t *testing.T) {
+	if _, err := NewKeyring(map[string][]byte{"a": testKey(1), "b": testKey(2)}, ""); err == nil {
+		t.Error("several keys without an active one = nil error")
+	}
+	if _, err := NewKeyring(map[string][]byte{"a": testKey(1)}, "b"); err == nil {
+		t.Error("unknown active key = nil error")
+	}
+	if _, err := NewKeyring(map[string][]byte{"a": []byte("short")}, ""); err == nil {
+		t.Error("short key = nil error")
+	}
+}
+
+func TestLoadKeyring_Dir(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "old"), []byte(base64.StdEncoding.EncodeToString(testKey(1))+"\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "new"), testKey(2), 0600)
+	os.Mkdir(filepath.Join(dir, "..data"), 0700)
+
+	keys, err := LoadKeyring(dir, "new")
+	if err != nil {
+		t.Fatalf("LoadKeyring() error = %v", err)
+	}
+	if ids := keys.KeyIDs(); len(ids) != 2 || ids[0] != "new" || ids[1] != "old" {
+		t.Errorf("KeyIDs() = %v", ids)
+	}
+}
+
+func TestStore_Encryption(t *testing.T) {
+	dir := t.TempDir()
+	keys, _ := NewKeyring(map[string][]byte{"k1": testKey(1)}, "")
+
+	plain, _ := NewStore(dir)
+	legacy := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "legacy"})
+	if err := plain.Save(legacy); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewStore(dir, WithCompression("gzip"), WithEncryption(keys))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+	report.SetLogs([]string{"panic: secret token abc123"})
+	if err := store.Save(report); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, report.ID+"_*"))
+	if len(files) != 1 || !strings.HasSuffix(files[0], ".json.gz.enc") {
+		t.Fatalf("report files = %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	if !isEncrypted(data) {
+		t.Error("report file is not encrypted")
+	}
+
+	loaded, err := store.Load(report.ID)
+	if err != nil || len(loaded.Logs) != 1 || loaded.Logs[0] != report.Logs[0] {
+		t.Fatalf("Load() = %v, %v", loaded, err)
+	}
+	if reports, err := store.List(); err != nil || len(reports) != 2 {
+		t.Errorf("List() = %d reports, %v; want plaintext and encrypted", len(reports), err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, searchIndexFileName)); err == nil {
+		t.Error("search index written to disk with encryption on")
+	}
+	if _, err := plain.Load(report.ID); err == nil {
+		t.Error("Load() without keys = nil error")
+	}
+
+	// Rotate: reports written with k1 are rewrapped, plaintext ones encrypted.
+	rotated, _ := NewKeyring(map[string][]byte{"k1": testKey(1), "k2": testKey(2)}, "k2")
+	store, _ = NewStore(dir, WithEncryption(rotated))
+	res, err := store.Reencrypt()
+	if err != nil || res.Encrypted != 1 || res.Resealed != 1 {
+		t.Fatalf("Reencrypt() = %+v, %v", res, err)
+	}
+	if res, _ := store.Reencrypt(); res.Unchanged != 2 {
+		t.Errorf("second Reencrypt() = %+v", res)
+	}
+
+	only, _ := NewKeyring(map[string][]byte{"k2": testKey(2)}, "")
+	store, _ = NewStore(dir, WithEncryption(only))
+	for _, id := range []string{legacy.ID, report.ID} {
+		if _, err := store.Load(id); err != nil {
+			t.Errorf("Load(%s) with only the new key error = %v", id, err)
+		}
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, legacy.ID+"_*")); len(files) != 1 || !strings.HasSuffix(files[0], ".enc") {
+		t.Errorf("legacy report files = %v", files)
+	}
+}
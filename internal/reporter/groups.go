@@ -0,0 +1,20 @@
+package reporter
+
+import "github.com/kadirbelkuyu/kubecrsh/internal/domain"
+
+type Grouper interface {
+	Groups() ([]domain.CrashGroup, error)
+}
+
+func ListGroups(s Storage) ([]domain.CrashGroup, error) {
+	if g, ok := s.(Grouper); ok {
+		return g.Groups()
+	}
+
+	reports, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.GroupReports(reports), nil
+}
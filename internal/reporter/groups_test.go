@@ -0,0 +1,126 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+func TestListGroups_FallsBackToList(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	base := time.Now().Add(-time.Hour)
+	for i, pod := range []string{"api-7d9f8b6c5-abcde", "api-7d9f8b6c5-fghij", "worker-5c6d7e8f9-klmno"} {
+		report := domain.NewForensicReport(domain.PodCrash{
+			Namespace:     "payments",
+			PodName:       pod,
+			ContainerName: "app",
+			Reason:        "Error",
+			ExitCode:      1,
+		})
+		report.CollectedAt = base.Add(time.Duration(i) * time.Minute)
+		report.SetLogs([]string{"panic: connection refused to 10.0.0." + string(rune('1'+i)) + ":5432"})
+		report.UpdateFingerprint()
+		if err := store.Save(report); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	groups, err := ListGroups(store)
+	if err != nil {
+		t.Fatalf("ListGroups() error = %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("len(groups) = %d, want 2", len(groups))
+	}
+	if groups[0].Workload != "worker" || groups[0].Count != 1 {
+		t.Errorf("groups[0] = %+v, want worker with 1 report", groups[0])
+	}
+	if groups[1].Workload != "api" || groups[1].Count != 2 {
+		t.Errorf("groups[1] = %+v, want api with 2 reports", groups[1])
+	}
+}
+
+func TestElasticStore_Groups(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		query = string(body)
+
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"aggregations": map[string]any{
+				"groups": map[string]any{
+					"buckets": []any{
+						map[string]any{
+							"key":        map[string]any{"fingerprint": "abc123"},
+							"doc_count":  7,
+							"first_seen": map[string]any{"value": 1700000000000},
+							"last_seen":  map[string]any{"value": 1700003600000},
+							"latest": map[string]any{
+								"hits": map[string]any{
+									"hits": []any{
+										map[string]any{"_source": map[string]any{
+											"id":          "r-7",
+											"fingerprint": "abc123",
+											"signature":   "panic: boom",
+											"crash": map[string]any{
+												"namespace":      "payments",
+												"pod_name":       "api-7d9f8b6c5-abcde",
+												"workload":       "Deployment/api",
+												"container_name": "app",
+												"reason":         "Error",
+												"exit_code":      2,
+											},
+										}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{server.URL}})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	store := &ElasticStore{client: client, indexName: "kubecrsh-reports"}
+
+	groups, err := store.Groups()
+	if err != nil {
+		t.Fatalf("Groups() error = %v", err)
+	}
+
+	if !strings.Contains(query, `"composite"`) || !strings.Contains(query, `"field":"fingerprint"`) {
+		t.Errorf("query does not page through fingerprints: %s", query)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("len(groups) = %d, want 1", len(groups))
+	}
+
+	g := groups[0]
+	if g.Fingerprint != "abc123" || g.Count != 7 || g.LatestReportID != "r-7" {
+		t.Errorf("group = %+v", g)
+	}
+	if g.Workload != "Deployment/api" || g.Signature != "panic: boom" || g.ExitCode != 2 {
+		t.Errorf("group details = %+v", g)
+	}
+	if got := g.LastSeen.Sub(g.FirstSeen); got != time.Hour {
+		t.Errorf("LastSeen - FirstSeen = %v, want 1h", got)
+	}
+}
@@ -0,0 +1,381 @@
+package reporter
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	"github.com/kadirbelkuyu/kubecrsh/pkg/schema"
+)
+
+// Import outcomes of a single report.
+const (
+	ImportCreated  = "imported"
+	ImportRemapped = "remapped"
+	ImportSkipped  = "skipped"
+	ImportFailed   = "failed"
+)
+
+// maxBundleEntryBytes caps each file read from a bundle.
+const maxBundleEntryBytes = 256 << 20
+
+var bundleExts = []string{".tar.gz", ".tgz"}
+
+type ImportResult struct {
+	Imported int            `json:"imported"`
+	Remapped int            `json:"remapped"`
+	Skipped  int            `json:"skipped"`
+	Failed   int            `json:"failed"`
+	Reports  []ImportedItem `json:"reports,omitempty"`
+}
+
+// ImportedItem is one report read by Import. ID is the ID it was saved
+// under, which differs from OriginalID when it was remapped.
+type ImportedItem struct {
+	Source     string `json:"source"`
+	ID         string `json:"id,omitempty"`
+	OriginalID string `json:"originalID,omitempty"`
+	Action     string `json:"action"`
+	Error      string `json:"error,omitempty"`
+}
+
+type importer struct {
+	cluster string
+	keys    *Keyring
+	now     func() time.Time
+	saved   func(*domain.ForensicReport) error
+}
+
+type ImportOption func(*importer)
+
+// WithSourceCluster tags imported reports with the cluster they were
+// collected in. Reports that already carry a source cluster keep it.
+func WithSourceCluster(name string) ImportOption {
+	return func(i *importer) {
+		i.cluster = strings.TrimSpace(name)
+	}
+}
+
+// WithImportKeys decrypts encrypted report files.
+func WithImportKeys(keys *Keyring) ImportOption {
+	return func(i *importer) {
+		i.keys = keys
+	}
+}
+
+// WithImportHook calls fn with every report after it is saved, so callers
+// can record it elsewhere, such as in the report ledger.
+func WithImportHook(fn func(*domain.ForensicReport) error) ImportOption {
+	return func(i *importer) {
+		i.saved = fn
+	}
+}
+
+// Import saves the reports found at paths into dst. A path is an export
+// bundle (.tar.gz), a report file (.json, .json.gz, optionally encrypted)
+// or a directory holding either, such as a file store. Reports are decoded
+// with DecodeUntrustedReport before they are saved.
+//
+// IDs are kept unless dst already holds a different report under the same
+// ID; the report is then saved under an ID derived from its source cluster
+// and original ID. Importing the same report again is skipped, so Import can
+// be rerun safely. Reports that cannot be read or are invalid are counted
+// as failed; an error is only returned when dst fails to save.
+func Import(dst Storage, paths []string, opts ...ImportOption) (ImportResult, error) {
+	i := &importer{now: time.Now}
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	var res ImportResult
+	for _, p := range paths {
+		files, err := importFiles(p)
+		if err != nil {
+			res.add(ImportedItem{Source: p, Action: ImportFailed, Error: err.Error()})
+			continue
+		}
+		for _, file := range files {
+			if err := i.importFile(dst, file, &res); err != nil {
+				return res, err
+			}
+		}
+	}
+	return res, nil
+}
+
+func (r *ImportResult) add(item ImportedItem) {
+	switch item.Action {
+	case ImportCreated:
+		r.Imported++
+	case ImportRemapped:
+		r.Remapped++
+	case ImportSkipped:
+		r.Skipped++
+	default:
+		r.Failed++
+	}
+	r.Reports = append(r.Reports, item)
+}
+
+// importFiles expands p into the bundles and report files to import.
+func importFiles(p string) ([]string, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{p}, nil
+	}
+
+	src := &Store{baseDir: p}
+	files, err := src.globAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+	for _, ext := range bundleExts {
+		matches, err := filepath.Glob(filepath.Join(p, "*"+ext))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+func (i *importer) importFile(dst Storage, file string, res *ImportResult) error {
+	item := ImportedItem{Source: file, Action: ImportFailed}
+
+	var report *domain.ForensicReport
+	var err error
+	if isBundle(file) {
+		report, err = readBundle(file)
+	} else {
+		var data []byte
+		if data, err = readFile(file, i.keys); err == nil {
+			report, err = DecodeUntrustedReport(data)
+		}
+	}
+	if err != nil {
+		item.Error = err.Error()
+		res.add(item)
+		return nil
+	}
+	item.OriginalID = report.ID
+
+	i.tag(report, file)
+
+	id, action, err := i.place(dst, report)
+	if err != nil {
+		item.Error = err.Error()
+		res.add(item)
+		return nil
+	}
+	item.ID, item.Action = id, action
+	if action == ImportSkipped {
+		res.add(item)
+		return nil
+	}
+
+	if report.Fingerprint == "" {
+		report.UpdateFingerprint()
+	}
+	if err := dst.Save(report); err != nil {
+		return fmt.Errorf("failed to save report %s from %s: %w", report.ID, file, err)
+	}
+	if i.saved != nil {
+		if err := i.saved(report); err != nil {
+			return err
+		}
+	}
+	res.add(item)
+	return nil
+}
+
+func (i *importer) tag(report *domain.ForensicReport, file string) {
+	if report.Origin == nil {
+		if i.cluster == "" {
+			return
+		}
+		report.Origin = &domain.ReportOrigin{}
+	}
+	if report.Origin.Cluster == "" {
+		report.Origin.Cluster = i.cluster
+	}
+	report.Origin.Source = filepath.Base(file)
+	report.Origin.ImportedAt = i.now().UTC()
+}
+
+// place picks the ID report is saved under, renaming it when dst holds a
+// different report with the same ID.
+func (i *importer) place(dst Storage, report *domain.ForensicReport) (string, string, error) {
+	existing, err := dst.Load(report.ID)
+	if err != nil {
+		return report.ID, ImportCreated, nil
+	}
+	if sameReport(existing, report) {
+		return report.ID, ImportSkipped, nil
+	}
+
+	original := report.ID
+	report.ID = remapID(report.SourceCluster(), original)
+	if existing, err := dst.Load(report.ID); err == nil {
+		if sameReport(existing, report) {
+			return report.ID, ImportSkipped, nil
+		}
+		return "", "", fmt.Errorf("report ID %s and its remapped ID %s are both taken", original, report.ID)
+	}
+
+	if report.Origin == nil {
+		report.Origin = &domain.ReportOrigin{ImportedAt: i.now().UTC()}
+	}
+	if report.Origin.OriginalID == "" {
+		report.Origin.OriginalID = original
+	}
+	return report.ID, ImportRemapped, nil
+}
+
+// sameReport reports whether a and b describe the same collected crash.
+func sameReport(a, b *domain.ForensicReport) bool {
+	return a.Crash.Namespace == b.Crash.Namespace &&
+		a.Crash.PodName == b.Crash.PodName &&
+		a.Crash.ContainerName == b.Crash.ContainerName &&
+		a.CollectedAt.Equal(b.CollectedAt)
+}
+
+// remapID derives a stable ID for a report whose ID is taken, so importing
+// it again finds the same ID.
+func remapID(cluster, id string) string {
+	sum := sha256.Sum256([]byte(cluster + "/" + id))
+	return hex.EncodeToString(sum[:8])
+}
+
+// DecodeUntrustedReport decodes a report received from outside, such as
+// an imported file or one sent by an agent. It is migrated to the current
+// schema, checked against the published report schema and validated, so it
+// is safe to save.
+func DecodeUntrustedReport(data []byte) (*domain.ForensicReport, error) {
+	data, err := domain.MigrateReportJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := schema.ValidateReport(data); err != nil {
+		return nil, err
+	}
+
+	var report domain.ForensicReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to decode report: %w", err)
+	}
+	if err := report.Validate(); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func isBundle(file string) bool {
+	for _, ext := range bundleExts {
+		if strings.HasSuffix(file, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// readBundle reads the report of an export bundle after checking every
+// file against the hashes in its manifest.json.
+func readBundle(file string) (*domain.ForensicReport, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	files := map[string][]byte{}
+	dir := ""
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(hdr.Name)
+		d, rest, ok := strings.Cut(name, "/")
+		if !ok || (dir != "" && d != dir) {
+			return nil, fmt.Errorf("unexpected bundle entry %s", hdr.Name)
+		}
+		dir = d
+
+		data, err := io.ReadAll(io.LimitReader(tr, maxBundleEntryBytes+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if len(data) > maxBundleEntryBytes {
+			return nil, fmt.Errorf("bundle entry %s is too large", hdr.Name)
+		}
+		files[rest] = data
+	}
+
+	raw, ok := files["manifest.json"]
+	if !ok {
+		return nil, errors.New("bundle has no manifest.json")
+	}
+	var manifest struct {
+		ReportID string `json:"reportID"`
+		Files    []struct {
+			Name   string `json:"name"`
+			Size   int    `json:"size"`
+			SHA256 string `json:"sha256"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid bundle manifest: %w", err)
+	}
+
+	listed := false
+	for _, entry := range manifest.Files {
+		data, ok := files[entry.Name]
+		if !ok {
+			return nil, fmt.Errorf("bundle is missing %s", entry.Name)
+		}
+		sum := sha256.Sum256(data)
+		if len(data) != entry.Size || hex.EncodeToString(sum[:]) != entry.SHA256 {
+			return nil, fmt.Errorf("bundle file %s does not match its manifest hash", entry.Name)
+		}
+		listed = listed || entry.Name == "report.json"
+	}
+	if !listed {
+		return nil, errors.New("bundle manifest does not list report.json")
+	}
+
+	report, err := DecodeUntrustedReport(files["report.json"])
+	if err != nil {
+		return nil, err
+	}
+	if manifest.ReportID != "" && manifest.ReportID != report.ID {
+		return nil, fmt.Errorf("bundle manifest is for report %s, not %s", manifest.ReportID, report.ID)
+	}
+	return report, nil
+}
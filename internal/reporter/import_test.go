@@ -0,0 +1,184 @@
+package reporter
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	"github.com/kadirbelkuyu/kubecrsh/internal/export"
+)
+
+func newImportReport(pod string) *domain.ForensicReport {
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "payments", PodName: pod, ContainerName: "app", Reason: "Error", ExitCode: 1})
+	report.SetLogs([]string{"panic: boom"})
+	return report
+}
+
+func writeBundle(t *testing.T, dir string, report *domain.ForensicReport) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := export.Bundle(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, export.FileName(report, export.FormatBundle))
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImport(t *testing.T) {
+	src := t.TempDir()
+	dst, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundled := newImportReport("api-0")
+	bundle := writeBundle(t, src, bundled)
+
+	plain := newImportReport("api-1")
+	data, _ := json.Marshal(plain)
+	if err := os.WriteFile(filepath.Join(src, plain.ID+".json"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "broken.json"), []byte(`{"id": "x"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A different report already stored under the bundled report's ID.
+	taken := newImportReport("worker-0")
+	taken.ID = bundled.ID
+	if err := dst.Save(taken); err != nil {
+		t.Fatal(err)
+	}
+
+	var hooked []string
+	opts := []ImportOption{
+		WithSourceCluster("prod-eu"),
+		WithImportHook(func(r *domain.ForensicReport) error {
+			hooked = append(hooked, r.ID)
+			return nil
+		}),
+	}
+	res, err := Import(dst, []string{src}, opts...)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if res.Imported != 1 || res.Remapped != 1 || res.Failed != 1 || len(hooked) != 2 {
+		t.Fatalf("Import() = %+v, hooked %v", res, hooked)
+	}
+
+	loaded, err := dst.Load(plain.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.SourceCluster() != "prod-eu" || loaded.Origin.Source != plain.ID+".json" || loaded.Fingerprint == "" {
+		t.Errorf("imported report origin = %+v", loaded.Origin)
+	}
+
+	remapped := remapID("prod-eu", bundled.ID)
+	loaded, err = dst.Load(remapped)
+	if err != nil {
+		t.Fatalf("remapped report not stored: %v", err)
+	}
+	if loaded.Origin.OriginalID != bundled.ID || loaded.Crash.PodName != "api-0" || len(loaded.Logs) != 1 {
+		t.Errorf("remapped report = %+v", loaded)
+	}
+	if existing, _ := dst.Load(bundled.ID); existing.Crash.PodName != "worker-0" {
+		t.Error("import overwrote the existing report")
+	}
+
+	// Importing again changes nothing.
+	res, err = Import(dst, []string{bundle, filepath.Join(src, plain.ID+".json")}, opts...)
+	if err != nil || res.Skipped != 2 || res.Imported+res.Remapped+res.Failed != 0 {
+		t.Errorf("second Import() = %+v, %v", res, err)
+	}
+}
+
+func TestImport_TamperedBundle(t *testing.T) {
+	src := t.TempDir()
+	report := newImportReport("api-0")
+	path := writeBundle(t, src, report)
+	if _, err := readBundle(path); err != nil {
+		t.Fatalf("readBundle() error = %v", err)
+	}
+
+	// Edit report.json but keep the original manifest.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	forEachBundleEntry(t, data, func(name string, body []byte) {
+		if strings.HasSuffix(name, "/report.json") {
+			body = bytes.Replace(body, []byte("panic: boom"), []byte("panic: edit"), 1)
+		}
+		_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(body))})
+		_, _ = tw.Write(body)
+	})
+	tw.Close()
+	gz.Close()
+
+	tampered := filepath.Join(src, "tampered.tar.gz")
+	if err := os.WriteFile(tampered, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readBundle(tampered); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("readBundle(tampered) error = %v", err)
+	}
+
+	dst, _ := NewStore(t.TempDir())
+	res, err := Import(dst, []string{tampered}, WithSourceCluster("prod-eu"))
+	if err != nil || res.Failed != 1 || res.Reports[0].Error == "" {
+		t.Errorf("Import(tampered) = %+v, %v", res, err)
+	}
+}
+
+func forEachBundleEntry(t *testing.T, data []byte, fn func(name string, body []byte)) {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(tr)
+		fn(hdr.Name, body)
+	}
+}
+
+func TestImport_RejectsInvalidReports(t *testing.T) {
+	src := t.TempDir()
+	report := newImportReport("api-0")
+	report.ID = "../../escape"
+	bundle := writeBundle(t, src, report)
+
+	data, _ := json.Marshal(report)
+	file := filepath.Join(src, "escape.json")
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	dst, _ := NewStore(t.TempDir())
+	res, err := Import(dst, []string{bundle, file})
+	if err != nil || res.Failed != 2 {
+		t.Errorf("Import() = %+v, %v", res, err)
+	}
+}
@@ -0,0 +1,329 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+const indexFileName = "index.jsonl"
+
+// summaryIndex mirrors the report files of a Store in an append-only JSON
+// lines file, so listing, querying and pruning do not have to open every
+// report. Each save appends the new summary and each delete appends a
+// tombstone; the file is compacted once stale lines outnumber live ones.
+// Other processes sharing the directory append to the same file, and any
+// report file the index does not know about is indexed on the next read.
+type summaryIndex struct {
+	mu      sync.Mutex
+	path    string
+	keys    *Keyring
+	entries map[string]indexEntry
+	offset  int64
+	lines   int
+	file    os.FileInfo
+}
+
+type indexEntry struct {
+	Deleted     bool      `json:"deleted,omitempty"`
+	ID          string    `json:"id"`
+	File        string    `json:"file,omitempty"`
+	Namespace   string    `json:"namespace,omitempty"`
+	PodName     string    `json:"pod,omitempty"`
+	Workload    string    `json:"workload,omitempty"`
+	Container   string    `json:"container,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	ExitCode    int32     `json:"exitCode,omitempty"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	Signature   string    `json:"signature,omitempty"`
+	Severity    string    `json:"severity,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	CollectedAt time.Time `json:"collectedAt"`
+	Size        int64     `json:"size,omitempty"`
+	NoLogs      bool      `json:"noLogs,omitempty"`
+	Invalid     bool      `json:"invalid,omitempty"`
+}
+
+func newSummaryIndex(baseDir string, keys *Keyring) *summaryIndex {
+	return &summaryIndex{path: filepath.Join(baseDir, indexFileName), keys: keys}
+}
+
+func summarize(report *domain.ForensicReport, file string, size int64) indexEntry {
+	return indexEntry{
+		ID:          report.ID,
+		File:        file,
+		Namespace:   report.Crash.Namespace,
+		PodName:     report.Crash.PodName,
+		Workload:    report.Crash.WorkloadName(),
+		Container:   report.Crash.ContainerName,
+		Reason:      report.Crash.Reason,
+		ExitCode:    report.Crash.ExitCode,
+		Fingerprint: report.GroupKey(),
+		Signature:   report.GroupSignature(),
+		Severity:    report.SeverityLevel(),
+		Status:      report.TriageStatus(),
+		CollectedAt: report.CollectedAt,
+		Size:        size,
+		NoLogs:      !hasLogs(report),
+	}
+}
+
+// stub rebuilds as much of the report as the summary holds, enough for
+// Query matching and crash grouping.
+func (e indexEntry) stub() *domain.ForensicReport {
+	return &domain.ForensicReport{
+		ID:          e.ID,
+		Fingerprint: e.Fingerprint,
+		Signature:   e.Signature,
+		Crash: domain.PodCrash{
+			Namespace:     e.Namespace,
+			PodName:       e.PodName,
+			Workload:      e.Workload,
+			ContainerName: e.Container,
+			Reason:        e.Reason,
+			ExitCode:      e.ExitCode,
+		},
+		Severity:    &domain.Severity{Level: e.Severity},
+		Triage:      &domain.Triage{Status: e.Status},
+		CollectedAt: e.CollectedAt,
+	}
+}
+
+// sync brings the in-memory index up to date with the index file and the
+// report files on disk and returns the live entries.
+func (x *summaryIndex) sync(files []string) ([]indexEntry, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if err := x.readLocked(); err != nil {
+		if err := x.rebuildLocked(files); err != nil {
+			return nil, err
+		}
+	}
+
+	onDisk := make(map[string]bool, len(files))
+	for _, file := range files {
+		onDisk[filepath.Base(file)] = true
+	}
+
+	indexed := make(map[string]bool, len(x.entries))
+	for id, e := range x.entries {
+		if !onDisk[e.File] {
+			if err := x.appendLocked(indexEntry{ID: id, Deleted: true}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		indexed[e.File] = true
+	}
+
+	for _, file := range files {
+		if indexed[filepath.Base(file)] {
+			continue
+		}
+		if err := x.appendLocked(summarizeFile(file, x.keys)); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make([]indexEntry, 0, len(x.entries))
+	for _, e := range x.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].File < entries[j].File })
+
+	return entries, x.compactLocked()
+}
+
+func summarizeFile(path string, keys *Keyring) indexEntry {
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+
+	report, err := readReportFile(path, keys)
+	if err == nil {
+		return summarize(report, filepath.Base(path), size)
+	}
+
+	// Keep unreadable files in the index so that Prune still removes them
+	// once they age out.
+	e := indexEntry{ID: "invalid:" + filepath.Base(path), File: filepath.Base(path), Size: size, Invalid: true}
+	if at, err := readCollectedAt(path, keys); err == nil {
+		e.CollectedAt = at
+	} else if info, err := os.Stat(path); err == nil {
+		e.CollectedAt = info.ModTime()
+	}
+	return e
+}
+
+func (x *summaryIndex) put(e indexEntry) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if err := x.readLocked(); err != nil && !os.IsNotExist(err) {
+		// A corrupt index is rebuilt from the report files on the next
+		// read, which picks this report up as well.
+		return nil
+	}
+	return x.appendLocked(e)
+}
+
+func (x *summaryIndex) remove(id string) error {
+	return x.put(indexEntry{ID: id, Deleted: true})
+}
+
+// readLocked reads whatever other writers appended since the last call.
+func (x *summaryIndex) readLocked() error {
+	info, err := os.Stat(x.path)
+	if err != nil {
+		x.entries = nil
+		return err
+	}
+
+	// A compaction in another process replaces the file; start over.
+	if x.entries == nil || x.file == nil || !os.SameFile(x.file, info) || info.Size() < x.offset {
+		x.entries = make(map[string]indexEntry)
+		x.offset, x.lines = 0, 0
+	}
+	x.file = info
+
+	if info.Size() == x.offset {
+		return nil
+	}
+
+	f, err := os.Open(x.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(x.offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				x.entries = nil
+				return fmt.Errorf("index has a truncated line")
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var e indexEntry
+		if err := json.Unmarshal(line, &e); err != nil || e.ID == "" {
+			x.entries = nil
+			return fmt.Errorf("index line %d is corrupt", x.lines+1)
+		}
+		x.apply(e)
+		x.offset += int64(len(line))
+		x.lines++
+	}
+}
+
+func (x *summaryIndex) apply(e indexEntry) {
+	if e.Deleted {
+		delete(x.entries, e.ID)
+		return
+	}
+	x.entries[e.ID] = e
+}
+
+func (x *summaryIndex) appendLocked(e indexEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode index entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	f, err := os.OpenFile(x.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open index: %w", err)
+	}
+	defer f.Close()
+
+	// One write per line keeps concurrent appenders from interleaving.
+	if _, err := f.Write(line); err != nil {
+		return fmt.Errorf("failed to append to index: %w", err)
+	}
+
+	if err := x.readLocked(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (x *summaryIndex) compactLocked() error {
+	if x.lines < 64 || x.lines <= 2*len(x.entries) {
+		return nil
+	}
+
+	entries := make([]indexEntry, 0, len(x.entries))
+	for _, e := range x.entries {
+		entries = append(entries, e)
+	}
+	return x.writeLocked(entries)
+}
+
+func (x *summaryIndex) rebuildLocked(files []string) error {
+	entries := make([]indexEntry, 0, len(files))
+	for _, file := range files {
+		entries = append(entries, summarizeFile(file, x.keys))
+	}
+	return x.writeLocked(entries)
+}
+
+func (x *summaryIndex) writeLocked(entries []indexEntry) error {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].File < entries[j].File })
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("failed to encode index entry: %w", err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(x.path), strings.TrimSuffix(indexFileName, ".jsonl")+"_*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to sync index: %w", err)
+	}
+	tmp.Close()
+
+	if err := replaceFile(tmpPath, x.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace index: %w", err)
+	}
+
+	x.entries = nil
+	x.file = nil
+	return x.readLocked()
+}
@@ -0,0 +1,175 @@
+package reporter
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+func indexLines(t *testing.T, dir string) int {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join(dir, indexFileName))
+	if err != nil {
+		t.Fatalf("open index: %v", err)
+	}
+	defer f.Close()
+
+	n := 0
+	for sc := bufio.NewScanner(f); sc.Scan(); {
+		n++
+	}
+	return n
+}
+
+func saveFixtures(t *testing.T, store *Store) {
+	t.Helper()
+	for _, r := range queryFixtures()[:3] {
+		if err := store.Save(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStore_QueryReadsOnlyThePage(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saveFixtures(t, store)
+
+	if n := indexLines(t, dir); n != 3 {
+		t.Fatalf("index has %d lines after 3 saves, want 3", n)
+	}
+
+	// Break the oldest report. A page that does not include it must not
+	// need to read it, and the total still comes from the index.
+	files, _ := filepath.Glob(filepath.Join(dir, "r00_*.json"))
+	if err := os.WriteFile(files[0], []byte("{broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := store.Query(Query{Limit: 2})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if ids(res.Reports) != "r02,r01" || res.Total != 3 || res.NextCursor == "" {
+		t.Errorf("Query() = %s (total %d), want r02,r01 of 3", ids(res.Reports), res.Total)
+	}
+
+	groups, err := store.Groups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 3 {
+		t.Errorf("Groups() = %d groups, want 3", len(groups))
+	}
+}
+
+func TestStore_IndexRebuiltWhenCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir, WithCompression("gzip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	saveFixtures(t, store)
+
+	if err := os.WriteFile(filepath.Join(dir, indexFileName), []byte("{\"id\":\"r00\"}\nnot json\n{\"id\":"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewStore(dir, WithCompression("gzip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reports, err := reopened.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(reports) != 3 {
+		t.Errorf("List() after corruption = %d reports, want 3", len(reports))
+	}
+	if n := indexLines(t, dir); n != 3 {
+		t.Errorf("rebuilt index has %d lines, want 3", n)
+	}
+}
+
+func TestStore_IndexFollowsOtherWritersAndPrune(t *testing.T) {
+	dir := t.TempDir()
+	daemon, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tui, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "old"})
+	old.CollectedAt = time.Now().Add(-48 * time.Hour)
+	fresh := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "fresh"})
+	for _, r := range []*domain.ForensicReport{old, fresh} {
+		if err := daemon.Save(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if res, _ := tui.Query(Query{}); res.Total != 2 {
+		t.Fatalf("second store sees %d reports, want 2", res.Total)
+	}
+
+	// A report copied in by hand is indexed on the next read.
+	copied := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "copied"})
+	other, _ := NewStore(t.TempDir())
+	if err := other.Save(copied); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(other.baseDir, copied.ID+"_*.json"))
+	data, _ := os.ReadFile(files[0])
+	if err := os.WriteFile(filepath.Join(dir, filepath.Base(files[0])), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := daemon.Prune(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if res.Deleted != 1 || res.Kept != 2 {
+		t.Errorf("Prune() = %+v, want 1 deleted and 2 kept", res)
+	}
+
+	after, err := tui.Query(Query{Sort: SortOldest})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Total != 2 || after.Reports[0].ID == old.ID {
+		t.Errorf("second store after prune = %s, want fresh and copied", ids(after.Reports))
+	}
+}
+
+func TestStore_IndexCompacts(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+	for i := 0; i < 100; i++ {
+		if err := store.Save(report); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.List(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := indexLines(t, dir); n != 1 {
+		t.Errorf("index has %d lines after compaction, want 1", n)
+	}
+}
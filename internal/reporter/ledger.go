@@ -0,0 +1,426 @@
+package reporter
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+const LedgerFileName = "ledger.jsonl"
+
+// Report integrity statuses.
+const (
+	IntegrityIntact     = "intact"
+	IntegrityModified   = "modified"
+	IntegrityUnrecorded = "unrecorded"
+)
+
+// LedgerSuperseded marks an entry that replaces the recorded digest of a
+// report, for a reason such as LedgerReasonStripped.
+const LedgerSuperseded = "superseded"
+
+// LedgerReasonStripped is the reason recorded when retention stripped the
+// logs of a report.
+const LedgerReasonStripped = "retention-strip"
+
+// ledgerReasons are the reasons a report's content may change after it was
+// recorded.
+var ledgerReasons = map[string]bool{
+	LedgerReasonStripped: true,
+}
+
+// LedgerEntry records the content digest of a saved report. Each entry
+// carries the hash of the one before it, so editing, reordering or removing
+// an entry breaks the chain from that point on. An entry that changes the
+// digest of a recorded report is a LedgerSuperseded event with a Reason.
+type LedgerEntry struct {
+	Seq       int64     `json:"seq"`
+	Time      time.Time `json:"time"`
+	ReportID  string    `json:"report_id"`
+	Digest    string    `json:"digest"`
+	Event     string    `json:"event,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Prev      string    `json:"prev"`
+	Hash      string    `json:"hash"`
+	KeyID     string    `json:"key_id,omitempty"`
+	Signature string    `json:"signature,omitempty"`
+}
+
+func (e LedgerEntry) computeHash() string {
+	fields := []string{
+		strconv.FormatInt(e.Seq, 10),
+		e.Time.UTC().Format(time.RFC3339Nano),
+		e.ReportID,
+		e.Digest,
+		e.Prev,
+	}
+	if e.Event != "" || e.Reason != "" {
+		fields = append(fields, e.Event, e.Reason)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\n")))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// sanctioned reports whether e may change the digest recorded before it.
+func (e LedgerEntry) sanctioned() bool {
+	return e.Event == LedgerSuperseded && ledgerReasons[e.Reason]
+}
+
+// LedgerStatus is the result of checking the whole ledger.
+type LedgerStatus struct {
+	Entries    int      `json:"entries"`
+	Signed     int      `json:"signed"`
+	Unverified int      `json:"unverified"`
+	Head       string   `json:"head,omitempty"`
+	Problems   []string `json:"problems,omitempty"`
+}
+
+func (s LedgerStatus) Intact() bool {
+	return len(s.Problems) == 0
+}
+
+// ReportIntegrity compares a stored report with its latest ledger entry.
+// Reason is set when that entry superseded an earlier digest.
+type ReportIntegrity struct {
+	ID             string    `json:"id"`
+	Status         string    `json:"status"`
+	Digest         string    `json:"digest"`
+	RecordedDigest string    `json:"recordedDigest,omitempty"`
+	RecordedAt     time.Time `json:"recordedAt,omitzero"`
+	Seq            int64     `json:"seq,omitempty"`
+	Reason         string    `json:"reason,omitempty"`
+	Signed         bool      `json:"signed"`
+	LedgerIntact   bool      `json:"ledgerIntact"`
+}
+
+// Ledger is an append-only, hash-chained log of the digests of saved
+// reports, kept as JSON lines. With a signing key every entry is signed
+// with ed25519, which also stops an attacker from rewriting the chain from
+// the start.
+type Ledger struct {
+	path       string
+	signer     ed25519.PrivateKey
+	keyID      string
+	verifyKeys map[string]ed25519.PublicKey
+
+	mu     sync.Mutex
+	status LedgerStatus
+	head   LedgerEntry
+	latest map[string]LedgerEntry
+	// changed holds the reports whose digest changed without a sanctioned
+	// reason.
+	changed map[string]bool
+}
+
+type LedgerOption func(*Ledger)
+
+// WithSigningKey signs new entries with key. Its public key is also used to
+// verify entries.
+func WithSigningKey(key ed25519.PrivateKey) LedgerOption {
+	return func(l *Ledger) {
+		l.signer = key
+		pub := key.Public().(ed25519.PublicKey)
+		l.keyID = ledgerKeyID(pub)
+		l.verifyKeys[l.keyID] = pub
+	}
+}
+
+// WithVerifyKeys adds public keys to verify signatures with, such as the
+// keys of earlier signing keys.
+func WithVerifyKeys(keys ...ed25519.PublicKey) LedgerOption {
+	return func(l *Ledger) {
+		for _, key := range keys {
+			l.verifyKeys[ledgerKeyID(key)] = key
+		}
+	}
+}
+
+// OpenLedger reads and checks the ledger at path, which need not exist yet.
+// A broken chain is not an error: it shows in Status, and new entries are
+// chained to the last entry on disk.
+func OpenLedger(path string, opts ...LedgerOption) (*Ledger, error) {
+	l := &Ledger{path: path, verifyKeys: make(map[string]ed25519.PublicKey)}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create ledger directory: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read ledger: %w", err)
+	}
+	entries, status := l.check(data)
+
+	l.status = status
+	l.latest = make(map[string]LedgerEntry)
+	l.changed = make(map[string]bool)
+	for _, e := range entries {
+		if prev, ok := l.latest[e.ReportID]; ok && prev.Digest != e.Digest && !e.sanctioned() {
+			l.changed[e.ReportID] = true
+		}
+		l.latest[e.ReportID] = e
+		l.head = e
+	}
+	return l, nil
+}
+
+// Record appends the digest of report to the ledger, unless its latest
+// entry already has that digest. A report recorded with a different digest
+// is refused; use Supersede when its content changed for a known reason.
+func (l *Ledger) Record(report *domain.ForensicReport) (LedgerEntry, error) {
+	return l.append(report, "")
+}
+
+// Supersede records the new digest of a report whose content changed for
+// reason, such as LedgerReasonStripped.
+func (l *Ledger) Supersede(report *domain.ForensicReport, reason string) (LedgerEntry, error) {
+	if !ledgerReasons[reason] {
+		return LedgerEntry{}, fmt.Errorf("invalid ledger reason %q", reason)
+	}
+	return l.append(report, reason)
+}
+
+func (l *Ledger) append(report *domain.ForensicReport, reason string) (LedgerEntry, error) {
+	digest, err := report.ContentDigest()
+	if err != nil {
+		return LedgerEntry{}, fmt.Errorf("failed to hash report: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	prev, recorded := l.latest[report.ID]
+	if recorded && prev.Digest == digest {
+		return prev, nil
+	}
+	if recorded && reason == "" {
+		return LedgerEntry{}, fmt.Errorf("report %s is already recorded with a different digest", report.ID)
+	}
+
+	e := LedgerEntry{
+		Seq:      l.head.Seq + 1,
+		Time:     time.Now().UTC().Round(0),
+		ReportID: report.ID,
+		Digest:   digest,
+		Prev:     l.head.Hash,
+	}
+	if recorded {
+		e.Event, e.Reason = LedgerSuperseded, reason
+	}
+	e.Hash = e.computeHash()
+	if l.signer != nil {
+		e.KeyID = l.keyID
+		e.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(l.signer, []byte(e.Hash)))
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return LedgerEntry{}, err
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return LedgerEntry{}, fmt.Errorf("failed to open ledger: %w", err)
+	}
+	_, err = f.Write(append(line, '\n'))
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return LedgerEntry{}, fmt.Errorf("failed to append to ledger: %w", err)
+	}
+
+	l.head = e
+	l.latest[e.ReportID] = e
+	l.status.Entries++
+	l.status.Head = e.Hash
+	if e.Signature != "" {
+		l.status.Signed++
+	}
+	return e, nil
+}
+
+// Status returns the result of checking the ledger when it was opened,
+// updated with the entries recorded since.
+func (l *Ledger) Status() LedgerStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	status := l.status
+	status.Problems = append([]string(nil), l.status.Problems...)
+	return status
+}
+
+// VerifyReport compares the digest of report with the latest ledger entry
+// for it. A report whose recorded digest ever changed without a sanctioned
+// reason is modified even when it matches its latest entry.
+func (l *Ledger) VerifyReport(report *domain.ForensicReport) (ReportIntegrity, error) {
+	res := ReportIntegrity{ID: report.ID}
+
+	digest, err := report.ContentDigest()
+	if err != nil {
+		return res, fmt.Errorf("failed to hash report: %w", err)
+	}
+	res.Digest = digest
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	res.LedgerIntact = l.status.Intact()
+	e, ok := l.latest[report.ID]
+	if !ok {
+		res.Status = IntegrityUnrecorded
+		return res, nil
+	}
+
+	res.RecordedDigest = e.Digest
+	res.RecordedAt = e.Time
+	res.Seq = e.Seq
+	res.Reason = e.Reason
+	res.Signed = e.Signature != ""
+	res.Status = IntegrityIntact
+	if e.Digest != digest || l.changed[report.ID] {
+		res.Status = IntegrityModified
+	}
+	return res, nil
+}
+
+// check parses ledger data and verifies the chain and any signatures it
+// can. Once an entry is signed, unsigned entries after it are reported, so
+// signing cannot be silently turned off by whoever edits the file.
+func (l *Ledger) check(data []byte) ([]LedgerEntry, LedgerStatus) {
+	var status LedgerStatus
+	var entries []LedgerEntry
+	var prev LedgerEntry
+	signing := false
+
+	problem := func(format string, args ...any) {
+		status.Problems = append(status.Problems, fmt.Sprintf(format, args...))
+	}
+
+	for n, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var e LedgerEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			problem("line %d: unreadable entry: %v", n+1, err)
+			continue
+		}
+
+		if e.Seq != prev.Seq+1 {
+			problem("entry %d: expected sequence %d", e.Seq, prev.Seq+1)
+		}
+		if e.Prev != prev.Hash {
+			problem("entry %d: does not link to the entry before it", e.Seq)
+		}
+		if e.Hash != e.computeHash() {
+			problem("entry %d: hash does not match its content", e.Seq)
+		}
+
+		switch {
+		case e.Signature != "":
+			signing = true
+			status.Signed++
+			key, ok := l.verifyKeys[e.KeyID]
+			if !ok {
+				status.Unverified++
+				break
+			}
+			sig, err := base64.StdEncoding.DecodeString(e.Signature)
+			if err != nil || !ed25519.Verify(key, []byte(e.Hash), sig) {
+				problem("entry %d: invalid signature", e.Seq)
+			}
+		case signing:
+			problem("entry %d: not signed", e.Seq)
+		}
+
+		entries = append(entries, e)
+		prev = e
+	}
+
+	status.Entries = len(entries)
+	status.Head = prev.Hash
+	return entries, status
+}
+
+func ledgerKeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ParseSigningKey reads an ed25519 private key from a PKCS #8 PEM block, as
+// written by openssl genpkey -algorithm ed25519, or from the base64 or hex
+// encoding of its 32-byte seed.
+func ParseSigningKey(data []byte) (ed25519.PrivateKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		priv, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("not an ed25519 private key")
+		}
+		return priv, nil
+	}
+
+	seed, err := decodeKeyText(data, ed25519.SeedSize)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// ParseVerifyKey reads an ed25519 public key from a PKIX PEM block or from
+// the base64 or hex encoding of its 32 bytes.
+func ParseVerifyKey(data []byte) (ed25519.PublicKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("not an ed25519 public key")
+		}
+		return pub, nil
+	}
+
+	key, err := decodeKeyText(data, ed25519.PublicKeySize)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+func decodeKeyText(data []byte, size int) ([]byte, error) {
+	text := strings.TrimSpace(string(data))
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == size {
+		return key, nil
+	}
+	if key, err := hex.DecodeString(text); err == nil && len(key) == size {
+		return key, nil
+	}
+	return nil, fmt.Errorf("want a PEM key or %d bytes, base64 or hex encoded", size)
+}
@@ -1,53 +1,805 @@
 package reporter
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var _ Storage = (*MultiStore)(nil)
+var _ Querier = (*MultiStore)(nil)
+var _ Pruner = (*MultiStore)(nil)
+var _ PolicyPruner = (*MultiStore)(nil)
+var _ Searcher = (*MultiStore)(nil)
 
+const (
+	DefaultOutboxRetryInterval = 30 * time.Second
+	DefaultOutboxMaxBackoff    = 10 * time.Minute
+)
+
+// Backend is one of the stores behind a MultiStore. A save fails when a
+// required backend cannot store the report; an optional backend that fails
+// only gets the report queued in the outbox for a later retry.
+type Backend struct {
+	Name     string
+	Store    Storage
+	Required bool
+}
+
+type MultiOption func(*MultiStore)
+
+// WithOutbox keeps failed writes in dir and replays them in the background
+// until they succeed.
+func WithOutbox(dir string) MultiOption {
+	return func(m *MultiStore) {
+		m.outboxDir = dir
+	}
+}
+
+// WithOutboxEncryption encrypts queued reports with keys, like the reports
+// of an encrypted file store.
+func WithOutboxEncryption(keys *Keyring) MultiOption {
+	return func(m *MultiStore) {
+		m.outboxKeys = keys
+	}
+}
+
+// WithOutboxRetry sets how often the outbox is replayed and the longest a
+// failing backend waits between attempts.
+func WithOutboxRetry(interval, maxBackoff time.Duration) MultiOption {
+	return func(m *MultiStore) {
+		if interval > 0 {
+			m.retryInterval = interval
+		}
+		if maxBackoff > 0 {
+			m.maxBackoff = maxBackoff
+		}
+	}
+}
+
+// WithReconcile periodically copies reports collected within window that
+// some backends are missing to those backends.
+func WithReconcile(interval, window time.Duration) MultiOption {
+	return func(m *MultiStore) {
+		m.reconcileInterval = interval
+		m.reconcileWindow = window
+	}
+}
+
+// WithTombstones records the reports retention deletes in the file at path,
+// and makes reconciliation skip them.
+func WithTombstones(path string) MultiOption {
+	return func(m *MultiStore) {
+		m.tombstonesPath = path
+	}
+}
+
+func WithMultiStoreMetrics(metrics *MultiStoreMetrics) MultiOption {
+	return func(m *MultiStore) {
+		m.metrics = metrics
+	}
+}
+
+type MultiStoreMetrics struct {
+	Up            *prometheus.GaugeVec
+	WriteFailures *prometheus.CounterVec
+	OutboxPending *prometheus.GaugeVec
+	OutboxLag     *prometheus.GaugeVec
+	Replayed      *prometheus.CounterVec
+	Reconciled    *prometheus.CounterVec
+}
+
+func NewMultiStoreMetrics() *MultiStoreMetrics {
+	return &MultiStoreMetrics{
+		Up: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "kubecrsh_storage_backend_up",
+				Help: "Whether the last write to a storage backend succeeded",
+			},
+			[]string{"backend"},
+		),
+		WriteFailures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kubecrsh_storage_write_failures_total",
+				Help: "Total number of failed report writes per storage backend",
+			},
+			[]string{"backend"},
+		),
+		OutboxPending: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "kubecrsh_storage_outbox_pending",
+				Help: "Number of reports waiting in the outbox per storage backend",
+			},
+			[]string{"backend"},
+		),
+		OutboxLag: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "kubecrsh_storage_outbox_lag_seconds",
+				Help: "Age of the oldest report waiting in the outbox per storage backend",
+			},
+			[]string{"backend"},
+		),
+		Replayed: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kubecrsh_storage_outbox_replayed_total",
+				Help: "Total number of reports written from the outbox per storage backend",
+			},
+			[]string{"backend"},
+		),
+		Reconciled: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "kubecrsh_storage_reconciled_total",
+				Help: "Total number of missing reports copied to a storage backend by reconciliation",
+			},
+			[]string{"backend"},
+		),
+	}
+}
+
+// MultiStore writes every report to all of its backends and reads from the
+// first one that answers, in order.
 type MultiStore struct {
-	primary   Storage
-	secondary Storage
+	backends []*multiBackend
+	outbox   *outbox
+	metrics  *MultiStoreMetrics
+
+	outboxDir         string
+	outboxKeys        *Keyring
+	retryInterval     time.Duration
+	maxBackoff        time.Duration
+	reconcileInterval time.Duration
+	reconcileWindow   time.Duration
+	tombstonesPath    string
+	tombstones        *tombstones
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type multiBackend struct {
+	Backend
+
+	// writeMu orders the writes of one report to the backend, so an outbox
+	// replay cannot land after a newer direct write.
+	writeMu sync.Mutex
+
+	mu       sync.Mutex
+	failures int
+	next     time.Time
+}
+
+func NewMultiStore(backends []Backend, opts ...MultiOption) (*MultiStore, error) {
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("multi store needs at least one backend")
+	}
+
+	m := &MultiStore{
+		retryInterval: DefaultOutboxRetryInterval,
+		maxBackoff:    DefaultOutboxMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	names := make([]string, 0, len(backends))
+	for _, b := range backends {
+		if !validBackendName(b.Name) {
+			return nil, fmt.Errorf("invalid backend name %q", b.Name)
+		}
+		if contains(names, b.Name) {
+			return nil, fmt.Errorf("duplicate backend name %q", b.Name)
+		}
+		if b.Store == nil {
+			return nil, fmt.Errorf("backend %q has no store", b.Name)
+		}
+		names = append(names, b.Name)
+		m.backends = append(m.backends, &multiBackend{Backend: b})
+		if m.metrics != nil {
+			m.metrics.Up.WithLabelValues(b.Name).Set(1)
+		}
+	}
+
+	if m.outboxDir != "" {
+		o, err := newOutbox(m.outboxDir, names, m.outboxKeys)
+		if err != nil {
+			return nil, err
+		}
+		m.outbox = o
+		for _, b := range m.backends {
+			m.observe(b, time.Now())
+		}
+	}
+
+	for _, b := range m.backends {
+		if d, ok := b.Store.(dropNotifier); ok {
+			d.onDropped(func(ids []string) { m.requeueDropped(b, ids) })
+		}
+	}
+
+	if m.tombstonesPath != "" {
+		t, err := openTombstones(m.tombstonesPath)
+		if err != nil {
+			return nil, err
+		}
+		m.tombstones = t
+	}
+
+	if m.outbox != nil || m.reconcileInterval > 0 {
+		m.stop = make(chan struct{})
+		m.done = make(chan struct{})
+		go m.run()
+	}
+
+	return m, nil
+}
+
+// dropNotifier is a backend that can lose reports after Save accepted
+// them, as Elasticsearch does with bulk indexing.
+type dropNotifier interface {
+	onDropped(fn func(ids []string))
 }
 
-func NewMultiStore(primary, secondary Storage) *MultiStore {
-	return &MultiStore{
-		primary:   primary,
-		secondary: secondary,
+// requeueDropped queues the reports b accepted but could not store in the
+// outbox, reading them from the other backends.
+func (m *MultiStore) requeueDropped(b *multiBackend, ids []string) {
+	if m.outbox == nil {
+		fmt.Printf("Warning: %s lost %d reports and no outbox is configured; reconciliation copies them again: %s\n",
+			b.Name, len(ids), strings.Join(ids, ", "))
+		return
 	}
+
+	for _, id := range ids {
+		report, err := m.loadFromOthers(b, id)
+		if err == nil {
+			err = m.outbox.add(b.Name, report)
+		}
+		if err != nil {
+			fmt.Printf("Warning: failed to queue report %s for %s: %v\n", id, b.Name, err)
+		}
+	}
+	m.observe(b, time.Now())
 }
 
-func (m *MultiStore) Save(report *domain.ForensicReport) error {
-	primaryErr := m.primary.Save(report)
-	secondaryErr := m.secondary.Save(report)
+func (m *MultiStore) loadFromOthers(skip *multiBackend, id string) (*domain.ForensicReport, error) {
+	err := fmt.Errorf("report not found: %s", id)
+	for _, b := range m.backends {
+		if b == skip {
+			continue
+		}
+		var report *domain.ForensicReport
+		if report, err = b.Store.Load(id); err == nil {
+			return report, nil
+		}
+	}
+	return nil, err
+}
 
-	if primaryErr != nil && secondaryErr != nil {
-		return fmt.Errorf("both stores failed: primary: %w, secondary: %v", primaryErr, secondaryErr)
+func validBackendName(name string) bool {
+	if name == "" || strings.HasPrefix(name, ".") {
+		return false
 	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
 
-	if primaryErr != nil {
-		return fmt.Errorf("primary store failed (secondary succeeded): %w", primaryErr)
+// Close stops replaying the outbox. Pending writes stay on disk for the
+// next start.
+func (m *MultiStore) Close() {
+	if m.stop == nil {
+		return
 	}
+	m.closeOnce.Do(func() {
+		close(m.stop)
+		<-m.done
+	})
+}
+
+func (m *MultiStore) Save(report *domain.ForensicReport) error {
+	return m.saveTo(report, m.backends)
+}
 
-	if secondaryErr != nil {
-		fmt.Printf("Warning: secondary store failed: %v\n", secondaryErr)
+func (m *MultiStore) saveTo(report *domain.ForensicReport, backends []*multiBackend) error {
+	var errs []error
+	stored := 0
+	for _, b := range backends {
+		err := m.write(b, report)
+		if err == nil {
+			stored++
+			continue
+		}
+		if b.Required {
+			errs = append(errs, fmt.Errorf("%s: %w", b.Name, err))
+			continue
+		}
+		if m.outbox != nil {
+			fmt.Printf("Warning: %s store failed, queued for retry: %v\n", b.Name, err)
+		} else {
+			fmt.Printf("Warning: %s store failed: %v\n", b.Name, err)
+		}
 	}
 
+	if len(errs) > 0 {
+		return fmt.Errorf("required store failed: %w", errors.Join(errs...))
+	}
+	if stored == 0 && len(backends) > 0 {
+		return fmt.Errorf("all stores failed to save report %s", report.ID)
+	}
 	return nil
 }
 
-func (m *MultiStore) Load(id string) (*domain.ForensicReport, error) {
-	report, err := m.primary.Load(id)
+// write saves report to b and, when that fails, queues it in the outbox.
+func (m *MultiStore) write(b *multiBackend, report *domain.ForensicReport) error {
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+
+	err := b.Store.Save(report)
+	if m.metrics != nil {
+		if err != nil {
+			m.metrics.WriteFailures.WithLabelValues(b.Name).Inc()
+		}
+		m.metrics.Up.WithLabelValues(b.Name).Set(boolGauge(err == nil))
+	}
+
+	if m.outbox == nil {
+		return err
+	}
 	if err == nil {
-		return report, nil
+		// A queued copy of the report is now stale.
+		m.outbox.remove(b.Name, report.ID)
+	} else if qerr := m.outbox.add(b.Name, report); qerr != nil {
+		err = fmt.Errorf("%w (and queueing it failed: %v)", err, qerr)
 	}
+	m.observe(b, time.Now())
+	return err
+}
 
-	return m.secondary.Load(id)
+func (m *MultiStore) Load(id string) (*domain.ForensicReport, error) {
+	var err error
+	for _, b := range m.backends {
+		var report *domain.ForensicReport
+		if report, err = b.Store.Load(id); err == nil {
+			return report, nil
+		}
+	}
+	return nil, err
 }
 
+// List merges the reports of every backend, so a report only one of them
+// holds still shows up. The copy from the earliest backend wins.
 func (m *MultiStore) List() ([]*domain.ForensicReport, error) {
-	return m.primary.List()
+	var reports []*domain.ForensicReport
+	var firstErr error
+	seen := make(map[string]bool)
+	listed := 0
+
+	for _, b := range m.backends {
+		list, err := b.Store.List()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", b.Name, err)
+			}
+			continue
+		}
+		listed++
+		for _, r := range list {
+			if !seen[r.ID] {
+				seen[r.ID] = true
+				reports = append(reports, r)
+			}
+		}
+	}
+
+	if listed == 0 {
+		return nil, firstErr
+	}
+	if firstErr != nil {
+		fmt.Printf("Warning: listing reports: %v\n", firstErr)
+	}
+	return reports, nil
+}
+
+// Groups groups the merged reports of every backend, so a report only a
+// secondary holds is counted too.
+func (m *MultiStore) Groups() ([]domain.CrashGroup, error) {
+	if len(m.backends) == 1 {
+		return ListGroups(m.backends[0].Store)
+	}
+
+	reports, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	return domain.GroupReports(reports), nil
+}
+
+// Query runs q against every backend and pages through the merged matches,
+// so a report only a secondary holds shows up. As in List, the copy from
+// the earliest backend wins.
+func (m *MultiStore) Query(q Query) (QueryResult, error) {
+	if len(m.backends) == 1 {
+		return QueryReports(m.backends[0].Store, q)
+	}
+
+	var matched []*domain.ForensicReport
+	var firstErr error
+	seen := make(map[string]bool)
+	queried := 0
+
+	all := q
+	all.Cursor, all.Offset = "", 0
+	for _, b := range m.backends {
+		reports, err := queryAll(b.Store, all)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", b.Name, err)
+			}
+			continue
+		}
+		queried++
+		for _, r := range reports {
+			if !seen[r.ID] {
+				seen[r.ID] = true
+				matched = append(matched, r)
+			}
+		}
+	}
+
+	if queried == 0 {
+		return QueryResult{}, firstErr
+	}
+	if firstErr != nil {
+		fmt.Printf("Warning: querying reports: %v\n", firstErr)
+	}
+	return q.Apply(matched)
+}
+
+// queryAll returns every report of s that matches q, following cursors.
+func queryAll(s Storage, q Query) ([]*domain.ForensicReport, error) {
+	q.Limit = MaxQueryLimit
+	var reports []*domain.ForensicReport
+	for {
+		res, err := QueryReports(s, q)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, res.Reports...)
+		if res.NextCursor == "" || res.NextCursor == q.Cursor {
+			return reports, nil
+		}
+		q.Cursor = res.NextCursor
+	}
+}
+
+// Search prefers a backend after the first that can search on its own, as
+// Elasticsearch does, and falls back to the first.
+func (m *MultiStore) Search(q SearchQuery) (SearchResult, error) {
+	for _, b := range m.backends[1:] {
+		if _, ok := b.Store.(Searcher); !ok {
+			continue
+		}
+		res, err := SearchReports(b.Store, q)
+		if err == nil {
+			return res, nil
+		}
+		fmt.Printf("Warning: %s store search failed: %v\n", b.Name, err)
+	}
+
+	return SearchReports(m.backends[0].Store, q)
+}
+
+// Prune applies retention to each store that supports it, so reports do not
+// outlive it in any of them.
+func (m *MultiStore) Prune(retention time.Duration) (PruneResult, error) {
+	return m.PruneWithPolicy(RetentionPolicy{MaxAge: retention})
+}
+
+// PruneWithPolicy applies p to each store; those that only prune by age
+// apply MaxAge. A dry run skips stores that cannot do one. Reports deleted
+// by the quotas stay in the stores that only prune by age, so they are
+// recorded as tombstones for Reconcile not to copy them back.
+func (m *MultiStore) PruneWithPolicy(p RetentionPolicy) (PruneResult, error) {
+	res := PruneResult{Backends: make(map[string]BackendPruneResult)}
+	var firstErr error
+
+	for i, b := range m.backends {
+		if _, ok := b.Store.(PolicyPruner); !ok && p.DryRun {
+			continue
+		}
+
+		r, err := ApplyRetention(b.Store, p)
+		br := BackendPruneResult{Deleted: r.Deleted, Stripped: r.Stripped, Failed: r.Failed, BytesReclaimed: r.BytesReclaimed}
+		if err != nil {
+			br.Error = err.Error()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", b.Name, err)
+			}
+		}
+		res.Backends[b.Name] = br
+
+		if m.tombstones != nil && !p.DryRun {
+			if err := m.tombstones.add(r.Actions); err != nil {
+				fmt.Printf("Warning: failed to record pruned reports: %v\n", err)
+			}
+		}
+
+		// The other counts describe the first store.
+		if i == 0 {
+			backends := res.Backends
+			res = r
+			res.Backends = backends
+		} else {
+			res.Failed += r.Failed
+		}
+	}
+
+	return res, firstErr
+}
+
+type ReconcileResult struct {
+	// Checked is the number of distinct reports found across backends.
+	Checked int
+	// Copied counts the reports written to each backend that lacked them.
+	Copied map[string]int
+}
+
+// Reconcile copies the reports collected since the given time that some
+// backends lack from a backend that has them. Copies that fail are queued in
+// the outbox. A backend that cannot be listed is left out, and reports
+// retention deleted are not copied back.
+func (m *MultiStore) Reconcile(since time.Time) (ReconcileResult, error) {
+	res := ReconcileResult{Copied: make(map[string]int)}
+	if len(m.backends) < 2 {
+		return res, nil
+	}
+
+	if m.tombstones != nil {
+		// kubecrsh prune appends to the file too.
+		if err := m.tombstones.load(); err != nil {
+			return res, err
+		}
+		if err := m.tombstones.expire(since); err != nil {
+			fmt.Printf("Warning: failed to expire tombstones: %v\n", err)
+		}
+	}
+
+	var firstErr error
+
+	var order []string
+	holders := make(map[string][]*multiBackend)
+	var listed []*multiBackend
+
+	for _, b := range m.backends {
+		ids, err := m.reportIDs(b, since)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", b.Name, err)
+			}
+			continue
+		}
+		listed = append(listed, b)
+		for _, id := range ids {
+			if holders[id] == nil {
+				order = append(order, id)
+			}
+			holders[id] = append(holders[id], b)
+		}
+	}
+	res.Checked = len(order)
+
+	for _, id := range order {
+		has := holders[id]
+		if len(has) == len(listed) || (m.tombstones != nil && m.tombstones.has(id)) {
+			continue
+		}
+
+		report, err := has[0].Store.Load(id)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", has[0].Name, err)
+			}
+			continue
+		}
+
+		for _, b := range listed {
+			if containsBackend(has, b) {
+				continue
+			}
+			if err := m.write(b, report); err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s: %w", b.Name, err)
+				}
+				continue
+			}
+			res.Copied[b.Name]++
+			if m.metrics != nil {
+				m.metrics.Reconciled.WithLabelValues(b.Name).Inc()
+			}
+		}
+	}
+
+	return res, firstErr
+}
+
+func (m *MultiStore) reportIDs(b *multiBackend, since time.Time) ([]string, error) {
+	var ids []string
+	q := Query{Since: since, Limit: MaxQueryLimit}
+	for {
+		page, err := QueryReports(b.Store, q)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range page.Reports {
+			ids = append(ids, r.ID)
+		}
+		if page.NextCursor == "" || len(page.Reports) == 0 {
+			return ids, nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+func containsBackend(backends []*multiBackend, b *multiBackend) bool {
+	for _, c := range backends {
+		if c == b {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MultiStore) run() {
+	defer close(m.done)
+
+	var replay, reconcile <-chan time.Time
+	if m.outbox != nil {
+		ticker := time.NewTicker(m.retryInterval)
+		defer ticker.Stop()
+		replay = ticker.C
+		m.replay(time.Now())
+	}
+	if m.reconcileInterval > 0 {
+		ticker := time.NewTicker(m.reconcileInterval)
+		defer ticker.Stop()
+		reconcile = ticker.C
+	}
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-replay:
+			m.replay(now)
+		case now := <-reconcile:
+			res, err := m.Reconcile(now.Add(-m.reconcileWindow))
+			if err != nil {
+				fmt.Printf("Warning: reconciling stores: %v\n", err)
+			}
+			for name, n := range res.Copied {
+				fmt.Printf("Reconciled %d reports missing from the %s store\n", n, name)
+			}
+		}
+	}
+}
+
+// replay retries the outbox of every backend that is not backing off. A
+// backend whose first replay in a round fails is left alone until its next
+// attempt, so a store that is down is not sent every pending report.
+func (m *MultiStore) replay(now time.Time) {
+	for _, b := range m.backends {
+		b.mu.Lock()
+		due := !now.Before(b.next)
+		b.mu.Unlock()
+		if !due {
+			m.observe(b, now)
+			continue
+		}
+
+		var failed error
+		replayed := 0
+		for _, id := range m.outbox.ids(b.Name) {
+			select {
+			case <-m.stop:
+				return
+			default:
+			}
+
+			err := m.replayOne(b, id)
+			if err == nil {
+				replayed++
+				continue
+			}
+			failed = err
+			if replayed == 0 {
+				break
+			}
+		}
+
+		b.mu.Lock()
+		if failed != nil {
+			b.failures++
+			b.next = now.Add(m.retryDelay(b.failures))
+		} else {
+			b.failures = 0
+			b.next = time.Time{}
+		}
+		b.mu.Unlock()
+
+		if failed != nil {
+			fmt.Printf("Warning: replaying outbox to %s store: %v\n", b.Name, failed)
+		}
+		m.observe(b, now)
+	}
+}
+
+func (m *MultiStore) replayOne(b *multiBackend, id string) error {
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+
+	report, err := m.outbox.read(b.Name, id)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		fmt.Printf("Warning: discarding unreadable outbox entry %s/%s: %v\n", b.Name, id, err)
+		m.outbox.remove(b.Name, id)
+		return nil
+	}
+
+	if err := b.Store.Save(report); err != nil {
+		if m.metrics != nil {
+			m.metrics.WriteFailures.WithLabelValues(b.Name).Inc()
+			m.metrics.Up.WithLabelValues(b.Name).Set(0)
+		}
+		return err
+	}
+
+	m.outbox.remove(b.Name, id)
+	if m.metrics != nil {
+		m.metrics.Replayed.WithLabelValues(b.Name).Inc()
+		m.metrics.Up.WithLabelValues(b.Name).Set(1)
+	}
+	return nil
+}
+
+func (m *MultiStore) retryDelay(failures int) time.Duration {
+	delay := m.retryInterval
+	for i := 1; i < failures && delay < m.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > m.maxBackoff {
+		delay = m.maxBackoff
+	}
+	return delay
+}
+
+func (m *MultiStore) observe(b *multiBackend, now time.Time) {
+	if m.metrics == nil || m.outbox == nil {
+		return
+	}
+
+	pending, oldest := m.outbox.stats(b.Name)
+	lag := 0.0
+	if pending > 0 {
+		lag = now.Sub(oldest).Seconds()
+	}
+	m.metrics.OutboxPending.WithLabelValues(b.Name).Set(float64(pending))
+	m.metrics.OutboxLag.WithLabelValues(b.Name).Set(lag)
+}
+
+func boolGauge(ok bool) float64 {
+	if ok {
+		return 1
+	}
+	return 0
 }
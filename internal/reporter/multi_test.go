@@ -0,0 +1,345 @@
+package reporter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// flakyStorage is a plainStorage that can be made to fail, safe for use by
+// the outbox replay loop.
+type flakyStorage struct {
+	mu      sync.Mutex
+	down    bool
+	reports map[string]*domain.ForensicReport
+}
+
+func newFlakyStorage() *flakyStorage {
+	return &flakyStorage{reports: make(map[string]*domain.ForensicReport)}
+}
+
+func (f *flakyStorage) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func (f *flakyStorage) Save(r *domain.ForensicReport) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return errors.New("connection refused")
+	}
+	copied := *r
+	f.reports[r.ID] = &copied
+	return nil
+}
+
+func (f *flakyStorage) Load(id string) (*domain.ForensicReport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r, ok := f.reports[id]; ok {
+		return r, nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (f *flakyStorage) List() ([]*domain.ForensicReport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return nil, errors.New("connection refused")
+	}
+	reports := make([]*domain.ForensicReport, 0, len(f.reports))
+	for _, r := range f.reports {
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
+
+func (f *flakyStorage) has(id string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.reports[id] != nil
+}
+
+func TestMultiStore_OutboxReplaysFailedWrites(t *testing.T) {
+	dir := t.TempDir()
+	primary, secondary := newFlakyStorage(), newFlakyStorage()
+	secondary.setDown(true)
+	metrics := NewMultiStoreMetrics()
+
+	m, err := NewMultiStore([]Backend{
+		{Name: "file", Store: primary, Required: true},
+		{Name: "elasticsearch", Store: secondary},
+	}, WithOutbox(dir), WithOutboxRetry(time.Hour, 0), WithMultiStoreMetrics(metrics))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+	if err := m.Save(report); err != nil {
+		t.Fatalf("Save() with an optional backend down error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "elasticsearch", report.ID+".json")); err != nil {
+		t.Fatalf("failed write not queued: %v", err)
+	}
+	if got := testutil.ToFloat64(metrics.OutboxPending.WithLabelValues("elasticsearch")); got != 1 {
+		t.Errorf("pending = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.Up.WithLabelValues("elasticsearch")); got != 0 {
+		t.Errorf("up = %v, want 0", got)
+	}
+	m.Close()
+
+	// A new store picks up the queued write and replays it on start.
+	secondary.setDown(false)
+	m, err = NewMultiStore([]Backend{
+		{Name: "file", Store: primary, Required: true},
+		{Name: "elasticsearch", Store: secondary},
+	}, WithOutbox(dir), WithOutboxRetry(time.Hour, 0), WithMultiStoreMetrics(metrics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	replayed := metrics.Replayed.WithLabelValues("elasticsearch")
+	for deadline := time.Now().Add(2 * time.Second); testutil.ToFloat64(replayed) == 0; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("queued report was not replayed")
+		}
+	}
+	if !secondary.has(report.ID) {
+		t.Error("replayed report missing from the backend")
+	}
+	if n, _ := m.outbox.stats("elasticsearch"); n != 0 {
+		t.Errorf("outbox still holds %d reports", n)
+	}
+}
+
+func TestMultiStore_Policy(t *testing.T) {
+	primary, secondary := newFlakyStorage(), newFlakyStorage()
+	m, err := NewMultiStore([]Backend{
+		{Name: "file", Store: primary, Required: true},
+		{Name: "elasticsearch", Store: secondary},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+	primary.setDown(true)
+	if err := m.Save(report); err == nil {
+		t.Error("Save() with a required backend down = nil, want error")
+	}
+	if !secondary.has(report.ID) {
+		t.Error("optional backend should still get the report")
+	}
+
+	primary.setDown(false)
+	secondary.setDown(true)
+	if err := m.Save(report); err != nil {
+		t.Errorf("Save() with an optional backend down error = %v", err)
+	}
+
+	for _, backends := range [][]Backend{
+		nil,
+		{{Name: "a/b", Store: primary}},
+		{{Name: "file", Store: primary}, {Name: "file", Store: secondary}},
+		{{Name: "file"}},
+	} {
+		if _, err := NewMultiStore(backends); err == nil {
+			t.Errorf("NewMultiStore(%+v) = nil error", backends)
+		}
+	}
+}
+
+func TestMultiStore_DirectWriteSupersedesQueued(t *testing.T) {
+	secondary := newFlakyStorage()
+	m, err := NewMultiStore([]Backend{
+		{Name: "file", Store: newFlakyStorage(), Required: true},
+		{Name: "elasticsearch", Store: secondary},
+	}, WithOutbox(t.TempDir()), WithOutboxRetry(time.Hour, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+	secondary.setDown(true)
+	if err := m.Save(report); err != nil {
+		t.Fatal(err)
+	}
+
+	secondary.setDown(false)
+	report.AddWarning("collected again")
+	if err := m.Save(report); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := m.outbox.stats("elasticsearch"); n != 0 {
+		t.Errorf("stale copy left in the outbox: %d", n)
+	}
+}
+
+func TestMultiStore_ListMergesAndReconcile(t *testing.T) {
+	primary, secondary := newFlakyStorage(), newFlakyStorage()
+	old := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "old"})
+	old.CollectedAt = time.Now().Add(-48 * time.Hour)
+	onlyPrimary := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "a"})
+	onlySecondary := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "b"})
+	_ = primary.Save(old)
+	_ = primary.Save(onlyPrimary)
+	_ = secondary.Save(onlySecondary)
+
+	metrics := NewMultiStoreMetrics()
+	m, err := NewMultiStore([]Backend{
+		{Name: "file", Store: primary, Required: true},
+		{Name: "elasticsearch", Store: secondary},
+	}, WithMultiStoreMetrics(metrics))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reports, err := m.List()
+	if err != nil || len(reports) != 3 {
+		t.Fatalf("List() = %d reports, %v; want 3", len(reports), err)
+	}
+
+	res, err := m.Reconcile(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if res.Checked != 2 || res.Copied["file"] != 1 || res.Copied["elasticsearch"] != 1 {
+		t.Errorf("Reconcile() = %+v", res)
+	}
+	if !primary.has(onlySecondary.ID) || !secondary.has(onlyPrimary.ID) || secondary.has(old.ID) {
+		t.Error("reports not copied to the backends missing them")
+	}
+	if got := testutil.ToFloat64(metrics.Reconciled.WithLabelValues("elasticsearch")); got != 1 {
+		t.Errorf("reconciled = %v, want 1", got)
+	}
+
+	// A backend that cannot be listed is skipped rather than filled.
+	secondary.setDown(true)
+	if _, err := m.Reconcile(time.Time{}); err == nil {
+		t.Error("Reconcile() with a backend down = nil error")
+	}
+	if reports, err := m.List(); err != nil || len(reports) != 3 {
+		t.Errorf("List() with a backend down = %d reports, %v", len(reports), err)
+	}
+}
+
+func TestMultiStore_QueryAndGroupsMergeBackends(t *testing.T) {
+	primary, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondary := newFlakyStorage()
+
+	now := time.Now()
+	both := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api", ContainerName: "app", Reason: "Error"})
+	both.CollectedAt = now.Add(-time.Minute)
+	onlySecondary := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "worker", ContainerName: "app", Reason: "OOMKilled"})
+	onlySecondary.CollectedAt = now
+	if err := primary.Save(both); err != nil {
+		t.Fatal(err)
+	}
+	_ = secondary.Save(both)
+	_ = secondary.Save(onlySecondary)
+
+	m, err := NewMultiStore([]Backend{
+		{Name: "file", Store: primary, Required: true},
+		{Name: "elasticsearch", Store: secondary},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := m.Query(Query{Namespace: "default", Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Total != 2 || len(res.Reports) != 1 || res.Reports[0].ID != onlySecondary.ID || res.NextCursor == "" {
+		t.Fatalf("Query() = %+v, want the secondary's report first of 2", res)
+	}
+	res, err = m.Query(Query{Namespace: "default", Limit: 1, Cursor: res.NextCursor})
+	if err != nil || len(res.Reports) != 1 || res.Reports[0].ID != both.ID || res.NextCursor != "" {
+		t.Errorf("second page = %+v, %v", res, err)
+	}
+	if res, _ := m.Query(Query{Reason: "OOMKilled"}); res.Total != 1 {
+		t.Errorf("Query(reason) = %+v, want the secondary's report", res)
+	}
+
+	groups, err := m.Groups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || groups[0].LatestReportID != onlySecondary.ID || groups[1].Count != 1 {
+		t.Errorf("Groups() = %+v, want 2 groups of 1 report", groups)
+	}
+}
+
+func TestMultiStore_QuotaPruneIsNotReconciled(t *testing.T) {
+	dir := t.TempDir()
+	primary, err := NewStore(filepath.Join(dir, "reports"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondary := newFlakyStorage()
+	backends := []Backend{
+		{Name: "file", Store: primary, Required: true},
+		{Name: "elasticsearch", Store: secondary},
+	}
+	tombstones := filepath.Join(dir, TombstonesFileName)
+
+	m, err := NewMultiStore(backends, WithTombstones(tombstones))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api-7d9f8c6b5d-x2k4q"})
+		report.CollectedAt = time.Now().Add(-time.Duration(i) * time.Hour)
+		if err := m.Save(report); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := m.PruneWithPolicy(RetentionPolicy{MaxPerWorkload: 1})
+	if err != nil {
+		t.Fatalf("PruneWithPolicy() error = %v", err)
+	}
+	if res.Deleted != 2 || res.Backends["file"].Deleted != 2 || res.Backends["elasticsearch"].Deleted != 0 {
+		t.Errorf("PruneWithPolicy() = %+v", res)
+	}
+
+	// The tombstones outlive the store that recorded them.
+	m, err = NewMultiStore(backends, WithTombstones(tombstones))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := m.Reconcile(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if rec.Copied["file"] != 0 {
+		t.Errorf("Reconcile() copied %d pruned reports back", rec.Copied["file"])
+	}
+	if reports, _ := primary.List(); len(reports) != 1 {
+		t.Errorf("primary holds %d reports, want 1", len(reports))
+	}
+}
+
+func TestMultiStore_RetryDelay(t *testing.T) {
+	m := &MultiStore{retryInterval: 30 * time.Second, maxBackoff: 5 * time.Minute}
+	for failures, want := range map[int]time.Duration{1: 30 * time.Second, 2: time.Minute, 4: 4 * time.Minute, 10: 5 * time.Minute} {
+		if got := m.retryDelay(failures); got != want {
+			t.Errorf("retryDelay(%d) = %v, want %v", failures, got, want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+// outbox keeps the reports a backend failed to store, one file per backend
+// and report at <dir>/<backend>/<id>.json, until they are replayed. A newer
+// write of the same report replaces the pending one, so a replay never
+// sends a stale copy. Entries are encrypted when the outbox has keys.
+type outbox struct {
+	dir  string
+	keys *Keyring
+
+	mu      sync.Mutex
+	pending map[string]map[string]time.Time
+}
+
+type outboxEntry struct {
+	EnqueuedAt time.Time              `json:"enqueued_at"`
+	Report     *domain.ForensicReport `json:"report"`
+}
+
+func newOutbox(dir string, backends []string, keys *Keyring) (*outbox, error) {
+	o := &outbox{dir: dir, keys: keys, pending: make(map[string]map[string]time.Time)}
+
+	for _, backend := range backends {
+		bdir := filepath.Join(dir, backend)
+		if err := os.MkdirAll(bdir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+		}
+
+		o.pending[backend] = make(map[string]time.Time)
+		files, err := filepath.Glob(filepath.Join(bdir, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			id := strings.TrimSuffix(filepath.Base(file), ".json")
+			entry, err := o.readEntry(file)
+			if err != nil {
+				fmt.Printf("Warning: discarding unreadable outbox entry %s: %v\n", file, err)
+				_ = os.Remove(file)
+				continue
+			}
+			o.pending[backend][id] = entry.EnqueuedAt
+		}
+	}
+
+	return o, nil
+}
+
+func (o *outbox) add(backend string, report *domain.ForensicReport) error {
+	path, err := o.path(backend, report.ID)
+	if err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// Lag is measured from the first failed write, not the latest one.
+	entry := outboxEntry{EnqueuedAt: time.Now(), Report: report}
+	if at, ok := o.pending[backend][report.ID]; ok {
+		entry.EnqueuedAt = at
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox entry: %w", err)
+	}
+	if o.keys != nil {
+		if data, err = o.keys.seal(data, report.ID); err != nil {
+			return fmt.Errorf("failed to encrypt outbox entry: %w", err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".outbox_*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	tmp.Close()
+
+	if err := replaceFile(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	o.pending[backend][report.ID] = entry.EnqueuedAt
+	return nil
+}
+
+func (o *outbox) remove(backend, id string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, ok := o.pending[backend][id]; !ok {
+		return
+	}
+	path, err := o.path(backend, id)
+	if err != nil {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		fmt.Printf("Warning: failed to remove outbox entry %s: %v\n", path, err)
+		return
+	}
+	delete(o.pending[backend], id)
+}
+
+func (o *outbox) read(backend, id string) (*domain.ForensicReport, error) {
+	path, err := o.path(backend, id)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := o.readEntry(path)
+	if err != nil {
+		return nil, err
+	}
+	return entry.Report, nil
+}
+
+// ids returns the pending reports of backend, oldest first.
+func (o *outbox) ids(backend string) []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	pending := o.pending[backend]
+	ids := make([]string, 0, len(pending))
+	for id := range pending {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if !pending[ids[i]].Equal(pending[ids[j]]) {
+			return pending[ids[i]].Before(pending[ids[j]])
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// stats returns the number of pending reports of backend and when the
+// oldest of them was first queued.
+func (o *outbox) stats(backend string) (int, time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var oldest time.Time
+	for _, at := range o.pending[backend] {
+		if oldest.IsZero() || at.Before(oldest) {
+			oldest = at
+		}
+	}
+	return len(o.pending[backend]), oldest
+}
+
+func (o *outbox) path(backend, id string) (string, error) {
+	if id == "" || filepath.Base(id) != id || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid report id %q", id)
+	}
+	return filepath.Join(o.dir, backend, id+".json"), nil
+}
+
+func (o *outbox) readEntry(path string) (outboxEntry, error) {
+	var entry outboxEntry
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry, err
+	}
+	if isEncrypted(data) {
+		if o.keys == nil {
+			return entry, fmt.Errorf("outbox entry is encrypted and no encryption key is configured")
+		}
+		if data, err = o.keys.open(data, strings.TrimSuffix(filepath.Base(path), ".json")); err != nil {
+			return entry, err
+		}
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return entry, err
+	}
+	if entry.Report == nil {
+		return entry, fmt.Errorf("outbox entry has no report")
+	}
+	return entry, nil
+}
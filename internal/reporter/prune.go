@@ -3,76 +3,155 @@ package reporter
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 )
 
 type PruneResult struct {
-	Deleted int
-	Kept    int
-	Failed  int
+	Deleted        int   `json:"deleted"`
+	Kept           int   `json:"kept"`
+	Failed         int   `json:"failed"`
+	Stripped       int   `json:"stripped"`
+	BytesReclaimed int64 `json:"bytesReclaimed"`
+	// Actions lists what was deleted or stripped, or would be in a dry run.
+	Actions []PruneAction `json:"actions"`
+	// Backends counts what each backend of a MultiStore did.
+	Backends map[string]BackendPruneResult `json:"backends,omitempty"`
+}
+
+type BackendPruneResult struct {
+	Deleted        int    `json:"deleted"`
+	Stripped       int    `json:"stripped"`
+	Failed         int    `json:"failed"`
+	BytesReclaimed int64  `json:"bytesReclaimed"`
+	Error          string `json:"error,omitempty"`
+}
+
+type Pruner interface {
+	Prune(retention time.Duration) (PruneResult, error)
 }
 
 func (s *Store) Prune(retention time.Duration) (PruneResult, error) {
+	return s.PruneWithPolicy(RetentionPolicy{MaxAge: retention})
+}
+
+func (s *Store) PruneWithPolicy(p RetentionPolicy) (PruneResult, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	var res PruneResult
-	if retention <= 0 {
+	if p.IsZero() {
 		return res, nil
 	}
 
-	files, err := s.globAll()
+	entries, err := s.allSummaries()
 	if err != nil {
-		return res, fmt.Errorf("failed to list reports: %w", err)
+		return res, err
+	}
+
+	items := make([]retentionItem, 0, len(entries))
+	files := make(map[string]indexEntry, len(entries))
+	for _, e := range entries {
+		items = append(items, retentionItem{
+			ID:          e.ID,
+			Namespace:   e.Namespace,
+			Workload:    e.Workload,
+			CollectedAt: e.CollectedAt,
+			Size:        e.Size,
+			HasLogs:     !e.NoLogs,
+			Invalid:     e.Invalid,
+		})
+		files[e.ID] = e
 	}
 
 	now := time.Now()
+	actions := planRetention(items, p, now)
+	if p.DryRun {
+		res.Actions = actions
+		res.tally(len(entries))
+		return res, nil
+	}
+
 	var firstErr error
+	fail := func(err error) {
+		res.Failed++
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
 
-	for _, path := range files {
-		collectedAt, err := readCollectedAt(path)
-		if err != nil {
-			info, statErr := os.Stat(path)
-			if statErr != nil {
-				res.Failed++
-				if firstErr == nil {
-					firstErr = fmt.Errorf("failed to stat report: %w", statErr)
-				}
+	for _, a := range actions {
+		e := files[a.ID]
+		path := filepath.Join(s.baseDir, e.File)
+
+		if a.Action == RetentionStrip {
+			reclaimed, stripped, err := s.stripLocked(e, p.StripLogsAfter)
+			if err != nil {
+				fail(fmt.Errorf("failed to strip report: %w", err))
 				continue
 			}
-			collectedAt = info.ModTime()
-		}
-
-		if now.Sub(collectedAt) <= retention {
-			res.Kept++
+			if stripped {
+				a.Bytes = reclaimed
+				res.Actions = append(res.Actions, a)
+			}
 			continue
 		}
 
-		if err := os.Remove(path); err != nil {
-			res.Failed++
-			if firstErr == nil {
-				firstErr = fmt.Errorf("failed to delete report: %w", err)
-			}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			fail(fmt.Errorf("failed to delete report: %w", err))
 			continue
 		}
-
-		res.Deleted++
+		if err := s.index.remove(e.ID); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		res.Actions = append(res.Actions, a)
 	}
 
+	res.tally(len(entries))
 	return res, firstErr
 }
 
-func readCollectedAt(path string) (time.Time, error) {
+// stripLocked removes the logs of the report in e and returns the bytes
+// reclaimed. Reports without logs are only marked as such in the index, so
+// they are not read again.
+func (s *Store) stripLocked(e indexEntry, after time.Duration) (int64, bool, error) {
+	report, err := readReportFile(filepath.Join(s.baseDir, e.File), s.keys)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if !stripLogs(report, after) {
+		done := summarize(report, e.File, e.Size)
+		return 0, false, s.index.put(done)
+	}
+
+	saved, err := s.saveLocked(report)
+	if err != nil {
+		return 0, false, err
+	}
+
+	info, err := os.Stat(saved.Path)
+	if err != nil {
+		return 0, true, nil
+	}
+	return max(e.Size-info.Size(), 0), true, nil
+}
+
+func readCollectedAt(path string, keys *Keyring) (time.Time, error) {
 	var v struct {
-		CollectedAt time.Time `json:"CollectedAt"`
+		CollectedAt   time.Time `json:"collectedAt"`
+		V1CollectedAt time.Time `json:"CollectedAt"`
 	}
 
-	if err := readJSONFile(path, &v); err != nil {
+	if err := readJSONFile(path, keys, &v); err != nil {
 		return time.Time{}, err
 	}
 
 	if v.CollectedAt.IsZero() {
-		return time.Time{}, fmt.Errorf("missing CollectedAt")
+		v.CollectedAt = v.V1CollectedAt
+	}
+	if v.CollectedAt.IsZero() {
+		return time.Time{}, fmt.Errorf("missing collectedAt")
 	}
 
 	return v.CollectedAt, nil
@@ -0,0 +1,253 @@
+package reporter
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+const (
+	SortNewest = "newest"
+	SortOldest = "oldest"
+
+	DefaultQueryLimit = 200
+	MaxQueryLimit     = 1000
+)
+
+// Query selects reports by their summary fields. Zero values match
+// everything. Results are ordered by collection time, with the report ID
+// breaking ties so that cursors stay stable while new reports arrive.
+type Query struct {
+	Namespace   string
+	Workload    string
+	PodName     string
+	Reason      string
+	ExitCode    *int32
+	Fingerprint string
+	Status      string
+	Severities  []string
+	MinSeverity string
+	Since       time.Time
+	Until       time.Time
+
+	Sort   string
+	Limit  int
+	Offset int
+	Cursor string
+}
+
+type QueryResult struct {
+	Reports    []*domain.ForensicReport
+	Total      int
+	NextCursor string
+}
+
+type Querier interface {
+	Query(q Query) (QueryResult, error)
+}
+
+func QueryReports(s Storage, q Query) (QueryResult, error) {
+	if err := q.Validate(); err != nil {
+		return QueryResult{}, err
+	}
+
+	if qr, ok := s.(Querier); ok {
+		return qr.Query(q)
+	}
+
+	reports, err := s.List()
+	if err != nil {
+		return QueryResult{}, err
+	}
+
+	return q.Apply(reports)
+}
+
+func (q Query) Validate() error {
+	switch q.Sort {
+	case "", SortNewest, SortOldest:
+	default:
+		return fmt.Errorf("invalid sort %q", q.Sort)
+	}
+
+	for _, level := range q.Severities {
+		if !domain.ValidSeverity(level) {
+			return fmt.Errorf("invalid severity %q", level)
+		}
+	}
+	if q.MinSeverity != "" && !domain.ValidSeverity(q.MinSeverity) {
+		return fmt.Errorf("invalid minimum severity %q", q.MinSeverity)
+	}
+	if q.Status != "" && !domain.ValidTriageStatus(q.Status) {
+		return fmt.Errorf("invalid status %q", q.Status)
+	}
+
+	if _, _, err := decodeCursor(q.Cursor); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (q Query) Matches(r *domain.ForensicReport) bool {
+	switch {
+	case q.Namespace != "" && r.Crash.Namespace != q.Namespace,
+		q.Workload != "" && r.Crash.WorkloadName() != q.Workload,
+		q.PodName != "" && r.Crash.PodName != q.PodName,
+		q.Reason != "" && r.Crash.Reason != q.Reason,
+		q.ExitCode != nil && r.Crash.ExitCode != *q.ExitCode,
+		q.Fingerprint != "" && r.GroupKey() != q.Fingerprint,
+		q.Status != "" && r.TriageStatus() != q.Status,
+		!q.Since.IsZero() && r.CollectedAt.Before(q.Since),
+		!q.Until.IsZero() && !r.CollectedAt.Before(q.Until):
+		return false
+	}
+
+	if len(q.Severities) > 0 || q.MinSeverity != "" {
+		level := r.SeverityLevel()
+		if q.MinSeverity != "" && !domain.SeverityAtLeast(level, q.MinSeverity) {
+			return false
+		}
+		if len(q.Severities) > 0 && !contains(q.Severities, level) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Apply filters, sorts and paginates reports in memory, for stores that
+// have no index to push the query down to.
+func (q Query) Apply(reports []*domain.ForensicReport) (QueryResult, error) {
+	matched := make([]*domain.ForensicReport, 0, len(reports))
+	for _, r := range reports {
+		if q.Matches(r) {
+			matched = append(matched, r)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if q.Sort == SortOldest {
+			return reportBefore(matched[i], matched[j])
+		}
+		return reportBefore(matched[j], matched[i])
+	})
+
+	res := QueryResult{Total: len(matched)}
+
+	start := 0
+	if q.Cursor != "" {
+		at, id, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return QueryResult{}, err
+		}
+		start = sort.Search(len(matched), func(i int) bool {
+			return q.afterCursor(matched[i], at, id)
+		})
+	} else if q.Offset > 0 {
+		start = q.Offset
+	}
+	if start > len(matched) {
+		start = len(matched)
+	}
+
+	end := start + q.limit()
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	res.Reports = matched[start:end]
+	if end < len(matched) && end > start {
+		last := matched[end-1]
+		res.NextCursor = EncodeCursor(last.CollectedAt, last.ID)
+	}
+
+	return res, nil
+}
+
+func (q Query) limit() int {
+	switch {
+	case q.Limit <= 0:
+		return DefaultQueryLimit
+	case q.Limit > MaxQueryLimit:
+		return MaxQueryLimit
+	default:
+		return q.Limit
+	}
+}
+
+func (q Query) afterCursor(r *domain.ForensicReport, at time.Time, id string) bool {
+	cursor := &domain.ForensicReport{ID: id, CollectedAt: at}
+	if q.Sort == SortOldest {
+		return reportBefore(cursor, r)
+	}
+	return reportBefore(r, cursor)
+}
+
+// severityLevels expands the severity filters into the set of levels a
+// report may have, or nil when severity is not filtered.
+func (q Query) severityLevels() []string {
+	if len(q.Severities) == 0 && q.MinSeverity == "" {
+		return nil
+	}
+
+	var levels []string
+	for _, level := range []string{domain.SeverityLow, domain.SeverityMedium, domain.SeverityHigh, domain.SeverityCritical} {
+		if q.MinSeverity != "" && !domain.SeverityAtLeast(level, q.MinSeverity) {
+			continue
+		}
+		if len(q.Severities) > 0 && !contains(q.Severities, level) {
+			continue
+		}
+		levels = append(levels, level)
+	}
+	return levels
+}
+
+func reportBefore(a, b *domain.ForensicReport) bool {
+	if !a.CollectedAt.Equal(b.CollectedAt) {
+		return a.CollectedAt.Before(b.CollectedAt)
+	}
+	return a.ID < b.ID
+}
+
+func EncodeCursor(at time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(at.UnixNano(), 10) + ":" + id))
+}
+
+func decodeCursor(cursor string) (time.Time, string, error) {
+	if cursor == "" {
+		return time.Time{}, "", nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor")
+	}
+
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return time.Time{}, "", fmt.Errorf("invalid cursor")
+	}
+
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor")
+	}
+
+	return time.Unix(0, n), id, nil
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
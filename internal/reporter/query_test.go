@@ -0,0 +1,241 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+var queryBase = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func queryFixtures() []*domain.ForensicReport {
+	var reports []*domain.ForensicReport
+	for i := 0; i < 9; i++ {
+		ns := []string{"payments", "search", "payments"}[i%3]
+		reason := []string{"OOMKilled", "Error", "CrashLoopBackOff"}[i%3]
+		report := domain.NewForensicReport(domain.PodCrash{
+			Namespace:     ns,
+			PodName:       fmt.Sprintf("api-7d9f8b6c5-%05d", i),
+			ContainerName: "app",
+			Reason:        reason,
+			ExitCode:      int32(i % 2),
+		})
+		report.ID = fmt.Sprintf("r%02d", i)
+		// Two reports per timestamp, so ties are broken by ID.
+		report.CollectedAt = queryBase.Add(time.Duration(i/2) * time.Minute)
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+func ids(reports []*domain.ForensicReport) string {
+	out := make([]string, 0, len(reports))
+	for _, r := range reports {
+		out = append(out, r.ID)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestQuery_Apply(t *testing.T) {
+	exit1 := int32(1)
+	tests := []struct {
+		name  string
+		query Query
+		want  string
+		total int
+	}{
+		{"newest first", Query{Limit: 4}, "r08,r07,r06,r05", 9},
+		{"oldest first", Query{Sort: SortOldest, Limit: 3}, "r00,r01,r02", 9},
+		{"namespace", Query{Namespace: "search"}, "r07,r04,r01", 3},
+		{"reason and exit code", Query{Reason: "OOMKilled", ExitCode: &exit1}, "r03", 1},
+		{"workload", Query{Workload: "api", Limit: 1}, "r08", 9},
+		{"time range", Query{Since: queryBase.Add(time.Minute), Until: queryBase.Add(3 * time.Minute)}, "r05,r04,r03,r02", 4},
+		{"offset", Query{Offset: 7}, "r01,r00", 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := tt.query.Apply(queryFixtures())
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if got := ids(res.Reports); got != tt.want {
+				t.Errorf("reports = %s, want %s", got, tt.want)
+			}
+			if res.Total != tt.total {
+				t.Errorf("Total = %d, want %d", res.Total, tt.total)
+			}
+		})
+	}
+}
+
+func TestQuery_CursorWalksEveryReportOnce(t *testing.T) {
+	for _, order := range []string{SortNewest, SortOldest} {
+		q := Query{Sort: order, Limit: 4}
+		var seen []*domain.ForensicReport
+		for page := 0; page < 5; page++ {
+			res, err := q.Apply(queryFixtures())
+			if err != nil {
+				t.Fatal(err)
+			}
+			seen = append(seen, res.Reports...)
+			if res.NextCursor == "" {
+				break
+			}
+			q.Cursor = res.NextCursor
+		}
+
+		all, _ := Query{Sort: order, Limit: MaxQueryLimit}.Apply(queryFixtures())
+		if ids(seen) != ids(all.Reports) {
+			t.Errorf("%s: paged = %s, want %s", order, ids(seen), ids(all.Reports))
+		}
+	}
+}
+
+func TestQuery_Validate(t *testing.T) {
+	for _, q := range []Query{
+		{Sort: "sideways"},
+		{Severities: []string{"urgent"}},
+		{MinSeverity: "urgent"},
+		{Status: "done"},
+		{Cursor: "not a cursor"},
+	} {
+		if _, err := QueryReports(&plainStorage{}, q); err == nil {
+			t.Errorf("QueryReports(%+v) should fail", q)
+		}
+	}
+}
+
+func TestStore_Query(t *testing.T) {
+	store, err := NewStore(t.TempDir(), WithCompression("gzip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range queryFixtures() {
+		if err := store.Save(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := QueryReports(store, Query{Namespace: "payments", PodName: "api-7d9f8b6c5-00006"})
+	if err != nil {
+		t.Fatalf("QueryReports() error = %v", err)
+	}
+	if ids(res.Reports) != "r06" || res.Total != 1 {
+		t.Errorf("reports = %s (total %d), want r06", ids(res.Reports), res.Total)
+	}
+}
+
+func TestSQLiteStore_QueryMatchesInMemory(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	for _, r := range queryFixtures() {
+		if err := store.Save(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exit0 := int32(0)
+	queries := []Query{
+		{Limit: 4},
+		{Sort: SortOldest, Limit: 3, Offset: 2},
+		{Namespace: "payments", ExitCode: &exit0},
+		{Workload: "api", Reason: "Error"},
+		{Since: queryBase.Add(time.Minute), Until: queryBase.Add(3 * time.Minute)},
+		{Status: domain.TriageNew, Limit: 2},
+		{MinSeverity: domain.SeverityMedium},
+	}
+
+	for _, q := range queries {
+		want, _ := q.Apply(queryFixtures())
+		got, err := store.Query(q)
+		if err != nil {
+			t.Fatalf("Query(%+v) error = %v", q, err)
+		}
+		if ids(got.Reports) != ids(want.Reports) || got.Total != want.Total || got.NextCursor != want.NextCursor {
+			t.Errorf("Query(%+v) = %s (total %d, cursor %q), want %s (total %d, cursor %q)",
+				q, ids(got.Reports), got.Total, got.NextCursor, ids(want.Reports), want.Total, want.NextCursor)
+		}
+
+		if want.NextCursor != "" {
+			q.Cursor = want.NextCursor
+			wantNext, _ := q.Apply(queryFixtures())
+			gotNext, err := store.Query(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ids(gotNext.Reports) != ids(wantNext.Reports) {
+				t.Errorf("next page of %+v = %s, want %s", q, ids(gotNext.Reports), ids(wantNext.Reports))
+			}
+		}
+	}
+}
+
+func TestElasticStore_Query(t *testing.T) {
+	var search map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &search)
+
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"hits": map[string]any{
+				"total": map[string]any{"value": 5},
+				"hits": []any{
+					map[string]any{"_source": map[string]any{"id": "r-3", "collected_at": "2024-03-01T12:03:00Z", "crash": map[string]any{"namespace": "payments"}}},
+					map[string]any{"_source": map[string]any{"id": "r-2", "collected_at": "2024-03-01T12:02:00Z", "crash": map[string]any{"namespace": "payments"}}},
+					map[string]any{"_source": map[string]any{"id": "r-1", "collected_at": "2024-03-01T12:01:00Z", "crash": map[string]any{"namespace": "payments"}}},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{server.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &ElasticStore{client: client, indexName: "kubecrsh-reports"}
+
+	exit := int32(137)
+	res, err := store.Query(Query{
+		Namespace:   "payments",
+		ExitCode:    &exit,
+		Status:      domain.TriageNew,
+		MinSeverity: domain.SeverityHigh,
+		Limit:       2,
+		Cursor:      EncodeCursor(queryBase.Add(5*time.Minute), "r-5"),
+	})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+
+	encoded, _ := json.Marshal(search)
+	for _, want := range []string{
+		`{"term":{"crash.namespace":"payments"}}`,
+		`{"term":{"crash.exit_code":137}}`,
+		`{"terms":{"severity.level":["high","critical"]}}`,
+		`"must_not":{"exists":{"field":"triage.status"}}`,
+		`"search_after":[1709294700000,"r-5"]`,
+		`"size":3`,
+	} {
+		if !strings.Contains(string(encoded), want) {
+			t.Errorf("search body does not contain %s: %s", want, encoded)
+		}
+	}
+
+	if ids(res.Reports) != "r-3,r-2" || res.Total != 5 {
+		t.Errorf("reports = %s (total %d), want r-3,r-2 of 5", ids(res.Reports), res.Total)
+	}
+	if res.NextCursor != EncodeCursor(time.Date(2024, 3, 1, 12, 2, 0, 0, time.UTC), "r-2") {
+		t.Errorf("NextCursor = %q, want the cursor of r-2", res.NextCursor)
+	}
+}
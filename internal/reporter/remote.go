@@ -0,0 +1,189 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+var _ Storage = (*RemoteStore)(nil)
+
+const maxRemoteResponseBytes int64 = 64 << 20
+
+type RemoteConfig struct {
+	URL     string
+	Token   string
+	Timeout time.Duration
+}
+
+type RemoteStore struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func NewRemoteStore(cfg RemoteConfig) (*RemoteStore, error) {
+	raw := strings.TrimRight(strings.TrimSpace(cfg.URL), "/")
+	if raw == "" {
+		return nil, fmt.Errorf("remote store URL is required")
+	}
+	if _, err := url.ParseRequestURI(raw); err != nil {
+		return nil, fmt.Errorf("invalid remote store URL: %w", err)
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &RemoteStore{
+		baseURL: raw,
+		token:   cfg.Token,
+		client:  &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (s *RemoteStore) Save(report *domain.ForensicReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < 3; attempt++ {
+		resp, err := s.do(http.MethodPost, "/reports", bytes.NewReader(body))
+		if err != nil {
+			lastErr = fmt.Errorf("failed to forward report: %w", err)
+		} else {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxRemoteResponseBytes))
+			resp.Body.Close()
+
+			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				return nil
+			}
+
+			lastErr = fmt.Errorf("remote store returned status: %d", resp.StatusCode)
+			if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+				return lastErr
+			}
+		}
+
+		if attempt < 2 {
+			time.Sleep(time.Duration(200<<attempt) * time.Millisecond)
+		}
+	}
+
+	return lastErr
+}
+
+func (s *RemoteStore) Load(id string) (*domain.ForensicReport, error) {
+	resp, err := s.do(http.MethodGet, "/reports/"+url.PathEscape(id)+"?full=true", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("report not found: %s", id)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote store returned status: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+
+	return domain.DecodeReport(data)
+}
+
+// List follows the API's nextCursor until every page has been read.
+func (s *RemoteStore) List() ([]*domain.ForensicReport, error) {
+	var ids []string
+	seen := make(map[string]bool)
+	cursor := ""
+	for {
+		page, next, err := s.listPage(cursor)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, page...)
+		if next == "" {
+			break
+		}
+		if seen[next] {
+			return nil, fmt.Errorf("remote store repeated list cursor %q", next)
+		}
+		seen[next] = true
+		cursor = next
+	}
+
+	reports := make([]*domain.ForensicReport, 0, len(ids))
+	for _, id := range ids {
+		report, err := s.Load(id)
+		if err != nil {
+			continue
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+func (s *RemoteStore) listPage(cursor string) ([]string, string, error) {
+	params := url.Values{"limit": {strconv.Itoa(MaxQueryLimit)}}
+	if cursor != "" {
+		params.Set("cursor", cursor)
+	}
+
+	resp, err := s.do(http.MethodGet, "/reports?"+params.Encode(), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list reports: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("remote store returned status: %d", resp.StatusCode)
+	}
+
+	var page struct {
+		Items []struct {
+			ID string `json:"id"`
+		} `json:"items"`
+		NextCursor string `json:"nextCursor"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxRemoteResponseBytes)).Decode(&page); err != nil {
+		return nil, "", fmt.Errorf("failed to decode report list: %w", err)
+	}
+
+	ids := make([]string, 0, len(page.Items))
+	for _, item := range page.Items {
+		ids = append(ids, item.ID)
+	}
+	return ids, page.NextCursor, nil
+}
+
+func (s *RemoteStore) do(method, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	return s.client.Do(req)
+}
@@ -0,0 +1,171 @@
+package reporter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+func TestNewRemoteStore_RequiresURL(t *testing.T) {
+	if _, err := NewRemoteStore(RemoteConfig{}); err == nil {
+		t.Error("Expected error for empty URL")
+	}
+	if _, err := NewRemoteStore(RemoteConfig{URL: "not a url"}); err == nil {
+		t.Error("Expected error for invalid URL")
+	}
+}
+
+func TestRemoteStore_SaveLoadList(t *testing.T) {
+	var mu sync.Mutex
+	saved := make(map[string]*domain.ForensicReport)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/reports":
+			var rep domain.ForensicReport
+			if err := json.NewDecoder(r.Body).Decode(&rep); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			saved[rep.ID] = &rep
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/reports":
+			items := make([]map[string]string, 0, len(saved))
+			for id := range saved {
+				items = append(items, map[string]string{"id": id})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"items": items, "total": len(items)})
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/reports/"):
+			rep, ok := saved[strings.TrimPrefix(r.URL.Path, "/reports/")]
+			if !ok || r.URL.Query().Get("full") != "true" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(rep)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	store, err := NewRemoteStore(RemoteConfig{URL: server.URL + "/", Token: "secret"})
+	if err != nil {
+		t.Fatalf("NewRemoteStore() error = %v", err)
+	}
+
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api", NodeName: "node-1"})
+	report.SetPreviousLogs([]string{"panic: boom"})
+
+	if err := store.Save(report); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := store.Load(report.ID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Crash.NodeName != "node-1" || len(loaded.PreviousLog) != 1 {
+		t.Errorf("Load() = %+v, fields not preserved", loaded)
+	}
+
+	if _, err := store.Load("missing"); err == nil {
+		t.Error("Expected error for missing report")
+	}
+
+	reports, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(reports) != 1 {
+		t.Errorf("List() returned %d reports, want 1", len(reports))
+	}
+}
+
+func TestRemoteStore_ListFollowsCursor(t *testing.T) {
+	var ids []string
+	reports := make(map[string]*domain.ForensicReport)
+	for i := 0; i < 5; i++ {
+		report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+		ids = append(ids, report.ID)
+		reports[report.ID] = report
+	}
+
+	pages := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/reports" {
+			json.NewEncoder(w).Encode(reports[strings.TrimPrefix(r.URL.Path, "/reports/")])
+			return
+		}
+		pages++
+		if r.URL.Query().Get("limit") != "1000" {
+			t.Errorf("limit = %q, want 1000", r.URL.Query().Get("limit"))
+		}
+
+		// The server caps pages at two reports; the cursor is the next offset.
+		start, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+		end := min(start+2, len(ids))
+		items := make([]map[string]string, 0, end-start)
+		for _, id := range ids[start:end] {
+			items = append(items, map[string]string{"id": id})
+		}
+		resp := map[string]any{"items": items, "total": len(ids)}
+		if end < len(ids) {
+			resp["nextCursor"] = strconv.Itoa(end)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	store, err := NewRemoteStore(RemoteConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewRemoteStore() error = %v", err)
+	}
+
+	got, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(got) != len(ids) {
+		t.Fatalf("List() returned %d reports, want %d", len(got), len(ids))
+	}
+	for i, report := range got {
+		if report.ID != ids[i] {
+			t.Errorf("List()[%d] = %s, want %s", i, report.ID, ids[i])
+		}
+	}
+	if pages != 3 {
+		t.Errorf("List() fetched %d pages, want 3", pages)
+	}
+}
+
+func TestRemoteStore_Save_ClientErrorNotRetried(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	store, _ := NewRemoteStore(RemoteConfig{URL: server.URL})
+
+	if err := store.Save(domain.NewForensicReport(domain.PodCrash{})); err == nil {
+		t.Error("Expected error for 400 response")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
@@ -0,0 +1,168 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+// Retention actions and the rules that trigger them.
+const (
+	RetentionDelete = "delete"
+	RetentionStrip  = "strip"
+
+	ReasonAge       = "age"
+	ReasonWorkload  = "workload"
+	ReasonNamespace = "namespace"
+	ReasonBytes     = "bytes"
+	ReasonLogs      = "logs"
+)
+
+// RetentionPolicy decides which reports are kept. Reports older than MaxAge
+// are deleted; of the rest, only the newest MaxPerWorkload of each workload
+// and MaxPerNamespace of each namespace are kept, and the oldest are deleted
+// once the newest add up to MaxBytes. Surviving reports older than
+// StripLogsAfter lose their logs but keep everything else. Zero disables a
+// rule. A dry run only reports what would be done.
+type RetentionPolicy struct {
+	MaxAge          time.Duration
+	MaxBytes        int64
+	MaxPerNamespace int
+	MaxPerWorkload  int
+	StripLogsAfter  time.Duration
+	DryRun          bool
+}
+
+func (p RetentionPolicy) IsZero() bool {
+	return p.MaxAge <= 0 && !p.hasQuotas()
+}
+
+// hasQuotas reports whether the policy needs more than age-based pruning.
+func (p RetentionPolicy) hasQuotas() bool {
+	return p.MaxBytes > 0 || p.MaxPerNamespace > 0 || p.MaxPerWorkload > 0 || p.StripLogsAfter > 0
+}
+
+// PruneAction is one report deleted or stripped by a retention run. Bytes
+// is what the action reclaimed; a dry run does not know it for strips.
+type PruneAction struct {
+	ID          string    `json:"id"`
+	Namespace   string    `json:"namespace"`
+	Workload    string    `json:"workload"`
+	CollectedAt time.Time `json:"collectedAt"`
+	Action      string    `json:"action"`
+	Reason      string    `json:"reason"`
+	Bytes       int64     `json:"bytes"`
+}
+
+// PolicyPruner is implemented by stores that apply a full RetentionPolicy.
+type PolicyPruner interface {
+	PruneWithPolicy(p RetentionPolicy) (PruneResult, error)
+}
+
+// ApplyRetention applies p to s. Stores that only prune by age apply
+// MaxAge and ignore the other rules.
+func ApplyRetention(s Storage, p RetentionPolicy) (PruneResult, error) {
+	if pp, ok := s.(PolicyPruner); ok {
+		return pp.PruneWithPolicy(p)
+	}
+	if p.DryRun {
+		return PruneResult{}, fmt.Errorf("this report store does not support dry runs")
+	}
+	if pr, ok := s.(Pruner); ok {
+		return pr.Prune(p.MaxAge)
+	}
+	return PruneResult{}, nil
+}
+
+type retentionItem struct {
+	ID          string
+	Namespace   string
+	Workload    string
+	CollectedAt time.Time
+	Size        int64
+	HasLogs     bool
+	Invalid     bool
+}
+
+// planRetention returns the actions p calls for, newest reports first.
+// Reports that cannot be read only count towards age and size.
+func planRetention(items []retentionItem, p RetentionPolicy, now time.Time) []PruneAction {
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CollectedAt.Equal(items[j].CollectedAt) {
+			return items[i].CollectedAt.After(items[j].CollectedAt)
+		}
+		return items[i].ID < items[j].ID
+	})
+
+	var actions []PruneAction
+	perNamespace := make(map[string]int)
+	perWorkload := make(map[string]int)
+	var total int64
+	full := false
+
+	for _, it := range items {
+		workload := it.Namespace + "/" + it.Workload
+		action := PruneAction{ID: it.ID, Namespace: it.Namespace, Workload: it.Workload, CollectedAt: it.CollectedAt, Action: RetentionDelete, Bytes: it.Size}
+
+		switch {
+		case p.MaxAge > 0 && now.Sub(it.CollectedAt) > p.MaxAge:
+			action.Reason = ReasonAge
+		case !it.Invalid && p.MaxPerWorkload > 0 && perWorkload[workload] >= p.MaxPerWorkload:
+			action.Reason = ReasonWorkload
+		case !it.Invalid && p.MaxPerNamespace > 0 && perNamespace[it.Namespace] >= p.MaxPerNamespace:
+			action.Reason = ReasonNamespace
+		case p.MaxBytes > 0 && (full || total+it.Size > p.MaxBytes):
+			// Everything older than the first report over the limit goes,
+			// so space is reclaimed from the oldest reports.
+			full = true
+			action.Reason = ReasonBytes
+		}
+		if action.Reason != "" {
+			actions = append(actions, action)
+			continue
+		}
+
+		perWorkload[workload]++
+		perNamespace[it.Namespace]++
+		total += it.Size
+
+		if p.StripLogsAfter > 0 && it.HasLogs && !it.Invalid && now.Sub(it.CollectedAt) > p.StripLogsAfter {
+			action.Action, action.Reason, action.Bytes = RetentionStrip, ReasonLogs, 0
+			actions = append(actions, action)
+		}
+	}
+
+	return actions
+}
+
+// stripLogs removes the logs of report and reports whether it had any.
+func stripLogs(report *domain.ForensicReport, after time.Duration) bool {
+	if !hasLogs(report) {
+		return false
+	}
+	report.Logs = nil
+	report.PreviousLog = nil
+	report.LastWords = nil
+	report.AddWarning(fmt.Sprintf("logs removed by retention after %s", after))
+	return true
+}
+
+func hasLogs(report *domain.ForensicReport) bool {
+	return len(report.Logs) > 0 || len(report.PreviousLog) > 0 || len(report.LastWords) > 0
+}
+
+// tally fills in the counts of res from its actions.
+func (res *PruneResult) tally(total int) {
+	for _, a := range res.Actions {
+		switch a.Action {
+		case RetentionDelete:
+			res.Deleted++
+		case RetentionStrip:
+			res.Stripped++
+		}
+		res.BytesReclaimed += a.Bytes
+	}
+	res.Kept = total - res.Deleted - res.Failed
+}
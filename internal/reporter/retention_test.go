@@ -0,0 +1,157 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+func TestPlanRetention(t *testing.T) {
+	now := time.Now()
+	item := func(id, ns, workload string, age time.Duration, size int64) retentionItem {
+		return retentionItem{ID: id, Namespace: ns, Workload: workload, CollectedAt: now.Add(-age), Size: size, HasLogs: true}
+	}
+
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		items  []retentionItem
+		want   map[string]string
+	}{
+		{
+			name:   "age",
+			policy: RetentionPolicy{MaxAge: 24 * time.Hour},
+			items:  []retentionItem{item("a", "ns", "api", time.Hour, 10), item("b", "ns", "api", 48*time.Hour, 10)},
+			want:   map[string]string{"b": "delete/age"},
+		},
+		{
+			name:   "per workload and namespace",
+			policy: RetentionPolicy{MaxPerWorkload: 2, MaxPerNamespace: 3},
+			items: []retentionItem{
+				item("a1", "noisy", "api", 1*time.Minute, 10),
+				item("a2", "noisy", "api", 2*time.Minute, 10),
+				item("a3", "noisy", "api", 3*time.Minute, 10),
+				item("w1", "noisy", "worker", 4*time.Minute, 10),
+				item("w2", "noisy", "worker", 5*time.Minute, 10),
+				item("q1", "quiet", "db", 6*time.Minute, 10),
+			},
+			want: map[string]string{"a3": "delete/workload", "w2": "delete/namespace"},
+		},
+		{
+			name:   "bytes deletes the oldest",
+			policy: RetentionPolicy{MaxBytes: 25},
+			items: []retentionItem{
+				item("a", "ns", "api", 1*time.Minute, 10),
+				item("b", "ns", "api", 2*time.Minute, 10),
+				item("c", "ns", "api", 3*time.Minute, 10),
+				item("d", "ns", "api", 4*time.Minute, 1),
+			},
+			want: map[string]string{"c": "delete/bytes", "d": "delete/bytes"},
+		},
+		{
+			name:   "strip logs",
+			policy: RetentionPolicy{MaxAge: 30 * 24 * time.Hour, StripLogsAfter: 24 * time.Hour},
+			items: []retentionItem{
+				item("a", "ns", "api", time.Hour, 10),
+				item("b", "ns", "api", 48*time.Hour, 10),
+				{ID: "c", Namespace: "ns", Workload: "api", CollectedAt: now.Add(-72 * time.Hour), Size: 10},
+			},
+			want: map[string]string{"b": "strip/logs"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]string)
+			for _, a := range planRetention(tt.items, tt.policy, now) {
+				got[a.ID] = a.Action + "/" + a.Reason
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("actions = %v, want %v", got, tt.want)
+			}
+			for id, want := range tt.want {
+				if got[id] != want {
+					t.Errorf("action for %s = %q, want %q", id, got[id], want)
+				}
+			}
+		})
+	}
+}
+
+func TestStore_PruneWithPolicy(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reports []*domain.ForensicReport
+	for i, age := range []time.Duration{time.Minute, 2 * time.Hour, 3 * time.Hour, 4 * time.Hour} {
+		report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api", Workload: "api"})
+		report.CollectedAt = time.Now().Add(-age)
+		report.SetLogs([]string{"panic: nil map", "goroutine 1"})
+		if i == 0 {
+			report.Crash.Workload = "web"
+		}
+		if err := store.Save(report); err != nil {
+			t.Fatal(err)
+		}
+		reports = append(reports, report)
+	}
+
+	policy := RetentionPolicy{MaxPerWorkload: 2, StripLogsAfter: time.Hour, DryRun: true}
+	res, err := store.PruneWithPolicy(policy)
+	if err != nil || res.Deleted != 1 || res.Stripped != 2 || len(res.Actions) != 3 {
+		t.Fatalf("dry run PruneWithPolicy() = %+v, %v", res, err)
+	}
+	if all, _ := store.List(); len(all) != 4 {
+		t.Fatalf("dry run deleted reports: %d left", len(all))
+	}
+
+	policy.DryRun = false
+	res, err = store.PruneWithPolicy(policy)
+	if err != nil || res.Deleted != 1 || res.Stripped != 2 || res.Kept != 3 || res.BytesReclaimed <= 0 {
+		t.Fatalf("PruneWithPolicy() = %+v, %v", res, err)
+	}
+	if _, err := store.Load(reports[3].ID); err == nil {
+		t.Error("oldest report of the workload was not deleted")
+	}
+	stripped, err := store.Load(reports[1].ID)
+	if err != nil || len(stripped.Logs) != 0 || len(stripped.Warnings) == 0 {
+		t.Errorf("stripped report = %+v, %v", stripped, err)
+	}
+	if fresh, _ := store.Load(reports[0].ID); len(fresh.Logs) != 2 {
+		t.Error("logs of a recent report were stripped")
+	}
+
+	if res, _ := store.PruneWithPolicy(policy); len(res.Actions) != 0 {
+		t.Errorf("second PruneWithPolicy() actions = %+v", res.Actions)
+	}
+}
+
+func TestSQLiteStore_PruneWithPolicy(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	var reports []*domain.ForensicReport
+	for _, age := range []time.Duration{time.Minute, 2 * time.Hour, 48 * time.Hour} {
+		report := domain.NewForensicReport(domain.PodCrash{Namespace: "noisy", PodName: "api", Workload: "api"})
+		report.CollectedAt = time.Now().Add(-age)
+		report.SetLogs([]string{"panic: nil map"})
+		if err := store.Save(report); err != nil {
+			t.Fatal(err)
+		}
+		reports = append(reports, report)
+	}
+
+	res, err := store.PruneWithPolicy(RetentionPolicy{MaxAge: 24 * time.Hour, StripLogsAfter: time.Hour})
+	if err != nil || res.Deleted != 1 || res.Stripped != 1 || res.Kept != 2 {
+		t.Fatalf("PruneWithPolicy() = %+v, %v", res, err)
+	}
+	if stripped, err := store.Load(reports[1].ID); err != nil || len(stripped.Logs) != 0 {
+		t.Errorf("stripped report = %+v, %v", stripped, err)
+	}
+
+	if res, _ := store.PruneWithPolicy(RetentionPolicy{StripLogsAfter: time.Hour}); res.Stripped != 0 {
+		t.Errorf("second PruneWithPolicy() stripped %d reports again", res.Stripped)
+	}
+}
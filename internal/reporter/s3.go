@@ -0,0 +1,628 @@
+package reporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+var _ Storage = (*S3Store)(nil)
+var _ SaveWithResult = (*S3Store)(nil)
+
+const (
+	DefaultS3PartSize int64 = 16 << 20
+	minS3PartSize     int64 = 5 << 20
+
+	s3DeleteBatch     = 1000
+	s3CollectedAtMeta = "X-Amz-Meta-Collected-At"
+	s3DayLayout       = "2006/01/02"
+)
+
+type S3Config struct {
+	Endpoint             string
+	Region               string
+	Bucket               string
+	Prefix               string
+	AccessKeyID          string
+	SecretAccessKey      string
+	SessionToken         string
+	ServerSideEncryption string
+	KMSKeyID             string
+	PartSize             int64
+	Timeout              time.Duration
+}
+
+// S3Store keeps each report as a gzip-compressed object in an S3-compatible
+// bucket, addressed path-style so that MinIO, Ceph and similar servers work
+// without DNS wildcards. Reports live under
+// <prefix>reports/<yyyy>/<mm>/<dd>/<namespace>/<id>.json.gz, and a small
+// <prefix>ids/<id> object points at the report key so Load does not have to
+// scan the bucket.
+type S3Store struct {
+	endpoint *url.URL
+	region   string
+	bucket   string
+	prefix   string
+	creds    s3Credentials
+	sse      string
+	kmsKeyID string
+	partSize int64
+	client   *http.Client
+}
+
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	bucket := strings.TrimSpace(cfg.Bucket)
+	if bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+
+	region := strings.TrimSpace(cfg.Region)
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	raw := strings.TrimSpace(cfg.Endpoint)
+	if raw == "" {
+		raw = "https://s3." + region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(raw)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", raw)
+	}
+
+	creds := s3Credentials{
+		AccessKeyID:     cfg.AccessKeyID,
+		SecretAccessKey: cfg.SecretAccessKey,
+		SessionToken:    cfg.SessionToken,
+	}
+	if creds.AccessKeyID == "" && creds.SecretAccessKey == "" {
+		creds = s3Credentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 access key and secret key are required")
+	}
+
+	switch cfg.ServerSideEncryption {
+	case "", "AES256", "aws:kms":
+	default:
+		return nil, fmt.Errorf("invalid S3 server-side encryption %q (want AES256 or aws:kms)", cfg.ServerSideEncryption)
+	}
+	if cfg.KMSKeyID != "" && cfg.ServerSideEncryption != "aws:kms" {
+		return nil, fmt.Errorf("S3 KMS key requires server-side encryption aws:kms")
+	}
+
+	partSize := cfg.PartSize
+	if partSize <= 0 {
+		partSize = DefaultS3PartSize
+	}
+	if partSize < minS3PartSize {
+		partSize = minS3PartSize
+	}
+
+	prefix := strings.Trim(cfg.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	s := &S3Store{
+		endpoint: endpoint,
+		region:   region,
+		bucket:   bucket,
+		prefix:   prefix,
+		creds:    creds,
+		sse:      cfg.ServerSideEncryption,
+		kmsKeyID: cfg.KMSKeyID,
+		partSize: partSize,
+		client:   &http.Client{Timeout: timeout},
+	}
+
+	resp, err := s.do(http.MethodHead, "", nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach S3 bucket %s: %w", bucket, err)
+	}
+	resp.Body.Close()
+
+	return s, nil
+}
+
+func (s *S3Store) Save(report *domain.ForensicReport) error {
+	_, err := s.SaveWithResult(report)
+	return err
+}
+
+func (s *S3Store) SaveWithResult(report *domain.ForensicReport) (SaveResult, error) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return SaveResult{}, fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(data); err != nil {
+		return SaveResult{}, fmt.Errorf("failed to compress report: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return SaveResult{}, fmt.Errorf("failed to compress report: %w", err)
+	}
+	body := buf.Bytes()
+
+	key := s.reportKey(report)
+	header := s.encryptionHeader()
+	header.Set("Content-Type", "application/gzip")
+	header.Set(s3CollectedAtMeta, report.CollectedAt.UTC().Format(time.RFC3339Nano))
+
+	if int64(len(body)) > s.partSize {
+		err = s.putMultipart(key, body, header)
+	} else {
+		err = s.put(key, body, header)
+	}
+	if err != nil {
+		return SaveResult{}, fmt.Errorf("failed to upload report: %w", err)
+	}
+
+	pointer := s.encryptionHeader()
+	pointer.Set("Content-Type", "text/plain")
+	if err := s.put(s.idKey(report.ID), []byte(key), pointer); err != nil {
+		return SaveResult{}, fmt.Errorf("failed to upload report pointer: %w", err)
+	}
+
+	return SaveResult{BytesWritten: int64(len(body)), Path: "s3://" + s.bucket + "/" + key}, nil
+}
+
+func (s *S3Store) Load(id string) (*domain.ForensicReport, error) {
+	key, err := s.get(s.idKey(id))
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, fmt.Errorf("report not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to load report: %w", err)
+	}
+
+	body, err := s.get(strings.TrimSpace(string(key)))
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, fmt.Errorf("report not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to load report: %w", err)
+	}
+
+	return decodeCompressedReport(body)
+}
+
+func (s *S3Store) List() ([]*domain.ForensicReport, error) {
+	objects, err := s.list(s.prefix + "reports/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+
+	reports := make([]*domain.ForensicReport, 0, len(objects))
+	for _, obj := range objects {
+		body, err := s.get(obj.Key)
+		if err != nil {
+			continue
+		}
+		report, err := decodeCompressedReport(body)
+		if err != nil {
+			continue
+		}
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool { return reportBefore(reports[j], reports[i]) })
+
+	return reports, nil
+}
+
+// Prune deletes reports collected before the retention window. The date in
+// the key settles most objects without a request; only reports from the
+// cutoff day are checked individually. Deleting an object that a bucket
+// lifecycle rule already expired is not an error, so Prune can run alongside
+// such a rule on the same prefix.
+func (s *S3Store) Prune(retention time.Duration) (PruneResult, error) {
+	var res PruneResult
+	if retention <= 0 {
+		return res, nil
+	}
+
+	objects, err := s.list(s.prefix + "reports/")
+	if err != nil {
+		return res, fmt.Errorf("failed to list reports: %w", err)
+	}
+
+	cutoff := time.Now().Add(-retention)
+	var expired []string
+	pointers := make(map[string]string)
+	var firstErr error
+
+	for _, obj := range objects {
+		day, id, ok := s.parseReportKey(obj.Key)
+		if !ok {
+			continue
+		}
+
+		collectedAt := day
+		if day.Before(cutoff) && day.Add(24*time.Hour).After(cutoff) {
+			at, err := s.collectedAt(obj)
+			if err != nil {
+				res.Failed++
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			collectedAt = at
+		}
+
+		if !collectedAt.Before(cutoff) {
+			res.Kept++
+			continue
+		}
+		expired = append(expired, obj.Key)
+		pointers[obj.Key] = s.idKey(id)
+	}
+
+	// Pointers go only once their report object is gone, so a report that
+	// could not be deleted stays reachable through Load.
+	failed, err := s.deleteObjects(expired)
+	if err != nil && firstErr == nil {
+		firstErr = err
+	}
+	var removed []string
+	for _, key := range expired {
+		if failed[key] {
+			res.Failed++
+			continue
+		}
+		removed = append(removed, pointers[key])
+	}
+
+	failed, err = s.deleteObjects(removed)
+	if err != nil && firstErr == nil {
+		firstErr = err
+	}
+	for _, key := range removed {
+		if failed[key] {
+			res.Failed++
+		} else {
+			res.Deleted++
+		}
+	}
+
+	return res, firstErr
+}
+
+func (s *S3Store) reportKey(report *domain.ForensicReport) string {
+	namespace := report.Crash.Namespace
+	if namespace == "" {
+		namespace = "_"
+	}
+	return s.prefix + "reports/" + report.CollectedAt.UTC().Format(s3DayLayout) + "/" + namespace + "/" + report.ID + ".json.gz"
+}
+
+func (s *S3Store) idKey(id string) string {
+	return s.prefix + "ids/" + id
+}
+
+func (s *S3Store) parseReportKey(key string) (time.Time, string, bool) {
+	parts := strings.Split(strings.TrimPrefix(key, s.prefix+"reports/"), "/")
+	if len(parts) != 5 || !strings.HasSuffix(parts[4], ".json.gz") {
+		return time.Time{}, "", false
+	}
+
+	day, err := time.Parse(s3DayLayout, strings.Join(parts[:3], "/"))
+	if err != nil {
+		return time.Time{}, "", false
+	}
+
+	return day, strings.TrimSuffix(parts[4], ".json.gz"), true
+}
+
+func (s *S3Store) collectedAt(obj s3Object) (time.Time, error) {
+	resp, err := s.do(http.MethodHead, obj.Key, nil, nil, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read report metadata: %w", err)
+	}
+	resp.Body.Close()
+
+	if at, err := time.Parse(time.RFC3339Nano, resp.Header.Get(s3CollectedAtMeta)); err == nil {
+		return at, nil
+	}
+	return obj.LastModified, nil
+}
+
+func (s *S3Store) encryptionHeader() http.Header {
+	header := http.Header{}
+	if s.sse != "" {
+		header.Set("X-Amz-Server-Side-Encryption", s.sse)
+	}
+	if s.kmsKeyID != "" {
+		header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", s.kmsKeyID)
+	}
+	return header
+}
+
+func (s *S3Store) put(key string, body []byte, header http.Header) error {
+	resp, err := s.do(http.MethodPut, key, nil, header, body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Store) get(key string) ([]byte, error) {
+	resp, err := s.do(http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxRemoteResponseBytes))
+}
+
+func (s *S3Store) putMultipart(key string, body []byte, header http.Header) error {
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := s.doXML(http.MethodPost, key, url.Values{"uploads": {""}}, header, nil, &initiated); err != nil {
+		return fmt.Errorf("failed to start multipart upload: %w", err)
+	}
+
+	type part struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	}
+	complete := struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []part   `xml:"Part"`
+	}{}
+
+	err := func() error {
+		for start := int64(0); start < int64(len(body)); start += s.partSize {
+			end := start + s.partSize
+			if end > int64(len(body)) {
+				end = int64(len(body))
+			}
+			number := len(complete.Parts) + 1
+
+			query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {initiated.UploadID}}
+			resp, err := s.do(http.MethodPut, key, query, nil, body[start:end])
+			if err != nil {
+				return fmt.Errorf("failed to upload part %d: %w", number, err)
+			}
+			resp.Body.Close()
+
+			complete.Parts = append(complete.Parts, part{PartNumber: number, ETag: resp.Header.Get("ETag")})
+		}
+
+		payload, err := xml.Marshal(complete)
+		if err != nil {
+			return err
+		}
+		// S3 can report a failed completion with a 200 and an error body.
+		var result struct {
+			XMLName xml.Name
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		if err := s.doXML(http.MethodPost, key, url.Values{"uploadId": {initiated.UploadID}}, nil, payload, &result); err != nil {
+			return fmt.Errorf("failed to complete multipart upload: %w", err)
+		}
+		if result.XMLName.Local == "Error" {
+			return fmt.Errorf("failed to complete multipart upload: %s: %s", result.Code, result.Message)
+		}
+		return nil
+	}()
+	if err != nil {
+		if resp, abortErr := s.do(http.MethodDelete, key, url.Values{"uploadId": {initiated.UploadID}}, nil, nil); abortErr == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	return nil
+}
+
+type s3Object struct {
+	Key          string    `xml:"Key"`
+	LastModified time.Time `xml:"LastModified"`
+	Size         int64     `xml:"Size"`
+}
+
+func (s *S3Store) list(prefix string) ([]s3Object, error) {
+	var objects []s3Object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		var page struct {
+			Contents              []s3Object `xml:"Contents"`
+			IsTruncated           bool       `xml:"IsTruncated"`
+			NextContinuationToken string     `xml:"NextContinuationToken"`
+		}
+		if err := s.doXML(http.MethodGet, "", query, nil, nil, &page); err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, page.Contents...)
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// deleteObjects removes keys in batches and returns the keys S3 refused to
+// delete.
+func (s *S3Store) deleteObjects(keys []string) (map[string]bool, error) {
+	failed := make(map[string]bool)
+	var firstErr error
+
+	for start := 0; start < len(keys); start += s3DeleteBatch {
+		end := start + s3DeleteBatch
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		type object struct {
+			Key string `xml:"Key"`
+		}
+		request := struct {
+			XMLName xml.Name `xml:"Delete"`
+			Quiet   bool     `xml:"Quiet"`
+			Objects []object `xml:"Object"`
+		}{Quiet: true}
+		for _, key := range keys[start:end] {
+			request.Objects = append(request.Objects, object{Key: key})
+		}
+
+		payload, err := xml.Marshal(request)
+		if err != nil {
+			return nil, err
+		}
+		sum := md5.Sum(payload)
+		header := http.Header{}
+		header.Set("Content-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+		header.Set("Content-Type", "application/xml")
+
+		var result struct {
+			Errors []struct {
+				Key     string `xml:"Key"`
+				Code    string `xml:"Code"`
+				Message string `xml:"Message"`
+			} `xml:"Error"`
+		}
+		if err := s.doXML(http.MethodPost, "", url.Values{"delete": {""}}, header, payload, &result); err != nil {
+			for _, key := range keys[start:end] {
+				failed[key] = true
+			}
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to delete reports: %w", err)
+			}
+			continue
+		}
+
+		for _, e := range result.Errors {
+			if e.Code == "NoSuchKey" {
+				continue
+			}
+			failed[e.Key] = true
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to delete %s: %s: %s", e.Key, e.Code, e.Message)
+			}
+		}
+	}
+
+	return failed, firstErr
+}
+
+type s3Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *s3Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("S3 returned status: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("S3 returned status: %d: %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+func isS3NotFound(err error) bool {
+	var s3Err *s3Error
+	return errors.As(err, &s3Err) && s3Err.StatusCode == http.StatusNotFound
+}
+
+func (s *S3Store) doXML(method, key string, query url.Values, header http.Header, body []byte, dst any) error {
+	resp, err := s.do(method, key, query, header, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, maxRemoteResponseBytes)).Decode(dst); err != nil {
+		return fmt.Errorf("failed to decode S3 response: %w", err)
+	}
+	return nil
+}
+
+// do sends a signed request for key in the bucket, retrying throttling and
+// server errors. Responses outside 2xx are returned as *s3Error.
+func (s *S3Store) do(method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	path := strings.TrimSuffix(s.endpoint.Path, "/") + "/" + s.bucket
+	if key != "" {
+		path += "/" + key
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < 3; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(200<<(attempt-1)) * time.Millisecond)
+		}
+
+		u := *s.endpoint
+		u.Path = path
+		u.RawPath = s3Escape(path, false)
+		u.RawQuery = canonicalQuery(query)
+
+		req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.URL = &u
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		signV4(req, s.creds, s.region, sha256Hex(body), time.Now())
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		s3Err := &s3Error{StatusCode: resp.StatusCode}
+		if method != http.MethodHead {
+			_ = xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(s3Err)
+		}
+		resp.Body.Close()
+
+		lastErr = s3Err
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return nil, lastErr
+		}
+	}
+
+	return nil, lastErr
+}
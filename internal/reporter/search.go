@@ -0,0 +1,328 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+// Search fields, named after the Elasticsearch document fields so that a
+// field: prefix means the same thing on every backend.
+const (
+	SearchFieldLogs        = "logs"
+	SearchFieldPreviousLog = "previous_log"
+	SearchFieldLastWords   = "last_words"
+	SearchFieldEvents      = "event_messages"
+	SearchFieldWarnings    = "warnings"
+
+	maxSearchHighlights    = 3
+	maxSearchHighlightSize = 240
+)
+
+var searchFields = []string{SearchFieldLogs, SearchFieldPreviousLog, SearchFieldLastWords, SearchFieldEvents, SearchFieldWarnings}
+
+// SearchQuery looks for Text in the logs, previous logs, last words, event
+// messages and warnings of the reports selected by Query. Words and "quoted
+// phrases" must all occur; OR between two of them accepts either, a leading
+// - or NOT excludes, a trailing * matches a prefix and field: limits a term
+// to one field. Elasticsearch receives Text as query_string syntax, which
+// accepts more.
+type SearchQuery struct {
+	Query
+	Text string
+}
+
+type SearchHit struct {
+	Report *domain.ForensicReport
+	// Highlights are lines that matched, at most a few per report.
+	Highlights []string
+}
+
+type SearchResult struct {
+	Hits       []SearchHit
+	Total      int
+	NextCursor string
+}
+
+type Searcher interface {
+	Search(q SearchQuery) (SearchResult, error)
+}
+
+func SearchReports(s Storage, q SearchQuery) (SearchResult, error) {
+	if err := q.Validate(); err != nil {
+		return SearchResult{}, err
+	}
+
+	if sr, ok := s.(Searcher); ok {
+		return sr.Search(q)
+	}
+
+	reports, err := s.List()
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	expr, err := parseSearchText(q.Text)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	return q.apply(expr, reports)
+}
+
+func (q SearchQuery) Validate() error {
+	if strings.TrimSpace(q.Text) == "" {
+		return fmt.Errorf("search text is required")
+	}
+	if _, err := parseSearchText(q.Text); err != nil {
+		return err
+	}
+	return q.Query.Validate()
+}
+
+// apply matches, sorts and pages reports in memory.
+func (q SearchQuery) apply(expr searchExpr, reports []*domain.ForensicReport) (SearchResult, error) {
+	highlights := make(map[string][]string)
+	matched := make([]*domain.ForensicReport, 0, len(reports))
+	for _, r := range reports {
+		if !q.Matches(r) {
+			continue
+		}
+		if lines, ok := expr.match(r); ok {
+			highlights[r.ID] = lines
+			matched = append(matched, r)
+		}
+	}
+
+	res, err := q.Query.Apply(matched)
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	out := SearchResult{Total: res.Total, NextCursor: res.NextCursor}
+	for _, r := range res.Reports {
+		out.Hits = append(out.Hits, SearchHit{Report: r, Highlights: highlights[r.ID]})
+	}
+	return out, nil
+}
+
+// searchExpr is a conjunction of groups; a group matches when any of its
+// clauses does.
+type searchExpr struct {
+	groups [][]searchClause
+}
+
+type searchClause struct {
+	field  string
+	terms  []string
+	prefix bool
+	negate bool
+}
+
+func parseSearchText(text string) (searchExpr, error) {
+	var expr searchExpr
+	var negateNext, orNext bool
+
+	for rest := strings.TrimSpace(text); rest != ""; rest = strings.TrimSpace(rest) {
+		var raw string
+		var quoted bool
+		raw, rest, quoted = nextSearchToken(rest)
+
+		switch {
+		case !quoted && raw == "AND":
+			continue
+		case !quoted && raw == "OR":
+			orNext = len(expr.groups) > 0
+			continue
+		case !quoted && raw == "NOT":
+			negateNext = true
+			continue
+		}
+
+		c := searchClause{negate: negateNext}
+		negateNext = false
+
+		if !quoted {
+			if strings.HasPrefix(raw, "-") {
+				c.negate = true
+				raw = raw[1:]
+			}
+			if name, value, ok := strings.Cut(raw, ":"); ok && contains(searchFields, name) {
+				c.field = name
+				raw = value
+			}
+			// -"a phrase" and field:"a phrase" were cut at the first space.
+			if strings.HasPrefix(raw, `"`) {
+				raw, rest, _ = nextSearchToken(raw + rest)
+			}
+			c.prefix = strings.HasSuffix(raw, "*")
+		}
+
+		c.terms = searchTokens(raw)
+		if len(c.terms) == 0 {
+			continue
+		}
+
+		last := len(expr.groups) - 1
+		if orNext && !c.negate && !expr.groups[last][0].negate {
+			expr.groups[last] = append(expr.groups[last], c)
+		} else {
+			expr.groups = append(expr.groups, []searchClause{c})
+		}
+		orNext = false
+	}
+
+	if len(expr.groups) == 0 {
+		return expr, fmt.Errorf("search text has no words to look for")
+	}
+	return expr, nil
+}
+
+// nextSearchToken splits off the next whitespace-separated token, or the
+// next quoted phrase, which may be unterminated.
+func nextSearchToken(s string) (token, rest string, quoted bool) {
+	if phrase, ok := strings.CutPrefix(s, `"`); ok {
+		if i := strings.IndexByte(phrase, '"'); i >= 0 {
+			return phrase[:i], phrase[i+1:], true
+		}
+		return phrase, "", true
+	}
+	if i := strings.IndexFunc(s, unicode.IsSpace); i >= 0 {
+		return s[:i], s[i:], false
+	}
+	return s, "", false
+}
+
+// searchTokens lower-cases s and splits it into runs of letters and digits.
+func searchTokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// match reports whether r satisfies the expression and returns the lines
+// that matched its positive clauses.
+func (e searchExpr) match(r *domain.ForensicReport) ([]string, bool) {
+	var highlights []string
+	for _, group := range e.groups {
+		matched := false
+		for _, c := range group {
+			lines := c.lines(r)
+			if c.negate {
+				matched = len(lines) == 0
+				continue
+			}
+			if len(lines) > 0 {
+				matched = true
+				for _, line := range lines {
+					if len(highlights) < maxSearchHighlights && !contains(highlights, line) {
+						highlights = append(highlights, line)
+					}
+				}
+			}
+		}
+		if !matched {
+			return nil, false
+		}
+	}
+	return highlights, true
+}
+
+// lines returns the lines of r the clause occurs in, ignoring negation.
+func (c searchClause) lines(r *domain.ForensicReport) []string {
+	fields := searchFields
+	if c.field != "" {
+		fields = []string{c.field}
+	}
+
+	var out []string
+	for _, field := range fields {
+		for _, line := range searchFieldLines(r, field) {
+			if c.matchTokens(searchTokens(line)) {
+				out = append(out, truncateHighlight(line))
+			}
+		}
+	}
+	return out
+}
+
+func (c searchClause) matchTokens(tokens []string) bool {
+	for i := 0; i+len(c.terms) <= len(tokens); i++ {
+		ok := true
+		for j, term := range c.terms {
+			token := tokens[i+j]
+			if c.prefix && j == len(c.terms)-1 {
+				ok = strings.HasPrefix(token, term)
+			} else {
+				ok = token == term
+			}
+			if !ok {
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func searchFieldLines(r *domain.ForensicReport, field string) []string {
+	switch field {
+	case SearchFieldLogs:
+		return r.Logs
+	case SearchFieldPreviousLog:
+		return r.PreviousLog
+	case SearchFieldLastWords:
+		return r.LastWords
+	case SearchFieldEvents:
+		return eventMessages(r.Events)
+	case SearchFieldWarnings:
+		return r.Warnings
+	}
+	return nil
+}
+
+func eventMessages(events []domain.Event) []string {
+	lines := make([]string, 0, len(events))
+	for _, e := range events {
+		if e.Message != "" {
+			lines = append(lines, e.Reason+": "+e.Message)
+		}
+	}
+	return lines
+}
+
+// reportTerms returns the distinct searchable terms of r, sorted.
+func reportTerms(r *domain.ForensicReport) []string {
+	seen := make(map[string]bool)
+	for _, field := range searchFields {
+		for _, line := range searchFieldLines(r, field) {
+			for _, token := range searchTokens(line) {
+				seen[token] = true
+			}
+		}
+	}
+
+	terms := make([]string, 0, len(seen))
+	for term := range seen {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	return terms
+}
+
+func truncateHighlight(line string) string {
+	line = strings.TrimSpace(line)
+	if len(line) <= maxSearchHighlightSize {
+		return line
+	}
+	cut := maxSearchHighlightSize
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + "…"
+}
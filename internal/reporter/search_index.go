@@ -0,0 +1,276 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const searchIndexFileName = "search.jsonl"
+
+// searchIndex is an inverted index over the searchable text of the reports
+// in a Store. It is kept in search.jsonl next to the summary index, one line
+// with the distinct terms of each report, and loaded into memory on first
+// use. The summary index drives it: reports it has not seen, or whose file
+// changed, are tokenized on the next search, and deleted reports get a
+// tombstone. The index only narrows down candidates; matches are confirmed
+// against the report itself. The terms are taken from report logs, so with
+// encryption the index is only kept in memory.
+type searchIndex struct {
+	mu       sync.Mutex
+	path     string
+	keys     *Keyring
+	docs     map[string]searchDoc
+	postings map[string]map[string]bool
+	lines    int
+}
+
+type searchDoc struct {
+	Deleted bool     `json:"deleted,omitempty"`
+	ID      string   `json:"id"`
+	File    string   `json:"file,omitempty"`
+	Size    int64    `json:"size,omitempty"`
+	Terms   []string `json:"terms,omitempty"`
+}
+
+func newSearchIndex(baseDir string, keys *Keyring) *searchIndex {
+	path := filepath.Join(baseDir, searchIndexFileName)
+	if keys != nil {
+		_ = os.Remove(path)
+		path = ""
+	}
+	return &searchIndex{path: path, keys: keys}
+}
+
+// candidates brings the index up to date with entries and returns the IDs
+// of the reports that may match expr, or nil when every report may.
+func (x *searchIndex) candidates(baseDir string, entries []indexEntry, expr searchExpr) map[string]bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.docs == nil {
+		x.load()
+	}
+
+	var changed []searchDoc
+	live := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		live[e.ID] = true
+		if doc, ok := x.docs[e.ID]; ok && doc.File == e.File && doc.Size == e.Size {
+			continue
+		}
+
+		doc := searchDoc{ID: e.ID, File: e.File, Size: e.Size}
+		if report, err := readReportFile(filepath.Join(baseDir, e.File), x.keys); err == nil {
+			doc.Terms = reportTerms(report)
+		}
+		x.apply(doc)
+		changed = append(changed, doc)
+	}
+	for id := range x.docs {
+		if !live[id] {
+			doc := searchDoc{ID: id, Deleted: true}
+			x.apply(doc)
+			changed = append(changed, doc)
+		}
+	}
+
+	if err := x.persistLocked(changed); err != nil {
+		fmt.Printf("Warning: failed to update search index: %v\n", err)
+	}
+
+	return x.evaluate(expr)
+}
+
+func (x *searchIndex) evaluate(expr searchExpr) map[string]bool {
+	var result map[string]bool
+	for _, group := range expr.groups {
+		if group[0].negate {
+			continue
+		}
+
+		union := make(map[string]bool)
+		for _, c := range group {
+			for id := range x.lookup(c) {
+				union[id] = true
+			}
+		}
+
+		if result == nil {
+			result = union
+			continue
+		}
+		for id := range result {
+			if !union[id] {
+				delete(result, id)
+			}
+		}
+	}
+	return result
+}
+
+// lookup returns the reports containing every term of c, anywhere.
+func (x *searchIndex) lookup(c searchClause) map[string]bool {
+	var result map[string]bool
+	for i, term := range c.terms {
+		ids := x.postings[term]
+		if c.prefix && i == len(c.terms)-1 {
+			ids = make(map[string]bool)
+			for t, posting := range x.postings {
+				if strings.HasPrefix(t, term) {
+					for id := range posting {
+						ids[id] = true
+					}
+				}
+			}
+		}
+
+		if result == nil {
+			result = make(map[string]bool, len(ids))
+			for id := range ids {
+				result[id] = true
+			}
+			continue
+		}
+		for id := range result {
+			if !ids[id] {
+				delete(result, id)
+			}
+		}
+	}
+	return result
+}
+
+func (x *searchIndex) apply(doc searchDoc) {
+	if old, ok := x.docs[doc.ID]; ok {
+		for _, term := range old.Terms {
+			delete(x.postings[term], doc.ID)
+			if len(x.postings[term]) == 0 {
+				delete(x.postings, term)
+			}
+		}
+		delete(x.docs, doc.ID)
+	}
+	if doc.Deleted {
+		return
+	}
+
+	x.docs[doc.ID] = doc
+	for _, term := range doc.Terms {
+		if x.postings[term] == nil {
+			x.postings[term] = make(map[string]bool)
+		}
+		x.postings[term][doc.ID] = true
+	}
+}
+
+// load reads the index file. A missing or damaged file leaves the index
+// empty, so every report is tokenized again and the file rewritten.
+func (x *searchIndex) load() {
+	x.docs = make(map[string]searchDoc)
+	x.postings = make(map[string]map[string]bool)
+	x.lines = 0
+	if x.path == "" {
+		return
+	}
+
+	data, err := os.ReadFile(x.path)
+	if err != nil {
+		return
+	}
+
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var doc searchDoc
+		if line[len(line)-1] != '\n' || json.Unmarshal(line, &doc) != nil || doc.ID == "" {
+			x.docs = make(map[string]searchDoc)
+			x.postings = make(map[string]map[string]bool)
+			x.lines = -1
+			return
+		}
+		x.apply(doc)
+		x.lines++
+	}
+}
+
+func (x *searchIndex) persistLocked(changed []searchDoc) error {
+	if x.path == "" {
+		return nil
+	}
+	if x.lines >= 0 && len(changed) == 0 {
+		return nil
+	}
+	if x.lines >= 0 && (x.lines+len(changed) < 64 || x.lines+len(changed) <= 2*len(x.docs)) {
+		if err := x.appendLocked(changed); err != nil {
+			return err
+		}
+		x.lines += len(changed)
+		return nil
+	}
+	return x.rewriteLocked()
+}
+
+func (x *searchIndex) appendLocked(docs []searchDoc) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(x.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(buf.Bytes())
+	return err
+}
+
+func (x *searchIndex) rewriteLocked() error {
+	ids := make([]string, 0, len(x.docs))
+	for id := range x.docs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	tmp, err := os.CreateTemp(filepath.Dir(x.path), strings.TrimSuffix(searchIndexFileName, ".jsonl")+"_*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, id := range ids {
+		if err := enc.Encode(x.docs[id]); err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	tmp.Close()
+
+	if err := replaceFile(tmpPath, x.path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	x.lines = len(ids)
+	return nil
+}
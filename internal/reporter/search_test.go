@@ -0,0 +1,241 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+func searchFixtures() []*domain.ForensicReport {
+	reports := queryFixtures()
+	reports[0].SetLogs([]string{"dial tcp 10.0.0.7:6379: connect: connection refused", "redis: giving up"})
+	reports[1].SetLogs([]string{"dial tcp 10.0.0.9:5432: connect: connection refused"})
+	reports[2].SetPreviousLogs([]string{"Redis connection refused by upstream"})
+	reports[3].AddEvent(domain.Event{Type: "Warning", Reason: "BackOff", Message: "Back-off restarting failed container redis-proxy"})
+	reports[4].AddWarning("previous logs unavailable: connection refused")
+	reports[5].SetLastWords([]string{"refused connection to redis"})
+	return reports
+}
+
+func TestSearchReports_InMemory(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`"connection refused" redis`, "r02,r00"},
+		{`connection refused`, "r05,r04,r02,r01,r00"},
+		{`"connection refused" -redis`, "r04,r01"},
+		{`"connection refused" NOT redis`, "r04,r01"},
+		{`logs:"connection refused" redis`, "r00"},
+		{`previous_log:redis`, "r02"},
+		{`event_messages:redis*`, "r03"},
+		{`redis* OR postgres`, "r05,r03,r02,r00"},
+		{`6379 OR 5432`, "r01,r00"},
+		{`REFUSED AND Connection`, "r05,r04,r02,r01,r00"},
+		{`tcp "refused connection"`, ""},
+	}
+
+	store := &plainStorage{reports: map[string]*domain.ForensicReport{}}
+	for _, r := range searchFixtures() {
+		store.reports[r.ID] = r
+	}
+	for _, tt := range tests {
+		res, err := SearchReports(store, SearchQuery{Text: tt.text})
+		if err != nil {
+			t.Fatalf("SearchReports(%s) error = %v", tt.text, err)
+		}
+		if got := hitIDs(res.Hits); got != tt.want {
+			t.Errorf("SearchReports(%s) = %s, want %s", tt.text, got, tt.want)
+		}
+		if res.Total != len(res.Hits) {
+			t.Errorf("SearchReports(%s) Total = %d, want %d", tt.text, res.Total, len(res.Hits))
+		}
+	}
+
+	res, err := SearchReports(store, SearchQuery{Text: `"connection refused"`, Query: Query{Namespace: "payments", Limit: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hitIDs(res.Hits) != "r02" || res.Total != 2 || res.NextCursor == "" {
+		t.Errorf("filtered search = %s (total %d)", hitIDs(res.Hits), res.Total)
+	}
+	if got := res.Hits[0].Highlights; len(got) != 1 || got[0] != "Redis connection refused by upstream" {
+		t.Errorf("Highlights = %q", got)
+	}
+}
+
+func TestSearchQuery_Validate(t *testing.T) {
+	for _, q := range []SearchQuery{
+		{},
+		{Text: "  "},
+		{Text: `- "" *`},
+		{Text: "redis", Query: Query{Sort: "sideways"}},
+	} {
+		if err := q.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", q)
+		}
+	}
+}
+
+func TestStore_Search(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir, WithCompression("gzip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reports := searchFixtures()
+	for _, r := range reports {
+		if err := store.Save(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := store.Search(SearchQuery{Text: `"connection refused" redis`})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if hitIDs(res.Hits) != "r02,r00" {
+		t.Errorf("Search() = %s, want r02,r00", hitIDs(res.Hits))
+	}
+
+	// Changed and removed reports are picked up by a store that starts
+	// from the persisted index.
+	reports[1].SetLogs([]string{"redis connection refused"})
+	if err := store.Save(reports[1]); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, reports[0].ID+"_*"))
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reopened, err := NewStore(dir, WithCompression("gzip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = reopened.Search(SearchQuery{Text: `"connection refused" redis`})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if hitIDs(res.Hits) != "r02,r01" {
+		t.Errorf("Search() after update and delete = %s, want r02,r01", hitIDs(res.Hits))
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, searchIndexFileName))
+	if err != nil {
+		t.Fatalf("search index not written: %v", err)
+	}
+	if !strings.Contains(string(data), `"terms":[`) || !strings.Contains(string(data), `"deleted":true`) {
+		t.Errorf("search index = %s", data)
+	}
+}
+
+func TestStore_SearchRebuildsCorruptIndex(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range searchFixtures() {
+		if err := store.Save(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, searchIndexFileName), []byte(`{"id":"r00","terms":["redis"]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := store.Search(SearchQuery{Text: "6379"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if hitIDs(res.Hits) != "r00" {
+		t.Errorf("Search() = %s, want r00", hitIDs(res.Hits))
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, searchIndexFileName))
+	if lines := strings.Count(string(data), "\n"); lines != 9 {
+		t.Errorf("rebuilt search index has %d lines, want 9", lines)
+	}
+}
+
+func TestElasticStore_Search(t *testing.T) {
+	var search map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &search)
+
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"hits": map[string]any{
+				"total": map[string]any{"value": 1},
+				"hits": []any{map[string]any{
+					"_source":   map[string]any{"id": "r-1", "collected_at": "2024-03-01T12:01:00Z"},
+					"highlight": map[string]any{"logs": []string{"connect: connection refused"}, "event_messages": []string{"BackOff: redis down"}},
+				}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{server.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &ElasticStore{client: client, indexName: "kubecrsh-reports"}
+
+	res, err := store.Search(SearchQuery{Text: `"connection refused" AND redis`, Query: Query{Namespace: "payments", Since: queryBase}})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	encoded, _ := json.Marshal(search)
+	for _, want := range []string{
+		`"query_string":{"default_operator":"AND","fields":["logs","previous_log","last_words","event_messages","warnings"],"query":"\"connection refused\" AND redis"}`,
+		`{"term":{"crash.namespace":"payments"}}`,
+		`"range":{"collected_at":{"gte":"2024-03-01T12:00:00Z"}}`,
+		`"highlight":{`,
+	} {
+		if !strings.Contains(string(encoded), want) {
+			t.Errorf("search body does not contain %s: %s", want, encoded)
+		}
+	}
+
+	if hitIDs(res.Hits) != "r-1" || res.Total != 1 {
+		t.Fatalf("Search() = %s (total %d)", hitIDs(res.Hits), res.Total)
+	}
+	if got := res.Hits[0].Highlights; len(got) != 2 || got[0] != "connect: connection refused" || got[1] != "BackOff: redis down" {
+		t.Errorf("Highlights = %q", got)
+	}
+}
+
+func TestElasticStore_DocumentCarriesEventText(t *testing.T) {
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+	report.AddEvent(domain.Event{Reason: "BackOff", Message: "Back-off restarting failed container"})
+	report.CollectedAt = time.Now()
+
+	doc := (&ElasticStore{}).toDocument(report)
+	if len(doc.EventText) != 1 || doc.EventText[0] != "BackOff: Back-off restarting failed container" {
+		t.Errorf("EventText = %q", doc.EventText)
+	}
+}
+
+func hitIDs(hits []SearchHit) string {
+	out := make([]string, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, h.Report.ID)
+	}
+	return strings.Join(out, ",")
+}
@@ -0,0 +1,586 @@
+package reporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	_ "modernc.org/sqlite"
+)
+
+var _ Storage = (*SQLiteStore)(nil)
+var _ SaveWithResult = (*SQLiteStore)(nil)
+var _ Grouper = (*SQLiteStore)(nil)
+var _ Triager = (*SQLiteStore)(nil)
+var _ Querier = (*SQLiteStore)(nil)
+var _ PolicyPruner = (*SQLiteStore)(nil)
+
+// Each entry upgrades the database by one version; the current version is
+// kept in PRAGMA user_version. Never edit a released entry, append a new one.
+var sqliteMigrations = []string{
+	`CREATE TABLE reports (
+		id             TEXT PRIMARY KEY,
+		namespace      TEXT NOT NULL,
+		pod_name       TEXT NOT NULL,
+		workload       TEXT NOT NULL,
+		container      TEXT NOT NULL,
+		reason         TEXT NOT NULL,
+		exit_code      INTEGER NOT NULL,
+		fingerprint    TEXT NOT NULL,
+		signature      TEXT NOT NULL,
+		severity       TEXT NOT NULL,
+		status         TEXT NOT NULL,
+		collected_at   INTEGER NOT NULL,
+		schema_version INTEGER NOT NULL,
+		size           INTEGER NOT NULL,
+		body           BLOB NOT NULL
+	);
+	CREATE INDEX reports_namespace ON reports (namespace, collected_at);
+	CREATE INDEX reports_pod_name ON reports (pod_name, collected_at);
+	CREATE INDEX reports_workload ON reports (workload, collected_at);
+	CREATE INDEX reports_reason ON reports (reason, collected_at);
+	CREATE INDEX reports_collected_at ON reports (collected_at);
+	CREATE INDEX reports_fingerprint ON reports (fingerprint, collected_at);`,
+	// Existing rows may have logs; retention clears the flag when it finds
+	// out otherwise.
+	`ALTER TABLE reports ADD COLUMN has_logs INTEGER NOT NULL DEFAULT 1;`,
+}
+
+type SQLiteStore struct {
+	db         *sql.DB
+	path       string
+	importDir  string
+	importOpts []ImportOption
+}
+
+type SQLiteOption func(*SQLiteStore)
+
+// WithImportDir imports the reports of a file store directory with Import
+// when the database is created, so switching backends keeps existing
+// reports. The options describe the file store, such as its encryption keys.
+func WithImportDir(dir string, opts ...ImportOption) SQLiteOption {
+	return func(s *SQLiteStore) {
+		s.importDir = dir
+		s.importOpts = opts
+	}
+}
+
+// sqliteDSN builds the file: URI for path. SQLite opens it in URI mode, so
+// each path segment is escaped to keep "?", "#" and "%" in file names from
+// being read as the query, fragment or an escape.
+func sqliteDSN(path string) string {
+	segments := strings.Split(filepath.ToSlash(path), "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	u := url.URL{
+		Scheme: "file",
+		Opaque: strings.Join(segments, "/"),
+		RawQuery: url.Values{
+			"_pragma": {"busy_timeout(5000)", "journal_mode(WAL)", "synchronous(NORMAL)"},
+			"_txlock": {"immediate"},
+		}.Encode(),
+	}
+	return u.String()
+}
+
+func NewSQLiteStore(path string, opts ...SQLiteOption) (*SQLiteStore, error) {
+	if path == "" {
+		path = filepath.Join("reports", "reports.db")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", sqliteDSN(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	s := &SQLiteStore{db: db, path: path}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	from, err := s.migrate()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if from == 0 && s.importDir != "" {
+		if err := s.importReports(); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (s *SQLiteStore) importReports() error {
+	if _, err := os.Stat(s.importDir); os.IsNotExist(err) {
+		return nil
+	}
+	res, err := Import(s, []string{s.importDir}, s.importOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to import reports from %s: %w", s.importDir, err)
+	}
+	if res.Imported+res.Remapped > 0 || res.Failed > 0 {
+		fmt.Printf("Imported %d reports from %s into %s (%d failed)\n", res.Imported+res.Remapped, s.importDir, s.path, res.Failed)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) migrate() (int, error) {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read database version: %w", err)
+	}
+
+	if version > len(sqliteMigrations) {
+		return version, fmt.Errorf("database version %d is newer than supported version %d", version, len(sqliteMigrations))
+	}
+
+	for v := version; v < len(sqliteMigrations); v++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return version, fmt.Errorf("failed to migrate database: %w", err)
+		}
+		if _, err := tx.Exec(sqliteMigrations[v]); err != nil {
+			tx.Rollback()
+			return version, fmt.Errorf("failed to migrate database to version %d: %w", v+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", v+1)); err != nil {
+			tx.Rollback()
+			return version, fmt.Errorf("failed to migrate database to version %d: %w", v+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return version, fmt.Errorf("failed to migrate database to version %d: %w", v+1, err)
+		}
+	}
+
+	return version, nil
+}
+
+func (s *SQLiteStore) Save(report *domain.ForensicReport) error {
+	_, err := s.SaveWithResult(report)
+	return err
+}
+
+func (s *SQLiteStore) SaveWithResult(report *domain.ForensicReport) (SaveResult, error) {
+	n, err := saveSQLite(s.db, report)
+	if err != nil {
+		return SaveResult{}, err
+	}
+	return SaveResult{BytesWritten: n, Path: s.path}, nil
+}
+
+type sqlExecer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func saveSQLite(db sqlExecer, report *domain.ForensicReport) (int64, error) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	var body bytes.Buffer
+	gw := gzip.NewWriter(&body)
+	if _, err := gw.Write(data); err != nil {
+		return 0, fmt.Errorf("failed to compress report: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return 0, fmt.Errorf("failed to compress report: %w", err)
+	}
+
+	_, err = db.Exec(`INSERT INTO reports (
+			id, namespace, pod_name, workload, container, reason, exit_code,
+			fingerprint, signature, severity, status, collected_at, schema_version, size, body, has_logs
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			namespace = excluded.namespace,
+			pod_name = excluded.pod_name,
+			workload = excluded.workload,
+			container = excluded.container,
+			reason = excluded.reason,
+			exit_code = excluded.exit_code,
+			fingerprint = excluded.fingerprint,
+			signature = excluded.signature,
+			severity = excluded.severity,
+			status = excluded.status,
+			collected_at = excluded.collected_at,
+			schema_version = excluded.schema_version,
+			size = excluded.size,
+			body = excluded.body,
+			has_logs = excluded.has_logs`,
+		report.ID,
+		report.Crash.Namespace,
+		report.Crash.PodName,
+		report.Crash.WorkloadName(),
+		report.Crash.ContainerName,
+		report.Crash.Reason,
+		report.Crash.ExitCode,
+		report.GroupKey(),
+		report.GroupSignature(),
+		report.SeverityLevel(),
+		report.TriageStatus(),
+		report.CollectedAt.UnixNano(),
+		domain.SchemaVersion,
+		len(data),
+		body.Bytes(),
+		hasLogs(report),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save report: %w", err)
+	}
+
+	return int64(body.Len()), nil
+}
+
+type sqlQueryer interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func loadSQLite(db sqlQueryer, id string) (*domain.ForensicReport, error) {
+	var body []byte
+	err := db.QueryRow("SELECT body FROM reports WHERE id = ?", id).Scan(&body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("report not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load report: %w", err)
+	}
+
+	return decodeCompressedReport(body)
+}
+
+func decodeCompressedReport(body []byte) (*domain.ForensicReport, error) {
+	gr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open compressed report: %w", err)
+	}
+	defer gr.Close()
+
+	data, err := io.ReadAll(gr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+
+	return domain.DecodeReport(data)
+}
+
+func (s *SQLiteStore) Load(id string) (*domain.ForensicReport, error) {
+	return loadSQLite(s.db, id)
+}
+
+func (s *SQLiteStore) List() ([]*domain.ForensicReport, error) {
+	rows, err := s.db.Query("SELECT body FROM reports ORDER BY collected_at DESC, id DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*domain.ForensicReport
+	for rows.Next() {
+		var body []byte
+		if err := rows.Scan(&body); err != nil {
+			return nil, fmt.Errorf("failed to list reports: %w", err)
+		}
+
+		report, err := decodeCompressedReport(body)
+		if err != nil {
+			continue
+		}
+
+		reports = append(reports, report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+
+	return reports, nil
+}
+
+func (s *SQLiteStore) Query(q Query) (QueryResult, error) {
+	var where []string
+	var args []any
+	add := func(cond string, values ...any) {
+		where = append(where, cond)
+		args = append(args, values...)
+	}
+
+	for _, f := range []struct{ column, value string }{
+		{"namespace", q.Namespace},
+		{"workload", q.Workload},
+		{"pod_name", q.PodName},
+		{"reason", q.Reason},
+		{"fingerprint", q.Fingerprint},
+		{"status", q.Status},
+	} {
+		if f.value != "" {
+			add(f.column+" = ?", f.value)
+		}
+	}
+	if q.ExitCode != nil {
+		add("exit_code = ?", *q.ExitCode)
+	}
+	if levels := q.severityLevels(); levels != nil {
+		if len(levels) == 0 {
+			return QueryResult{}, nil
+		}
+		add("severity IN (?"+strings.Repeat(", ?", len(levels)-1)+")", toAny(levels)...)
+	}
+	if !q.Since.IsZero() {
+		add("collected_at >= ?", q.Since.UnixNano())
+	}
+	if !q.Until.IsZero() {
+		add("collected_at < ?", q.Until.UnixNano())
+	}
+
+	filter := ""
+	if len(where) > 0 {
+		filter = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var res QueryResult
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM reports"+filter, args...).Scan(&res.Total); err != nil {
+		return QueryResult{}, fmt.Errorf("failed to count reports: %w", err)
+	}
+
+	order, cmp := "DESC", "<"
+	if q.Sort == SortOldest {
+		order, cmp = "ASC", ">"
+	}
+
+	if q.Cursor != "" {
+		at, id, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return QueryResult{}, err
+		}
+		add(fmt.Sprintf("(collected_at %s ? OR (collected_at = ? AND id %s ?))", cmp, cmp), at.UnixNano(), at.UnixNano(), id)
+		filter = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	limit := q.limit()
+	page := fmt.Sprintf(" ORDER BY collected_at %s, id %s LIMIT %d", order, order, limit+1)
+	if q.Cursor == "" && q.Offset > 0 {
+		page += fmt.Sprintf(" OFFSET %d", q.Offset)
+	}
+
+	rows, err := s.db.Query("SELECT body FROM reports"+filter+page, args...)
+	if err != nil {
+		return QueryResult{}, fmt.Errorf("failed to query reports: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var body []byte
+		if err := rows.Scan(&body); err != nil {
+			return QueryResult{}, fmt.Errorf("failed to query reports: %w", err)
+		}
+
+		report, err := decodeCompressedReport(body)
+		if err != nil {
+			continue
+		}
+		res.Reports = append(res.Reports, report)
+	}
+	if err := rows.Err(); err != nil {
+		return QueryResult{}, fmt.Errorf("failed to query reports: %w", err)
+	}
+
+	if len(res.Reports) > limit {
+		res.Reports = res.Reports[:limit]
+		last := res.Reports[limit-1]
+		res.NextCursor = EncodeCursor(last.CollectedAt, last.ID)
+	}
+
+	return res, nil
+}
+
+func toAny(values []string) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
+
+func (s *SQLiteStore) Groups() ([]domain.CrashGroup, error) {
+	rows, err := s.db.Query(`SELECT fingerprint, namespace, workload, container, reason, exit_code, signature, total, first_seen, collected_at, id
+		FROM (
+			SELECT *,
+				ROW_NUMBER() OVER (PARTITION BY fingerprint ORDER BY collected_at DESC, rowid DESC) AS rn,
+				COUNT(*) OVER (PARTITION BY fingerprint) AS total,
+				MIN(collected_at) OVER (PARTITION BY fingerprint) AS first_seen
+			FROM reports
+		)
+		WHERE rn = 1
+		ORDER BY collected_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to group reports: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []domain.CrashGroup
+	for rows.Next() {
+		var g domain.CrashGroup
+		var firstSeen, lastSeen int64
+		if err := rows.Scan(&g.Fingerprint, &g.Namespace, &g.Workload, &g.Container, &g.Reason, &g.ExitCode,
+			&g.Signature, &g.Count, &firstSeen, &lastSeen, &g.LatestReportID); err != nil {
+			return nil, fmt.Errorf("failed to group reports: %w", err)
+		}
+		g.FirstSeen = time.Unix(0, firstSeen)
+		g.LastSeen = time.Unix(0, lastSeen)
+		groups = append(groups, g)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to group reports: %w", err)
+	}
+
+	return groups, nil
+}
+
+func (s *SQLiteStore) Prune(retention time.Duration) (PruneResult, error) {
+	return s.PruneWithPolicy(RetentionPolicy{MaxAge: retention})
+}
+
+// PruneWithPolicy plans the run from the indexed columns, so only reports
+// whose logs are stripped are read. Deleted rows free pages for reuse rather
+// than shrinking the file.
+func (s *SQLiteStore) PruneWithPolicy(p RetentionPolicy) (PruneResult, error) {
+	var res PruneResult
+	if p.IsZero() {
+		return res, nil
+	}
+
+	rows, err := s.db.Query("SELECT id, namespace, workload, collected_at, length(body), has_logs FROM reports")
+	if err != nil {
+		return res, fmt.Errorf("failed to list reports: %w", err)
+	}
+	var items []retentionItem
+	for rows.Next() {
+		var it retentionItem
+		var collectedAt int64
+		if err := rows.Scan(&it.ID, &it.Namespace, &it.Workload, &collectedAt, &it.Size, &it.HasLogs); err != nil {
+			rows.Close()
+			return res, fmt.Errorf("failed to list reports: %w", err)
+		}
+		it.CollectedAt = time.Unix(0, collectedAt)
+		items = append(items, it)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return res, fmt.Errorf("failed to list reports: %w", err)
+	}
+
+	actions := planRetention(items, p, time.Now())
+	if p.DryRun {
+		res.Actions = actions
+		res.tally(len(items))
+		return res, nil
+	}
+
+	var firstErr error
+	for _, a := range actions {
+		var err error
+		if a.Action == RetentionStrip {
+			a.Bytes, err = s.strip(a.ID, p.StripLogsAfter)
+		} else {
+			_, err = s.db.Exec("DELETE FROM reports WHERE id = ?", a.ID)
+		}
+		if err != nil {
+			res.Failed++
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to %s report %s: %w", a.Action, a.ID, err)
+			}
+			continue
+		}
+		if a.Action == RetentionStrip && a.Bytes < 0 {
+			continue
+		}
+		res.Actions = append(res.Actions, a)
+	}
+
+	res.tally(len(items))
+	return res, firstErr
+}
+
+// strip removes the logs of a report and returns the bytes reclaimed, or -1
+// when it had none.
+func (s *SQLiteStore) strip(id string, after time.Duration) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var before int64
+	if err := tx.QueryRow("SELECT length(body) FROM reports WHERE id = ?", id).Scan(&before); err != nil {
+		return 0, err
+	}
+	report, err := loadSQLite(tx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	reclaimed := int64(-1)
+	if stripLogs(report, after) {
+		size, err := saveSQLite(tx, report)
+		if err != nil {
+			return 0, err
+		}
+		reclaimed = max(before-size, 0)
+	} else if _, err := tx.Exec("UPDATE reports SET has_logs = 0 WHERE id = ?", id); err != nil {
+		return 0, err
+	}
+
+	return reclaimed, tx.Commit()
+}
+
+func (s *SQLiteStore) UpdateTriage(id string, update domain.TriageUpdate, actor domain.TriageActor) (*domain.ForensicReport, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to update triage: %w", err)
+	}
+	defer tx.Rollback()
+
+	report, err := loadSQLite(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := report.ApplyTriage(update, actor, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if _, err := saveSQLite(tx, report); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to update triage: %w", err)
+	}
+
+	return report, nil
+}
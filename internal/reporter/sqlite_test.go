@@ -0,0 +1,196 @@
+package reporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+func newTestSQLiteStore(t *testing.T, opts ...SQLiteOption) *SQLiteStore {
+	t.Helper()
+
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "reports.db"), opts...)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+func TestNewSQLiteStore_PathWithURIDelimiters(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "crash?mode=ro#a%20b")
+	path := filepath.Join(dir, "reports.db")
+
+	store, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+	if err := store.Save(report); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	store.Close()
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("database not created at %s: %v", path, err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(dir))
+	if len(entries) != 1 {
+		t.Errorf("files next to the database directory = %d, want 1", len(entries))
+	}
+}
+
+func TestSQLiteStore_SaveLoadList(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, pod := range []string{"api-7d9f8b6c5-abcde", "api-7d9f8b6c5-fghij", "worker-0"} {
+		report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: pod, ContainerName: "app", Reason: "Error", ExitCode: 1})
+		report.ID = pod
+		report.CollectedAt = base.Add(time.Duration(i) * time.Minute)
+		report.SetLogs([]string{"panic: timeout after 30s"})
+		report.UpdateFingerprint()
+
+		res, err := store.SaveWithResult(report)
+		if err != nil {
+			t.Fatalf("SaveWithResult() error = %v", err)
+		}
+		if res.BytesWritten == 0 {
+			t.Error("BytesWritten = 0, want the compressed size")
+		}
+	}
+
+	loaded, err := store.Load("worker-0")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Crash.PodName != "worker-0" || len(loaded.Logs) != 1 {
+		t.Errorf("Load() = %+v", loaded.Crash)
+	}
+
+	if _, err := store.Load("missing"); err == nil {
+		t.Error("Load() of a missing report should fail")
+	}
+
+	reports, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(reports) != 3 || reports[0].ID != "worker-0" {
+		t.Fatalf("List() returned %d reports, want 3 newest first", len(reports))
+	}
+
+	groups, err := store.Groups()
+	if err != nil {
+		t.Fatalf("Groups() error = %v", err)
+	}
+	want := domain.GroupReports(reports)
+	if len(groups) != len(want) {
+		t.Fatalf("Groups() returned %d groups, want %d", len(groups), len(want))
+	}
+	for i := range want {
+		g, w := groups[i], want[i]
+		if g.Fingerprint != w.Fingerprint || g.Count != w.Count || g.LatestReportID != w.LatestReportID ||
+			g.Signature != w.Signature || !g.FirstSeen.Equal(w.FirstSeen) || !g.LastSeen.Equal(w.LastSeen) {
+			t.Errorf("group %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestSQLiteStore_UpdateTriage(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api", Reason: "Error"})
+	if err := store.Save(report); err != nil {
+		t.Fatal(err)
+	}
+
+	acked := domain.TriageAcknowledged
+	updated, err := store.UpdateTriage(report.ID, domain.TriageUpdate{Status: &acked}, domain.TriageActor{Name: "alice", Source: "api"})
+	if err != nil {
+		t.Fatalf("UpdateTriage() error = %v", err)
+	}
+	if updated.TriageStatus() != domain.TriageAcknowledged {
+		t.Errorf("TriageStatus() = %s", updated.TriageStatus())
+	}
+
+	reports, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 || reports[0].TriageStatus() != domain.TriageAcknowledged {
+		t.Errorf("List() = %d reports, want the single updated report", len(reports))
+	}
+}
+
+func TestSQLiteStore_Prune(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	for i, age := range []time.Duration{time.Hour, 48 * time.Hour, 72 * time.Hour} {
+		report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+		report.ID = string(rune('a' + i))
+		report.CollectedAt = time.Now().Add(-age)
+		if err := store.Save(report); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := store.Prune(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if res.Deleted != 2 || res.Kept != 1 {
+		t.Errorf("Prune() = %+v, want 2 deleted and 1 kept", res)
+	}
+}
+
+func TestSQLiteStore_ImportsDirectoryOnCreate(t *testing.T) {
+	dir := t.TempDir()
+	files, err := NewStore(dir, WithCompression("gzip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pod := range []string{"api", "worker"} {
+		if err := files.Save(domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: pod, ContainerName: "app"})); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := filepath.Join(dir, "reports.db")
+	store, err := NewSQLiteStore(path, WithImportDir(dir))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+
+	reports, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 {
+		t.Errorf("List() after import returned %d reports, want 2", len(reports))
+	}
+
+	if err := store.Save(domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "late"})); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	// Reopening an existing database must not import again or lose data.
+	store, err = NewSQLiteStore(path, WithImportDir(dir))
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	defer store.Close()
+
+	reports, err = store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 3 {
+		t.Errorf("List() after reopen returned %d reports, want 3", len(reports))
+	}
+}
@@ -1,6 +1,7 @@
 package reporter
 
 import (
+	"bytes"
 	"compress/gzip"
 	"encoding/json"
 	"fmt"
@@ -15,10 +16,17 @@ import (
 
 var _ Storage = (*Store)(nil)
 var _ SaveWithResult = (*Store)(nil)
+var _ Querier = (*Store)(nil)
+var _ Grouper = (*Store)(nil)
+var _ Searcher = (*Store)(nil)
+var _ PolicyPruner = (*Store)(nil)
 
 type Store struct {
 	baseDir     string
 	compression string
+	keys        *Keyring
+	index       *summaryIndex
+	search      *searchIndex
 	mu          sync.RWMutex
 }
 
@@ -30,6 +38,14 @@ func WithCompression(compression string) Option {
 	}
 }
 
+// WithEncryption seals new reports with the active key of keys and opens
+// encrypted reports on read. A nil keyring leaves reports unencrypted.
+func WithEncryption(keys *Keyring) Option {
+	return func(s *Store) {
+		s.keys = keys
+	}
+}
+
 func NewStore(baseDir string, opts ...Option) (*Store, error) {
 	if baseDir == "" {
 		baseDir = "reports"
@@ -43,6 +59,8 @@ func NewStore(baseDir string, opts ...Option) (*Store, error) {
 	for _, opt := range opts {
 		opt(s)
 	}
+	s.index = newSummaryIndex(baseDir, s.keys)
+	s.search = newSearchIndex(baseDir, s.keys)
 
 	if s.compression == "" {
 		s.compression = "none"
@@ -65,11 +83,15 @@ func (s *Store) SaveWithResult(report *domain.ForensicReport) (SaveResult, error
 }
 
 func (s *Store) saveLocked(report *domain.ForensicReport) (SaveResult, error) {
+	compress := s.compression == "gzip" || s.compression == "gz"
 
 	ext := ".json"
-	if s.compression == "gzip" || s.compression == "gz" {
+	if compress {
 		ext = ".json.gz"
 	}
+	if s.keys != nil {
+		ext += encryptedExt
+	}
 
 	filename := fmt.Sprintf("%s_%s_%s%s",
 		report.ID,
@@ -90,17 +112,22 @@ func (s *Store) saveLocked(report *domain.ForensicReport) (SaveResult, error) {
 	writeErr := func() error {
 		defer tmp.Close()
 
-		var w io.Writer = tmp
-		if ext == ".json.gz" {
-			gw := gzip.NewWriter(tmp)
-			defer gw.Close()
-			w = gw
-		}
-		w = &countingWriter{w: w, n: &bytesWritten}
-
-		enc := json.NewEncoder(w)
-		if err := enc.Encode(report); err != nil {
-			return fmt.Errorf("failed to encode report: %w", err)
+		if s.keys == nil {
+			if err := encodeReport(tmp, report, compress, &bytesWritten); err != nil {
+				return err
+			}
+		} else {
+			var buf bytes.Buffer
+			if err := encodeReport(&buf, report, compress, &bytesWritten); err != nil {
+				return err
+			}
+			sealed, err := s.keys.seal(buf.Bytes(), report.ID)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt report: %w", err)
+			}
+			if _, err := tmp.Write(sealed); err != nil {
+				return fmt.Errorf("failed to write report: %w", err)
+			}
 		}
 
 		if err := tmp.Sync(); err != nil {
@@ -119,6 +146,26 @@ func (s *Store) saveLocked(report *domain.ForensicReport) (SaveResult, error) {
 		return SaveResult{}, fmt.Errorf("failed to move report into place: %w", err)
 	}
 
+	// A copy written with other compression or encryption settings would
+	// otherwise shadow this one, or keep a plaintext copy around.
+	if files, err := s.globByID(report.ID); err == nil {
+		for _, file := range files {
+			if file != path {
+				_ = os.Remove(file)
+			}
+		}
+	}
+
+	// The index holds the size on disk, which is what size-based retention
+	// has to reclaim.
+	size := bytesWritten
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+	if err := s.index.put(summarize(report, filename, size)); err != nil {
+		fmt.Printf("Warning: failed to update report index: %v\n", err)
+	}
+
 	return SaveResult{BytesWritten: bytesWritten, Path: path}, nil
 }
 
@@ -135,85 +182,281 @@ func (s *Store) Load(id string) (*domain.ForensicReport, error) {
 		return nil, fmt.Errorf("report not found: %s", id)
 	}
 
-	var report domain.ForensicReport
-	if err := readJSONFile(files[0], &report); err != nil {
-		return nil, err
-	}
-
-	return &report, nil
+	return readReportFile(files[0], s.keys)
 }
 
 func (s *Store) List() ([]*domain.ForensicReport, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	files, err := s.globAll()
+	entries, err := s.summaries()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list reports: %w", err)
+		return nil, err
 	}
 
-	reports := make([]*domain.ForensicReport, 0, len(files))
-	for _, file := range files {
-		var report domain.ForensicReport
-		if err := readJSONFile(file, &report); err != nil {
+	reports := make([]*domain.ForensicReport, 0, len(entries))
+	for _, e := range entries {
+		report, err := readReportFile(filepath.Join(s.baseDir, e.File), s.keys)
+		if err != nil {
 			continue
 		}
 
-		reports = append(reports, &report)
+		reports = append(reports, report)
 	}
 
 	return reports, nil
 }
 
-func (s *Store) globByID(id string) ([]string, error) {
-	jsonFiles, err := filepath.Glob(filepath.Join(s.baseDir, id+"_*.json"))
+// Query filters, sorts and pages on the summary index and only reads the
+// report files of the requested page.
+func (s *Store) Query(q Query) (QueryResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := s.summaries()
 	if err != nil {
-		return nil, fmt.Errorf("failed to search for report: %w", err)
+		return QueryResult{}, err
 	}
-	gzFiles, err := filepath.Glob(filepath.Join(s.baseDir, id+"_*.json.gz"))
+
+	files := make(map[string]string, len(entries))
+	stubs := make([]*domain.ForensicReport, 0, len(entries))
+	for _, e := range entries {
+		files[e.ID] = e.File
+		stubs = append(stubs, e.stub())
+	}
+
+	res, err := q.Apply(stubs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search for report: %w", err)
+		return QueryResult{}, err
+	}
+
+	page := make([]*domain.ForensicReport, 0, len(res.Reports))
+	for _, stub := range res.Reports {
+		report, err := readReportFile(filepath.Join(s.baseDir, files[stub.ID]), s.keys)
+		if err != nil {
+			continue
+		}
+		page = append(page, report)
 	}
-	return append(jsonFiles, gzFiles...), nil
+	res.Reports = page
+
+	return res, nil
 }
 
-func (s *Store) globAll() ([]string, error) {
-	jsonFiles, err := filepath.Glob(filepath.Join(s.baseDir, "*.json"))
+// Search looks the text up in the full-text index and only reads the
+// report files that may match.
+func (s *Store) Search(q SearchQuery) (SearchResult, error) {
+	expr, err := parseSearchText(q.Text)
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := s.summaries()
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	candidates := s.search.candidates(s.baseDir, entries, expr)
+
+	reports := make([]*domain.ForensicReport, 0, len(entries))
+	for _, e := range entries {
+		if candidates != nil && !candidates[e.ID] || !q.Matches(e.stub()) {
+			continue
+		}
+		report, err := readReportFile(filepath.Join(s.baseDir, e.File), s.keys)
+		if err != nil {
+			continue
+		}
+		reports = append(reports, report)
+	}
+
+	return q.apply(expr, reports)
+}
+
+func (s *Store) Groups() ([]domain.CrashGroup, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := s.summaries()
 	if err != nil {
 		return nil, err
 	}
-	gzFiles, err := filepath.Glob(filepath.Join(s.baseDir, "*.json.gz"))
+
+	stubs := make([]*domain.ForensicReport, 0, len(entries))
+	for _, e := range entries {
+		stubs = append(stubs, e.stub())
+	}
+
+	return domain.GroupReports(stubs), nil
+}
+
+// summaries returns the index entries of every readable report.
+func (s *Store) summaries() ([]indexEntry, error) {
+	entries, err := s.allSummaries()
 	if err != nil {
 		return nil, err
 	}
-	return append(jsonFiles, gzFiles...), nil
+
+	valid := entries[:0]
+	for _, e := range entries {
+		if !e.Invalid {
+			valid = append(valid, e)
+		}
+	}
+	return valid, nil
 }
 
-func readJSONFile(path string, dst any) error {
-	f, err := os.Open(path)
+func (s *Store) allSummaries() ([]indexEntry, error) {
+	files, err := s.globAll()
 	if err != nil {
-		return fmt.Errorf("failed to open report: %w", err)
+		return nil, fmt.Errorf("failed to list reports: %w", err)
 	}
-	defer f.Close()
 
-	var r io.Reader = f
-	if strings.HasSuffix(path, ".gz") {
-		gr, err := gzip.NewReader(f)
+	entries, err := s.index.sync(files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report index: %w", err)
+	}
+	return entries, nil
+}
+
+// reportExts are the extensions of report files: plain or gzipped JSON,
+// either of them optionally encrypted.
+var reportExts = []string{".json", ".json.gz", ".json" + encryptedExt, ".json.gz" + encryptedExt}
+
+// globByID returns the files of report id. The glob only narrows the
+// search: "a_*" also matches the files of report "a_b", so a match is kept
+// only when the ID in its name is exactly id.
+func (s *Store) globByID(id string) ([]string, error) {
+	var files []string
+	for _, ext := range reportExts {
+		matches, err := filepath.Glob(filepath.Join(s.baseDir, globEscape(id)+"_*"+ext))
 		if err != nil {
-			return fmt.Errorf("failed to open gzip report: %w", err)
+			return nil, fmt.Errorf("failed to search for report: %w", err)
 		}
-		defer gr.Close()
-		r = gr
+		for _, match := range matches {
+			if reportFileID(match) == id {
+				files = append(files, match)
+			}
+		}
+	}
+	return files, nil
+}
+
+// reportFileID returns the report ID from a file named id_namespace_pod.
+// Kubernetes names never contain underscores, so everything before the last
+// two is the ID.
+func reportFileID(path string) string {
+	name := filepath.Base(path)
+	for range 2 {
+		i := strings.LastIndexByte(name, '_')
+		if i < 0 {
+			return ""
+		}
+		name = name[:i]
 	}
+	return name
+}
+
+// globEscape quotes the glob metacharacters in s as character classes,
+// which unlike backslashes also work on Windows.
+func globEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("*?[", r) {
+			b.WriteString("[" + string(r) + "]")
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (s *Store) globAll() ([]string, error) {
+	var files []string
+	for _, ext := range reportExts {
+		matches, err := filepath.Glob(filepath.Join(s.baseDir, "*"+ext))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
 
-	dec := json.NewDecoder(r)
-	if err := dec.Decode(dst); err != nil {
+func encodeReport(w io.Writer, report *domain.ForensicReport, compress bool, n *int64) error {
+	var gw *gzip.Writer
+	if compress {
+		gw = gzip.NewWriter(w)
+		w = gw
+	}
+
+	if err := json.NewEncoder(&countingWriter{w: w, n: n}).Encode(report); err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	if gw != nil {
+		if err := gw.Close(); err != nil {
+			return fmt.Errorf("failed to compress report: %w", err)
+		}
+	}
+	return nil
+}
+
+func readReportFile(path string, keys *Keyring) (*domain.ForensicReport, error) {
+	data, err := readFile(path, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.DecodeReport(data)
+}
+
+func readJSONFile(path string, keys *Keyring, dst any) error {
+	data, err := readFile(path, keys)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, dst); err != nil {
 		return fmt.Errorf("failed to decode report: %w", err)
 	}
 
 	return nil
 }
 
+func readFile(path string, keys *Keyring) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open report: %w", err)
+	}
+
+	if name, ok := strings.CutSuffix(path, encryptedExt); ok {
+		if keys == nil {
+			return nil, fmt.Errorf("report %s is encrypted and no encryption key is configured", filepath.Base(path))
+		}
+		if data, err = keys.open(data, reportFileID(name)); err != nil {
+			return nil, err
+		}
+		path = name
+	}
+
+	if strings.HasSuffix(path, ".gz") {
+		gr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip report: %w", err)
+		}
+		defer gr.Close()
+
+		if data, err = io.ReadAll(gr); err != nil {
+			return nil, fmt.Errorf("failed to read report: %w", err)
+		}
+	}
+
+	return data, nil
+}
+
 type countingWriter struct {
 	w io.Writer
 	n *int64
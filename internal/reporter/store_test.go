@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
 )
@@ -125,6 +126,30 @@ func TestStore_Load_NotFound(t *testing.T) {
 	}
 }
 
+func TestStore_SaveKeepsReportsWithSharedPrefix(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+
+	ids := []string{"a_b", "a[", "a"}
+	for _, id := range ids {
+		report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+		report.ID = id
+		if err := store.Save(report); err != nil {
+			t.Fatalf("Save(%q) error = %v", id, err)
+		}
+	}
+
+	for _, id := range ids {
+		loaded, err := store.Load(id)
+		if err != nil {
+			t.Errorf("Load(%q) error = %v", id, err)
+			continue
+		}
+		if loaded.ID != id {
+			t.Errorf("Load(%q) returned report %q", id, loaded.ID)
+		}
+	}
+}
+
 func TestStore_List(t *testing.T) {
 	tmpDir := t.TempDir()
 	store, _ := NewStore(tmpDir)
@@ -221,3 +246,31 @@ func BenchmarkStore_List(b *testing.B) {
 		store.List()
 	}
 }
+
+func TestStore_LoadLegacyReport(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, _ := NewStore(tmpDir)
+
+	legacy := `{"ID":"old","Crash":{"Namespace":"default","PodName":"api"},"PreviousLog":["x"],"CollectedAt":"2024-01-02T03:04:05Z"}`
+	if err := os.WriteFile(filepath.Join(tmpDir, "old_default_api.json"), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := store.Load("old")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if report.SchemaVersion != domain.SchemaVersion || report.Crash.PodName != "api" || len(report.PreviousLog) != 1 {
+		t.Errorf("legacy report not migrated: %+v", report)
+	}
+
+	reports, err := store.List()
+	if err != nil || len(reports) != 1 {
+		t.Errorf("List() = %d reports, err %v; want 1", len(reports), err)
+	}
+
+	res, err := store.Prune(24 * time.Hour)
+	if err != nil || res.Deleted != 1 {
+		t.Errorf("Prune() = %+v, err %v; want the legacy report deleted", res, err)
+	}
+}
@@ -0,0 +1,140 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const TombstonesFileName = "tombstones.jsonl"
+
+// tombstones remembers the reports retention deleted from a store, so that
+// reconciliation does not copy them back from a backend that only prunes by
+// age. They are kept one per line in a file, which kubecrsh prune appends
+// to as well, and dropped once the report is older than the reconcile
+// window.
+type tombstones struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]tombstone
+}
+
+type tombstone struct {
+	ID          string    `json:"id"`
+	CollectedAt time.Time `json:"collected_at"`
+	DeletedAt   time.Time `json:"deleted_at"`
+}
+
+func openTombstones(path string) (*tombstones, error) {
+	t := &tombstones{path: path}
+	if err := t.load(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// RecordTombstones records the reports deleted by a retention run in the
+// tombstones file at path, for a store whose reports are also kept in
+// other backends.
+func RecordTombstones(path string, actions []PruneAction) error {
+	t, err := openTombstones(path)
+	if err != nil {
+		return err
+	}
+	return t.add(actions)
+}
+
+func (t *tombstones) load() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.entries = make(map[string]tombstone)
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read tombstones: %w", err)
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var ts tombstone
+		// A torn last line from a crash mid-write is skipped.
+		if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &ts) != nil {
+			continue
+		}
+		t.entries[ts.ID] = ts
+	}
+	return nil
+}
+
+func (t *tombstones) add(actions []PruneAction) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	now := time.Now().UTC()
+	for _, a := range actions {
+		if a.Action != RetentionDelete {
+			continue
+		}
+		ts := tombstone{ID: a.ID, CollectedAt: a.CollectedAt, DeletedAt: now}
+		if err := enc.Encode(ts); err != nil {
+			return err
+		}
+		t.entries[a.ID] = ts
+	}
+	if buf.Len() == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return fmt.Errorf("failed to create tombstones directory: %w", err)
+	}
+	f, err := os.OpenFile(t.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open tombstones: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write tombstones: %w", err)
+	}
+	return f.Sync()
+}
+
+func (t *tombstones) has(id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.entries[id]
+	return ok
+}
+
+// expire forgets the reports collected before since, which reconciliation
+// no longer looks at.
+func (t *tombstones) expire(since time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	expired := false
+	for id, ts := range t.entries {
+		if ts.CollectedAt.Before(since) {
+			delete(t.entries, id)
+			expired = true
+			continue
+		}
+		if err := enc.Encode(ts); err != nil {
+			return err
+		}
+	}
+	if !expired {
+		return nil
+	}
+	return writeFileAtomic(t.path, buf.Bytes())
+}
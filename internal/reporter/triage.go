@@ -0,0 +1,83 @@
+package reporter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+var _ Triager = (*Store)(nil)
+var _ Triager = (*MultiStore)(nil)
+
+type Triager interface {
+	UpdateTriage(id string, update domain.TriageUpdate, actor domain.TriageActor) (*domain.ForensicReport, error)
+}
+
+func UpdateTriage(s Storage, id string, update domain.TriageUpdate, actor domain.TriageActor) (*domain.ForensicReport, error) {
+	if t, ok := s.(Triager); ok {
+		return t.UpdateTriage(id, update, actor)
+	}
+
+	report, err := s.Load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := report.ApplyTriage(update, actor, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.Save(report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (s *Store) UpdateTriage(id string, update domain.TriageUpdate, actor domain.TriageActor) (*domain.ForensicReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := s.globByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("report not found: %s", id)
+	}
+
+	report, err := readReportFile(files[0], s.keys)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := report.ApplyTriage(update, actor, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.saveLocked(report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// UpdateTriage applies the update in the first backend that holds the
+// report and writes the result to the others.
+func (m *MultiStore) UpdateTriage(id string, update domain.TriageUpdate, actor domain.TriageActor) (*domain.ForensicReport, error) {
+	var err error
+	for i, b := range m.backends {
+		var report *domain.ForensicReport
+		if report, err = UpdateTriage(b.Store, id, update, actor); err != nil {
+			continue
+		}
+
+		others := append(append([]*multiBackend{}, m.backends[:i]...), m.backends[i+1:]...)
+		if err := m.saveTo(report, others); err != nil {
+			fmt.Printf("Warning: failed to save triage to other stores: %v\n", err)
+		}
+		return report, nil
+	}
+	return nil, err
+}
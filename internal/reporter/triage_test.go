@@ -0,0 +1,155 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+func TestStore_UpdateTriage(t *testing.T) {
+	tmpDir := t.TempDir()
+	plain, _ := NewStore(tmpDir)
+
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+	if err := plain.Save(report); err != nil {
+		t.Fatal(err)
+	}
+
+	// Triage through a store with different compression replaces the file
+	// instead of leaving two copies behind.
+	gz, _ := NewStore(tmpDir, WithCompression("gzip"))
+	resolved := domain.TriageResolved
+	actor := domain.TriageActor{Name: "bob", Source: "tui"}
+	if _, err := gz.UpdateTriage(report.ID, domain.TriageUpdate{Status: &resolved, Note: "fixed in v2"}, actor); err != nil {
+		t.Fatalf("UpdateTriage() error = %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(tmpDir, report.ID+"_*"))
+	if len(files) != 1 || !strings.HasSuffix(files[0], ".json.gz") {
+		t.Fatalf("files = %v, want a single gzip report", files)
+	}
+
+	loaded, err := plain.Load(report.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.TriageStatus() != domain.TriageResolved || len(loaded.Triage.Notes) != 1 || loaded.Triage.UpdatedBy != "bob" {
+		t.Errorf("Triage = %+v", loaded.Triage)
+	}
+
+	if _, err := plain.UpdateTriage("missing", domain.TriageUpdate{Note: "x"}, actor); err == nil {
+		t.Error("expected error for a missing report")
+	}
+	if _, err := plain.UpdateTriage(report.ID, domain.TriageUpdate{}, actor); err == nil {
+		t.Error("expected error for an empty update")
+	}
+}
+
+type plainStorage struct {
+	reports map[string]*domain.ForensicReport
+}
+
+func (p *plainStorage) Save(r *domain.ForensicReport) error {
+	p.reports[r.ID] = r
+	return nil
+}
+
+func (p *plainStorage) Load(id string) (*domain.ForensicReport, error) {
+	if r, ok := p.reports[id]; ok {
+		return r, nil
+	}
+	return nil, os.ErrNotExist
+}
+
+func (p *plainStorage) List() ([]*domain.ForensicReport, error) {
+	reports := make([]*domain.ForensicReport, 0, len(p.reports))
+	for _, r := range p.reports {
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
+
+func TestUpdateTriage_FallsBackToLoadAndSave(t *testing.T) {
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+	primary := &plainStorage{reports: map[string]*domain.ForensicReport{}}
+	secondary := &plainStorage{reports: map[string]*domain.ForensicReport{report.ID: report}}
+
+	multi, err := NewMultiStore([]Backend{{Name: "primary", Store: primary, Required: true}, {Name: "secondary", Store: secondary}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assignee := "carol"
+	updated, err := UpdateTriage(multi, report.ID, domain.TriageUpdate{Assignee: &assignee}, domain.TriageActor{Name: "carol"})
+	if err != nil {
+		t.Fatalf("UpdateTriage() error = %v", err)
+	}
+	if updated.Triage.Assignee != "carol" || secondary.reports[report.ID].Triage == nil {
+		t.Errorf("Triage = %+v, want the secondary copy updated", updated.Triage)
+	}
+}
+
+func TestElasticStore_UpdateTriage_RetriesOnConflict(t *testing.T) {
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+	source := (&ElasticStore{}).toDocument(report)
+
+	var indexed []string
+	var written elasticDocument
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(map[string]any{
+				"_id":           report.ID,
+				"_seq_no":       len(indexed) + 4,
+				"_primary_term": 1,
+				"found":         true,
+				"_source":       source,
+			})
+			return
+		}
+
+		indexed = append(indexed, r.URL.RawQuery)
+		if len(indexed) == 1 {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error":{"type":"version_conflict_engine_exception"}}`))
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &written)
+		w.Write([]byte(`{"result":"updated"}`))
+	}))
+	defer server.Close()
+
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{server.URL}})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	store := &ElasticStore{client: client, indexName: "kubecrsh-reports"}
+
+	acked := domain.TriageAcknowledged
+	updated, err := store.UpdateTriage(report.ID, domain.TriageUpdate{Status: &acked}, domain.TriageActor{Name: "dave", Source: "api"})
+	if err != nil {
+		t.Fatalf("UpdateTriage() error = %v", err)
+	}
+
+	if len(indexed) != 2 || !strings.Contains(indexed[1], "if_seq_no=5") || !strings.Contains(indexed[1], "if_primary_term=1") {
+		t.Errorf("index requests = %v, want a retry guarded by the new sequence number", indexed)
+	}
+	if updated.TriageStatus() != domain.TriageAcknowledged || written.Triage == nil || written.Triage.Status != domain.TriageAcknowledged {
+		t.Errorf("written triage = %+v", written.Triage)
+	}
+	if len(written.Triage.History) != 1 || written.Triage.History[0].Actor != "dave" {
+		t.Errorf("history = %+v", written.Triage.History)
+	}
+}
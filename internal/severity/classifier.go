@@ -0,0 +1,151 @@
+package severity
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	DefaultCriticalityLabel = "kubecrsh.io/criticality"
+	DefaultFrequencyWindow  = time.Hour
+
+	namespaceCacheTTL = 5 * time.Minute
+)
+
+type Classifier struct {
+	client kubernetes.Interface
+	label  string
+	window time.Duration
+	now    func() time.Time
+
+	mu         sync.Mutex
+	crashes    map[string][]time.Time
+	namespaces map[string]cachedLabels
+}
+
+type cachedLabels struct {
+	labels    map[string]string
+	fetchedAt time.Time
+}
+
+type Option func(*Classifier)
+
+func WithClient(client kubernetes.Interface) Option {
+	return func(c *Classifier) {
+		c.client = client
+	}
+}
+
+func WithCriticalityLabel(label string) Option {
+	return func(c *Classifier) {
+		if label = strings.TrimSpace(label); label != "" {
+			c.label = label
+		}
+	}
+}
+
+func WithFrequencyWindow(window time.Duration) Option {
+	return func(c *Classifier) {
+		if window > 0 {
+			c.window = window
+		}
+	}
+}
+
+func New(opts ...Option) *Classifier {
+	c := &Classifier{
+		label:      DefaultCriticalityLabel,
+		window:     DefaultFrequencyWindow,
+		now:        time.Now,
+		crashes:    make(map[string][]time.Time),
+		namespaces: make(map[string]cachedLabels),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+func (c *Classifier) Classify(ctx context.Context, report *domain.ForensicReport, pod *corev1.Pod) domain.Severity {
+	sc := domain.SeverityContext{
+		RecentCrashes:   c.record(report.GroupKey()),
+		FrequencyWindow: c.window,
+	}
+
+	if pod != nil {
+		if v := pod.Labels[c.label]; v != "" {
+			sc.Criticality, sc.CriticalitySource = v, "workload"
+		}
+	}
+	if sc.Criticality == "" {
+		if v := c.namespaceLabels(ctx, report.Crash.Namespace)[c.label]; v != "" {
+			sc.Criticality, sc.CriticalitySource = v, "namespace"
+		}
+	}
+
+	s := domain.ClassifySeverity(report, sc)
+	report.SetSeverity(s)
+	return s
+}
+
+func (c *Classifier) record(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	cutoff := now.Add(-c.window)
+
+	seen := c.crashes[key][:0]
+	for _, t := range c.crashes[key] {
+		if t.After(cutoff) {
+			seen = append(seen, t)
+		}
+	}
+	seen = append(seen, now)
+	c.crashes[key] = seen
+
+	// Drop keys that went quiet so the map does not grow with every
+	// workload that ever crashed.
+	for k, times := range c.crashes {
+		if len(times) > 0 && !times[len(times)-1].After(cutoff) {
+			delete(c.crashes, k)
+		}
+	}
+
+	return len(seen)
+}
+
+func (c *Classifier) namespaceLabels(ctx context.Context, namespace string) map[string]string {
+	if c.client == nil || namespace == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	cached, ok := c.namespaces[namespace]
+	c.mu.Unlock()
+	if ok && c.now().Sub(cached.fetchedAt) < namespaceCacheTTL {
+		return cached.labels
+	}
+
+	// Namespaced installs cannot read Namespace objects; treat that the
+	// same as an unlabelled namespace.
+	var labels map[string]string
+	if ns, err := c.client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{}); err == nil {
+		labels = ns.Labels
+	}
+
+	c.mu.Lock()
+	c.namespaces[namespace] = cachedLabels{labels: labels, fetchedAt: c.now()}
+	c.mu.Unlock()
+
+	return labels
+}
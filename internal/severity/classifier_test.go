@@ -0,0 +1,82 @@
+package severity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func crashReport(namespace, reason string, exitCode, restarts int32) *domain.ForensicReport {
+	return domain.NewForensicReport(domain.PodCrash{
+		Namespace:     namespace,
+		PodName:       "api-7d9f8b6c5-abcde",
+		ContainerName: "app",
+		Reason:        reason,
+		ExitCode:      exitCode,
+		RestartCount:  restarts,
+	})
+}
+
+func TestClassifier_Classify(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: map[string]string{DefaultCriticalityLabel: "critical"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev", Labels: map[string]string{DefaultCriticalityLabel: "low"}}},
+	)
+
+	tests := []struct {
+		name   string
+		report *domain.ForensicReport
+		pod    *corev1.Pod
+		want   string
+	}{
+		{"one-off error in dev", crashReport("dev", "Error", 1, 0), nil, domain.SeverityLow},
+		{"error in unlabelled namespace", crashReport("staging", "Error", 1, 0), nil, domain.SeverityLow},
+		{"OOM loop in production", crashReport("prod", "OOMKilled", 137, 12), nil, domain.SeverityCritical},
+		{"OOM in unlabelled namespace", crashReport("staging", "OOMKilled", 137, 0), nil, domain.SeverityMedium},
+		{
+			"workload label overrides namespace",
+			crashReport("dev", "CrashLoopBackOff", 1, 6),
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{DefaultCriticalityLabel: "high"}}},
+			domain.SeverityHigh,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(WithClient(client))
+			s := c.Classify(context.Background(), tt.report, tt.pod)
+			if s.Level != tt.want {
+				t.Errorf("Level = %s (score %d, %v), want %s", s.Level, s.Score, s.Reasons, tt.want)
+			}
+			if tt.report.Severity == nil || tt.report.SeverityLevel() != tt.want {
+				t.Errorf("report severity not stored: %+v", tt.report.Severity)
+			}
+		})
+	}
+}
+
+func TestClassifier_CrashFrequency(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := New(WithFrequencyWindow(time.Hour))
+	c.now = func() time.Time { return now }
+
+	var s domain.Severity
+	for i := 0; i < 10; i++ {
+		s = c.Classify(context.Background(), crashReport("staging", "Error", 1, 0), nil)
+		now = now.Add(time.Minute)
+	}
+	if s.Level != domain.SeverityMedium {
+		t.Errorf("10 crashes in an hour: Level = %s (%v), want %s", s.Level, s.Reasons, domain.SeverityMedium)
+	}
+
+	now = now.Add(2 * time.Hour)
+	s = c.Classify(context.Background(), crashReport("staging", "Error", 1, 0), nil)
+	if s.Level != domain.SeverityLow {
+		t.Errorf("after the window: Level = %s (%v), want %s", s.Level, s.Reasons, domain.SeverityLow)
+	}
+}
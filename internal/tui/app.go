@@ -3,13 +3,21 @@ package tui
 import (
 	"context"
 	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/help"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/kadirbelkuyu/kubecrsh/internal/collector"
+	"github.com/kadirbelkuyu/kubecrsh/internal/diagnosis"
 	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+	"github.com/kadirbelkuyu/kubecrsh/internal/export"
 	"github.com/kadirbelkuyu/kubecrsh/internal/reporter"
+	"github.com/kadirbelkuyu/kubecrsh/internal/severity"
 	"github.com/kadirbelkuyu/kubecrsh/internal/tui/views"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -18,18 +26,28 @@ type viewState int
 const (
 	stateList viewState = iota
 	stateDetail
+	stateGroups
 )
 
 type model struct {
 	state      viewState
 	listView   views.ListView
 	detailView views.DetailView
+	groupView  views.GroupListView
+	reports    []*domain.ForensicReport
+	groupFocus string
+	searchText string
+	minLevel   string
 	help       help.Model
 	width      int
 	height     int
 	client     kubernetes.Interface
 	collector  *collector.Collector
-	store      *reporter.Store
+	diagnoser  *diagnosis.Engine
+	classifier *severity.Classifier
+	store      reporter.Storage
+	actor      string
+	notice     string
 	err        error
 }
 
@@ -41,25 +59,51 @@ type reportMsg struct {
 	report domain.ForensicReport
 }
 
+type triagedMsg struct {
+	report *domain.ForensicReport
+}
+
 type reportsLoadedMsg struct {
 	reports []*domain.ForensicReport
 }
 
+type searchResultsMsg struct {
+	text string
+	hits []reporter.SearchHit
+}
+
+type exportedMsg struct {
+	path string
+}
+
 type errMsg struct {
 	err error
 }
 
-func New(client kubernetes.Interface, store *reporter.Store) model {
+func New(client kubernetes.Interface, store reporter.Storage) model {
 	return model{
-		state:     stateList,
-		listView:  views.NewListView([]*domain.ForensicReport{}),
-		help:      help.New(),
-		client:    client,
-		collector: collector.New(client),
-		store:     store,
+		state:      stateList,
+		listView:   views.NewListView([]*domain.ForensicReport{}),
+		help:       help.New(),
+		client:     client,
+		collector:  collector.New(client),
+		diagnoser:  diagnosis.New(),
+		classifier: severity.New(severity.WithClient(client)),
+		store:      store,
+		actor:      currentActor(),
 	}
 }
 
+func currentActor() string {
+	if name := strings.TrimSpace(os.Getenv("KUBECRSH_USER")); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "tui"
+}
+
 func (m model) Init() tea.Cmd {
 	return m.loadReports()
 }
@@ -70,12 +114,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.listView = m.listView.SetSize(msg.Width, msg.Height-2)
+		m.groupView = m.groupView.SetSize(msg.Width, msg.Height-2)
 		if m.state == stateDetail {
 			m.detailView = m.detailView.SetSize(msg.Width, msg.Height-2)
 		}
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.isFiltering() {
+			break
+		}
+		m.notice = ""
+
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
@@ -85,6 +135,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = stateList
 				return m, nil
 			}
+			if m.state == stateList && m.searchText != "" {
+				m.showReports(m.groupFocus)
+				return m, nil
+			}
+			if m.state == stateList && m.groupFocus != "" {
+				m.state = stateGroups
+				return m, nil
+			}
+
+		case "g":
+			switch m.state {
+			case stateList:
+				m.showGroups()
+				return m, nil
+			case stateGroups:
+				m.showReports("")
+				return m, nil
+			}
 
 		case "enter":
 			if m.state == stateList {
@@ -95,15 +163,98 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				}
 			}
+			if m.state == stateGroups {
+				if group := m.groupView.SelectedGroup(); group != nil {
+					m.showReports(group.Fingerprint)
+					return m, nil
+				}
+			}
+
+		case "/":
+			if m.state == stateList {
+				var cmd tea.Cmd
+				m.listView, cmd = m.listView.StartSearch()
+				return m, cmd
+			}
+
+		case "s":
+			if m.state == stateList {
+				m.minLevel = nextMinSeverity(m.minLevel)
+				m.showReports(m.groupFocus)
+				return m, nil
+			}
 
 		case "tab":
 			if m.state == stateDetail {
-				activeTab := (m.detailView.ActiveTab + 1) % 4
+				activeTab := (m.detailView.ActiveTab + 1) % views.TabCount
 				m.detailView = m.detailView.SetActiveTab(activeTab)
 				return m, nil
 			}
+
+		case "a", "r", "i", "u", "m":
+			if m.state == stateDetail {
+				if update, ok := m.triageKey(msg.String()); ok {
+					return m, m.triage(update)
+				}
+			}
+
+		case "e":
+			if cmd := m.export(); cmd != nil {
+				return m, cmd
+			}
+
+		case "n", "L":
+			if m.state == stateDetail {
+				kind := views.InputNote
+				if msg.String() == "L" {
+					kind = views.InputLink
+				}
+				var cmd tea.Cmd
+				m.detailView, cmd = m.detailView.StartInput(kind)
+				return m, cmd
+			}
+		}
+
+	case views.TriageInputMsg:
+		switch msg.Kind {
+		case views.InputLink:
+			return m, m.triage(domain.TriageUpdate{Link: parseLink(msg.Value)})
+		default:
+			return m, m.triage(domain.TriageUpdate{Note: msg.Value})
 		}
 
+	case views.SearchMsg:
+		return m, m.search(msg.Query)
+
+	case searchResultsMsg:
+		m.err = nil
+		reports := make([]*domain.ForensicReport, 0, len(msg.hits))
+		highlights := make(map[string]string, len(msg.hits))
+		for _, hit := range msg.hits {
+			reports = append(reports, hit.Report)
+			if len(hit.Highlights) > 0 {
+				highlights[hit.Report.ID] = hit.Highlights[0]
+			}
+		}
+		m.searchText = msg.text
+		m.listView = views.NewListView(reports).SetTitle(fmt.Sprintf("Search: %s (%d)", msg.text, len(reports))).SetHighlights(highlights)
+		m.listView = m.listView.SetSize(m.width, m.height-2)
+		m.state = stateList
+		return m, nil
+
+	case triagedMsg:
+		m.err = nil
+		for i, r := range m.reports {
+			if r.ID == msg.report.ID {
+				m.reports[i] = msg.report
+			}
+		}
+		m.listView = m.listView.UpdateReport(*msg.report)
+		if m.state == stateDetail {
+			m.detailView = m.detailView.SetReport(msg.report)
+		}
+		return m, nil
+
 	case crashMsg:
 		return m, m.collectForensics(msg.crash)
 
@@ -111,12 +262,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if err := m.store.Save(&msg.report); err != nil {
 			m.err = err
 		}
-		m.listView = m.listView.AddReport(msg.report)
+		report := msg.report
+		m.reports = append([]*domain.ForensicReport{&report}, m.reports...)
+		if m.searchText == "" && m.matchesFocus(&report) {
+			m.listView = m.listView.AddReport(report)
+		}
+		if m.state == stateGroups {
+			m.showGroups()
+		}
 		return m, nil
 
 	case reportsLoadedMsg:
-		m.listView = views.NewListView(msg.reports)
-		m.listView = m.listView.SetSize(m.width, m.height-2)
+		m.reports = msg.reports
+		m.showReports("")
+		return m, nil
+
+	case exportedMsg:
+		m.err = nil
+		m.notice = "Exported to " + msg.path
 		return m, nil
 
 	case errMsg:
@@ -130,11 +293,95 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.listView, cmd = m.listView.Update(msg)
 	case stateDetail:
 		m.detailView, cmd = m.detailView.Update(msg)
+	case stateGroups:
+		m.groupView, cmd = m.groupView.Update(msg)
 	}
 
 	return m, cmd
 }
 
+func (m model) isFiltering() bool {
+	switch m.state {
+	case stateList:
+		return m.listView.IsFiltering()
+	case stateGroups:
+		return m.groupView.IsFiltering()
+	case stateDetail:
+		return m.detailView.IsEditing()
+	}
+	return false
+}
+
+func (m model) triageKey(k string) (domain.TriageUpdate, bool) {
+	status := func(s string) (domain.TriageUpdate, bool) {
+		return domain.TriageUpdate{Status: &s}, true
+	}
+
+	switch k {
+	case "a":
+		return status(domain.TriageAcknowledged)
+	case "r":
+		return status(domain.TriageResolved)
+	case "i":
+		return status(domain.TriageIgnored)
+	case "u":
+		return status(domain.TriageNew)
+	case "m":
+		assignee := m.actor
+		if report := m.detailView.Report(); report != nil && report.Triage != nil && report.Triage.Assignee == m.actor {
+			assignee = ""
+		}
+		return domain.TriageUpdate{Assignee: &assignee}, true
+	}
+	return domain.TriageUpdate{}, false
+}
+
+func parseLink(value string) *domain.TriageLink {
+	url, title, _ := strings.Cut(strings.TrimSpace(value), " ")
+	return &domain.TriageLink{URL: url, Title: strings.TrimSpace(title)}
+}
+
+var minSeverityCycle = []string{"", domain.SeverityMedium, domain.SeverityHigh, domain.SeverityCritical}
+
+func nextMinSeverity(current string) string {
+	for i, level := range minSeverityCycle {
+		if level == current {
+			return minSeverityCycle[(i+1)%len(minSeverityCycle)]
+		}
+	}
+	return ""
+}
+
+func (m model) matchesFocus(report *domain.ForensicReport) bool {
+	if m.groupFocus != "" && report.GroupKey() != m.groupFocus {
+		return false
+	}
+	return m.minLevel == "" || domain.SeverityAtLeast(report.SeverityLevel(), m.minLevel)
+}
+
+func (m *model) showGroups() {
+	m.groupView = views.NewGroupListView(domain.GroupReports(m.reports))
+	m.groupView = m.groupView.SetSize(m.width, m.height-2)
+	m.state = stateGroups
+}
+
+func (m *model) showReports(fingerprint string) {
+	reports := m.reports
+	title := "Crash Reports"
+	if fingerprint != "" {
+		reports = domain.FilterByFingerprint(reports, fingerprint)
+		if len(reports) > 0 {
+			title = "Crash Reports · " + reports[0].Crash.WorkloadName()
+		}
+	}
+
+	m.groupFocus = fingerprint
+	m.searchText = ""
+	m.listView = views.NewListView(reports).SetTitle(title)
+	m.listView = m.listView.SetSize(m.width, m.height-2)
+	m.state = stateList
+}
+
 func (m model) View() string {
 	if m.width == 0 {
 		return "Loading..."
@@ -150,6 +397,12 @@ func (m model) View() string {
 		}
 	case stateDetail:
 		view = m.detailView.View()
+	case stateGroups:
+		if m.groupView.IsEmpty() {
+			view = m.listView.EmptyMessage()
+		} else {
+			view = m.groupView.View()
+		}
 	}
 
 	help := helpStyle.Render(m.help.ShortHelpView(keys.ShortHelp()))
@@ -158,17 +411,31 @@ func (m model) View() string {
 		errMsg := errorStyle.Render(fmt.Sprintf("Error: %v", m.err))
 		return fmt.Sprintf("%s\n\n%s\n%s", view, errMsg, help)
 	}
+	if m.notice != "" {
+		return fmt.Sprintf("%s\n\n%s\n%s", view, successStyle.Render(m.notice), help)
+	}
 
 	return fmt.Sprintf("%s\n%s", view, help)
 }
 
 func (m model) loadReports() tea.Cmd {
 	return func() tea.Msg {
-		reports, err := m.store.List()
+		res, err := reporter.QueryReports(m.store, reporter.Query{Limit: reporter.MaxQueryLimit})
+		if err != nil {
+			return errMsg{err}
+		}
+		return reportsLoadedMsg{reports: res.Reports}
+	}
+}
+
+func (m model) search(text string) tea.Cmd {
+	return func() tea.Msg {
+		q := reporter.SearchQuery{Query: reporter.Query{Limit: reporter.MaxQueryLimit}, Text: text}
+		res, err := reporter.SearchReports(m.store, q)
 		if err != nil {
 			return errMsg{err}
 		}
-		return reportsLoadedMsg{reports: reports}
+		return searchResultsMsg{text: text, hits: res.Hits}
 	}
 }
 
@@ -179,10 +446,77 @@ func (m model) collectForensics(crash domain.PodCrash) tea.Cmd {
 		if err != nil {
 			return errMsg{err}
 		}
+
+		pod, err := m.client.CoreV1().Pods(crash.Namespace).Get(ctx, crash.PodName, metav1.GetOptions{})
+		if err != nil {
+			pod = nil
+		}
+		m.diagnoser.Diagnose(report, pod)
+		m.classifier.Classify(ctx, report, pod)
+
 		return reportMsg{report: *report}
 	}
 }
 
+func (m model) triage(update domain.TriageUpdate) tea.Cmd {
+	report := m.detailView.Report()
+	if report == nil {
+		return nil
+	}
+
+	id := report.ID
+	actor := domain.TriageActor{Name: m.actor, Source: "tui"}
+	return func() tea.Msg {
+		updated, err := reporter.UpdateTriage(m.store, id, update, actor)
+		if err != nil {
+			return errMsg{err}
+		}
+		return triagedMsg{report: updated}
+	}
+}
+
+// export writes the report open in the detail view, or selected in the list,
+// as Markdown to the current directory.
+func (m model) export() tea.Cmd {
+	var report *domain.ForensicReport
+	switch m.state {
+	case stateDetail:
+		report = m.detailView.Report()
+	case stateList:
+		report = m.listView.SelectedReport()
+	}
+	if report == nil {
+		return nil
+	}
+
+	id := report.ID
+	return func() tea.Msg {
+		// List entries can be summaries, so export what is stored.
+		full, err := m.store.Load(id)
+		if err != nil {
+			return errMsg{err}
+		}
+
+		path := export.FileName(full, export.FormatMarkdown)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return errMsg{fmt.Errorf("failed to export report: %w", err)}
+		}
+		if err := export.Markdown(f, full); err != nil {
+			f.Close()
+			return errMsg{fmt.Errorf("failed to export report: %w", err)}
+		}
+		if err := f.Close(); err != nil {
+			return errMsg{fmt.Errorf("failed to export report: %w", err)}
+		}
+
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		return exportedMsg{path: path}
+	}
+}
+
 func OnCrash(crash domain.PodCrash) tea.Msg {
 	return crashMsg{crash: crash}
 }
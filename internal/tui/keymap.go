@@ -12,8 +12,19 @@ type keyMap struct {
 	Enter  key.Binding
 	Back   key.Binding
 	Tab    key.Binding
+	Search key.Binding
+	Group  key.Binding
+	Level  key.Binding
 	Export key.Binding
 	Quit   key.Binding
+
+	Acknowledge key.Binding
+	Resolve     key.Binding
+	Ignore      key.Binding
+	Reopen      key.Binding
+	Assign      key.Binding
+	Note        key.Binding
+	Link        key.Binding
 }
 
 func (k keyMap) ShortHelp() []key.Binding {
@@ -23,8 +34,9 @@ func (k keyMap) ShortHelp() []key.Binding {
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Left, k.Right},
-		{k.Enter, k.Back, k.Tab},
-		{k.Export, k.Quit},
+		{k.Enter, k.Back, k.Tab, k.Search},
+		{k.Group, k.Level, k.Export, k.Quit},
+		{k.Acknowledge, k.Resolve, k.Ignore, k.Reopen, k.Assign, k.Note, k.Link},
 	}
 }
 
@@ -57,12 +69,52 @@ var keys = keyMap{
 		key.WithKeys("tab"),
 		key.WithHelp("tab", "switch tab"),
 	),
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "search reports"),
+	),
+	Group: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "group by signature"),
+	),
+	Level: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "minimum severity"),
+	),
 	Export: key.NewBinding(
 		key.WithKeys("e"),
-		key.WithHelp("e", "export"),
+		key.WithHelp("e", "export markdown"),
 	),
 	Quit: key.NewBinding(
 		key.WithKeys("q", "ctrl+c"),
 		key.WithHelp("q", "quit"),
 	),
+	Acknowledge: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "acknowledge"),
+	),
+	Resolve: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "resolve"),
+	),
+	Ignore: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "ignore"),
+	),
+	Reopen: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "reopen"),
+	),
+	Assign: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "assign to me"),
+	),
+	Note: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "add note"),
+	),
+	Link: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "add link"),
+	),
 }
@@ -15,11 +15,11 @@ func TestKeyMap_ShortHelp(t *testing.T) {
 func TestKeyMap_FullHelp(t *testing.T) {
 	groups := keys.FullHelp()
 
-	if len(groups) != 3 {
-		t.Errorf("FullHelp() returned %d groups, want 3", len(groups))
+	if len(groups) != 4 {
+		t.Errorf("FullHelp() returned %d groups, want 4", len(groups))
 	}
 
-	expectedGroupSizes := []int{4, 3, 2}
+	expectedGroupSizes := []int{4, 4, 4, 7}
 	for i, group := range groups {
 		if len(group) != expectedGroupSizes[i] {
 			t.Errorf("Group %d has %d bindings, want %d", i, len(group), expectedGroupSizes[i])
@@ -39,8 +39,18 @@ func TestKeyMap_KeyBindings(t *testing.T) {
 		{"Enter", keys.Enter},
 		{"Back", keys.Back},
 		{"Tab", keys.Tab},
+		{"Search", keys.Search},
+		{"Group", keys.Group},
+		{"Level", keys.Level},
 		{"Export", keys.Export},
 		{"Quit", keys.Quit},
+		{"Acknowledge", keys.Acknowledge},
+		{"Resolve", keys.Resolve},
+		{"Ignore", keys.Ignore},
+		{"Reopen", keys.Reopen},
+		{"Assign", keys.Assign},
+		{"Note", keys.Note},
+		{"Link", keys.Link},
 	}
 
 	for _, tt := range tests {
@@ -4,15 +4,32 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
 )
 
+var detailTabs = [...]string{"Overview", "Logs", "Previous Logs", "Events", "Timeline", "Last Words"}
+
+const TabCount = len(detailTabs)
+
+const (
+	InputNote = "note"
+	InputLink = "link"
+)
+
+type TriageInputMsg struct {
+	Kind  string
+	Value string
+}
+
 type DetailView struct {
 	report    *domain.ForensicReport
 	viewport  viewport.Model
+	input     textinput.Model
+	inputKind string
 	ActiveTab int
 	width     int
 	height    int
@@ -35,10 +52,58 @@ func (v DetailView) Init() tea.Cmd {
 
 func (v DetailView) Update(msg tea.Msg) (DetailView, tea.Cmd) {
 	var cmd tea.Cmd
+	if v.IsEditing() {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			switch key.String() {
+			case "enter":
+				input := TriageInputMsg{Kind: v.inputKind, Value: strings.TrimSpace(v.input.Value())}
+				v.inputKind = ""
+				if input.Value == "" {
+					return v, nil
+				}
+				return v, func() tea.Msg { return input }
+			case "esc":
+				v.inputKind = ""
+				return v, nil
+			}
+		}
+		v.input, cmd = v.input.Update(msg)
+		return v, cmd
+	}
+
 	v.viewport, cmd = v.viewport.Update(msg)
 	return v, cmd
 }
 
+func (v DetailView) StartInput(kind string) (DetailView, tea.Cmd) {
+	v.input = textinput.New()
+	v.input.Width = v.width - 12
+	switch kind {
+	case InputLink:
+		v.input.Prompt = "Link: "
+		v.input.Placeholder = "https://tickets.example.com/OPS-123 [title]"
+	default:
+		v.input.Prompt = "Note: "
+		v.input.Placeholder = "what was found, what was done"
+	}
+	v.inputKind = kind
+	return v, v.input.Focus()
+}
+
+func (v DetailView) Report() *domain.ForensicReport {
+	return v.report
+}
+
+func (v DetailView) IsEditing() bool {
+	return v.inputKind != ""
+}
+
+func (v DetailView) SetReport(report *domain.ForensicReport) DetailView {
+	v.report = report
+	v.updateContentInternal()
+	return v
+}
+
 func (v DetailView) View() string {
 	if v.report == nil {
 		return "No report selected"
@@ -48,6 +113,10 @@ func (v DetailView) View() string {
 	tabs := v.renderTabs()
 	content := v.renderContent()
 
+	if v.IsEditing() {
+		return lipgloss.JoinVertical(lipgloss.Left, header, tabs, content, v.input.View())
+	}
+
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
 		header,
@@ -80,21 +149,31 @@ func (v DetailView) renderHeader() string {
 		Width(v.width).
 		Render(fmt.Sprintf("Crash Report: %s", v.report.Summary()))
 
+	exit := v.report.ExitInfo()
+	cause := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FFB86C")).
+		Render(fmt.Sprintf("Exit %s: %s (initiated by %s)", exit.ExitLabel(), exit.Cause, exit.Initiator))
+
+	status := v.report.TriageStatus()
+	if v.report.Triage != nil && v.report.Triage.Assignee != "" {
+		status += " → " + v.report.Triage.Assignee
+	}
 	info := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#6272A4")).
-		Render(fmt.Sprintf("ID: %s | Collected: %s",
+		Render(fmt.Sprintf("ID: %s | Collected: %s | Severity: %s | Status: %s",
 			v.report.ID,
 			v.report.CollectedAt.Format("2006-01-02 15:04:05"),
+			v.report.SeverityLevel(),
+			status,
 		))
 
-	return lipgloss.JoinVertical(lipgloss.Left, title, info)
+	return lipgloss.JoinVertical(lipgloss.Left, title, cause, info)
 }
 
 func (v DetailView) renderTabs() string {
-	tabs := []string{"Overview", "Logs", "Previous Logs", "Events"}
-	renderedTabs := make([]string, 0, len(tabs))
+	renderedTabs := make([]string, 0, len(detailTabs))
 
-	for i, tab := range tabs {
+	for i, tab := range detailTabs {
 		style := lipgloss.NewStyle().Padding(0, 2)
 		if i == v.ActiveTab {
 			style = style.
@@ -126,6 +205,10 @@ func (v *DetailView) updateContentInternal() {
 		content = v.renderLogs(v.report.PreviousLog)
 	case 3:
 		content = v.renderEvents()
+	case 4:
+		content = v.renderTimeline()
+	case 5:
+		content = v.renderLogs(v.report.LastWords)
 	}
 
 	v.viewport.SetContent(content)
@@ -133,6 +216,58 @@ func (v *DetailView) updateContentInternal() {
 
 func (v DetailView) renderOverview() string {
 	var b strings.Builder
+	exit := v.report.ExitInfo()
+
+	if len(v.report.Findings) > 0 {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Render("Diagnosis"))
+		b.WriteString("\n\n")
+		for _, f := range v.report.Findings {
+			b.WriteString(lipgloss.NewStyle().Foreground(findingColor(f.Severity)).Render(f.String()))
+			b.WriteString("\n")
+			if f.Detail != "" {
+				b.WriteString(fmt.Sprintf("  %s\n", f.Detail))
+			}
+			if f.Action != "" {
+				b.WriteString(fmt.Sprintf("  → %s\n", f.Action))
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if sev := v.report.Severity; sev != nil {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Render("Severity"))
+		b.WriteString("\n\n")
+		b.WriteString(lipgloss.NewStyle().Foreground(severityColor(sev.Level)).Render(fmt.Sprintf("%s (score %d)", strings.ToUpper(sev.Level), sev.Score)))
+		b.WriteString("\n")
+		for _, reason := range sev.Reasons {
+			b.WriteString(fmt.Sprintf("  • %s\n", reason))
+		}
+		b.WriteString("\n")
+	}
+
+	if t := v.report.Triage; t != nil {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Render("Triage"))
+		b.WriteString("\n\n")
+		b.WriteString(lipgloss.NewStyle().Foreground(triageColor(t.Status)).Render(fmt.Sprintf("Status:        %s", v.report.TriageStatus())))
+		b.WriteString("\n")
+		if t.Assignee != "" {
+			b.WriteString(fmt.Sprintf("Assignee:      %s\n", t.Assignee))
+		}
+		if !t.UpdatedAt.IsZero() {
+			b.WriteString(fmt.Sprintf("Updated:       %s by %s\n", t.UpdatedAt.Format("2006-01-02 15:04:05"), t.UpdatedBy))
+		}
+		for _, l := range t.Links {
+			if l.Title != "" {
+				b.WriteString(fmt.Sprintf("Link:          %s %s\n", l.Title, l.URL))
+			} else {
+				b.WriteString(fmt.Sprintf("Link:          %s\n", l.URL))
+			}
+		}
+		for _, n := range t.Notes {
+			b.WriteString(fmt.Sprintf("  %s %s: %s\n", n.CreatedAt.Format("2006-01-02 15:04"), n.Author, n.Text))
+		}
+		b.WriteString("\n")
+	}
 
 	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Crash Details"))
 	b.WriteString("\n\n")
@@ -141,7 +276,9 @@ func (v DetailView) renderOverview() string {
 	b.WriteString(fmt.Sprintf("Pod:           %s\n", v.report.Crash.PodName))
 	b.WriteString(fmt.Sprintf("Container:     %s\n", v.report.Crash.ContainerName))
 	b.WriteString(fmt.Sprintf("Reason:        %s\n", v.report.Crash.Reason))
-	b.WriteString(fmt.Sprintf("Exit Code:     %d\n", v.report.Crash.ExitCode))
+	b.WriteString(fmt.Sprintf("Exit Code:     %s\n", exit.ExitLabel()))
+	b.WriteString(fmt.Sprintf("Cause:         %s\n", exit.Cause))
+	b.WriteString(fmt.Sprintf("Initiated By:  %s\n", exit.Initiator))
 	b.WriteString(fmt.Sprintf("Restart Count: %d\n", v.report.Crash.RestartCount))
 
 	if !v.report.Crash.StartedAt.IsZero() {
@@ -150,6 +287,23 @@ func (v DetailView) renderOverview() string {
 	if !v.report.Crash.FinishedAt.IsZero() {
 		b.WriteString(fmt.Sprintf("Finished:      %s\n", v.report.Crash.FinishedAt.Format("2006-01-02 15:04:05")))
 	}
+	if summary := v.report.TimelineSummary(); summary != "" {
+		b.WriteString(fmt.Sprintf("History:       %s\n", summary))
+	}
+
+	if len(v.report.OOMKills) > 0 {
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().Bold(true).Render("Kernel OOM Kills"))
+		b.WriteString("\n\n")
+		for _, k := range v.report.OOMKills {
+			scope := "node memory pressure"
+			if k.IsCgroupLimit() {
+				scope = "container memory limit"
+			}
+			b.WriteString(fmt.Sprintf("%s  pid %d (%s)  rss %s  %s\n",
+				k.Time.Format("15:04:05"), k.PID, k.Comm, formatKB(k.RSSKB()), scope))
+		}
+	}
 
 	if len(v.report.EnvVars) > 0 {
 		b.WriteString("\n")
@@ -163,6 +317,43 @@ func (v DetailView) renderOverview() string {
 	return b.String()
 }
 
+func findingColor(severity string) lipgloss.Color {
+	switch severity {
+	case domain.FindingCritical:
+		return lipgloss.Color("#FF5555")
+	case domain.FindingWarning:
+		return lipgloss.Color("#FFB86C")
+	default:
+		return lipgloss.Color("#8BE9FD")
+	}
+}
+
+func severityColor(level string) lipgloss.Color {
+	switch level {
+	case domain.SeverityCritical:
+		return lipgloss.Color("#FF5555")
+	case domain.SeverityHigh:
+		return lipgloss.Color("#FFB86C")
+	case domain.SeverityMedium:
+		return lipgloss.Color("#F1FA8C")
+	default:
+		return lipgloss.Color("#8BE9FD")
+	}
+}
+
+func triageColor(status string) lipgloss.Color {
+	switch status {
+	case domain.TriageAcknowledged:
+		return lipgloss.Color("#FFB86C")
+	case domain.TriageResolved:
+		return lipgloss.Color("#50FA7B")
+	case domain.TriageIgnored:
+		return lipgloss.Color("#6272A4")
+	default:
+		return lipgloss.Color("#FF5555")
+	}
+}
+
 func (v DetailView) renderLogs(logs []string) string {
 	if len(logs) == 0 {
 		return lipgloss.NewStyle().
@@ -204,3 +395,48 @@ func (v DetailView) renderEvents() string {
 
 	return b.String()
 }
+
+func (v DetailView) renderTimeline() string {
+	if len(v.report.Timeline) == 0 {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#6272A4")).
+			Render("No termination history recorded")
+	}
+
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render(v.report.TimelineSummary()))
+	b.WriteString("\n\n")
+
+	for i, t := range v.report.Timeline {
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFB86C"))
+		if t.Reason == "OOMKilled" {
+			style = style.Foreground(lipgloss.Color("#FF5555"))
+		}
+
+		reason := t.Reason
+		if reason == "" {
+			reason = "Terminated"
+		}
+
+		b.WriteString(style.Render(fmt.Sprintf("#%d %s (exit: %d)", i+1, reason, t.ExitCode)))
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("  Ran: %s | Finished: %s | Restarts: %d\n\n",
+			t.RunDuration(),
+			t.FinishedAt.Format("2006-01-02 15:04:05"),
+			t.RestartCount,
+		))
+	}
+
+	return b.String()
+}
+
+func formatKB(kb int64) string {
+	switch {
+	case kb >= 1024*1024:
+		return fmt.Sprintf("%.1fGi", float64(kb)/(1024*1024))
+	case kb >= 1024:
+		return fmt.Sprintf("%.1fMi", float64(kb)/1024)
+	default:
+		return fmt.Sprintf("%dKi", kb)
+	}
+}
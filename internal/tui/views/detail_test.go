@@ -0,0 +1,54 @@
+package views
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+func TestDetailView_TriageInput(t *testing.T) {
+	v := NewDetailView(domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})).SetSize(100, 30)
+	v, _ = v.StartInput(InputNote)
+	if !v.IsEditing() {
+		t.Fatal("IsEditing() = false after StartInput")
+	}
+
+	for _, r := range "rolled back" {
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	v, cmd := v.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if v.IsEditing() || cmd == nil {
+		t.Fatalf("enter should finish editing and emit the input")
+	}
+	if msg, ok := cmd().(TriageInputMsg); !ok || msg.Kind != InputNote || msg.Value != "rolled back" {
+		t.Errorf("msg = %+v, want the note", msg)
+	}
+
+	v, _ = v.StartInput(InputLink)
+	v, cmd = v.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if v.IsEditing() || cmd != nil {
+		t.Errorf("esc should cancel without emitting input")
+	}
+}
+
+func TestDetailView_RendersTriage(t *testing.T) {
+	report := domain.NewForensicReport(domain.PodCrash{Namespace: "default", PodName: "api"})
+	resolved := domain.TriageResolved
+	assignee := "alice"
+	_ = report.ApplyTriage(domain.TriageUpdate{
+		Status:   &resolved,
+		Assignee: &assignee,
+		Note:     "fixed by rollback",
+		Link:     &domain.TriageLink{Title: "OPS-1", URL: "https://tickets.example.com/OPS-1"},
+	}, domain.TriageActor{Name: "alice"}, time.Now())
+
+	view := NewDetailView(report).SetSize(120, 60).View()
+	for _, want := range []string{"Status: resolved → alice", "fixed by rollback", "https://tickets.example.com/OPS-1"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q", want)
+		}
+	}
+}
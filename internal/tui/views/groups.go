@@ -0,0 +1,90 @@
+package views
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+type groupItem struct {
+	group domain.CrashGroup
+}
+
+func (i groupItem) Title() string {
+	return fmt.Sprintf("%s/%s [%s] ×%d", i.group.Namespace, i.group.Workload, i.group.Container, i.group.Count)
+}
+
+func (i groupItem) Description() string {
+	signature := i.group.Signature
+	if signature == "" {
+		signature = fmt.Sprintf("%s (exit: %d)", i.group.Reason, i.group.ExitCode)
+	}
+	return fmt.Sprintf("%s - last %s", signature, i.group.LastSeen.Format(time.DateTime))
+}
+
+func (i groupItem) FilterValue() string {
+	return i.group.Workload + " " + i.group.Signature
+}
+
+type GroupListView struct {
+	list   list.Model
+	width  int
+	height int
+}
+
+func NewGroupListView(groups []domain.CrashGroup) GroupListView {
+	items := make([]list.Item, len(groups))
+	for i, g := range groups {
+		items[i] = groupItem{group: g}
+	}
+
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FF79C6")).
+		Bold(true)
+	delegate.Styles.SelectedDesc = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#BD93F9"))
+
+	l := list.New(items, delegate, 0, 0)
+	l.Title = "Crash Signatures"
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(true)
+
+	return GroupListView{list: l}
+}
+
+func (v GroupListView) Update(msg tea.Msg) (GroupListView, tea.Cmd) {
+	var cmd tea.Cmd
+	v.list, cmd = v.list.Update(msg)
+	return v, cmd
+}
+
+func (v GroupListView) View() string {
+	return v.list.View()
+}
+
+func (v GroupListView) SetSize(width, height int) GroupListView {
+	v.width = width
+	v.height = height
+	v.list.SetSize(width, height)
+	return v
+}
+
+func (v GroupListView) SelectedGroup() *domain.CrashGroup {
+	if item, ok := v.list.SelectedItem().(groupItem); ok {
+		return &item.group
+	}
+	return nil
+}
+
+func (v GroupListView) IsEmpty() bool {
+	return len(v.list.Items()) == 0
+}
+
+func (v GroupListView) IsFiltering() bool {
+	return v.list.FilterState() == list.Filtering
+}
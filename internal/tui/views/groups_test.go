@@ -0,0 +1,55 @@
+package views
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+func TestGroupItem(t *testing.T) {
+	item := groupItem{group: domain.CrashGroup{
+		Namespace: "payments",
+		Workload:  "Deployment/api",
+		Container: "app",
+		Reason:    "Error",
+		ExitCode:  2,
+		Signature: "panic: dial tcp <ip>: connection refused",
+		Count:     12,
+		LastSeen:  time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}}
+
+	if title := item.Title(); !strings.Contains(title, "payments/Deployment/api") || !strings.Contains(title, "×12") {
+		t.Errorf("Title() = %q", title)
+	}
+	if desc := item.Description(); !strings.Contains(desc, "connection refused") || !strings.Contains(desc, "2024-05-01 12:00:00") {
+		t.Errorf("Description() = %q", desc)
+	}
+
+	item.group.Signature = ""
+	if desc := item.Description(); !strings.Contains(desc, "Error (exit: 2)") {
+		t.Errorf("Description() without signature = %q", desc)
+	}
+}
+
+func TestGroupListView_SelectedGroup(t *testing.T) {
+	reports := []*domain.ForensicReport{
+		createTestReport("default", "api-1", "Error", 1),
+		createTestReport("default", "api-2", "Error", 1),
+	}
+
+	view := NewGroupListView(domain.GroupReports(reports))
+	if view.IsEmpty() {
+		t.Fatal("IsEmpty() should be false")
+	}
+
+	group := view.SelectedGroup()
+	if group == nil || group.Count != 2 {
+		t.Errorf("SelectedGroup() = %+v, want group of 2", group)
+	}
+
+	if NewGroupListView(nil).SelectedGroup() != nil {
+		t.Error("SelectedGroup() should be nil for an empty view")
+	}
+}
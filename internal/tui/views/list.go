@@ -2,15 +2,23 @@ package views
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
 )
 
+// SearchMsg is sent when a search typed into the list is submitted.
+type SearchMsg struct {
+	Query string
+}
+
 type crashItem struct {
-	report domain.ForensicReport
+	report    domain.ForensicReport
+	highlight string
 }
 
 func (i crashItem) Title() string {
@@ -18,11 +26,25 @@ func (i crashItem) Title() string {
 }
 
 func (i crashItem) Description() string {
-	return fmt.Sprintf("%s (exit: %d) - %d restarts",
+	desc := fmt.Sprintf("[%s] %s (exit: %d) - %d restarts",
+		i.report.SeverityLevel(),
 		i.report.Crash.Reason,
 		i.report.Crash.ExitCode,
 		i.report.Crash.RestartCount,
 	)
+
+	if status := i.report.TriageStatus(); status != domain.TriageNew {
+		desc += " · " + status
+		if i.report.Triage.Assignee != "" {
+			desc += " (" + i.report.Triage.Assignee + ")"
+		}
+	}
+
+	if i.highlight != "" {
+		desc += " · " + i.highlight
+	}
+
+	return desc
 }
 
 func (i crashItem) FilterValue() string {
@@ -30,9 +52,11 @@ func (i crashItem) FilterValue() string {
 }
 
 type ListView struct {
-	list   list.Model
-	width  int
-	height int
+	list      list.Model
+	search    textinput.Model
+	searching bool
+	width     int
+	height    int
 }
 
 func NewListView(reports []*domain.ForensicReport) ListView {
@@ -51,7 +75,8 @@ func NewListView(reports []*domain.ForensicReport) ListView {
 	l := list.New(items, delegate, 0, 0)
 	l.Title = "Crash Reports"
 	l.SetShowStatusBar(true)
-	l.SetFilteringEnabled(true)
+	// "/" searches the stored reports instead of filtering the loaded ones.
+	l.SetFilteringEnabled(false)
 
 	return ListView{list: l}
 }
@@ -62,14 +87,57 @@ func (v ListView) Init() tea.Cmd {
 
 func (v ListView) Update(msg tea.Msg) (ListView, tea.Cmd) {
 	var cmd tea.Cmd
+	if v.searching {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			switch key.String() {
+			case "enter":
+				search := SearchMsg{Query: strings.TrimSpace(v.search.Value())}
+				v.searching = false
+				if search.Query == "" {
+					return v, nil
+				}
+				return v, func() tea.Msg { return search }
+			case "esc":
+				v.searching = false
+				return v, nil
+			}
+		}
+		v.search, cmd = v.search.Update(msg)
+		return v, cmd
+	}
+
 	v.list, cmd = v.list.Update(msg)
 	return v, cmd
 }
 
 func (v ListView) View() string {
+	if v.searching {
+		return lipgloss.JoinVertical(lipgloss.Left, v.list.View(), v.search.View())
+	}
 	return v.list.View()
 }
 
+func (v ListView) StartSearch() (ListView, tea.Cmd) {
+	v.search = textinput.New()
+	v.search.Width = v.width - 12
+	v.search.Prompt = "Search: "
+	v.search.Placeholder = `"connection refused" -redis`
+	v.searching = true
+	return v, v.search.Focus()
+}
+
+// SetHighlights shows the matched line of each report, keyed by report ID,
+// next to its description.
+func (v ListView) SetHighlights(highlights map[string]string) ListView {
+	for i, item := range v.list.Items() {
+		if c, ok := item.(crashItem); ok {
+			c.highlight = highlights[c.report.ID]
+			v.list.SetItem(i, c)
+		}
+	}
+	return v
+}
+
 func (v ListView) SetSize(width, height int) ListView {
 	v.width = width
 	v.height = height
@@ -89,6 +157,25 @@ func (v ListView) AddReport(report domain.ForensicReport) ListView {
 	return v
 }
 
+func (v ListView) UpdateReport(report domain.ForensicReport) ListView {
+	for i, item := range v.list.Items() {
+		if c, ok := item.(crashItem); ok && c.report.ID == report.ID {
+			v.list.SetItem(i, crashItem{report: report, highlight: c.highlight})
+			break
+		}
+	}
+	return v
+}
+
+func (v ListView) SetTitle(title string) ListView {
+	v.list.Title = title
+	return v
+}
+
+func (v ListView) IsFiltering() bool {
+	return v.searching || v.list.FilterState() == list.Filtering
+}
+
 func (v ListView) IsEmpty() bool {
 	return len(v.list.Items()) == 0
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
 )
 
@@ -206,3 +207,28 @@ func BenchmarkCrashItem_Title(b *testing.B) {
 		item.Title()
 	}
 }
+
+func TestListView_Search(t *testing.T) {
+	report := createTestReport("default", "api", "Error", 1)
+	v := NewListView([]*domain.ForensicReport{report}).SetSize(100, 30)
+	v, _ = v.StartSearch()
+	if !v.IsFiltering() {
+		t.Fatal("IsFiltering() = false after StartSearch")
+	}
+
+	for _, r := range `"connection refused"` {
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	v, cmd := v.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if v.IsFiltering() || cmd == nil {
+		t.Fatalf("enter should finish the search input and emit the query")
+	}
+	if msg, ok := cmd().(SearchMsg); !ok || msg.Query != `"connection refused"` {
+		t.Errorf("msg = %+v, want the query", msg)
+	}
+
+	v = v.SetHighlights(map[string]string{report.ID: "dial tcp: connection refused"})
+	if desc := v.list.Items()[0].(crashItem).Description(); !strings.Contains(desc, "dial tcp: connection refused") {
+		t.Errorf("Description() = %q, want the highlight", desc)
+	}
+}
@@ -3,23 +3,36 @@ package watcher
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 )
 
+var cronJobSuffixRe = regexp.MustCompile(`-\d{8,}$`)
+
 type CrashHandler func(crash domain.PodCrash)
 
+type TerminationHandler func(crash domain.PodCrash)
+
+type PodObserver func(pod *corev1.Pod)
+
 type Watcher struct {
 	client            kubernetes.Interface
 	namespace         string
+	nodeName          string
 	factory           informers.SharedInformerFactory
 	handler           CrashHandler
+	terminations      TerminationHandler
+	observer          PodObserver
 	reasons           map[string]bool
 	lastNotifications map[string]time.Time
 	dedupTTL          time.Duration
@@ -34,6 +47,12 @@ func WithNamespace(ns string) Option {
 	}
 }
 
+func WithNodeName(node string) Option {
+	return func(w *Watcher) {
+		w.nodeName = node
+	}
+}
+
 func WithReasons(reasons []string) Option {
 	return func(w *Watcher) {
 		for _, r := range reasons {
@@ -48,6 +67,18 @@ func WithDedupTTL(ttl time.Duration) Option {
 	}
 }
 
+func WithTerminationHandler(handler TerminationHandler) Option {
+	return func(w *Watcher) {
+		w.terminations = handler
+	}
+}
+
+func WithPodObserver(observer PodObserver) Option {
+	return func(w *Watcher) {
+		w.observer = observer
+	}
+}
+
 func New(client kubernetes.Interface, handler CrashHandler, opts ...Option) *Watcher {
 	w := &Watcher{
 		client:  client,
@@ -69,17 +100,18 @@ func New(client kubernetes.Interface, handler CrashHandler, opts ...Option) *Wat
 }
 
 func (w *Watcher) Start(ctx context.Context) error {
-	var factory informers.SharedInformerFactory
-
+	var factoryOpts []informers.SharedInformerOption
 	if w.namespace != "" {
-		factory = informers.NewSharedInformerFactoryWithOptions(
-			w.client,
-			0,
-			informers.WithNamespace(w.namespace),
-		)
-	} else {
-		factory = informers.NewSharedInformerFactory(w.client, 0)
+		factoryOpts = append(factoryOpts, informers.WithNamespace(w.namespace))
 	}
+	if w.nodeName != "" {
+		selector := fields.OneTermEqualSelector("spec.nodeName", w.nodeName).String()
+		factoryOpts = append(factoryOpts, informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = selector
+		}))
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(w.client, 0, factoryOpts...)
 
 	w.factory = factory
 	podInformer := factory.Core().V1().Pods().Informer()
@@ -90,6 +122,7 @@ func (w *Watcher) Start(ctx context.Context) error {
 			if !ok {
 				return
 			}
+			w.observe(pod)
 			w.checkPodOnAdd(pod)
 		},
 		UpdateFunc: w.onUpdate,
@@ -138,9 +171,16 @@ func (w *Watcher) onUpdate(oldObj, newObj interface{}) {
 		return
 	}
 
+	w.observe(newPod)
 	w.detectCrashes(oldPod, newPod)
 }
 
+func (w *Watcher) observe(pod *corev1.Pod) {
+	if w.observer != nil {
+		w.observer(pod)
+	}
+}
+
 func (w *Watcher) detectCrashes(oldPod, newPod *corev1.Pod) {
 	for i, cs := range newPod.Status.ContainerStatuses {
 		var oldStatus *corev1.ContainerStatus
@@ -148,6 +188,8 @@ func (w *Watcher) detectCrashes(oldPod, newPod *corev1.Pod) {
 			oldStatus = &oldPod.Status.ContainerStatuses[i]
 		}
 
+		w.observeTermination(newPod, cs, oldStatus)
+
 		if crash := w.checkContainerCrash(newPod, cs, oldStatus); crash != nil {
 			if w.shouldNotify(crash) {
 				w.handler(*crash)
@@ -196,7 +238,11 @@ func (w *Watcher) createCrashFromTerminated(pod *corev1.Pod, cs corev1.Container
 	return &domain.PodCrash{
 		Namespace:     pod.Namespace,
 		PodName:       pod.Name,
+		PodUID:        string(pod.UID),
+		NodeName:      pod.Spec.NodeName,
+		Workload:      workloadOf(pod),
 		ContainerName: cs.Name,
+		ContainerID:   containerID(terminated.ContainerID),
 		ExitCode:      terminated.ExitCode,
 		Reason:        reason,
 		Signal:        terminated.Signal,
@@ -220,7 +266,11 @@ func (w *Watcher) createCrashFromLastTerminated(pod *corev1.Pod, cs corev1.Conta
 	return &domain.PodCrash{
 		Namespace:     pod.Namespace,
 		PodName:       pod.Name,
+		PodUID:        string(pod.UID),
+		NodeName:      pod.Spec.NodeName,
+		Workload:      workloadOf(pod),
 		ContainerName: cs.Name,
+		ContainerID:   containerID(terminated.ContainerID),
 		ExitCode:      terminated.ExitCode,
 		Reason:        reason,
 		Signal:        terminated.Signal,
@@ -238,12 +288,16 @@ func (w *Watcher) createCrashLoopBackOff(pod *corev1.Pod, cs corev1.ContainerSta
 	crash := &domain.PodCrash{
 		Namespace:     pod.Namespace,
 		PodName:       pod.Name,
+		PodUID:        string(pod.UID),
+		NodeName:      pod.Spec.NodeName,
+		Workload:      workloadOf(pod),
 		ContainerName: cs.Name,
 		Reason:        "CrashLoopBackOff",
 		RestartCount:  cs.RestartCount,
 	}
 
 	if cs.LastTerminationState.Terminated != nil {
+		crash.ContainerID = containerID(cs.LastTerminationState.Terminated.ContainerID)
 		crash.ExitCode = cs.LastTerminationState.Terminated.ExitCode
 		crash.Signal = cs.LastTerminationState.Terminated.Signal
 		crash.StartedAt = cs.LastTerminationState.Terminated.StartedAt.Time
@@ -275,6 +329,8 @@ func (w *Watcher) shouldNotify(crash *domain.PodCrash) bool {
 
 func (w *Watcher) checkPodOnAdd(pod *corev1.Pod) {
 	for _, cs := range pod.Status.ContainerStatuses {
+		w.observeTermination(pod, cs, nil)
+
 		if crash := w.checkContainerCrash(pod, cs, nil); crash != nil {
 			if w.shouldNotify(crash) {
 				w.handler(*crash)
@@ -282,3 +338,72 @@ func (w *Watcher) checkPodOnAdd(pod *corev1.Pod) {
 		}
 	}
 }
+
+func (w *Watcher) observeTermination(pod *corev1.Pod, cs corev1.ContainerStatus, oldStatus *corev1.ContainerStatus) {
+	if w.terminations == nil {
+		return
+	}
+
+	if cs.LastTerminationState.Terminated != nil {
+		if oldStatus == nil ||
+			oldStatus.LastTerminationState.Terminated == nil ||
+			cs.RestartCount > oldStatus.RestartCount {
+			w.terminations(*terminationCrash(pod, cs, cs.LastTerminationState.Terminated))
+		}
+	}
+
+	if cs.State.Terminated != nil {
+		if oldStatus == nil || oldStatus.State.Terminated == nil {
+			w.terminations(*terminationCrash(pod, cs, cs.State.Terminated))
+		}
+	}
+}
+
+func terminationCrash(pod *corev1.Pod, cs corev1.ContainerStatus, terminated *corev1.ContainerStateTerminated) *domain.PodCrash {
+	return &domain.PodCrash{
+		Namespace:     pod.Namespace,
+		PodName:       pod.Name,
+		PodUID:        string(pod.UID),
+		NodeName:      pod.Spec.NodeName,
+		Workload:      workloadOf(pod),
+		ContainerName: cs.Name,
+		ContainerID:   containerID(terminated.ContainerID),
+		ExitCode:      terminated.ExitCode,
+		Reason:        terminated.Reason,
+		Signal:        terminated.Signal,
+		RestartCount:  cs.RestartCount,
+		StartedAt:     terminated.StartedAt.Time,
+		FinishedAt:    terminated.FinishedAt.Time,
+	}
+}
+
+func containerID(raw string) string {
+	if i := strings.Index(raw, "://"); i >= 0 {
+		return raw[i+3:]
+	}
+	return raw
+}
+
+func workloadOf(pod *corev1.Pod) string {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return ""
+	}
+
+	switch owner.Kind {
+	case "ReplicaSet":
+		if hash := pod.Labels["pod-template-hash"]; hash != "" {
+			if name, ok := strings.CutSuffix(owner.Name, "-"+hash); ok {
+				return "Deployment/" + name
+			}
+		}
+		return "ReplicaSet/" + owner.Name
+	case "Job":
+		if name := cronJobSuffixRe.ReplaceAllString(owner.Name, ""); name != owner.Name {
+			return "CronJob/" + name
+		}
+		return "Job/" + owner.Name
+	default:
+		return owner.Kind + "/" + owner.Name
+	}
+}
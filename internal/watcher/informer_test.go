@@ -60,6 +60,17 @@ func TestWithNamespace(t *testing.T) {
 	}
 }
 
+func TestWithNodeName(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	handler := func(crash domain.PodCrash) {}
+
+	watcher := New(client, handler, WithNodeName("node-1"))
+
+	if watcher.nodeName != "node-1" {
+		t.Errorf("nodeName = %v, want node-1", watcher.nodeName)
+	}
+}
+
 func TestWithReasons(t *testing.T) {
 	client := fake.NewSimpleClientset()
 	handler := func(crash domain.PodCrash) {}
@@ -278,6 +289,69 @@ func TestWatcher_detectCrashes(t *testing.T) {
 	}
 }
 
+func TestWatcher_detectCrashes_ObservesTerminationsBeyondDedup(t *testing.T) {
+	var mu sync.Mutex
+	var crashes, terminations []domain.PodCrash
+
+	client := fake.NewSimpleClientset()
+	handler := func(crash domain.PodCrash) {
+		mu.Lock()
+		crashes = append(crashes, crash)
+		mu.Unlock()
+	}
+	watcher := New(client, handler, WithTerminationHandler(func(crash domain.PodCrash) {
+		mu.Lock()
+		terminations = append(terminations, crash)
+		mu.Unlock()
+	}))
+
+	podWithRestart := func(restarts int32, finished time.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "loop-pod", Namespace: "default"},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:         "main",
+					RestartCount: restarts,
+					State: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{},
+					},
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode:   1,
+							Reason:     "Error",
+							StartedAt:  metav1.NewTime(finished.Add(-3 * time.Second)),
+							FinishedAt: metav1.NewTime(finished),
+						},
+					},
+				}},
+			},
+		}
+	}
+
+	base := time.Now()
+	pods := []*corev1.Pod{
+		podWithRestart(1, base),
+		podWithRestart(2, base.Add(10*time.Second)),
+		podWithRestart(3, base.Add(20*time.Second)),
+	}
+
+	watcher.checkPodOnAdd(pods[0])
+	watcher.detectCrashes(pods[0], pods[1])
+	watcher.detectCrashes(pods[1], pods[2])
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(crashes) != 1 {
+		t.Errorf("Expected 1 deduplicated crash, got %d", len(crashes))
+	}
+	if len(terminations) != 3 {
+		t.Fatalf("Expected 3 observed terminations, got %d", len(terminations))
+	}
+	if terminations[2].RestartCount != 3 {
+		t.Errorf("RestartCount = %v, want 3", terminations[2].RestartCount)
+	}
+}
+
 func TestWatcher_Start_ContextCancellation(t *testing.T) {
 	client := fake.NewSimpleClientset()
 	handler := func(crash domain.PodCrash) {}
@@ -411,3 +485,37 @@ func BenchmarkWatcher_detectCrashes(b *testing.B) {
 		watcher.detectCrashes(oldPod, newPod)
 	}
 }
+
+func TestWorkloadOf(t *testing.T) {
+	controller := true
+	owned := func(kind, name string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Labels: labels,
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: kind, Name: name, Controller: &controller},
+			},
+		}}
+	}
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want string
+	}{
+		{"deployment", owned("ReplicaSet", "api-7d9f8b6c5", map[string]string{"pod-template-hash": "7d9f8b6c5"}), "Deployment/api"},
+		{"bare replicaset", owned("ReplicaSet", "api", nil), "ReplicaSet/api"},
+		{"statefulset", owned("StatefulSet", "db", nil), "StatefulSet/db"},
+		{"daemonset", owned("DaemonSet", "agent", nil), "DaemonSet/agent"},
+		{"cronjob", owned("Job", "backup-28930560", nil), "CronJob/backup"},
+		{"job", owned("Job", "migrate", nil), "Job/migrate"},
+		{"no owner", &corev1.Pod{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := workloadOf(tt.pod); got != tt.want {
+				t.Errorf("workloadOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
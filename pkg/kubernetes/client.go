@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -29,6 +30,20 @@ func NewClient(cfg ClientConfig) (kubernetes.Interface, error) {
 	return client, nil
 }
 
+func NewDynamicClient(cfg ClientConfig) (dynamic.Interface, error) {
+	config, err := buildConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build config: %w", err)
+	}
+
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	return client, nil
+}
+
 func buildConfig(cfg ClientConfig) (*rest.Config, error) {
 	if config, err := rest.InClusterConfig(); err == nil {
 		return config, nil
@@ -0,0 +1,12 @@
+package schema
+
+import _ "embed"
+
+const ReportContentType = "application/schema+json"
+
+//go:embed report.v2.schema.json
+var reportV2 []byte
+
+func Report() []byte {
+	return reportV2
+}
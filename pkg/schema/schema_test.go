@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kadirbelkuyu/kubecrsh/internal/domain"
+)
+
+func TestReport_ValidJSON(t *testing.T) {
+	var doc map[string]any
+	if err := json.Unmarshal(Report(), &doc); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	version := doc["properties"].(map[string]any)["schemaVersion"].(map[string]any)["const"]
+	if version != float64(domain.SchemaVersion) {
+		t.Errorf("schemaVersion const = %v, want %d", version, domain.SchemaVersion)
+	}
+}
+
+func TestReport_MatchesDomainReport(t *testing.T) {
+	now := time.Now()
+	report := domain.NewForensicReport(domain.PodCrash{
+		Namespace:     "payments",
+		PodName:       "api-7d9f8b6c5-abcde",
+		PodUID:        "uid",
+		NodeName:      "node-1",
+		ContainerName: "app",
+		ContainerID:   "containerd://abc",
+		Workload:      "Deployment/api",
+		ExitCode:      137,
+		Reason:        "OOMKilled",
+		Signal:        9,
+		RestartCount:  3,
+		StartedAt:     now.Add(-time.Hour),
+		FinishedAt:    now,
+	})
+	report.SetLogs([]string{"starting"})
+	report.SetPreviousLogs([]string{"panic: boom"})
+	report.SetLastWords([]string{"allocating"})
+	report.AddEvent(*domain.NewEvent("Warning", "BackOff", "Back-off restarting failed container"))
+	report.SetEnvVar("MODE", "prod")
+	report.AddWarning("logs truncated")
+	report.SetTimeline([]domain.Termination{{StartedAt: now, FinishedAt: now, ExitCode: 137, Signal: 9, Reason: "OOMKilled", RestartCount: 2}})
+	report.SetOOMKills([]domain.OOMKill{{Time: now, PID: 42, Comm: "app", CgroupPath: "/kubepods", Constraint: domain.ConstraintMemcg, AnonRSSKB: 1024, OOMScoreAdj: 999, PodUID: "uid", ContainerID: "abc"}})
+	report.SetFindings([]domain.Finding{{Rule: "memory-limit", Severity: domain.FindingCritical, Confidence: 0.9, Title: "Memory limit reached", Detail: "d", Action: "a", Evidence: []string{"e"}}})
+	report.UpdateFingerprint()
+	report.SetSeverity(domain.ClassifySeverity(report, domain.SeverityContext{Criticality: "high", RecentCrashes: 3}))
+	acked := domain.TriageAcknowledged
+	assignee := "alice"
+	if err := report.ApplyTriage(domain.TriageUpdate{
+		Status:   &acked,
+		Assignee: &assignee,
+		Note:     "looking into it",
+		Link:     &domain.TriageLink{Title: "OPS-1", URL: "https://tickets.example.com/OPS-1"},
+	}, domain.TriageActor{Name: "api-token", Hint: "alice", Source: "api"}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	report.Origin = &domain.ReportOrigin{Cluster: "prod-eu", OriginalID: "abc", Source: "bundle.tar.gz", ImportedAt: now}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema, doc map[string]any
+	if err := json.Unmarshal(Report(), &schema); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	v := validator{defs: schema["$defs"].(map[string]any)}
+	if errs := v.validate("$", schema, doc); len(errs) > 0 {
+		t.Errorf("report does not match the schema:\n%s", strings.Join(errs, "\n"))
+	}
+
+	// Every field of the report must be declared by the schema, so a new
+	// field without a schema update fails here.
+	delete(doc, "schemaVersion")
+	doc["unknown"] = true
+	if errs := v.validate("$", schema, doc); len(errs) != 2 {
+		t.Errorf("expected missing schemaVersion and unknown field errors, got %v", errs)
+	}
+}
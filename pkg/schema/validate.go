@@ -0,0 +1,156 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// maxValidationErrors caps the problems listed by ValidateReport.
+const maxValidationErrors = 5
+
+var reportSchema = sync.OnceValues(func() (map[string]any, error) {
+	var schema map[string]any
+	if err := json.Unmarshal(reportV2, &schema); err != nil {
+		return nil, fmt.Errorf("invalid report schema: %w", err)
+	}
+	return schema, nil
+})
+
+// ValidateReport checks a report document of the current schema version
+// against the published report schema. It covers the keywords the schema
+// uses, not all of JSON Schema.
+func ValidateReport(data []byte) error {
+	schema, err := reportSchema()
+	if err != nil {
+		return err
+	}
+
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to decode report: %w", err)
+	}
+
+	defs, _ := schema["$defs"].(map[string]any)
+	errs := validator{defs: defs}.validate("$", schema, doc)
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs)
+	if len(errs) > maxValidationErrors {
+		errs = append(errs[:maxValidationErrors], fmt.Sprintf("and %d more", len(errs)-maxValidationErrors))
+	}
+	return fmt.Errorf("report does not match the schema: %s", strings.Join(errs, "; "))
+}
+
+type validator struct {
+	defs map[string]any
+}
+
+func (v validator) validate(path string, schema map[string]any, value any) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		return v.validate(path, v.defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any), value)
+	}
+
+	var errs []string
+	if c, ok := schema["const"]; ok && c != value {
+		errs = append(errs, fmt.Sprintf("%s: %v != const %v", path, value, c))
+	}
+	if enum, ok := schema["enum"].([]any); ok && !contains(enum, value) {
+		errs = append(errs, fmt.Sprintf("%s: %v not in %v", path, value, enum))
+	}
+	if t, ok := schema["type"]; ok && !matchesType(t, value) {
+		return append(errs, fmt.Sprintf("%s: %v is not of type %v", path, value, t))
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := value[name.(string)]; !ok {
+				errs = append(errs, fmt.Sprintf("%s: missing required %s", path, name))
+			}
+		}
+		for name, field := range value {
+			if prop, ok := props[name]; ok {
+				errs = append(errs, v.validate(path+"."+name, prop.(map[string]any), field)...)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					errs = append(errs, fmt.Sprintf("%s: unexpected property %s", path, name))
+				}
+			case map[string]any:
+				errs = append(errs, v.validate(path+"."+name, extra, field)...)
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				errs = append(errs, v.validate(fmt.Sprintf("%s[%d]", path, i), items, item)...)
+			}
+		}
+	case string:
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %q is not a date-time", path, value))
+			}
+		}
+		if n, ok := schema["minLength"].(float64); ok && float64(utf8.RuneCountInString(value)) < n {
+			errs = append(errs, fmt.Sprintf("%s: %q is shorter than %v", path, value, n))
+		}
+		if n, ok := schema["maxLength"].(float64); ok && float64(utf8.RuneCountInString(value)) > n {
+			errs = append(errs, fmt.Sprintf("%s: %q is longer than %v", path, value, n))
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err != nil || !re.MatchString(value) {
+				errs = append(errs, fmt.Sprintf("%s: %q does not match %s", path, value, pattern))
+			}
+		}
+	}
+
+	return errs
+}
+
+func matchesType(t any, value any) bool {
+	if types, ok := t.([]any); ok {
+		for _, t := range types {
+			if matchesType(t, value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case string:
+		return t == "string"
+	case float64:
+		return t == "number" || (t == "integer" && value == float64(int64(value)))
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	}
+	return false
+}
+
+func contains(values []any, v any) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
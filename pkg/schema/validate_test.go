@@ -0,0 +1,34 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateReport(t *testing.T) {
+	valid := `{"schemaVersion":2,"id":"abc","collectedAt":"2024-01-01T00:00:00Z",` +
+		`"crash":{"namespace":"default","podName":"api","containerName":"main","exitCode":1,"reason":"Error"}}`
+	if err := ValidateReport([]byte(valid)); err != nil {
+		t.Fatalf("ValidateReport() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		from string
+		to   string
+	}{
+		{"path in id", `"id":"abc"`, `"id":"../abc"`},
+		{"unknown field", `"id":"abc"`, `"id":"abc","extra":true`},
+		{"missing crash field", `"reason":"Error"`, `"signal":9`},
+		{"wrong type", `"exitCode":1`, `"exitCode":"1"`},
+		{"bad date", `"2024-01-01T00:00:00Z"`, `"yesterday"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateReport([]byte(strings.Replace(valid, tt.from, tt.to, 1)))
+			if err == nil || !strings.Contains(err.Error(), "does not match the schema") {
+				t.Errorf("ValidateReport() error = %v", err)
+			}
+		})
+	}
+}